@@ -0,0 +1,10 @@
+package db
+
+import "embed"
+
+/*
+Migrations contains the goose migrations in ./migrations, they are embedded in the binary so the
+database can be migrated when the application connects to it
+*/
+//go:embed migrations/*.sql
+var Migrations embed.FS
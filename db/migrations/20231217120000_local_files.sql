@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE local_files (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    path TEXT UNIQUE,
+    size INTEGER,
+    modified_at DATETIME,
+    content_hash TEXT,
+    title TEXT,
+    artist TEXT,
+    album TEXT,
+    genre TEXT,
+    duration REAL,
+    fingerprint TEXT,
+    missing BOOLEAN
+);
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE local_files;
+-- +goose StatementEnd
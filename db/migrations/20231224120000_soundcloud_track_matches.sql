@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE soundcloud_tracks ADD COLUMN duration REAL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE soundcloud_track_matches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    soundcloud_track_id INTEGER UNIQUE,
+    source TEXT,
+    path TEXT,
+    confidence REAL,
+    reason TEXT,
+    CONSTRAINT fk_track_matches_soundcloud_track FOREIGN KEY (
+        soundcloud_track_id
+    )
+    REFERENCES soundcloud_tracks (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE soundcloud_track_matches;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE soundcloud_tracks DROP COLUMN duration;
+-- +goose StatementEnd
@@ -0,0 +1,95 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE soundcloud_playlists_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    external_id INTEGER,
+    name TEXT,
+    search_url TEXT,
+    permalink_url TEXT,
+    source_type TEXT NOT NULL DEFAULT 'playlist',
+    UNIQUE (source_type, external_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO soundcloud_playlists_new (
+    id,
+    created_at,
+    updated_at,
+    external_id,
+    name,
+    search_url,
+    permalink_url
+)
+SELECT
+    id,
+    created_at,
+    updated_at,
+    external_id,
+    name,
+    search_url,
+    permalink_url
+FROM soundcloud_playlists;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE soundcloud_playlists;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE soundcloud_playlists_new RENAME TO soundcloud_playlists;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM soundcloud_playlist_tracks
+WHERE soundcloud_playlist_id IN (
+    SELECT id
+    FROM soundcloud_playlists
+    WHERE source_type != 'playlist'
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE soundcloud_playlists_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    external_id INTEGER UNIQUE,
+    name TEXT,
+    search_url TEXT,
+    permalink_url TEXT
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO soundcloud_playlists_old (
+    id,
+    created_at,
+    updated_at,
+    external_id,
+    name,
+    search_url,
+    permalink_url
+)
+SELECT
+    id,
+    created_at,
+    updated_at,
+    external_id,
+    name,
+    search_url,
+    permalink_url
+FROM soundcloud_playlists
+WHERE source_type = 'playlist';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE soundcloud_playlists;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE soundcloud_playlists_old RENAME TO soundcloud_playlists;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE soundcloud_playlist_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    soundcloud_playlist_id INTEGER,
+    soundcloud_track_id INTEGER,
+    change_type TEXT,
+    old_value TEXT,
+    new_value TEXT,
+    CONSTRAINT fk_playlist_changes_soundcloud_playlist FOREIGN KEY (
+        soundcloud_playlist_id
+    )
+    REFERENCES soundcloud_playlists (id),
+    CONSTRAINT fk_playlist_changes_soundcloud_track FOREIGN KEY (
+        soundcloud_track_id
+    )
+    REFERENCES soundcloud_tracks (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE soundcloud_playlist_changes;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE soundcloud_tracks ADD COLUMN purchase_category TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE soundcloud_tracks DROP COLUMN purchase_category;
+-- +goose StatementEnd
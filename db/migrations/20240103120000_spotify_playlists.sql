@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE spotify_playlists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    external_id TEXT UNIQUE,
+    name TEXT,
+    owner TEXT,
+    search_url TEXT,
+    permalink_url TEXT
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE spotify_tracks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    external_id TEXT UNIQUE,
+    name TEXT,
+    permalink_url TEXT,
+    artists TEXT,
+    album TEXT,
+    isrc TEXT,
+    duration REAL,
+    removed_from_playlist BOOLEAN
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE spotify_playlist_tracks (
+    spotify_track_id    INTEGER,
+    spotify_playlist_id INTEGER,
+    PRIMARY KEY (
+        spotify_track_id,
+        spotify_playlist_id
+    ),
+    CONSTRAINT fk_playlist_tracks_spotify_track FOREIGN KEY (
+        spotify_track_id
+    )
+    REFERENCES spotify_tracks (id),
+    CONSTRAINT fk_playlist_tracks_spotify_playlist FOREIGN KEY (
+        spotify_playlist_id
+    )
+    REFERENCES spotify_playlists (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE spotify_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE spotify_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE spotify_playlists;
+-- +goose StatementEnd
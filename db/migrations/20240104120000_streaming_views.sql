@@ -0,0 +1,107 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE VIEW streaming_playlists AS
+SELECT
+    'soundcloud' AS platform,
+    id,
+    created_at,
+    updated_at,
+    CAST(external_id AS TEXT) AS external_id,
+    source_type,
+    name,
+    NULL AS owner,
+    search_url,
+    permalink_url
+FROM soundcloud_playlists
+UNION ALL
+SELECT
+    'spotify' AS platform,
+    id,
+    created_at,
+    updated_at,
+    external_id,
+    'playlist' AS source_type,
+    name,
+    owner,
+    search_url,
+    permalink_url
+FROM spotify_playlists;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIEW streaming_tracks AS
+SELECT
+    'soundcloud' AS platform,
+    id,
+    created_at,
+    updated_at,
+    CAST(external_id AS TEXT) AS external_id,
+    name,
+    CASE
+        WHEN coalesce(nullif(publisher_artist, ''), nullif(sound_cloud_user, '')) IS NULL THEN json_array()
+        ELSE json_array(coalesce(nullif(publisher_artist, ''), sound_cloud_user))
+    END AS artists,
+    NULL AS album,
+    genre,
+    NULL AS isrc,
+    permalink_url,
+    artwork_url,
+    purchase_url,
+    purchase_title,
+    purchase_category,
+    has_downloads_left AS downloadable,
+    local_path,
+    removed_from_playlist,
+    duration
+FROM soundcloud_tracks
+UNION ALL
+SELECT
+    'spotify' AS platform,
+    id,
+    created_at,
+    updated_at,
+    external_id,
+    name,
+    artists,
+    album,
+    NULL AS genre,
+    isrc,
+    permalink_url,
+    NULL AS artwork_url,
+    NULL AS purchase_url,
+    NULL AS purchase_title,
+    NULL AS purchase_category,
+    FALSE AS downloadable,
+    NULL AS local_path,
+    removed_from_playlist,
+    duration
+FROM spotify_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIEW streaming_playlist_tracks AS
+SELECT
+    'soundcloud' AS platform,
+    soundcloud_playlist_id AS playlist_id,
+    soundcloud_track_id AS track_id
+FROM soundcloud_playlist_tracks
+UNION ALL
+SELECT
+    'spotify' AS platform,
+    spotify_playlist_id AS playlist_id,
+    spotify_track_id AS track_id
+FROM spotify_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW streaming_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP VIEW streaming_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP VIEW streaming_playlists;
+-- +goose StatementEnd
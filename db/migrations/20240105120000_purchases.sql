@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE purchases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    store TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    order_id TEXT,
+    purchased_at DATETIME,
+    kind TEXT,
+    artist TEXT,
+    title TEXT,
+    album TEXT,
+    label TEXT,
+    isrc TEXT,
+    url TEXT,
+    duration REAL,
+    local_path TEXT,
+    match_confidence REAL,
+    UNIQUE (store, external_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE purchases;
+-- +goose StatementEnd
@@ -0,0 +1,128 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE soundcloud_playlist_tracks ADD COLUMN position INTEGER;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE soundcloud_playlist_tracks ADD COLUMN added_at DATETIME;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE soundcloud_playlist_tracks ADD COLUMN removed_at DATETIME;
+-- +goose StatementEnd
+
+-- tracks were linked in playlist order, so the order they were inserted in is the best guess at
+-- their position. removed_from_playlist was set on the track, so it's copied to each of its playlists
+-- +goose StatementBegin
+UPDATE soundcloud_playlist_tracks
+SET position = (
+        SELECT count(*)
+        FROM soundcloud_playlist_tracks o
+        WHERE o.soundcloud_playlist_id = soundcloud_playlist_tracks.soundcloud_playlist_id
+            AND o.rowid < soundcloud_playlist_tracks.rowid
+    ),
+    added_at = (
+        SELECT t.created_at
+        FROM soundcloud_tracks t
+        WHERE t.id = soundcloud_playlist_tracks.soundcloud_track_id
+    ),
+    removed_at = (
+        SELECT t.updated_at
+        FROM soundcloud_tracks t
+        WHERE t.id = soundcloud_playlist_tracks.soundcloud_track_id
+            AND t.removed_from_playlist
+    );
+-- +goose StatementEnd
+
+-- a row for each time a track was in a playlist, removed_at is NULL while it still is
+-- +goose StatementBegin
+CREATE TABLE soundcloud_playlist_track_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    soundcloud_playlist_id INTEGER,
+    soundcloud_track_id INTEGER,
+    added_at DATETIME,
+    removed_at DATETIME,
+    CONSTRAINT fk_playlist_track_history_soundcloud_playlist FOREIGN KEY (
+        soundcloud_playlist_id
+    )
+    REFERENCES soundcloud_playlists (id),
+    CONSTRAINT fk_playlist_track_history_soundcloud_track FOREIGN KEY (
+        soundcloud_track_id
+    )
+    REFERENCES soundcloud_tracks (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO soundcloud_playlist_track_history (
+    soundcloud_playlist_id,
+    soundcloud_track_id,
+    added_at,
+    removed_at
+)
+SELECT
+    soundcloud_playlist_id,
+    soundcloud_track_id,
+    added_at,
+    removed_at
+FROM soundcloud_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP VIEW streaming_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIEW streaming_playlist_tracks AS
+SELECT
+    'soundcloud' AS platform,
+    soundcloud_playlist_id AS playlist_id,
+    soundcloud_track_id AS track_id,
+    position,
+    removed_at
+FROM soundcloud_playlist_tracks
+UNION ALL
+SELECT
+    'spotify' AS platform,
+    spotify_playlist_id AS playlist_id,
+    spotify_track_id AS track_id,
+    NULL AS position,
+    NULL AS removed_at
+FROM spotify_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW streaming_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIEW streaming_playlist_tracks AS
+SELECT
+    'soundcloud' AS platform,
+    soundcloud_playlist_id AS playlist_id,
+    soundcloud_track_id AS track_id
+FROM soundcloud_playlist_tracks
+UNION ALL
+SELECT
+    'spotify' AS platform,
+    spotify_playlist_id AS playlist_id,
+    spotify_track_id AS track_id
+FROM spotify_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE soundcloud_playlist_track_history;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE soundcloud_playlist_tracks DROP COLUMN removed_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE soundcloud_playlist_tracks DROP COLUMN added_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE soundcloud_playlist_tracks DROP COLUMN position;
+-- +goose StatementEnd
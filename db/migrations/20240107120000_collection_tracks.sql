@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE collection_tracks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    platform TEXT NOT NULL,
+    path TEXT NOT NULL,
+    title TEXT,
+    artist TEXT,
+    album TEXT,
+    genre TEXT,
+    label TEXT,
+    comments TEXT,
+    key TEXT,
+    bpm REAL,
+    duration REAL,
+    UNIQUE (platform, path)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE collection_tracks;
+-- +goose StatementEnd
@@ -0,0 +1,94 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE spotify_playlist_tracks ADD COLUMN position INTEGER;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE spotify_playlist_tracks ADD COLUMN added_at DATETIME;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE spotify_playlist_tracks ADD COLUMN removed_at DATETIME;
+-- +goose StatementEnd
+
+-- as with soundcloud_playlist_tracks, the order tracks were linked in is the best guess at their
+-- position, and removed_from_playlist on the track is copied to each of its playlists
+-- +goose StatementBegin
+UPDATE spotify_playlist_tracks
+SET position = (
+        SELECT count(*)
+        FROM spotify_playlist_tracks o
+        WHERE o.spotify_playlist_id = spotify_playlist_tracks.spotify_playlist_id
+            AND o.rowid < spotify_playlist_tracks.rowid
+    ),
+    added_at = (
+        SELECT t.created_at
+        FROM spotify_tracks t
+        WHERE t.id = spotify_playlist_tracks.spotify_track_id
+    ),
+    removed_at = (
+        SELECT t.updated_at
+        FROM spotify_tracks t
+        WHERE t.id = spotify_playlist_tracks.spotify_track_id
+            AND t.removed_from_playlist
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP VIEW streaming_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIEW streaming_playlist_tracks AS
+SELECT
+    'soundcloud' AS platform,
+    soundcloud_playlist_id AS playlist_id,
+    soundcloud_track_id AS track_id,
+    position,
+    removed_at
+FROM soundcloud_playlist_tracks
+UNION ALL
+SELECT
+    'spotify' AS platform,
+    spotify_playlist_id AS playlist_id,
+    spotify_track_id AS track_id,
+    position,
+    removed_at
+FROM spotify_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW streaming_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIEW streaming_playlist_tracks AS
+SELECT
+    'soundcloud' AS platform,
+    soundcloud_playlist_id AS playlist_id,
+    soundcloud_track_id AS track_id,
+    position,
+    removed_at
+FROM soundcloud_playlist_tracks
+UNION ALL
+SELECT
+    'spotify' AS platform,
+    spotify_playlist_id AS playlist_id,
+    spotify_track_id AS track_id,
+    NULL AS position,
+    NULL AS removed_at
+FROM spotify_playlist_tracks;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE spotify_playlist_tracks DROP COLUMN removed_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE spotify_playlist_tracks DROP COLUMN added_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE spotify_playlist_tracks DROP COLUMN position;
+-- +goose StatementEnd
@@ -0,0 +1,76 @@
+-- name: InsertSoundCloudPlaylistChange :one
+INSERT INTO soundcloud_playlist_changes (
+    created_at,
+    soundcloud_playlist_id,
+    soundcloud_track_id,
+    change_type,
+    old_value,
+    new_value
+) VALUES (
+    CURRENT_TIMESTAMP,
+    sqlc.narg('soundcloud_playlist_id'),
+    sqlc.narg('soundcloud_track_id'),
+    sqlc.narg('change_type'),
+    sqlc.narg('old_value'),
+    sqlc.narg('new_value')
+)
+RETURNING *;
+
+-- name: ListSoundCloudPlaylistChanges :many
+SELECT
+    c.*,
+    p.name AS playlist_name,
+    t.name AS track_name,
+    t.permalink_url AS track_permalink_url
+FROM soundcloud_playlist_changes c
+JOIN soundcloud_playlists p
+    ON c.soundcloud_playlist_id = p.id
+JOIN soundcloud_tracks t
+    ON c.soundcloud_track_id = t.id
+ORDER BY c.id DESC
+LIMIT @limit;
+
+-- name: InsertSoundCloudPlaylistTrackHistory :exec
+-- Starts a period in the history for a track in the playlist, unless one has already been started
+INSERT INTO soundcloud_playlist_track_history (
+    soundcloud_playlist_id,
+    soundcloud_track_id,
+    added_at
+)
+SELECT
+    pt.soundcloud_playlist_id,
+    pt.soundcloud_track_id,
+    pt.added_at
+FROM soundcloud_playlist_tracks pt
+WHERE pt.soundcloud_playlist_id = @soundcloud_playlist_id
+    AND pt.soundcloud_track_id = @soundcloud_track_id
+    AND pt.removed_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1
+        FROM soundcloud_playlist_track_history h
+        WHERE h.soundcloud_playlist_id = pt.soundcloud_playlist_id
+            AND h.soundcloud_track_id = pt.soundcloud_track_id
+            AND h.removed_at IS NULL
+    );
+
+-- name: EndSoundCloudPlaylistTrackHistory :exec
+UPDATE soundcloud_playlist_track_history
+SET removed_at = CURRENT_TIMESTAMP
+WHERE soundcloud_playlist_id = @soundcloud_playlist_id
+    AND soundcloud_track_id = @soundcloud_track_id
+    AND removed_at IS NULL;
+
+-- name: ListSoundCloudPlaylistTrackHistory :many
+SELECT
+    h.*,
+    t.external_id AS track_external_id,
+    t.name AS track_name,
+    t.permalink_url AS track_permalink_url
+FROM soundcloud_playlist_track_history h
+JOIN soundcloud_playlists p
+    ON h.soundcloud_playlist_id = p.id
+JOIN soundcloud_tracks t
+    ON h.soundcloud_track_id = t.id
+WHERE p.external_id = @playlist_external_id
+    AND p.source_type = @source_type
+ORDER BY h.added_at DESC, h.id DESC;
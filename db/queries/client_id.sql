@@ -0,0 +1,15 @@
+-- name: GetLatestSoundCloudClientID :one
+SELECT *
+FROM soundcloud_client_ids
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: InsertSoundCloudClientID :one
+INSERT INTO soundcloud_client_ids (
+    created_at,
+    client_id
+) VALUES (
+    CURRENT_TIMESTAMP,
+    sqlc.narg('client_id')
+)
+RETURNING *;
@@ -0,0 +1,50 @@
+-- name: DeleteCollectionTrack :exec
+DELETE FROM collection_tracks
+WHERE id = @id;
+
+-- name: ListCollectionTracksByPlatform :many
+SELECT *
+FROM collection_tracks
+WHERE platform = @platform;
+
+-- name: UpsertCollectionTrack :exec
+INSERT INTO collection_tracks (
+    created_at,
+    updated_at,
+    platform,
+    path,
+    title,
+    artist,
+    album,
+    genre,
+    label,
+    comments,
+    key,
+    bpm,
+    duration
+) VALUES (
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP,
+    @platform,
+    @path,
+    sqlc.narg('title'),
+    sqlc.narg('artist'),
+    sqlc.narg('album'),
+    sqlc.narg('genre'),
+    sqlc.narg('label'),
+    sqlc.narg('comments'),
+    sqlc.narg('key'),
+    sqlc.narg('bpm'),
+    sqlc.narg('duration')
+) ON CONFLICT (platform, path) DO UPDATE SET
+    updated_at = CURRENT_TIMESTAMP,
+
+    title = excluded.title,
+    artist = excluded.artist,
+    album = excluded.album,
+    genre = excluded.genre,
+    label = excluded.label,
+    comments = excluded.comments,
+    key = excluded.key,
+    bpm = excluded.bpm,
+    duration = excluded.duration;
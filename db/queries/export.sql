@@ -0,0 +1,28 @@
+-- name: ListSoundCloudPlaylistTrackLinks :many
+SELECT
+    p.source_type,
+    p.external_id AS playlist_external_id,
+    t.external_id AS track_external_id,
+    pt.removed_at
+FROM soundcloud_playlist_tracks pt
+JOIN soundcloud_playlists p
+    ON pt.soundcloud_playlist_id = p.id
+JOIN soundcloud_tracks t
+    ON pt.soundcloud_track_id = t.id
+ORDER BY p.id, pt.position, t.id;
+
+-- name: ListSpotifyTracks :many
+SELECT *
+FROM spotify_tracks;
+
+-- name: ListSpotifyPlaylistTrackLinks :many
+SELECT
+    p.external_id AS playlist_external_id,
+    t.external_id AS track_external_id,
+    pt.removed_at
+FROM spotify_playlist_tracks pt
+JOIN spotify_playlists p
+    ON pt.spotify_playlist_id = p.id
+JOIN spotify_tracks t
+    ON pt.spotify_track_id = t.id
+ORDER BY p.id, pt.position, t.id;
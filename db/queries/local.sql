@@ -0,0 +1,64 @@
+-- name: ListLocalFiles :many
+SELECT *
+FROM local_files
+WHERE coalesce(missing, false) = false;
+
+-- name: GetLocalFileByPath :one
+SELECT *
+FROM local_files
+WHERE path = @path;
+
+-- name: SetLocalFileMissing :exec
+UPDATE local_files
+SET updated_at = CURRENT_TIMESTAMP,
+    missing = @missing
+WHERE path = @path;
+
+-- name: DeleteLocalFileByPath :exec
+DELETE FROM local_files
+WHERE path = @path;
+
+-- name: UpsertLocalFile :one
+INSERT INTO local_files (
+    created_at,
+    updated_at,
+    path,
+    size,
+    modified_at,
+    content_hash,
+    title,
+    artist,
+    album,
+    genre,
+    duration,
+    fingerprint,
+    missing
+) VALUES (
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP,
+    sqlc.narg('path'),
+    sqlc.narg('size'),
+    sqlc.narg('modified_at'),
+    sqlc.narg('content_hash'),
+    sqlc.narg('title'),
+    sqlc.narg('artist'),
+    sqlc.narg('album'),
+    sqlc.narg('genre'),
+    sqlc.narg('duration'),
+    sqlc.narg('fingerprint'),
+    sqlc.narg('missing')
+) ON CONFLICT (path) DO UPDATE SET
+    updated_at = CURRENT_TIMESTAMP,
+
+    size = coalesce(?2, size),
+    modified_at = coalesce(?3, modified_at),
+    content_hash = coalesce(?4, content_hash),
+    title = coalesce(?5, title),
+    artist = coalesce(?6, artist),
+    album = coalesce(?7, album),
+    genre = coalesce(?8, genre),
+    duration = coalesce(?9, duration),
+    fingerprint = coalesce(?10, fingerprint),
+    missing = coalesce(?11, missing)
+
+RETURNING *;
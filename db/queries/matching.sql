@@ -0,0 +1,38 @@
+-- name: ListUnmatchedSoundCloudTracks :many
+SELECT t.*
+FROM soundcloud_tracks t
+LEFT JOIN soundcloud_track_matches m
+    ON t.id = m.soundcloud_track_id
+WHERE m.id IS NULL
+    AND coalesce(t.removed_from_playlist, false) = false;
+
+-- name: DeleteSoundCloudTrackMatchByTrackID :exec
+DELETE FROM soundcloud_track_matches
+WHERE soundcloud_track_id = @soundcloud_track_id;
+
+-- name: UpsertSoundCloudTrackMatch :one
+INSERT INTO soundcloud_track_matches (
+    created_at,
+    updated_at,
+    soundcloud_track_id,
+    source,
+    path,
+    confidence,
+    reason
+) VALUES (
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP,
+    sqlc.narg('soundcloud_track_id'),
+    sqlc.narg('source'),
+    sqlc.narg('path'),
+    sqlc.narg('confidence'),
+    sqlc.narg('reason')
+) ON CONFLICT (soundcloud_track_id) DO UPDATE SET
+    updated_at = CURRENT_TIMESTAMP,
+
+    source = coalesce(?2, source),
+    path = coalesce(?3, path),
+    confidence = coalesce(?4, confidence),
+    reason = coalesce(?5, reason)
+
+RETURNING *;
@@ -0,0 +1,58 @@
+-- name: ListPurchases :many
+SELECT *
+FROM purchases
+ORDER BY purchased_at DESC, artist, title;
+
+-- name: UpsertPurchase :one
+INSERT INTO purchases (
+    created_at,
+    updated_at,
+    store,
+    external_id,
+    order_id,
+    purchased_at,
+    kind,
+    artist,
+    title,
+    album,
+    label,
+    isrc,
+    url,
+    duration
+) VALUES (
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP,
+    @store,
+    @external_id,
+    sqlc.narg('order_id'),
+    sqlc.narg('purchased_at'),
+    sqlc.narg('kind'),
+    sqlc.narg('artist'),
+    sqlc.narg('title'),
+    sqlc.narg('album'),
+    sqlc.narg('label'),
+    sqlc.narg('isrc'),
+    sqlc.narg('url'),
+    sqlc.narg('duration')
+) ON CONFLICT (store, external_id) DO UPDATE SET
+    updated_at = CURRENT_TIMESTAMP,
+
+    order_id = coalesce(?3, order_id),
+    purchased_at = coalesce(?4, purchased_at),
+    kind = coalesce(?5, kind),
+    artist = coalesce(?6, artist),
+    title = coalesce(?7, title),
+    album = coalesce(?8, album),
+    label = coalesce(?9, label),
+    isrc = coalesce(?10, isrc),
+    url = coalesce(?11, url),
+    duration = coalesce(?12, duration)
+
+RETURNING *;
+
+-- name: SetPurchaseMatch :exec
+UPDATE purchases
+SET updated_at = CURRENT_TIMESTAMP,
+    local_path = sqlc.narg('local_path'),
+    match_confidence = sqlc.narg('match_confidence')
+WHERE id = @id;
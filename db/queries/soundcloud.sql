@@ -10,23 +10,36 @@ WHERE search_url = @url;
 -- name: GetNumSoundCloudPlaylistByExternalID :one
 SELECT count(*)
 FROM soundcloud_playlists
-WHERE external_id = @external_id; 
+WHERE external_id = @external_id
+AND source_type = @source_type; 
+
+-- name: ListSoundCloudTracks :many
+SELECT *
+FROM soundcloud_tracks;
 
 -- name: ListSoundCloudTracksByPlaylistID :many
 SELECT t.*
 FROM soundcloud_tracks t
 JOIN soundcloud_playlist_tracks pt 
     ON t.id = pt.soundcloud_track_id
-WHERE soundcloud_playlist_id = @playlist_id;
+WHERE soundcloud_playlist_id = @playlist_id
+ORDER BY pt.position, t.id;
 
 -- name: ListSoundCloudTracksByPlaylistExternalID :many
-SELECT t.*
+-- Lists the tracks in playlist order, along with when each was added to and removed from the playlist
+SELECT
+    sqlc.embed(t),
+    pt.position,
+    pt.added_at,
+    pt.removed_at
 FROM soundcloud_tracks t
 JOIN soundcloud_playlist_tracks pt 
     ON t.id = pt.soundcloud_track_id
 JOIN soundcloud_playlists p 
     ON pt.soundcloud_playlist_id = p.id
-WHERE p.external_id = @playlist_external_id;
+WHERE p.external_id = @playlist_external_id
+AND p.source_type = @source_type
+ORDER BY pt.position, t.id;
 
 -- name: ListSoundCloudTracksHasLocalPath :many
 SELECT t.*
@@ -45,7 +58,10 @@ SELECT COUNT(*)
 FROM soundcloud_tracks t
 JOIN soundcloud_playlist_tracks pt 
     ON t.id = pt.soundcloud_track_id
-WHERE pt.soundcloud_playlist_id = @playlist_external_id;
+JOIN soundcloud_playlists p 
+    ON pt.soundcloud_playlist_id = p.id
+WHERE p.external_id = @playlist_external_id
+AND p.source_type = @source_type;
 
 -- name: UpsertSoundCloudPlaylist :one
 INSERT INTO soundcloud_playlists (
@@ -54,15 +70,17 @@ INSERT INTO soundcloud_playlists (
     external_id,
     name,
     search_url,
-    permalink_url
+    permalink_url,
+    source_type
 ) VALUES (
     CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP,
     sqlc.narg('external_id'),
     sqlc.narg('name'),
     sqlc.narg('search_url'),
-    sqlc.narg('permalink_url')
-) ON CONFLICT (external_id) DO UPDATE SET
+    sqlc.narg('permalink_url'),
+    sqlc.arg('source_type')
+) ON CONFLICT (source_type, external_id) DO UPDATE SET
     updated_at = CURRENT_TIMESTAMP,
 
     name = coalesce(?2, name),
@@ -72,6 +90,8 @@ INSERT INTO soundcloud_playlists (
 RETURNING *;
 
 -- name: UpsertSoundCloudTrack :one
+-- Only sets the fields which come from SoundCloud, local_path, local_path_broken and
+-- removed_from_playlist are set with their own queries so they aren't overwritten
 INSERT INTO soundcloud_tracks (
     created_at,
     updated_at,
@@ -86,9 +106,8 @@ INSERT INTO soundcloud_tracks (
     tag_list,
     publisher_artist,
     sound_cloud_user,
-    local_path,
-    local_path_broken,
-    removed_from_playlist
+    duration,
+    purchase_category
 ) VALUES (
     CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP,
@@ -103,9 +122,8 @@ INSERT INTO soundcloud_tracks (
     sqlc.narg('tag_list'),
     sqlc.narg('publisher_artist'),
     sqlc.narg('sound_cloud_user'),
-    sqlc.narg('local_path'),
-    sqlc.narg('local_path_broken'),
-    sqlc.narg('removed_from_playlist')
+    sqlc.narg('duration'),
+    sqlc.narg('purchase_category')
 ) ON CONFLICT (external_id) DO UPDATE SET
     updated_at = CURRENT_TIMESTAMP,
 
@@ -119,18 +137,77 @@ INSERT INTO soundcloud_tracks (
     tag_list = coalesce(?9, tag_list),
     publisher_artist = coalesce(?10, publisher_artist),
     sound_cloud_user = coalesce(?11, sound_cloud_user),
-    local_path = coalesce(?12, local_path),
-    local_path_broken = coalesce(?13, local_path_broken),
-    removed_from_playlist = coalesce(?14, removed_from_playlist)
+    duration = coalesce(?12, duration),
+    purchase_category = coalesce(?13, purchase_category)
 
 RETURNING *;
 
 -- name: UpsertSoundCloudPlaylistTrack :one
+-- Adds the track to the playlist, or moves it if it's already there. A track which had been removed
+-- from the playlist is added again
 INSERT INTO soundcloud_playlist_tracks (
     soundcloud_playlist_id,
-    soundcloud_track_id
+    soundcloud_track_id,
+    position,
+    added_at
 ) VALUES (
     sqlc.narg('soundcloud_playlist_id'),
-    sqlc.narg('soundcloud_track_id')
-) ON CONFLICT (soundcloud_playlist_id, soundcloud_track_id) DO NOTHING
-RETURNING *;
\ No newline at end of file
+    sqlc.narg('soundcloud_track_id'),
+    sqlc.narg('position'),
+    CURRENT_TIMESTAMP
+) ON CONFLICT (soundcloud_playlist_id, soundcloud_track_id) DO UPDATE SET
+    position = coalesce(?3, position),
+    added_at = CASE
+        WHEN removed_at IS NULL THEN coalesce(added_at, CURRENT_TIMESTAMP)
+        ELSE CURRENT_TIMESTAMP
+    END,
+    removed_at = NULL
+RETURNING *;
+
+-- name: InsertRemovedSoundCloudPlaylistTrack :exec
+-- Links a track to the playlist as already removed, unless it's already linked
+INSERT INTO soundcloud_playlist_tracks (
+    soundcloud_playlist_id,
+    soundcloud_track_id,
+    removed_at
+) VALUES (
+    sqlc.narg('soundcloud_playlist_id'),
+    sqlc.narg('soundcloud_track_id'),
+    CURRENT_TIMESTAMP
+) ON CONFLICT (soundcloud_playlist_id, soundcloud_track_id) DO NOTHING;
+
+-- name: RemoveSoundCloudPlaylistTrack :exec
+UPDATE soundcloud_playlist_tracks
+SET removed_at = coalesce(removed_at, CURRENT_TIMESTAMP)
+WHERE soundcloud_playlist_id = @soundcloud_playlist_id
+    AND soundcloud_track_id = @soundcloud_track_id;
+
+-- name: SetSoundCloudTracksRemovedFromPlaylistByPlaylistID :exec
+-- Sets removed_from_playlist on each track in the playlist, a track is only removed once it has been
+-- removed from every playlist it was in
+UPDATE soundcloud_tracks
+SET updated_at = CURRENT_TIMESTAMP,
+    removed_from_playlist = NOT EXISTS (
+        SELECT 1
+        FROM soundcloud_playlist_tracks pt
+        WHERE pt.soundcloud_track_id = soundcloud_tracks.id
+            AND pt.removed_at IS NULL
+    )
+WHERE id IN (
+    SELECT soundcloud_track_id
+    FROM soundcloud_playlist_tracks
+    WHERE soundcloud_playlist_id = @playlist_id
+);
+
+-- name: SetSoundCloudTrackLocalPath :exec
+UPDATE soundcloud_tracks
+SET updated_at = CURRENT_TIMESTAMP,
+    local_path = sqlc.narg('local_path'),
+    local_path_broken = sqlc.narg('local_path_broken')
+WHERE external_id = sqlc.narg('external_id');
+
+-- name: SetSoundCloudTrackRemovedFromPlaylist :exec
+UPDATE soundcloud_tracks
+SET updated_at = CURRENT_TIMESTAMP,
+    removed_from_playlist = sqlc.narg('removed_from_playlist')
+WHERE external_id = sqlc.narg('external_id');
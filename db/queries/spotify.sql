@@ -0,0 +1,143 @@
+-- name: ListSpotifyPlaylists :many
+SELECT *
+FROM spotify_playlists;
+
+-- name: GetNumSpotifyPlaylistByExternalID :one
+SELECT count(*)
+FROM spotify_playlists
+WHERE external_id = @external_id;
+
+-- name: ListSpotifyTracksByPlaylistExternalID :many
+-- Lists the tracks in playlist order, along with when each was added to and removed from the playlist
+SELECT
+    sqlc.embed(t),
+    pt.position,
+    pt.added_at,
+    pt.removed_at
+FROM spotify_tracks t
+JOIN spotify_playlist_tracks pt
+    ON t.id = pt.spotify_track_id
+JOIN spotify_playlists p
+    ON pt.spotify_playlist_id = p.id
+WHERE p.external_id = @playlist_external_id
+ORDER BY pt.position, t.id;
+
+-- name: UpsertSpotifyPlaylist :one
+INSERT INTO spotify_playlists (
+    created_at,
+    updated_at,
+    external_id,
+    name,
+    owner,
+    search_url,
+    permalink_url
+) VALUES (
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP,
+    sqlc.narg('external_id'),
+    sqlc.narg('name'),
+    sqlc.narg('owner'),
+    sqlc.narg('search_url'),
+    sqlc.narg('permalink_url')
+) ON CONFLICT (external_id) DO UPDATE SET
+    updated_at = CURRENT_TIMESTAMP,
+
+    name = coalesce(?2, name),
+    owner = coalesce(?3, owner),
+    search_url = coalesce(?4, search_url),
+    permalink_url = coalesce(?5, permalink_url)
+
+RETURNING *;
+
+-- name: UpsertSpotifyTrack :one
+INSERT INTO spotify_tracks (
+    created_at,
+    updated_at,
+    external_id,
+    name,
+    permalink_url,
+    artists,
+    album,
+    isrc,
+    duration,
+    removed_from_playlist
+) VALUES (
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP,
+    sqlc.narg('external_id'),
+    sqlc.narg('name'),
+    sqlc.narg('permalink_url'),
+    sqlc.narg('artists'),
+    sqlc.narg('album'),
+    sqlc.narg('isrc'),
+    sqlc.narg('duration'),
+    sqlc.narg('removed_from_playlist')
+) ON CONFLICT (external_id) DO UPDATE SET
+    updated_at = CURRENT_TIMESTAMP,
+
+    name = coalesce(?2, name),
+    permalink_url = coalesce(?3, permalink_url),
+    artists = coalesce(?4, artists),
+    album = coalesce(?5, album),
+    isrc = coalesce(?6, isrc),
+    duration = coalesce(?7, duration),
+    removed_from_playlist = coalesce(?8, removed_from_playlist)
+
+RETURNING *;
+
+-- name: UpsertSpotifyPlaylistTrack :one
+-- Adds the track to the playlist, or moves it if it's already there. A track which had been removed
+-- from the playlist is added again
+INSERT INTO spotify_playlist_tracks (
+    spotify_playlist_id,
+    spotify_track_id,
+    position,
+    added_at
+) VALUES (
+    sqlc.narg('spotify_playlist_id'),
+    sqlc.narg('spotify_track_id'),
+    sqlc.narg('position'),
+    CURRENT_TIMESTAMP
+) ON CONFLICT (spotify_playlist_id, spotify_track_id) DO UPDATE SET
+    position = coalesce(?3, position),
+    added_at = CASE
+        WHEN removed_at IS NULL THEN coalesce(added_at, CURRENT_TIMESTAMP)
+        ELSE CURRENT_TIMESTAMP
+    END,
+    removed_at = NULL
+RETURNING *;
+
+-- name: InsertRemovedSpotifyPlaylistTrack :exec
+-- Links a track to the playlist as already removed, unless it's already linked
+INSERT INTO spotify_playlist_tracks (
+    spotify_playlist_id,
+    spotify_track_id,
+    removed_at
+) VALUES (
+    sqlc.narg('spotify_playlist_id'),
+    sqlc.narg('spotify_track_id'),
+    CURRENT_TIMESTAMP
+) ON CONFLICT (spotify_playlist_id, spotify_track_id) DO NOTHING;
+
+-- name: RemoveSpotifyPlaylistTrack :exec
+UPDATE spotify_playlist_tracks
+SET removed_at = coalesce(removed_at, CURRENT_TIMESTAMP)
+WHERE spotify_playlist_id = @spotify_playlist_id
+    AND spotify_track_id = @spotify_track_id;
+
+-- name: SetSpotifyTracksRemovedFromPlaylistByPlaylistID :exec
+-- Sets removed_from_playlist on each track in the playlist, a track is only removed once it has been
+-- removed from every playlist it was in
+UPDATE spotify_tracks
+SET updated_at = CURRENT_TIMESTAMP,
+    removed_from_playlist = NOT EXISTS (
+        SELECT 1
+        FROM spotify_playlist_tracks pt
+        WHERE pt.spotify_track_id = spotify_tracks.id
+            AND pt.removed_at IS NULL
+    )
+WHERE id IN (
+    SELECT spotify_track_id
+    FROM spotify_playlist_tracks
+    WHERE spotify_playlist_id = @playlist_id
+);
@@ -0,0 +1,21 @@
+-- name: ListStreamingPlaylists :many
+SELECT *
+FROM streaming_playlists
+WHERE sqlc.narg('platform') IS NULL OR platform = sqlc.narg('platform')
+ORDER BY platform, name;
+
+-- name: ListStreamingTracksByPlaylist :many
+SELECT
+    sqlc.embed(t),
+    pt.removed_at
+FROM streaming_tracks t
+JOIN streaming_playlist_tracks pt
+    ON t.platform = pt.platform
+    AND t.id = pt.track_id
+JOIN streaming_playlists p
+    ON pt.platform = p.platform
+    AND pt.playlist_id = p.id
+WHERE p.platform = @platform
+    AND p.source_type = @source_type
+    AND p.external_id = @external_id
+ORDER BY pt.position, t.id;
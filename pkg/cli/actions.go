@@ -1,18 +1,95 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
 	"github.com/billiem/seren-management/pkg/collection"
+	"github.com/billiem/seren-management/pkg/data"
 	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations"
+	"github.com/billiem/seren-management/pkg/purchases"
 	"github.com/billiem/seren-management/pkg/streaming"
 	"github.com/urfave/cli/v2"
 )
 
+// keys in c.App.Metadata holding the env and the plan confirmed by the user, shared between planning and flattening
+const (
+	flattenEnvKey  = "flattenEnv"
+	flattenPlanKey = "flattenPlan"
+)
+
 func flattenDir(c *cli.Context) error {
 
-	e, err := buildCliEnv(c.String("config"))
+	if c.Bool("dry-run") {
+		return nil
+	}
+
+	return runFlattenDir(c, false)
+}
+
+/*
+planFlattenDir prints the steps a flatten would take, and asks the user
+to confirm before continuing (unless this is a dry run)
+
+The plan is kept in c.App.Metadata, so the flatten carries out the steps the user confirmed, along
+with the env so the database is only connected to once
+*/
+func planFlattenDir(c *cli.Context) error {
+
+	if c.Args().First() == "" {
+		return helpers.ErrNoDirPath
+	}
+
+	err := runFlattenDir(c, true)
+
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("dry-run") {
+		return nil
+	}
+
+	fmt.Println("continue? (y/n)")
+	var proceed string
+	fmt.Scanln(&proceed)
+	if strings.ToLower(proceed) != "y" {
+		return helpers.ErrUserCancelled
+	}
+	return nil
+}
+
+/*
+flattenCliEnv returns the env built whilst planning the flatten, building it if this is the first use
+*/
+func flattenCliEnv(c *cli.Context) (*cliEnv, error) {
+
+	if e, ok := c.App.Metadata[flattenEnvKey].(*cliEnv); ok {
+		return e, nil
+	}
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.App.Metadata == nil {
+		c.App.Metadata = map[string]any{}
+	}
+	c.App.Metadata[flattenEnvKey] = e
+
+	return e, nil
+}
+
+func runFlattenDir(c *cli.Context, dryRun bool) error {
+
+	e, err := flattenCliEnv(c)
 
 	if err != nil {
 		return err
@@ -24,11 +101,325 @@ func flattenDir(c *cli.Context) error {
 		return err
 	}
 
+	opts := operations.FlattenDirectoryOpts{
+		InDirPath: absPath,
+		Collision: helpers.CollisionStrategy(c.String("collision")),
+		DryRun:    dryRun,
+	}
+
+	if plan, ok := c.App.Metadata[flattenPlanKey].(operations.FlattenPlan); ok && !dryRun {
+		opts.Plan = plan
+	}
+
+	var opErr error
+
 	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(data map[string]any) {
+		if dryRun {
+			plan, _ := data["plan"].(operations.FlattenPlan)
+			c.App.Metadata[flattenPlanKey] = plan
+			fmt.Print(plan)
+			return
+		}
+		fmt.Printf(
+			"Moved %v files, skipped %v, removed %v duplicates\n",
+			data["moved"], data["skipped"], data["deduped"],
+		)
+		if data["journal"] != "" {
+			fmt.Printf("Journal written to %v\n", data["journal"])
+		}
+	}, func(err error) {
+		opErr = err
+	})
 
-	opEnv.FlattenDirectory(absPath)
+	opEnv.FlattenDirectory(c.Context, opts)
 
-	return nil
+	return opErr
+}
+
+func undoFlattenDir(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	journalPath, err := helpers.GetAbsOrWdPath(c.String("journal"))
+
+	if err != nil {
+		return err
+	}
+
+	opts := operations.UndoFlattenDirectoryOpts{
+		JournalPath: journalPath,
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(data map[string]any) {
+		fmt.Printf("Restored %v files\n", data["restored"])
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.UndoFlattenDirectory(c.Context, opts)
+
+	return opErr
+}
+
+func organiseLibrary(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	inDirPath, err := helpers.GetAbsOrWdPath(c.String("in"))
+	if err != nil {
+		return err
+	}
+
+	outDirPath, err := helpers.GetAbsOrWdPath(c.String("out"))
+	if err != nil {
+		return err
+	}
+
+	opts := operations.OrganiseLibraryOpts{
+		InDirPath:  inDirPath,
+		OutDirPath: outDirPath,
+		Template:   c.String("template"),
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(data map[string]any) {
+		fmt.Printf("Moved %v files\n", data["moved"])
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.OrganiseLibrary(c.Context, opts)
+
+	return opErr
+}
+
+func indexLocalFiles(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	inDirPath, err := helpers.GetAbsOrWdPath(c.String("in"))
+	if err != nil {
+		return err
+	}
+
+	opts := operations.IndexLocalFilesOpts{
+		InDirPath:   inDirPath,
+		Fingerprint: c.Bool("fingerprint"),
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(data map[string]any) {
+		fmt.Printf("Indexed %v files, %v files missing\n", data["indexed"], data["missing"])
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.IndexLocalFiles(c.Context, opts)
+
+	return opErr
+}
+
+func findDuplicates(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	opts := operations.FindDuplicatesOpts{
+		Method:    operations.DuplicateMethod(c.String("method")),
+		Threshold: c.Float64("threshold"),
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		groups, _ := d["groups"].([][]data.LocalFile)
+		for i, g := range groups {
+			fmt.Printf("Group %v:\n", i+1)
+			for _, f := range g {
+				fmt.Printf("  %s\n", f.Path.String)
+			}
+		}
+		fmt.Printf("Found %v groups of duplicates\n", len(groups))
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.FindDuplicates(c.Context, opts)
+
+	return opErr
+}
+
+func relocate(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	opts := operations.FindRelocationsOpts{
+		MinConfidence: c.Float64("min-confidence"),
+	}
+
+	var matches []operations.RelocationMatch
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		matches, _ = d["matches"].([]operations.RelocationMatch)
+		for _, m := range matches {
+			fmt.Printf("[%s] %s -> %s (%.0f%%, %s)\n", m.Source, m.OldPath, m.NewPath, m.Confidence*100, m.Reason)
+		}
+		fmt.Printf("Found %v matches, %v broken paths unmatched\n", len(matches), d["unmatched"])
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.FindRelocations(c.Context, opts)
+
+	if opErr != nil || !c.Bool("apply") || len(matches) == 0 {
+		return opErr
+	}
+
+	opEnv = e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		fmt.Printf("Relocated %v SoundCloud tracks and %v collection entries\n", d["soundcloud"], d["traktor"])
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ApplyRelocations(c.Context, operations.ApplyRelocationsOpts{
+		Matches: matches,
+	})
+
+	return opErr
+}
+
+func matchSoundCloudTracks(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	opts := operations.MatchSoundCloudTracksOpts{
+		MinConfidence: c.Float64("min-confidence"),
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		unmatched, _ := d["unmatched"].([]streaming.SoundCloudTrack)
+		for _, t := range unmatched {
+			fmt.Printf("%s (%s)\n", t.Name, t.PermalinkUrl)
+		}
+		fmt.Printf("Matched %v tracks, %v tracks unmatched\n", d["matched"], len(unmatched))
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.MatchSoundCloudTracks(c.Context, opts)
+
+	return opErr
+}
+
+func importPurchases(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	opts := operations.ImportPurchasesOpts{
+		Store:         purchases.Store(c.String("store")),
+		FilePath:      c.String("file"),
+		MinConfidence: c.Float64("min-confidence"),
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		fmt.Printf("Imported %v purchases, %v matched to local files\n", d["imported"], d["matched"])
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ImportPurchases(c.Context, opts)
+
+	return opErr
+}
+
+func listPurchases(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	opts := operations.ListPurchasesOpts{
+		Status: operations.PurchaseStatus(c.String("status")),
+		Store:  purchases.Store(c.String("store")),
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		ps, _ := d["purchases"].([]purchases.Purchase)
+		for _, p := range ps {
+			if p.LocalPath == "" {
+				fmt.Println(p)
+				continue
+			}
+			fmt.Printf("%s (%s)\n", p, p.LocalPath)
+		}
+		fmt.Printf("%v purchases\n", len(ps))
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ListPurchases(c.Context, opts)
+
+	return opErr
 }
 
 func convertMp3(c *cli.Context) error {
@@ -45,7 +436,7 @@ func convertMp3(c *cli.Context) error {
 
 func readTraktorCollection(c *cli.Context) error {
 
-	e, err := buildCliEnv(c.String("config"))
+	e, err := buildCliEnv(c)
 
 	if err != nil {
 		return err
@@ -80,9 +471,69 @@ func readTraktorCollection(c *cli.Context) error {
 	return nil
 }
 
+func importPlaylist(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {}, func(d map[string]any) {
+		p, _ := d["playlist"].(streaming.SourcePlaylist)
+		for _, t := range p.Tracks {
+			if t.RemovedFromPlaylist {
+				fmt.Printf("%s (removed)\n", t)
+				continue
+			}
+			fmt.Println(t)
+		}
+		fmt.Printf("Imported %s from %s, %v tracks\n", p.Name, p.Platform, len(p.Tracks))
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ImportPlaylist(c.Context, operations.ImportPlaylistOpts{
+		PlaylistURL: c.String("url"),
+		Refresh:     c.Bool("refresh"),
+	})
+
+	return opErr
+}
+
+func listPlaylists(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {}, func(d map[string]any) {
+		playlists, _ := d["playlists"].([]streaming.SourcePlaylist)
+		for _, p := range playlists {
+			fmt.Printf("%s\t%s\t%v tracks\t%s\n", p.Platform, p.Name, len(p.Tracks), p.PermalinkUrl)
+		}
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ListPlaylists(c.Context, operations.ListPlaylistsOpts{
+		Platform: streaming.Platform(c.String("platform")),
+	})
+
+	return opErr
+}
+
 func getSoundcloudPlaylist(c *cli.Context) error {
 
-	// e, err := buildCliEnv(c.String("config"))
+	// e, err := buildCliEnv(c)
 
 	// if err != nil {
 	// 	return err
@@ -100,28 +551,606 @@ func getSoundcloudPlaylist(c *cli.Context) error {
 	return nil
 }
 
-func getSpotifyPlaylist(c *cli.Context) error {
+func downloadSoundCloudPlaylist(c *cli.Context) error {
 
-	e, err := buildCliEnv(c.String("config"))
+	e, err := buildCliEnv(c)
 
 	if err != nil {
 		return err
 	}
 
-	_ = e
+	playlist, err := e.getSoundCloudPlaylistByURL(c.Context, c.String("url"))
 
-	return nil
+	if err != nil {
+		return err
+	}
+
+	opts := operations.DownloadSoundCloudPlaylistOpts{
+		PlaylistExternalID: playlist.ExternalID.Int64,
+		PlaylistSourceType: streaming.SoundCloudSourceType(playlist.SourceType),
+		PlaylistName:       playlist.Name.String,
+		Workers:            c.Int("workers"),
+		RequestsPerSecond:  c.Float64("rate"),
+		Collision:          helpers.CollisionStrategy(c.String("collision")),
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+		fmt.Printf("Progress: %.0f%%\n", f*100)
+	}, func(d map[string]any) {
+		tracks, _ := d["tracks"].([]streaming.SoundCloudTrack)
+		for _, t := range tracks {
+			fmt.Printf("%s -> %s\n", t.Name, t.LocalPath)
+		}
+		fmt.Printf("Downloaded %v tracks, %v failed\n", len(tracks), d["failed"])
+	}, func(err error) {
+		opErr = err
+	})
+	opEnv.AttachDefaultPostDownloadHooks()
+
+	opEnv.DownloadSoundCloudPlaylist(c.Context, opts)
+
+	return opErr
 }
 
-func generateClientID(c *cli.Context) error {
+func embedSoundCloudArtwork(c *cli.Context) error {
 
-	clientID, err := streaming.GenerateSoundCloudClientID()
+	e, err := buildCliEnv(c)
 
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(clientID)
+	playlist, err := e.getSoundCloudPlaylistByURL(c.Context, c.String("url"))
 
-	return nil
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+		fmt.Printf("Progress: %.0f%%\n", f*100)
+	}, func(d map[string]any) {
+		fmt.Printf("Embedded artwork into %v files, %v skipped, %v failed\n", d["embedded"], d["skipped"], d["failed"])
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.EmbedSoundCloudArtwork(c.Context, operations.EmbedSoundCloudArtworkOpts{
+		PlaylistExternalID: playlist.ExternalID.Int64,
+		PlaylistSourceType: streaming.SoundCloudSourceType(playlist.SourceType),
+	})
+
+	return opErr
+}
+
+func refreshAllSoundCloudPlaylists(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	// stop scheduled refreshes on ctrl+c
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt)
+	defer stop()
+
+	interval := c.Duration("every")
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+		fmt.Printf("Progress: %.0f%%\n", f*100)
+	}, func(d map[string]any) {
+		changes, _ := d["changes"].([]operations.SoundCloudPlaylistChange)
+		printPlaylistChanges(changes)
+		fmt.Printf("Refreshed %v playlists, %v failed, %v changes\n", d["refreshed"], d["failed"], len(changes))
+		if interval > 0 {
+			fmt.Printf("Next refresh at %s\n", time.Now().Add(interval).Format(time.DateTime))
+		}
+	}, func(err error) {
+		// scheduled refreshes keep running after a failed run
+		if interval > 0 {
+			fmt.Println(err)
+			return
+		}
+		opErr = err
+	})
+
+	opEnv.RefreshAllSoundCloudPlaylists(ctx, operations.RefreshAllSoundCloudPlaylistsOpts{
+		Interval: interval,
+	})
+
+	return opErr
+}
+
+func listSoundCloudPlaylistChanges(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {}, func(d map[string]any) {
+		changes, _ := d["changes"].([]operations.SoundCloudPlaylistChange)
+		if len(changes) == 0 {
+			fmt.Println("No changes found, run refresh-all first")
+			return
+		}
+		printPlaylistChanges(changes)
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ListSoundCloudPlaylistChanges(c.Context, operations.ListSoundCloudPlaylistChangesOpts{
+		Limit: c.Int("limit"),
+	})
+
+	return opErr
+}
+
+func printPlaylistChanges(changes []operations.SoundCloudPlaylistChange) {
+	for _, c := range changes {
+		prefix := fmt.Sprintf("%s [%s] %s", c.CreatedAt.Local().Format(time.DateTime), c.PlaylistName, c.TrackName)
+
+		switch c.Type {
+		case operations.SoundCloudChangeAdded:
+			fmt.Printf("%s: added\n", prefix)
+		case operations.SoundCloudChangeRemoved:
+			fmt.Printf("%s: removed\n", prefix)
+		case operations.SoundCloudChangeDownloadEnabled:
+			fmt.Printf("%s: free download available (%s)\n", prefix, c.TrackPermalinkUrl)
+		case operations.SoundCloudChangePurchaseURL:
+			fmt.Printf("%s: purchase link changed from '%s' to '%s'\n", prefix, c.OldValue, c.NewValue)
+		}
+	}
+}
+
+func listSoundCloudPlaylistTrackHistory(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	playlist, err := e.getSoundCloudPlaylistByURL(c.Context, c.String("url"))
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {}, func(d map[string]any) {
+		history, _ := d["history"].([]operations.SoundCloudPlaylistTrackPeriod)
+		for _, h := range history {
+			removed := "still in playlist"
+			if !h.RemovedAt.IsZero() {
+				removed = "removed " + h.RemovedAt.Local().Format(time.DateTime)
+			}
+			fmt.Printf("%s: added %s, %s\n", h.TrackName, h.AddedAt.Local().Format(time.DateTime), removed)
+		}
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ListSoundCloudPlaylistTrackHistory(c.Context, operations.ListSoundCloudPlaylistTrackHistoryOpts{
+		PlaylistExternalID: playlist.ExternalID.Int64,
+		PlaylistSourceType: streaming.SoundCloudSourceType(playlist.SourceType),
+	})
+
+	return opErr
+}
+
+func listSoundCloudPurchaseLinks(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {}, func(d map[string]any) {
+		tracks, _ := d["tracks"].([]streaming.SoundCloudTrack)
+		counts, _ := d["counts"].(map[streaming.PurchaseCategory]int)
+
+		for _, t := range tracks {
+			fmt.Printf("[%s] %s: %s (%s)\n", t.PurchaseCategory, t.Name, t.PurchaseURL, t.PurchaseTitle)
+		}
+
+		fmt.Printf(
+			"%v stores, %v free gates, %v label sites\n",
+			counts[streaming.PurchaseStore],
+			counts[streaming.PurchaseFreeGate],
+			counts[streaming.PurchaseLabelSite],
+		)
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ListSoundCloudPurchaseLinks(c.Context, operations.ListSoundCloudPurchaseLinksOpts{
+		Category:       streaming.PurchaseCategory(c.String("category")),
+		IncludeRemoved: c.Bool("include-removed"),
+	})
+
+	return opErr
+}
+
+/*
+getSoundCloudPlaylistByURL finds a playlist in the database by the url it was added with, or its permalink
+*/
+func (e cliEnv) getSoundCloudPlaylistByURL(ctx context.Context, url string) (data.SoundcloudPlaylist, error) {
+
+	playlists, err := e.SerenDB.ListSoundCloudPlaylists(ctx)
+
+	if err != nil {
+		return data.SoundcloudPlaylist{}, err
+	}
+
+	for _, p := range playlists {
+		if p.SearchUrl.String == url || p.PermalinkUrl.String == url {
+			return p, nil
+		}
+	}
+
+	return data.SoundcloudPlaylist{}, helpers.ErrPlaylistNotFound
+}
+
+func getSpotifyPlaylist(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {}, func(d map[string]any) {
+		p, _ := d["playlist"].(streaming.SpotifyPlaylist)
+		for _, t := range p.Tracks {
+			if t.RemovedFromPlaylist {
+				fmt.Printf("%s (removed)\n", t)
+				continue
+			}
+			fmt.Println(t)
+		}
+		fmt.Printf("Imported %s by %s, %v tracks\n", p.Name, p.Owner, len(p.Tracks))
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.GetSpotifyPlaylist(c.Context, operations.GetSpotifyPlaylistOpts{
+		PlaylistURL: c.String("url"),
+		Refresh:     c.Bool("refresh"),
+	})
+
+	return opErr
+}
+
+func clientIDStatus(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {}, func(d map[string]any) {
+		switch d["source"] {
+		case operations.ClientIDSourceNone:
+			fmt.Println("No client_id has been generated yet, one will be generated when needed")
+		case operations.ClientIDSourceConfig:
+			fmt.Printf("%s (from SOUNDCLOUD_CLIENT_ID)\n", d["client_id"])
+		default:
+			createdAt, _ := d["created_at"].(time.Time)
+			fmt.Printf("%s (generated %s)\n", d["client_id"], createdAt.Local().Format(time.DateTime))
+		}
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.SoundCloudClientIDStatus(c.Context)
+
+	return opErr
+}
+
+func refreshClientID(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {}, func(d map[string]any) {
+		fmt.Println(d["client_id"])
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.RefreshSoundCloudClientID(c.Context)
+
+	return opErr
+}
+
+func generateClientID(c *cli.Context) error {
+
+	clientID, err := streaming.GenerateSoundCloudClientID()
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(clientID)
+
+	return nil
+}
+
+func listProfiles(c *cli.Context) error {
+
+	profiles, err := helpers.ListProfiles()
+
+	if err != nil {
+		return err
+	}
+
+	active := c.String("profile")
+
+	if active == "" {
+		active, err = helpers.GetActiveProfile()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range profiles {
+		if p == active {
+			fmt.Printf("* %s\n", p)
+			continue
+		}
+		fmt.Printf("  %s\n", p)
+	}
+
+	return nil
+}
+
+func createProfile(c *cli.Context) error {
+
+	name := c.Args().First()
+
+	if !helpers.CheckProfileName(name) {
+		return helpers.ErrInvalidProfileName
+	}
+
+	cfg, err := helpers.CreateProfile(name)
+
+	if err != nil {
+		return err
+	}
+
+	dbPath, err := cfg.GetDBPath()
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created profile '%s', its database will be %s\n", name, dbPath)
+
+	return nil
+}
+
+func useProfile(c *cli.Context) error {
+
+	name := c.Args().First()
+
+	err := helpers.SetActiveProfile(name)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Using profile '%s'\n", name)
+
+	return nil
+}
+
+func backupDatabase(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	opts := operations.BackupDatabaseOpts{
+		Keep: c.Int("keep"),
+	}
+
+	if c.String("dir") != "" {
+		opts.Dir, err = helpers.GetAbsOrWdPath(c.String("dir"))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		fmt.Printf("Backed up database to %s\n", d["path"])
+		removed, _ := d["removed"].([]string)
+		for _, p := range removed {
+			fmt.Printf("Removed old backup %s\n", p)
+		}
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.BackupDatabase(c.Context, opts)
+
+	return opErr
+}
+
+func exportDatabase(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	outPath := c.String("out")
+
+	if outPath == "" {
+		outPath = fmt.Sprintf("seren-%s.%s", time.Now().Format("20060102-150405"), c.String("format"))
+	}
+
+	outPath, err = helpers.GetAbsOrWdPath(outPath)
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		dump, _ := d["dump"].(data.Dump)
+		fmt.Printf(
+			"Exported %v SoundCloud playlists, %v SoundCloud tracks, %v Spotify playlists and %v Spotify tracks to %s\n",
+			len(dump.SoundCloudPlaylists),
+			len(dump.SoundCloudTracks),
+			len(dump.SpotifyPlaylists),
+			len(dump.SpotifyTracks),
+			outPath,
+		)
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ExportDatabase(c.Context, operations.ExportDatabaseOpts{
+		Format:  operations.DumpFormat(c.String("format")),
+		OutPath: outPath,
+	})
+
+	return opErr
+}
+
+func importDatabase(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	inPath, err := helpers.GetAbsOrWdPath(c.String("in"))
+
+	if err != nil {
+		return err
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		dump, _ := d["dump"].(data.Dump)
+		fmt.Printf("Backed up database to %s\n", d["backup"])
+		fmt.Printf(
+			"Imported %v SoundCloud playlists, %v SoundCloud tracks, %v Spotify playlists and %v Spotify tracks\n",
+			len(dump.SoundCloudPlaylists),
+			len(dump.SoundCloudTracks),
+			len(dump.SpotifyPlaylists),
+			len(dump.SpotifyTracks),
+		)
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.ImportDatabase(c.Context, operations.ImportDatabaseOpts{
+		InPath: inPath,
+	})
+
+	return opErr
+}
+
+func searchLibrary(c *cli.Context) error {
+
+	e, err := buildCliEnv(c)
+
+	if err != nil {
+		return err
+	}
+
+	opts := operations.SearchLibraryOpts{
+		Query:            strings.Join(c.Args().Slice(), " "),
+		Limit:            c.Int("limit"),
+		IndexCollections: c.Bool("index-collection"),
+	}
+
+	var opErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(func(f float64) {
+	}, func(d map[string]any) {
+		results, _ := d["results"].([]data.SearchResult)
+		for _, r := range results {
+			fmt.Printf("[%s] %s - %s", r.Source, r.Artist.String, r.Title.String)
+
+			var info []string
+			if r.Bpm.Valid {
+				info = append(info, fmt.Sprintf("%g", r.Bpm.Float64))
+			}
+			if r.Key.Valid {
+				info = append(info, r.Key.String)
+			}
+			if len(info) > 0 {
+				fmt.Printf(" (%s)", strings.Join(info, " "))
+			}
+
+			switch {
+			case r.Path.Valid:
+				fmt.Printf(" %s", r.Path.String)
+			case r.PermalinkUrl.Valid:
+				fmt.Printf(" %s", r.PermalinkUrl.String)
+			}
+
+			fmt.Println()
+		}
+		fmt.Printf("%v results\n", len(results))
+	}, func(err error) {
+		opErr = err
+	})
+
+	opEnv.SearchLibrary(c.Context, opts)
+
+	return opErr
 }
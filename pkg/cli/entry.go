@@ -2,12 +2,11 @@ package cli
 
 import (
 	"errors"
-	"fmt"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations"
 	"github.com/urfave/cli/v2"
 )
 
@@ -30,24 +29,191 @@ func Entry() {
 					"fd",
 					"flatten",
 				},
-				Usage:  "Flattens a directory structure",
-				Action: flattenDir,
-				Before: func(c *cli.Context) error {
-					path := c.Args().First()
-					if path == "" {
-						return helpers.ErrNoDirPath
-					}
-					filePaths, err := helpers.GetFilesInDir(path, true)
-					if err != nil {
-						return err
-					}
-					fmt.Printf("%v files in dir, continue? (y/n)\n", len(filePaths))
-					var proceed string
-					fmt.Scanln(&proceed)
-					if strings.ToLower(proceed) != "y" {
-						return helpers.ErrUserCancelled
-					}
-					return nil
+				Usage:     "Flattens a directory structure",
+				ArgsUsage: "<dir>",
+				Action:    flattenDir,
+				Before:    planFlattenDir,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "collision",
+						Aliases:  []string{"col"},
+						Usage:    "How to handle files with the same name, one of 'suffix', 'skip' or 'hash' (remove identical files, suffix the rest)",
+						Value:    string(helpers.CollisionSuffix),
+						Required: false,
+					},
+					&cli.BoolFlag{
+						Name:     "dry-run",
+						Usage:    "Print the changes which would be made without moving any files",
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "undo-flatten",
+				Aliases: []string{"uf"},
+				Usage:   "Undoes a previous flatten-dir using its journal",
+				Action:  undoFlattenDir,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "journal",
+						Aliases:  []string{"j"},
+						Usage:    "Path to the journal to undo, if not given we default to the most recent journal",
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "organise",
+				Aliases: []string{"org"},
+				Usage:   "Moves files from the download directory into the library, using a template built from their tags",
+				Action:  organiseLibrary,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "in",
+						Aliases:  []string{"i"},
+						Usage:    "Directory to organise files from, if not given we default to the download directory stored in application config",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "out",
+						Aliases:  []string{"o"},
+						Usage:    "Library directory to move files into, if not given we default to the base directory stored in application config",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "template",
+						Aliases:  []string{"t"},
+						Usage:    "Template used to build file paths, i.e. '{genre}/{year}/{artist} - {title}'",
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "index",
+				Aliases: []string{"idx"},
+				Usage:   "Indexes the audio files in a directory into the applications database",
+				Action:  indexLocalFiles,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "in",
+						Aliases:  []string{"i"},
+						Usage:    "Directory to index, if not given we default to the base directory stored in application config",
+						Required: false,
+					},
+					&cli.BoolFlag{
+						Name:     "fingerprint",
+						Aliases:  []string{"fp"},
+						Usage:    "Calculate acoustic fingerprints for each file, requires fpcalc (Chromaprint)",
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "duplicates",
+				Aliases: []string{"dupes"},
+				Usage:   "Lists groups of duplicate tracks found in the local file index",
+				Action:  findDuplicates,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "method",
+						Aliases:  []string{"m"},
+						Usage:    "How to compare files, one of 'hash', 'name' or 'fingerprint'",
+						Value:    string(operations.DuplicateContentHash),
+						Required: false,
+					},
+					&cli.Float64Flag{
+						Name:     "threshold",
+						Usage:    "Min similarity (0-1) for fingerprints to be considered duplicates",
+						Value:    operations.DefaultFingerprintThreshold,
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "relocate",
+				Aliases: []string{"rel"},
+				Usage:   "Finds new locations for broken SoundCloud local paths and missing collection entries using the local file index",
+				Action:  relocate,
+				Flags: []cli.Flag{
+					&cli.Float64Flag{
+						Name:     "min-confidence",
+						Aliases:  []string{"mc"},
+						Usage:    "Min confidence (0-1) for a match to be proposed",
+						Value:    operations.DefaultMinRelocationConfidence,
+						Required: false,
+					},
+					&cli.BoolFlag{
+						Name:     "apply",
+						Usage:    "Apply the proposed matches, otherwise they are only listed",
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:   "match",
+				Usage:  "Matches SoundCloud tracks to files in the local file index and collection, and lists the tracks left unmatched",
+				Action: matchSoundCloudTracks,
+				Flags: []cli.Flag{
+					&cli.Float64Flag{
+						Name:     "min-confidence",
+						Aliases:  []string{"mc"},
+						Usage:    "Min confidence (0-1) for a track to be considered matched",
+						Value:    operations.DefaultMinMatchConfidence,
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "purchases",
+				Aliases: []string{"pu"},
+				Usage:   "Imports purchase history from music stores and finds purchases missing from the library",
+				Subcommands: []*cli.Command{
+					{
+						Name:    "import",
+						Aliases: []string{"i"},
+						Usage:   "Imports a Beatport order history CSV or Bandcamp collection JSON and matches it to the local file index",
+						Action:  importPurchases,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "store",
+								Aliases:  []string{"s"},
+								Usage:    "Store the export is from, one of 'beatport' or 'bandcamp'",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "file",
+								Aliases:  []string{"f"},
+								Usage:    "Path to the exported purchase history",
+								Required: true,
+							},
+							&cli.Float64Flag{
+								Name:     "min-confidence",
+								Aliases:  []string{"mc"},
+								Usage:    "Min confidence (0-1) for a purchase to be matched to a local file",
+								Value:    operations.DefaultMinMatchConfidence,
+								Required: false,
+							},
+						},
+					},
+					{
+						Name:    "list",
+						Aliases: []string{"l"},
+						Usage:   "Lists imported purchases",
+						Action:  listPurchases,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "status",
+								Usage:    "Only list purchases which are 'not_downloaded' or 'not_in_collection'",
+								Required: false,
+							},
+							&cli.StringFlag{
+								Name:     "store",
+								Aliases:  []string{"s"},
+								Usage:    "Only list purchases from this store",
+								Required: false,
+							},
+						},
+					},
 				},
 			},
 			{
@@ -87,13 +253,39 @@ func Entry() {
 			{
 				Name:    "get-playlist",
 				Aliases: []string{"gp"},
-				Usage:   "Gets a playlist from a given streaming platform and stores it in the applications database",
+				Usage:   "Gets a playlist from a given streaming platform and stores it in the applications database, the platform is found from the URL unless a subcommand is used",
+				Action:  importPlaylist,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "url",
+						Aliases:  []string{"u"},
+						Usage:    "The link of the playlist on any supported platform",
+						Required: false, // not required by the subcommands, ImportPlaylist checks it is set
+					},
+					&cli.BoolFlag{
+						Name:     "refresh",
+						Usage:    "Update the playlist if it has already been imported",
+						Required: false,
+					},
+				},
 				Subcommands: []*cli.Command{
 					{
 						Name:    "spotify",
 						Aliases: []string{"sp"},
 						Usage:   "Get playlists from Spotify and store them in the applications database",
 						Action:  getSpotifyPlaylist,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "url",
+								Usage:    "The link or URI of the Spotify playlist",
+								Required: true,
+							},
+							&cli.BoolFlag{
+								Name:     "refresh",
+								Usage:    "Update the playlist if it has already been imported",
+								Required: false,
+							},
+						},
 					},
 					{
 						Name:    "soundcloud",
@@ -103,6 +295,248 @@ func Entry() {
 					},
 				},
 			},
+			{
+				Name:    "list-playlists",
+				Aliases: []string{"lp"},
+				Usage:   "Lists the playlists from every streaming platform stored in the applications database",
+				Action:  listPlaylists,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "platform",
+						Aliases:  []string{"p"},
+						Usage:    "Only list playlists from this platform, one of 'soundcloud' or 'spotify'",
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "download-playlist",
+				Aliases: []string{"dp"},
+				Usage:   "Downloads every free download track in a SoundCloud playlist stored in the applications database",
+				Action:  downloadSoundCloudPlaylist,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "url",
+						Aliases:  []string{"u"},
+						Usage:    "URL of the SoundCloud playlist, it must have been added with get-playlist first",
+						Required: true,
+					},
+					&cli.IntFlag{
+						Name:     "workers",
+						Aliases:  []string{"w"},
+						Usage:    "Number of tracks to download at once",
+						Required: false,
+					},
+					&cli.Float64Flag{
+						Name:     "rate",
+						Usage:    "Max number of downloads to start per second",
+						Value:    operations.DefaultDownloadRequestsPerSecond,
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "collision",
+						Aliases:  []string{"col"},
+						Usage:    "How to handle tracks with the same file name as an existing file, one of 'suffix', 'skip' or 'hash' (keep the existing file if identical, suffix otherwise)",
+						Value:    string(helpers.CollisionSuffix),
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "embed-artwork",
+				Aliases: []string{"ea"},
+				Usage:   "Embeds the SoundCloud artwork of every downloaded track in a SoundCloud playlist into its file",
+				Action:  embedSoundCloudArtwork,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "url",
+						Aliases:  []string{"u"},
+						Usage:    "URL of the SoundCloud playlist, it must have been added with get-playlist first",
+						Required: true,
+					},
+				},
+			},
+			{
+				Name:    "refresh-all",
+				Aliases: []string{"ra"},
+				Usage:   "Refreshes every SoundCloud playlist in the applications database, logging the changes found",
+				Action:  refreshAllSoundCloudPlaylists,
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:     "every",
+						Usage:    "Keep running, refreshing again after each interval (at least 15m), i.e. '6h'",
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "whats-new",
+				Aliases: []string{"wn"},
+				Usage:   "Lists the changes found when refreshing SoundCloud playlists, most recent first",
+				Action:  listSoundCloudPlaylistChanges,
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:     "limit",
+						Aliases:  []string{"l"},
+						Usage:    "Max number of changes to list",
+						Value:    operations.DefaultChangeLogLimit,
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:    "playlist-history",
+				Aliases: []string{"ph"},
+				Usage:   "Lists when each track was added to and removed from a SoundCloud playlist, most recent first",
+				Action:  listSoundCloudPlaylistTrackHistory,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "url",
+						Aliases:  []string{"u"},
+						Usage:    "URL of the SoundCloud playlist, it must have been added with get-playlist first",
+						Required: true,
+					},
+				},
+			},
+			{
+				Name:    "purchase-links",
+				Aliases: []string{"pl"},
+				Usage:   "Lists SoundCloud tracks by the kind of purchase link they have, i.e. free download gates",
+				Action:  listSoundCloudPurchaseLinks,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "category",
+						Aliases:  []string{"c"},
+						Usage:    "Only list links in this category: 'store', 'free_gate' or 'label_site'",
+						Required: false,
+					},
+					&cli.BoolFlag{
+						Name:     "include-removed",
+						Usage:    "Include tracks removed from their playlist",
+						Required: false,
+					},
+				},
+			},
+			{
+				Name:  "client-id",
+				Usage: "Manages the SoundCloud client_id stored in the applications database",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "status",
+						Usage:  "Prints the SoundCloud client_id in use and when it was generated",
+						Action: clientIDStatus,
+					},
+					{
+						Name:   "refresh",
+						Usage:  "Generates a new SoundCloud client_id and stores it in the applications database",
+						Action: refreshClientID,
+					},
+				},
+			},
+			{
+				Name:  "db",
+				Usage: "Backs up, exports and imports the applications database",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "backup",
+						Usage:  "Backs up the database to a timestamped file, removing the oldest backups",
+						Action: backupDatabase,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "dir",
+								Aliases:  []string{"d"},
+								Usage:    "Directory to store backups in, if not given we default to 'backups' next to the database",
+								Required: false,
+							},
+							&cli.IntFlag{
+								Name:     "keep",
+								Aliases:  []string{"k"},
+								Usage:    "Number of backups to keep",
+								Value:    operations.DefaultBackupsKept,
+								Required: false,
+							},
+						},
+					},
+					{
+						Name:   "export",
+						Usage:  "Exports the playlists and tracks in the database, along with the links between them",
+						Action: exportDatabase,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "format",
+								Aliases:  []string{"f"},
+								Usage:    "Format to export to, only 'json' is supported",
+								Value:    string(operations.DumpFormatJSON),
+								Required: false,
+							},
+							&cli.StringFlag{
+								Name:     "out",
+								Aliases:  []string{"o"},
+								Usage:    "Path to write the export to, if not given we default to seren-{timestamp}.json in the working directory",
+								Required: false,
+							},
+						},
+					},
+					{
+						Name:   "import",
+						Usage:  "Merges an export into the database, the database is backed up first",
+						Action: importDatabase,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "in",
+								Aliases:  []string{"i"},
+								Usage:    "Path to the export to import",
+								Required: true,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "profiles",
+				Usage: "Manages library profiles, each profile has its own config and database",
+				Subcommands: []*cli.Command{
+					{
+						Name:    "list",
+						Aliases: []string{"l"},
+						Usage:   "Lists the profiles, marking the profile in use",
+						Action:  listProfiles,
+					},
+					{
+						Name:      "create",
+						Usage:     "Creates a profile with the default config",
+						ArgsUsage: "<name>",
+						Action:    createProfile,
+					},
+					{
+						Name:      "use",
+						Usage:     "Sets the profile used when --profile isn't given, by both the CLI and GUI",
+						ArgsUsage: "<name>",
+						Action:    useProfile,
+					},
+				},
+			},
+			{
+				Name:      "search",
+				Aliases:   []string{"s"},
+				Usage:     "Searches SoundCloud tracks, local files and collection entries, i.e. 'bicep label:\"ninja tune\" bpm:120-128 key:8A source:collection'",
+				ArgsUsage: "<query>",
+				Action:    searchLibrary,
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:     "limit",
+						Aliases:  []string{"l"},
+						Usage:    "Max number of results to list",
+						Value:    operations.DefaultSearchLimit,
+						Required: false,
+					},
+					&cli.BoolFlag{
+						Name:     "index-collection",
+						Usage:    "Read the collection before searching, so entries changed since it was last read are found",
+						Required: false,
+					},
+				},
+			},
 			{
 				Name:    "get-client-id",
 				Aliases: []string{"gcid"},
@@ -114,8 +548,7 @@ func Entry() {
 			&cli.StringFlag{
 				Name:     "config",
 				Aliases:  []string{"c"},
-				Usage:    "Optional path to the config file",
-				Value:    "config.json",
+				Usage:    "Optional path to the config file, if not given we default to the profile's config",
 				Required: false,
 				Action: func(c *cli.Context, s string) error {
 					if _, err := os.Stat(s); errors.Is(err, os.ErrNotExist) {
@@ -124,6 +557,17 @@ func Entry() {
 					return nil
 				},
 			},
+			&cli.StringFlag{
+				Name:     "profile",
+				Usage:    "Library profile to use, if not given we default to the profile chosen with 'profiles use'",
+				EnvVars:  []string{"SEREN_PROFILE"},
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "db",
+				Usage:    "Optional path to the database, if not given we default to the path in config or the profile's directory",
+				Required: false,
+			},
 		},
 	}
 
@@ -6,6 +6,7 @@ import (
 	"github.com/billiem/seren-management/pkg/data"
 	"github.com/billiem/seren-management/pkg/helpers"
 	"github.com/billiem/seren-management/pkg/operations"
+	"github.com/urfave/cli/v2"
 )
 
 type cliEnv struct {
@@ -17,13 +18,17 @@ type cliEnv struct {
 func (e cliEnv) opEnv() operations.OpEnv {
 	return operations.OpEnv{
 		Config:  e.Config,
+		Logger:  e.logger,
 		SerenDB: e.SerenDB,
 	}
 }
 
-func buildCliEnv(configPath string) (*cliEnv, error) {
+/*
+buildCliEnv loads the config and database chosen by the global --config, --profile and --db flags
+*/
+func buildCliEnv(c *cli.Context) (*cliEnv, error) {
 
-	cfg, err := helpers.LoadCLIConfig(configPath)
+	cfg, err := helpers.LoadCLIConfig(c.String("config"), c.String("profile"))
 
 	if err != nil {
 		return nil, fault.Wrap(
@@ -32,6 +37,14 @@ func buildCliEnv(configPath string) (*cliEnv, error) {
 		)
 	}
 
+	if c.IsSet("db") {
+		cfg.DBPath, err = helpers.GetAbsOrWdPath(c.String("db"))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	loggers, err := helpers.BuildAppLoggers(cfg)
 
 	if err != nil {
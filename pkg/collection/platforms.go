@@ -9,6 +9,26 @@ import (
 type CollectionPlatform interface {
 	ReadCollection() error
 	UpdateCollection() error
+	ListTracks() ([]Track, error)
+	RelocateTracks(map[string]string) (int, error)
+	MergeTracks(string, []string) (int, error)
+}
+
+/*
+Track is a platform agnostic representation of an entry in a collection
+*/
+type Track struct {
+	Path     string
+	Title    string
+	Artist   string
+	Album    string
+	Genre    string
+	Label    string
+	Comments string
+	Key      string
+	BPM      float64
+	Duration float64 // in seconds
+	Size     int64   // in bytes, may be approximate depending on the platform
 }
 
 type ReadCollectionOpts interface {
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/billiem/seren-management/pkg/helpers"
 )
@@ -49,8 +50,13 @@ func (c Traktor) String() string {
 	return "Traktor"
 }
 
-func (t Traktor) ReadCollection() error {
-	err := t.loadCollection()
+/*
+ReadCollection reads the collection and writes it to CollectionOutPath, as with RelocateTracks
+the collection is read from CollectionOutPath if it has already been written, so reading the
+collection doesn't discard changes which haven't been imported into Traktor yet
+*/
+func (t *Traktor) ReadCollection() error {
+	err := t.loadPendingCollection()
 
 	if err != nil {
 		return err
@@ -71,17 +77,319 @@ func (t Traktor) UpdateCollection() error {
 	return nil
 }
 
+/*
+ListTracks returns each entry in the collection which has a location, including any changes
+written to CollectionOutPath which haven't been imported into Traktor yet
+*/
+func (t *Traktor) ListTracks() ([]Track, error) {
+	err := t.loadPendingCollection()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t.NML.COLLECTION == nil {
+		return nil, nil
+	}
+
+	var tracks []Track
+
+	for _, entry := range t.NML.COLLECTION.ENTRY {
+		if len(entry.LOCATION) == 0 || entry.LOCATION[0] == nil {
+			continue
+		}
+		tracks = append(tracks, traktorEntryToTrack(entry))
+	}
+
+	return tracks, nil
+}
+
+/*
+traktorEntryToTrack converts a collection entry into a Track
+
+Traktor stores file sizes in kilobytes, so the size is approximate
+*/
+func traktorEntryToTrack(entry *ENTRY) Track {
+	track := Track{
+		Path:   traktorLocationToPath(entry.LOCATION[0]),
+		Title:  entry.TITLEAttr,
+		Artist: entry.ARTISTAttr,
+	}
+
+	if len(entry.ALBUM) > 0 && entry.ALBUM[0] != nil {
+		track.Album = entry.ALBUM[0].TITLEAttr
+	}
+
+	if len(entry.INFO) > 0 && entry.INFO[0] != nil {
+		info := entry.INFO[0]
+		track.Genre = info.GENREAttr
+		track.Label = info.LABELAttr
+		track.Comments = info.COMMENTAttr
+		track.Key = info.KEYAttr
+		track.Duration = info.PLAYTIMEFLOATAttr
+		track.Size = int64(info.FILESIZEAttr) * 1024
+	}
+
+	if len(entry.TEMPO) > 0 && entry.TEMPO[0] != nil {
+		track.BPM = entry.TEMPO[0].BPMAttr
+	}
+
+	return track
+}
+
+/*
+RelocateTracks updates the location of any tracks in the collection which have been moved,
+moves is a map of old file paths to new file paths
+
+Playlist entries referencing the moved tracks are updated too, as Traktor references
+tracks inside of playlists by their location
+
+The collection is read from CollectionOutPath if an earlier change has written it, so repeated
+runs don't lose each others changes
+
+Returns the number of collection entries which were relocated
+*/
+func (t *Traktor) RelocateTracks(moves map[string]string) (int, error) {
+	err := t.loadPendingCollection()
+
+	if err != nil {
+		return 0, err
+	}
+
+	if t.NML.COLLECTION == nil {
+		return 0, nil
+	}
+
+	// map of old primary keys to new primary keys, used to update playlists
+	movedKeys := make(map[string]string)
+
+	for _, entry := range t.NML.COLLECTION.ENTRY {
+		if len(entry.LOCATION) == 0 || entry.LOCATION[0] == nil {
+			continue
+		}
+
+		location := entry.LOCATION[0]
+
+		newPath, ok := moves[traktorLocationToPath(location)]
+
+		if !ok {
+			continue
+		}
+
+		oldKey := traktorPrimaryKey(location)
+		setTraktorLocationPath(location, newPath)
+		movedKeys[oldKey] = traktorPrimaryKey(location)
+	}
+
+	if len(movedKeys) == 0 {
+		return 0, nil
+	}
+
+	if t.NML.PLAYLISTS != nil {
+		relocatePlaylistNodes(t.NML.PLAYLISTS.NODE, movedKeys)
+	}
+
+	err = t.writeCollection()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(movedKeys), nil
+}
+
+/*
+MergeTracks merges the collection entries of duplicate files into the entry of the file being kept,
+cue points which the keeper doesn't have are copied over and play counts are combined
+
+The duplicate entries are removed from the collection, and any playlists referencing them
+are updated to reference the keeper instead
+
+If the keeper isn't in the collection, the first duplicate which is will be relocated to the keepers
+path and used as the keeper, as with RelocateTracks the collection is read from CollectionOutPath
+if it has already been written
+
+Returns the number of collection entries which were merged into the keeper
+*/
+func (t *Traktor) MergeTracks(keeperPath string, duplicatePaths []string) (int, error) {
+	err := t.loadPendingCollection()
+
+	if err != nil {
+		return 0, err
+	}
+
+	if t.NML.COLLECTION == nil {
+		return 0, nil
+	}
+
+	isDuplicate := make(map[string]bool, len(duplicatePaths))
+	for _, p := range duplicatePaths {
+		isDuplicate[p] = true
+	}
+
+	var keeper *ENTRY
+	var duplicates []*ENTRY
+
+	for _, entry := range t.NML.COLLECTION.ENTRY {
+		if len(entry.LOCATION) == 0 || entry.LOCATION[0] == nil {
+			continue
+		}
+
+		path := traktorLocationToPath(entry.LOCATION[0])
+
+		if path == keeperPath && keeper == nil {
+			keeper = entry
+		} else if isDuplicate[path] {
+			duplicates = append(duplicates, entry)
+		}
+	}
+
+	if len(duplicates) == 0 {
+		return 0, nil
+	}
+
+	// map of old primary keys to new primary keys, used to update playlists
+	movedKeys := make(map[string]string)
+
+	if keeper == nil {
+		keeper, duplicates = duplicates[0], duplicates[1:]
+		oldKey := traktorPrimaryKey(keeper.LOCATION[0])
+		setTraktorLocationPath(keeper.LOCATION[0], keeperPath)
+		movedKeys[oldKey] = traktorPrimaryKey(keeper.LOCATION[0])
+	}
+
+	keeperKey := traktorPrimaryKey(keeper.LOCATION[0])
+	removed := make(map[*ENTRY]bool, len(duplicates))
+
+	for _, d := range duplicates {
+		mergeTraktorEntry(keeper, d)
+		movedKeys[traktorPrimaryKey(d.LOCATION[0])] = keeperKey
+		removed[d] = true
+	}
+
+	var entries []*ENTRY
+	for _, entry := range t.NML.COLLECTION.ENTRY {
+		if !removed[entry] {
+			entries = append(entries, entry)
+		}
+	}
+
+	t.NML.COLLECTION.ENTRY = entries
+	t.NML.COLLECTION.ENTRIESAttr = uint16(len(entries))
+
+	if t.NML.PLAYLISTS != nil {
+		relocatePlaylistNodes(t.NML.PLAYLISTS.NODE, movedKeys)
+	}
+
+	err = t.writeCollection()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(duplicates), nil
+}
+
+/*
+mergeTraktorEntry merges the metadata of a duplicate entry into the keeper entry
+
+Cue points are copied if the keeper has no cue point of the same type at the same position,
+play counts are added together and the highest ranking is kept
+*/
+func mergeTraktorEntry(keeper *ENTRY, duplicate *ENTRY) {
+
+	for _, cue := range duplicate.CUEV2 {
+		if cue == nil {
+			continue
+		}
+
+		exists := false
+		for _, kCue := range keeper.CUEV2 {
+			if kCue != nil && kCue.TYPEAttr == cue.TYPEAttr && kCue.STARTAttr == cue.STARTAttr {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			c := *cue
+			keeper.CUEV2 = append(keeper.CUEV2, &c)
+		}
+	}
+
+	if len(duplicate.INFO) == 0 || duplicate.INFO[0] == nil {
+		return
+	}
+
+	if len(keeper.INFO) == 0 || keeper.INFO[0] == nil {
+		info := *duplicate.INFO[0]
+		keeper.INFO = []*INFO{&info}
+		return
+	}
+
+	keeper.INFO[0].PLAYCOUNTAttr += duplicate.INFO[0].PLAYCOUNTAttr
+
+	if duplicate.INFO[0].RANKINGAttr > keeper.INFO[0].RANKINGAttr {
+		keeper.INFO[0].RANKINGAttr = duplicate.INFO[0].RANKINGAttr
+	}
+}
+
+/*
+relocatePlaylistNodes recursively updates the primary keys of playlist entries
+inside of the given nodes
+*/
+func relocatePlaylistNodes(nodes []*NODE, movedKeys map[string]string) {
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		for _, playlist := range node.PLAYLIST {
+			for _, entry := range playlist.ENTRIES {
+				if entry.PRIMARYKEY == nil {
+					continue
+				}
+				if newKey, ok := movedKeys[entry.PRIMARYKEY.KEYAttr]; ok {
+					entry.PRIMARYKEY.KEYAttr = newKey
+				}
+			}
+		}
+		if node.SUBNODES != nil {
+			relocatePlaylistNodes(node.SUBNODES.NODE, movedKeys)
+		}
+	}
+}
+
 func (t *Traktor) loadCollection() error {
+	return t.loadCollectionFrom(t.CollectionInPath)
+}
 
-	fmt.Println("load collection", t.CollectionInPath)
+/*
+loadPendingCollection loads the collection at CollectionOutPath if a previous change has written one,
+otherwise the collection at CollectionInPath, so changes made by separate runs build on each other
+rather than each starting again from the original collection
+*/
+func (t *Traktor) loadPendingCollection() error {
+	if helpers.DoesFileExist(t.CollectionOutPath) {
+		return t.loadCollectionFrom(t.CollectionOutPath)
+	}
+
+	return t.loadCollection()
+}
+
+func (t *Traktor) loadCollectionFrom(path string) error {
+
+	fmt.Println("load collection", path)
 
 	// read xml
-	data, err := os.ReadFile(t.CollectionInPath)
+	data, err := os.ReadFile(path)
 
 	if err != nil {
 		return err
 	}
 
+	// unmarshalling into an already loaded collection would append to its entries
+	t.NML = NML{}
+
 	err = xml.Unmarshal(data, &t.NML)
 
 	if err != nil {
@@ -115,3 +423,69 @@ func (t *Traktor) writeCollection() error {
 func traktorXMLHeader() string {
 	return "<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"no\"?>\n"
 }
+
+/*
+Traktor stores locations as a volume (i.e. 'H:'), a directory with each
+folder prefixed by '/:' (i.e. '/:Music/:processed/:'), and a file name
+
+Playlists reference tracks by a primary key, which is the concatenation of these
+*/
+
+func traktorPrimaryKey(l *LOCATION) string {
+	return l.VOLUMEAttr + l.DIRAttr + l.FILEAttr
+}
+
+/*
+traktorLocationToPath converts a Traktor location into a file path
+
+Volumes which are not drive letters (i.e. macOS volume names) are omitted,
+as these refer to the root of the file system
+*/
+func traktorLocationToPath(l *LOCATION) string {
+	dir := strings.ReplaceAll(l.DIRAttr, "/:", "/")
+
+	if isDriveLetterVolume(l.VOLUMEAttr) {
+		return l.VOLUMEAttr + dir + l.FILEAttr
+	}
+
+	return dir + l.FILEAttr
+}
+
+/*
+setTraktorLocationPath updates a Traktor location to point to the given file path
+
+The existing volume is kept unless the path begins with a drive letter
+*/
+func setTraktorLocationPath(l *LOCATION, path string) {
+	path = strings.ReplaceAll(path, "\\", "/")
+
+	if len(path) >= 2 && isDriveLetterVolume(path[:2]) {
+		l.VOLUMEAttr = path[:2]
+		path = path[2:]
+	}
+
+	dir, file := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir, file = path[:i], path[i+1:]
+	}
+
+	dirParts := strings.Split(strings.Trim(dir, "/"), "/")
+	traktorDir := "/:"
+	for _, p := range dirParts {
+		if p == "" {
+			continue
+		}
+		traktorDir += p + "/:"
+	}
+
+	l.DIRAttr = traktorDir
+	l.FILEAttr = file
+}
+
+func isDriveLetterVolume(v string) bool {
+	if len(v) != 2 || v[1] != ':' {
+		return false
+	}
+	c := v[0]
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
@@ -1,7 +1,245 @@
 package collection
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+)
 
 func TestReadAndWriteTraktorCollection(t *testing.T) {
 
 }
+
+func TestTraktorLocationToPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		location LOCATION
+		want     string
+	}{
+		{
+			name: "windows drive volume",
+			location: LOCATION{
+				DIRAttr:    "/:Music/:processed/:",
+				FILEAttr:   "10 - Track 10.mp3",
+				VOLUMEAttr: "H:",
+			},
+			want: "H:/Music/processed/10 - Track 10.mp3",
+		},
+		{
+			name: "macOS named volume",
+			location: LOCATION{
+				DIRAttr:    "/:Users/:seren/:Music/:",
+				FILEAttr:   "funky cool song.mp3",
+				VOLUMEAttr: "Macintosh HD",
+			},
+			want: "/Users/seren/Music/funky cool song.mp3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := traktorLocationToPath(&tt.location); got != tt.want {
+				t.Errorf("traktorLocationToPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTraktorLocationPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		location LOCATION
+		path     string
+		want     LOCATION
+	}{
+		{
+			name: "windows drive volume",
+			location: LOCATION{
+				DIRAttr:    "/:Music/:to process/:",
+				FILEAttr:   "10 - Track 10.mp3",
+				VOLUMEAttr: "H:",
+			},
+			path: "H:/Music/Pop/2017/Artist - Track 10.mp3",
+			want: LOCATION{
+				DIRAttr:    "/:Music/:Pop/:2017/:",
+				FILEAttr:   "Artist - Track 10.mp3",
+				VOLUMEAttr: "H:",
+			},
+		},
+		{
+			name: "new windows drive volume",
+			location: LOCATION{
+				DIRAttr:    "/:Music/:",
+				FILEAttr:   "song.mp3",
+				VOLUMEAttr: "H:",
+			},
+			path: "D:/Library/song.mp3",
+			want: LOCATION{
+				DIRAttr:    "/:Library/:",
+				FILEAttr:   "song.mp3",
+				VOLUMEAttr: "D:",
+			},
+		},
+		{
+			name: "macOS named volume is kept",
+			location: LOCATION{
+				DIRAttr:    "/:Users/:seren/:Downloads/:",
+				FILEAttr:   "song.mp3",
+				VOLUMEAttr: "Macintosh HD",
+			},
+			path: "/Users/seren/Music/song.mp3",
+			want: LOCATION{
+				DIRAttr:    "/:Users/:seren/:Music/:",
+				FILEAttr:   "song.mp3",
+				VOLUMEAttr: "Macintosh HD",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTraktorLocationPath(&tt.location, tt.path)
+			if tt.location != tt.want {
+				t.Errorf("setTraktorLocationPath() = %+v, want %+v", tt.location, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeTraktorEntry(t *testing.T) {
+	keeper := &ENTRY{
+		INFO: []*INFO{{PLAYCOUNTAttr: 3, RANKINGAttr: 51}},
+		CUEV2: []*CUEV2{
+			{TYPEAttr: 4, STARTAttr: 12.5},
+		},
+	}
+
+	duplicate := &ENTRY{
+		INFO: []*INFO{{PLAYCOUNTAttr: 2, RANKINGAttr: 255}},
+		CUEV2: []*CUEV2{
+			{TYPEAttr: 4, STARTAttr: 12.5},
+			{TYPEAttr: 0, STARTAttr: 30000, HOTCUEAttr: 1},
+		},
+	}
+
+	mergeTraktorEntry(keeper, duplicate)
+
+	if keeper.INFO[0].PLAYCOUNTAttr != 5 {
+		t.Errorf("expected play count 5, got %v", keeper.INFO[0].PLAYCOUNTAttr)
+	}
+
+	if keeper.INFO[0].RANKINGAttr != 255 {
+		t.Errorf("expected ranking 255, got %v", keeper.INFO[0].RANKINGAttr)
+	}
+
+	if len(keeper.CUEV2) != 2 {
+		t.Fatalf("expected 2 cues, got %v", len(keeper.CUEV2))
+	}
+
+	if keeper.CUEV2[1].STARTAttr != 30000 || keeper.CUEV2[1].HOTCUEAttr != 1 {
+		t.Errorf("expected hot cue to be copied, got %+v", keeper.CUEV2[1])
+	}
+}
+
+func TestTraktorEntryToTrack(t *testing.T) {
+	entry := &ENTRY{
+		TITLEAttr:  "Funky Song",
+		ARTISTAttr: "Coolman",
+		LOCATION: []*LOCATION{{
+			DIRAttr:    "/:Music/:",
+			FILEAttr:   "Coolman - Funky Song.wav",
+			VOLUMEAttr: "H:",
+		}},
+		ALBUM: []*ALBUM{{TITLEAttr: "Funky EP"}},
+		INFO: []*INFO{{
+			GENREAttr:         "House",
+			LABELAttr:         "Cool Records",
+			KEYAttr:           "8A",
+			PLAYTIMEFLOATAttr: 360.5,
+			FILESIZEAttr:      2000,
+		}},
+		TEMPO: []*TEMPO{{BPMAttr: 124}},
+	}
+
+	want := Track{
+		Path:     "H:/Music/Coolman - Funky Song.wav",
+		Title:    "Funky Song",
+		Artist:   "Coolman",
+		Album:    "Funky EP",
+		Genre:    "House",
+		Label:    "Cool Records",
+		Key:      "8A",
+		BPM:      124,
+		Duration: 360.5,
+		Size:     2000 * 1024,
+	}
+
+	if got := traktorEntryToTrack(entry); got != want {
+		t.Errorf("traktorEntryToTrack() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRelocateTracksKeepsEarlierChanges(t *testing.T) {
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "collection.nml")
+
+	collection := traktorXMLHeader() + `<NML VERSION="19">
+  <COLLECTION ENTRIES="2">
+    <ENTRY TITLE="One"><LOCATION DIR="/:Music/:to process/:" FILE="one.mp3" VOLUME="H:"></LOCATION></ENTRY>
+    <ENTRY TITLE="Two"><LOCATION DIR="/:Music/:to process/:" FILE="two.mp3" VOLUME="H:"></LOCATION></ENTRY>
+  </COLLECTION>
+</NML>`
+
+	if err := os.WriteFile(inPath, []byte(collection), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	moves := []map[string]string{
+		{"H:/Music/to process/one.mp3": "H:/Music/House/one.mp3"},
+		{"H:/Music/to process/two.mp3": "H:/Music/Techno/two.mp3"},
+	}
+
+	var c *Traktor
+
+	// each run starts from a new Traktor, as separate operations do
+	for _, m := range moves {
+		c = ReadTraktorOpts{CollectionInPath: inPath}.Build(helpers.Config{}).(*Traktor)
+
+		n, err := c.RelocateTracks(m)
+
+		if err != nil {
+			t.Fatalf("RelocateTracks() error = %v", err)
+		}
+
+		if n != 1 {
+			t.Fatalf("RelocateTracks() = %v, want 1", n)
+		}
+
+		// reading the collection in between runs mustn't discard the pending changes
+		err = ReadTraktorOpts{CollectionInPath: inPath}.Build(helpers.Config{}).ReadCollection()
+
+		if err != nil {
+			t.Fatalf("ReadCollection() error = %v", err)
+		}
+	}
+
+	tracks, err := c.ListTracks()
+
+	if err != nil {
+		t.Fatalf("ListTracks() error = %v", err)
+	}
+
+	want := []string{"H:/Music/House/one.mp3", "H:/Music/Techno/two.mp3"}
+
+	if len(tracks) != len(want) {
+		t.Fatalf("ListTracks() returned %v tracks, want %v", len(tracks), len(want))
+	}
+
+	for i, track := range tracks {
+		if track.Path != want[i] {
+			t.Errorf("tracks[%d].Path = %v, want %v", i, track.Path, want[i])
+		}
+	}
+}
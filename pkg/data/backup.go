@@ -0,0 +1,95 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/mattn/go-sqlite3"
+)
+
+/*
+Path returns the path of the database file, or an empty string if the database isn't stored in a file
+*/
+func (sDB *SerenDB) Path() string {
+	return sDB.path
+}
+
+/*
+Backup copies the database to destPath with SQLite's online backup API, so the database can still be
+used while it is copied. destPath mustn't already exist
+*/
+func (sDB *SerenDB) Backup(ctx context.Context, destPath string) error {
+
+	if sDB.path == "" {
+		return helpers.ErrDatabaseNotFile
+	}
+
+	if helpers.DoesFileExist(destPath) {
+		return helpers.ErrBackupExists
+	}
+
+	// the backup API needs the sqlite3 connections, which the logged connections in SerenDB hide
+	srcDB, err := sql.Open("sqlite3", dsn(sDB.path, "mode=ro"))
+
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+
+	destDB, err := sql.Open("sqlite3", dsn(destPath, "mode=rwc"))
+
+	if err != nil {
+		return err
+	}
+	defer destDB.Close()
+
+	srcConn, err := srcDB.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	destConn, err := destDB.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	err = destConn.Raw(func(destDriverConn any) error {
+		return srcConn.Raw(func(srcDriverConn any) error {
+
+			backup, err := destDriverConn.(*sqlite3.SQLiteConn).Backup("main", srcDriverConn.(*sqlite3.SQLiteConn), "main")
+
+			if err != nil {
+				return err
+			}
+
+			// copy every page in one step, which holds a read lock so writes during the backup are not half copied
+			_, err = backup.Step(-1)
+
+			if err != nil {
+				backup.Finish()
+				return err
+			}
+
+			return backup.Finish()
+		})
+	})
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error backing up database",
+				"There was an error backing up your database",
+			),
+		)
+	}
+
+	return nil
+}
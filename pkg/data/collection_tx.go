@@ -0,0 +1,125 @@
+package data
+
+import (
+	"context"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+)
+
+/*
+Contains a series of functions used to interface with the collection queries generated by sqlc
+for the purpose of processing multiple records at once
+*/
+
+/*
+TxSyncCollectionTracks makes the stored entries of a platform's collection match the given tracks, only
+entries which were added, changed or removed are written, so the search index isn't rewritten for
+entries which haven't changed
+
+The number of entries written is returned
+*/
+func (sDB *SerenDB) TxSyncCollectionTracks(ctx context.Context, platform string, tracks []CollectionTrack) (int, error) {
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return 0, fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
+	defer tx.Rollback()
+
+	qtx := sDB.Queries.WithTx(tx)
+
+	existing, err := qtx.ListCollectionTracksByPlatform(ctx, platform)
+
+	if err != nil {
+		return 0, fault.Wrap(
+			err,
+			fmsg.With("Error listing collection tracks"),
+		)
+	}
+
+	stored := make(map[string]CollectionTrack, len(existing))
+	for _, t := range existing {
+		stored[t.Path] = t
+	}
+
+	written := 0
+
+	for _, t := range tracks {
+
+		s, ok := stored[t.Path]
+		delete(stored, t.Path)
+
+		if ok && sameCollectionTrack(s, t) {
+			continue
+		}
+
+		err := qtx.UpsertCollectionTrack(ctx, UpsertCollectionTrackParams{
+			Platform: platform,
+			Path:     t.Path,
+			Title:    t.Title,
+			Artist:   t.Artist,
+			Album:    t.Album,
+			Genre:    t.Genre,
+			Label:    t.Label,
+			Comments: t.Comments,
+			Key:      t.Key,
+			Bpm:      t.Bpm,
+			Duration: t.Duration,
+		})
+
+		if err != nil {
+			return 0, fault.Wrap(
+				err,
+				fmsg.With("Error upserting collection track"),
+			)
+		}
+
+		written++
+	}
+
+	// anything left has been removed from the collection
+	for _, s := range stored {
+
+		err := qtx.DeleteCollectionTrack(ctx, s.ID)
+
+		if err != nil {
+			return 0, fault.Wrap(
+				err,
+				fmsg.With("Error deleting collection track"),
+			)
+		}
+
+		written++
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return 0, fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return written, nil
+}
+
+/*
+sameCollectionTrack returns whether the stored entry s has the same tags as the entry t read from the
+collection
+*/
+func sameCollectionTrack(s CollectionTrack, t CollectionTrack) bool {
+	return s.Title == t.Title &&
+		s.Artist == t.Artist &&
+		s.Album == t.Album &&
+		s.Genre == t.Genre &&
+		s.Label == t.Label &&
+		s.Comments == t.Comments &&
+		s.Key == t.Key &&
+		s.Bpm == t.Bpm &&
+		s.Duration == t.Duration
+}
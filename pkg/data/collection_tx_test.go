@@ -0,0 +1,83 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestTxSyncCollectionTracks(t *testing.T) {
+
+	ctx := context.Background()
+	s := func(v string) sql.NullString { return sql.NullString{Valid: true, String: v} }
+	f := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Valid: true, Float64: v} }
+
+	sDB := newTestSerenDB(t)
+
+	glue := CollectionTrack{Path: "/music/glue.mp3", Title: s("Glue"), Artist: s("Bicep"), Bpm: f(128)}
+	opal := CollectionTrack{Path: "/music/opal.mp3", Title: s("Opal"), Artist: s("Four Tet"), Bpm: f(122)}
+
+	syncs := []struct {
+		name        string
+		tracks      []CollectionTrack
+		wantWritten int
+		wantTitles  []string
+	}{
+		{
+			name:        "new collection",
+			tracks:      []CollectionTrack{glue, opal},
+			wantWritten: 2,
+			wantTitles:  []string{"Glue", "Opal"},
+		},
+		{
+			name:        "unchanged",
+			tracks:      []CollectionTrack{glue, opal},
+			wantWritten: 0,
+			wantTitles:  []string{"Glue", "Opal"},
+		},
+		{
+			name: "changed, added and removed",
+			tracks: []CollectionTrack{
+				{Path: glue.Path, Title: s("Glue (Edit)"), Artist: glue.Artist, Bpm: glue.Bpm},
+				{Path: "/music/atlas.mp3", Title: s("Atlas"), Artist: s("Bicep")},
+			},
+			wantWritten: 3,
+			wantTitles:  []string{"Atlas", "Glue (Edit)"},
+		},
+	}
+
+	for _, sync := range syncs {
+
+		written, err := sDB.TxSyncCollectionTracks(ctx, "traktor", sync.tracks)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if written != sync.wantWritten {
+			t.Errorf("%s: got %d entries written, want %d", sync.name, written, sync.wantWritten)
+		}
+
+		got := search(t, sDB, SearchParams{Source: SearchSourceCollection})
+
+		if len(got) != len(sync.wantTitles) {
+			t.Fatalf("%s: got indexed entries %v, want %v", sync.name, got, sync.wantTitles)
+		}
+
+		for i, title := range sync.wantTitles {
+			if got[i].Title != title {
+				t.Errorf("%s: got indexed entries %v, want %v", sync.name, got, sync.wantTitles)
+				break
+			}
+		}
+	}
+
+	// other platforms' entries are left alone
+	if _, err := sDB.TxSyncCollectionTracks(ctx, "rekordbox", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := search(t, sDB, SearchParams{Source: SearchSourceCollection}); len(got) != 2 {
+		t.Errorf("got indexed entries %v, want the traktor entries", got)
+	}
+}
@@ -1,7 +1,11 @@
 package data
 
 import (
+	"context"
 	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -10,24 +14,65 @@ import (
 	sqldblogger "github.com/simukti/sqldb-logger"
 )
 
+var dsnPathEscaper = strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+
 type SerenDB struct {
 	*sql.DB
 	*Queries
+	path string // empty if the database isn't stored in a file
+}
+
+/*
+dsn returns the data source name for the database file at path
+*/
+func dsn(path string, params string) string {
+	// the path is part of a URI, so characters with a meaning in URIs are escaped
+	return "file:" + dsnPathEscaper.Replace(path) + "?" + params
 }
 
+/*
+Connect opens the database at the path in config, creating it if it doesn't exist, and migrates it to
+the latest schema version
+*/
 func Connect(c helpers.Config, l zap.SugaredLogger) (*SerenDB, error) {
 
-	dsn := "file:seren.db?cache=shared&mode=rwc"
+	dbPath, err := c.GetDBPath()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dbPath), 0755)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dbDSN := dsn(dbPath, "cache=shared&mode=rwc")
+
+	// migrations run through their own handle, so every statement in them isn't logged
+	migrateDB, err := sql.Open("sqlite3", dbDSN)
+
+	if err != nil {
+		return nil, err
+	}
+	defer migrateDB.Close()
+
+	err = Migrate(context.Background(), migrateDB, dbPath, l)
+
+	if err != nil {
+		return nil, err
+	}
 
-	db, err := sql.Open("sqlite3", dsn)
+	err = checkSearchIndex(context.Background(), migrateDB, l)
 
 	if err != nil {
 		return nil, err
 	}
 
-	db = sqldblogger.OpenDriver(
-		dsn,
-		db.Driver(),
+	db := sqldblogger.OpenDriver(
+		dbDSN,
+		migrateDB.Driver(),
 		NewZapAdapter(l.Desugar()),
 	)
 
@@ -36,5 +81,6 @@ func Connect(c helpers.Config, l zap.SugaredLogger) (*SerenDB, error) {
 	return &SerenDB{
 		DB:      db,
 		Queries: queries,
+		path:    dbPath,
 	}, nil
 }
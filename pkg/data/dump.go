@@ -0,0 +1,520 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Contains the export of the streaming playlists and tracks in the database to a dump, and the import
+of a dump into another database
+
+Playlists link to their tracks by external ID rather than row ID, so a dump can be merged into a
+database with different row IDs. Empty values in a dump are imported as NULL, which the upserts leave
+as the value already in the database
+*/
+
+// DumpFormatVersion is the version of the dump format, it is increased when a change means older
+// versions of the application can't import the dump
+const DumpFormatVersion = 1
+
+type Dump struct {
+	FormatVersion       int                      `json:"formatVersion"`
+	SchemaVersion       int64                    `json:"schemaVersion"` // schema version of the database the dump was exported from
+	ExportedAt          time.Time                `json:"exportedAt"`
+	SoundCloudPlaylists []DumpSoundCloudPlaylist `json:"soundcloudPlaylists"`
+	SoundCloudTracks    []DumpSoundCloudTrack    `json:"soundcloudTracks"`
+	SpotifyPlaylists    []DumpSpotifyPlaylist    `json:"spotifyPlaylists"`
+	SpotifyTracks       []DumpSpotifyTrack       `json:"spotifyTracks"`
+}
+
+type DumpSoundCloudPlaylist struct {
+	ExternalID              int64   `json:"externalId"`
+	SourceType              string  `json:"sourceType"`
+	Name                    string  `json:"name,omitempty"`
+	SearchURL               string  `json:"searchUrl,omitempty"`
+	PermalinkURL            string  `json:"permalinkUrl,omitempty"`
+	TrackExternalIDs        []int64 `json:"trackExternalIds"`                  // in playlist order
+	RemovedTrackExternalIDs []int64 `json:"removedTrackExternalIds,omitempty"` // tracks removed from the playlist
+}
+
+type DumpSoundCloudTrack struct {
+	ExternalID          int64   `json:"externalId"`
+	Name                string  `json:"name,omitempty"`
+	PermalinkURL        string  `json:"permalinkUrl,omitempty"`
+	PurchaseTitle       string  `json:"purchaseTitle,omitempty"`
+	PurchaseURL         string  `json:"purchaseUrl,omitempty"`
+	PurchaseCategory    string  `json:"purchaseCategory,omitempty"`
+	HasDownloadsLeft    *bool   `json:"hasDownloadsLeft,omitempty"`
+	Genre               string  `json:"genre,omitempty"`
+	ArtworkURL          string  `json:"artworkUrl,omitempty"`
+	TagList             string  `json:"tagList,omitempty"`
+	PublisherArtist     string  `json:"publisherArtist,omitempty"`
+	SoundCloudUser      string  `json:"soundCloudUser,omitempty"`
+	LocalPath           string  `json:"localPath,omitempty"`
+	LocalPathBroken     *bool   `json:"localPathBroken,omitempty"`
+	RemovedFromPlaylist *bool   `json:"removedFromPlaylist,omitempty"`
+	Duration            float64 `json:"duration,omitempty"`
+}
+
+type DumpSpotifyPlaylist struct {
+	ExternalID              string   `json:"externalId"`
+	Name                    string   `json:"name,omitempty"`
+	Owner                   string   `json:"owner,omitempty"`
+	SearchURL               string   `json:"searchUrl,omitempty"`
+	PermalinkURL            string   `json:"permalinkUrl,omitempty"`
+	TrackExternalIDs        []string `json:"trackExternalIds"`                  // in playlist order
+	RemovedTrackExternalIDs []string `json:"removedTrackExternalIds,omitempty"` // tracks removed from the playlist
+}
+
+type DumpSpotifyTrack struct {
+	ExternalID          string  `json:"externalId"`
+	Name                string  `json:"name,omitempty"`
+	PermalinkURL        string  `json:"permalinkUrl,omitempty"`
+	Artists             string  `json:"artists,omitempty"`
+	Album               string  `json:"album,omitempty"`
+	ISRC                string  `json:"isrc,omitempty"`
+	Duration            float64 `json:"duration,omitempty"`
+	RemovedFromPlaylist *bool   `json:"removedFromPlaylist,omitempty"`
+}
+
+/*
+Export dumps the SoundCloud and Spotify playlists and tracks in the database, along with the links
+between them
+*/
+func (sDB *SerenDB) Export(ctx context.Context) (Dump, error) {
+
+	schemaVersion, _, err := SchemaVersion(ctx, sDB.DB)
+
+	if err != nil {
+		return Dump{}, err
+	}
+
+	d := Dump{
+		FormatVersion:       DumpFormatVersion,
+		SchemaVersion:       schemaVersion,
+		ExportedAt:          time.Now().UTC(),
+		SoundCloudPlaylists: []DumpSoundCloudPlaylist{},
+		SoundCloudTracks:    []DumpSoundCloudTrack{},
+		SpotifyPlaylists:    []DumpSpotifyPlaylist{},
+		SpotifyTracks:       []DumpSpotifyTrack{},
+	}
+
+	scPlaylists, err := sDB.ListSoundCloudPlaylists(ctx)
+
+	if err != nil {
+		return Dump{}, fault.Wrap(err, fmsg.With("Error listing SoundCloud playlists"))
+	}
+
+	scLinks, err := sDB.ListSoundCloudPlaylistTrackLinks(ctx)
+
+	if err != nil {
+		return Dump{}, fault.Wrap(err, fmsg.With("Error listing SoundCloud playlist tracks"))
+	}
+
+	type scPlaylistKey struct {
+		sourceType string
+		externalID int64
+	}
+
+	scPlaylistTracks := map[scPlaylistKey][]int64{}
+	scPlaylistRemovedTracks := map[scPlaylistKey][]int64{}
+	for _, l := range scLinks {
+		k := scPlaylistKey{l.SourceType, l.PlaylistExternalID.Int64}
+		if l.RemovedAt.Valid {
+			scPlaylistRemovedTracks[k] = append(scPlaylistRemovedTracks[k], l.TrackExternalID.Int64)
+			continue
+		}
+		scPlaylistTracks[k] = append(scPlaylistTracks[k], l.TrackExternalID.Int64)
+	}
+
+	for _, p := range scPlaylists {
+		k := scPlaylistKey{p.SourceType, p.ExternalID.Int64}
+
+		trackIDs := scPlaylistTracks[k]
+		if trackIDs == nil {
+			trackIDs = []int64{}
+		}
+
+		d.SoundCloudPlaylists = append(d.SoundCloudPlaylists, DumpSoundCloudPlaylist{
+			ExternalID:              p.ExternalID.Int64,
+			SourceType:              p.SourceType,
+			Name:                    p.Name.String,
+			SearchURL:               p.SearchUrl.String,
+			PermalinkURL:            p.PermalinkUrl.String,
+			TrackExternalIDs:        trackIDs,
+			RemovedTrackExternalIDs: scPlaylistRemovedTracks[k],
+		})
+	}
+
+	scTracks, err := sDB.ListSoundCloudTracks(ctx)
+
+	if err != nil {
+		return Dump{}, fault.Wrap(err, fmsg.With("Error listing SoundCloud tracks"))
+	}
+
+	for _, t := range scTracks {
+		d.SoundCloudTracks = append(d.SoundCloudTracks, DumpSoundCloudTrack{
+			ExternalID:          t.ExternalID.Int64,
+			Name:                t.Name.String,
+			PermalinkURL:        t.PermalinkUrl.String,
+			PurchaseTitle:       t.PurchaseTitle.String,
+			PurchaseURL:         t.PurchaseUrl.String,
+			PurchaseCategory:    t.PurchaseCategory.String,
+			HasDownloadsLeft:    boolPtr(t.HasDownloadsLeft),
+			Genre:               t.Genre.String,
+			ArtworkURL:          t.ArtworkUrl.String,
+			TagList:             t.TagList.String,
+			PublisherArtist:     t.PublisherArtist.String,
+			SoundCloudUser:      t.SoundCloudUser.String,
+			LocalPath:           t.LocalPath.String,
+			LocalPathBroken:     boolPtr(t.LocalPathBroken),
+			RemovedFromPlaylist: boolPtr(t.RemovedFromPlaylist),
+			Duration:            t.Duration.Float64,
+		})
+	}
+
+	spPlaylists, err := sDB.ListSpotifyPlaylists(ctx)
+
+	if err != nil {
+		return Dump{}, fault.Wrap(err, fmsg.With("Error listing Spotify playlists"))
+	}
+
+	spLinks, err := sDB.ListSpotifyPlaylistTrackLinks(ctx)
+
+	if err != nil {
+		return Dump{}, fault.Wrap(err, fmsg.With("Error listing Spotify playlist tracks"))
+	}
+
+	spPlaylistTracks := map[string][]string{}
+	spPlaylistRemovedTracks := map[string][]string{}
+	for _, l := range spLinks {
+		k := l.PlaylistExternalID.String
+		if l.RemovedAt.Valid {
+			spPlaylistRemovedTracks[k] = append(spPlaylistRemovedTracks[k], l.TrackExternalID.String)
+			continue
+		}
+		spPlaylistTracks[k] = append(spPlaylistTracks[k], l.TrackExternalID.String)
+	}
+
+	for _, p := range spPlaylists {
+		trackIDs := spPlaylistTracks[p.ExternalID.String]
+		if trackIDs == nil {
+			trackIDs = []string{}
+		}
+
+		d.SpotifyPlaylists = append(d.SpotifyPlaylists, DumpSpotifyPlaylist{
+			ExternalID:              p.ExternalID.String,
+			Name:                    p.Name.String,
+			Owner:                   p.Owner.String,
+			SearchURL:               p.SearchUrl.String,
+			PermalinkURL:            p.PermalinkUrl.String,
+			TrackExternalIDs:        trackIDs,
+			RemovedTrackExternalIDs: spPlaylistRemovedTracks[p.ExternalID.String],
+		})
+	}
+
+	spTracks, err := sDB.ListSpotifyTracks(ctx)
+
+	if err != nil {
+		return Dump{}, fault.Wrap(err, fmsg.With("Error listing Spotify tracks"))
+	}
+
+	for _, t := range spTracks {
+		d.SpotifyTracks = append(d.SpotifyTracks, DumpSpotifyTrack{
+			ExternalID:          t.ExternalID.String,
+			Name:                t.Name.String,
+			PermalinkURL:        t.PermalinkUrl.String,
+			Artists:             t.Artists.String,
+			Album:               t.Album.String,
+			ISRC:                t.Isrc.String,
+			Duration:            t.Duration.Float64,
+			RemovedFromPlaylist: boolPtr(t.RemovedFromPlaylist),
+		})
+	}
+
+	return d, nil
+}
+
+/*
+TxImportDump merges the dump into the database, playlists and tracks already in the database are
+matched by their external ID and updated
+*/
+func (sDB *SerenDB) TxImportDump(ctx context.Context, d Dump) error {
+
+	if d.FormatVersion > DumpFormatVersion {
+		return helpers.ErrUnsupportedDumpVersion
+	}
+
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
+	defer tx.Rollback()
+
+	qtx := sDB.Queries.WithTx(tx)
+
+	scTrackIDs := make(map[int64]int64, len(d.SoundCloudTracks))
+
+	for _, t := range d.SoundCloudTracks {
+
+		insertedT, err := qtx.UpsertSoundCloudTrack(ctx, upsertSoundCloudTrackParams(SoundcloudTrack{
+			ExternalID:       sql.NullInt64{Valid: true, Int64: t.ExternalID},
+			Name:             nullString(t.Name),
+			PermalinkUrl:     nullString(t.PermalinkURL),
+			PurchaseTitle:    nullString(t.PurchaseTitle),
+			PurchaseUrl:      nullString(t.PurchaseURL),
+			PurchaseCategory: nullString(t.PurchaseCategory),
+			HasDownloadsLeft: nullBool(t.HasDownloadsLeft),
+			Genre:            nullString(t.Genre),
+			ArtworkUrl:       nullString(t.ArtworkURL),
+			TagList:          nullString(t.TagList),
+			PublisherArtist:  nullString(t.PublisherArtist),
+			SoundCloudUser:   nullString(t.SoundCloudUser),
+			Duration:         sql.NullFloat64{Valid: t.Duration > 0, Float64: t.Duration},
+		}))
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error inserting SoundCloud track"),
+			)
+		}
+
+		// local state is only imported if it was exported, so it isn't cleared by dumps without it
+		if t.LocalPath != "" {
+			err = qtx.SetSoundCloudTrackLocalPath(ctx, SetSoundCloudTrackLocalPathParams{
+				LocalPath:       nullString(t.LocalPath),
+				LocalPathBroken: nullBool(t.LocalPathBroken),
+				ExternalID:      insertedT.ExternalID,
+			})
+
+			if err != nil {
+				return fault.Wrap(
+					err,
+					fmsg.With("Error setting SoundCloud track local path"),
+				)
+			}
+		}
+
+		if t.RemovedFromPlaylist != nil {
+			err = qtx.SetSoundCloudTrackRemovedFromPlaylist(ctx, SetSoundCloudTrackRemovedFromPlaylistParams{
+				RemovedFromPlaylist: nullBool(t.RemovedFromPlaylist),
+				ExternalID:          insertedT.ExternalID,
+			})
+
+			if err != nil {
+				return fault.Wrap(
+					err,
+					fmsg.With("Error setting SoundCloud track removed from playlist"),
+				)
+			}
+		}
+
+		scTrackIDs[t.ExternalID] = insertedT.ID
+	}
+
+	for _, p := range d.SoundCloudPlaylists {
+
+		insertedP, err := qtx.UpsertSoundCloudPlaylist(ctx, UpsertSoundCloudPlaylistParams{
+			ExternalID:   sql.NullInt64{Valid: true, Int64: p.ExternalID},
+			Name:         nullString(p.Name),
+			SearchUrl:    nullString(p.SearchURL),
+			PermalinkUrl: nullString(p.PermalinkURL),
+			SourceType:   p.SourceType,
+		})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error inserting SoundCloud playlist"),
+			)
+		}
+
+		for i, externalID := range p.TrackExternalIDs {
+
+			trackID, ok := scTrackIDs[externalID]
+
+			// links to tracks missing from the dump are skipped rather than failing the import
+			if !ok {
+				continue
+			}
+
+			err = linkSoundCloudPlaylistTrack(ctx, qtx, insertedP.ID, trackID, int64(i))
+
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, externalID := range p.RemovedTrackExternalIDs {
+
+			trackID, ok := scTrackIDs[externalID]
+
+			if !ok {
+				continue
+			}
+
+			// removed tracks missing from the playlist are linked as removed, so the playlist keeps
+			// the tracks removed from it
+			err = qtx.InsertRemovedSoundCloudPlaylistTrack(ctx, InsertRemovedSoundCloudPlaylistTrackParams{
+				SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: insertedP.ID},
+				SoundcloudTrackID:    sql.NullInt64{Valid: true, Int64: trackID},
+			})
+
+			if err != nil {
+				return fault.Wrap(
+					err,
+					fmsg.With("Error inserting removed SoundCloud playlist track"),
+				)
+			}
+
+			err = unlinkSoundCloudPlaylistTrack(ctx, qtx, insertedP.ID, trackID)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		err = qtx.SetSoundCloudTracksRemovedFromPlaylistByPlaylistID(ctx, sql.NullInt64{Valid: true, Int64: insertedP.ID})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error setting SoundCloud tracks removed from playlist"),
+			)
+		}
+	}
+
+	spTrackIDs := make(map[string]int64, len(d.SpotifyTracks))
+
+	for _, t := range d.SpotifyTracks {
+
+		insertedT, err := qtx.UpsertSpotifyTrack(ctx, upsertSpotifyTrackParams(SpotifyTrack{
+			ExternalID:          nullString(t.ExternalID),
+			Name:                nullString(t.Name),
+			PermalinkUrl:        nullString(t.PermalinkURL),
+			Artists:             nullString(t.Artists),
+			Album:               nullString(t.Album),
+			Isrc:                nullString(t.ISRC),
+			Duration:            sql.NullFloat64{Valid: t.Duration > 0, Float64: t.Duration},
+			RemovedFromPlaylist: nullBool(t.RemovedFromPlaylist),
+		}))
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error inserting Spotify track"),
+			)
+		}
+
+		spTrackIDs[t.ExternalID] = insertedT.ID
+	}
+
+	for _, p := range d.SpotifyPlaylists {
+
+		insertedP, err := qtx.UpsertSpotifyPlaylist(ctx, UpsertSpotifyPlaylistParams{
+			ExternalID:   nullString(p.ExternalID),
+			Name:         nullString(p.Name),
+			Owner:        nullString(p.Owner),
+			SearchUrl:    nullString(p.SearchURL),
+			PermalinkUrl: nullString(p.PermalinkURL),
+		})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error inserting Spotify playlist"),
+			)
+		}
+
+		for i, externalID := range p.TrackExternalIDs {
+
+			trackID, ok := spTrackIDs[externalID]
+
+			if !ok {
+				continue
+			}
+
+			err = linkSpotifyPlaylistTrack(ctx, qtx, insertedP.ID, trackID, int64(i))
+
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, externalID := range p.RemovedTrackExternalIDs {
+
+			trackID, ok := spTrackIDs[externalID]
+
+			if !ok {
+				continue
+			}
+
+			err = qtx.InsertRemovedSpotifyPlaylistTrack(ctx, InsertRemovedSpotifyPlaylistTrackParams{
+				SpotifyPlaylistID: sql.NullInt64{Valid: true, Int64: insertedP.ID},
+				SpotifyTrackID:    sql.NullInt64{Valid: true, Int64: trackID},
+			})
+
+			if err != nil {
+				return fault.Wrap(
+					err,
+					fmsg.With("Error inserting removed Spotify playlist track"),
+				)
+			}
+
+			err = unlinkSpotifyPlaylistTrack(ctx, qtx, insertedP.ID, trackID)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		err = qtx.SetSpotifyTracksRemovedFromPlaylistByPlaylistID(ctx, sql.NullInt64{Valid: true, Int64: insertedP.ID})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error setting Spotify tracks removed from playlist"),
+			)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{Valid: s != "", String: s}
+}
+
+func nullBool(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Valid: true, Bool: *b}
+}
+
+func boolPtr(b sql.NullBool) *bool {
+	if !b.Valid {
+		return nil
+	}
+	return &b.Bool
+}
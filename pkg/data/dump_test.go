@@ -0,0 +1,210 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestExportImportDump(t *testing.T) {
+
+	ctx := context.Background()
+	i := func(v int64) sql.NullInt64 { return sql.NullInt64{Valid: true, Int64: v} }
+	s := func(v string) sql.NullString { return sql.NullString{Valid: true, String: v} }
+	b := func(v bool) sql.NullBool { return sql.NullBool{Valid: true, Bool: v} }
+
+	src := newTestSerenDB(t)
+
+	err := src.TxUpsertSoundCloudPlaylistAndTracks(ctx,
+		SoundcloudPlaylist{ExternalID: i(10), Name: s("Warm up"), PermalinkUrl: s("https://soundcloud.com/dj/sets/warm-up"), SourceType: "playlist"},
+		[]SoundcloudTrack{
+			{ExternalID: i(1), Name: s("Track 1"), PurchaseUrl: s("https://hypeddit.com/1"), PurchaseCategory: s("free_gate"), HasDownloadsLeft: b(false)},
+			{ExternalID: i(2), Name: s("Track 2"), Duration: sql.NullFloat64{Valid: true, Float64: 300}},
+		},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// track 2 has been removed from the playlist, and track 4 added
+	err = src.TxSaveSoundCloudPlaylistRefresh(ctx,
+		SoundcloudPlaylist{ExternalID: i(10), SourceType: "playlist"},
+		[]SoundcloudTrack{{ExternalID: i(4), Name: s("Track 4")}, {ExternalID: i(1)}, {ExternalID: i(2), RemovedFromPlaylist: b(true)}},
+		nil,
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a track which isn't in a playlist any more
+	err = src.TxUpsertSoundCloudTracks(ctx, []SoundcloudTrack{{ExternalID: i(3), Name: s("Track 3")}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = src.SetSoundCloudTrackRemovedFromPlaylist(ctx, SetSoundCloudTrackRemovedFromPlaylistParams{RemovedFromPlaylist: b(true), ExternalID: i(3)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = src.TxUpsertSpotifyPlaylistAndTracks(ctx,
+		SpotifyPlaylist{ExternalID: s("sp1"), Name: s("Peak time"), Owner: s("dj")},
+		[]SpotifyTrack{
+			{ExternalID: s("spt1"), Name: s("Spotify track"), Artists: s(`["A"]`), Isrc: s("GB0000000001")},
+			{ExternalID: s("spt2"), Name: s("Spotify track 2")},
+		},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// spotify track 1 has been removed from the playlist, and track 3 added before track 2
+	err = src.TxUpsertSpotifyPlaylistAndTracks(ctx,
+		SpotifyPlaylist{ExternalID: s("sp1")},
+		[]SpotifyTrack{{ExternalID: s("spt3"), Name: s("Spotify track 3")}, {ExternalID: s("spt2")}, {ExternalID: s("spt1"), RemovedFromPlaylist: b(true)}},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dump, err := src.Export(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dump.FormatVersion != DumpFormatVersion || dump.SchemaVersion == 0 {
+		t.Errorf("got format version %d and schema version %d", dump.FormatVersion, dump.SchemaVersion)
+	}
+
+	wantSpotify := DumpSpotifyPlaylist{
+		ExternalID:              "sp1",
+		Name:                    "Peak time",
+		Owner:                   "dj",
+		TrackExternalIDs:        []string{"spt3", "spt2"},
+		RemovedTrackExternalIDs: []string{"spt1"},
+	}
+
+	if diff := cmp.Diff([]DumpSpotifyPlaylist{wantSpotify}, dump.SpotifyPlaylists); diff != "" {
+		t.Errorf("exported Spotify playlists mismatch (-want +got):\n%s", diff)
+	}
+
+	// dumps are imported from the JSON written by export
+	j, err := json.Marshal(dump)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var imported Dump
+
+	if err := json.Unmarshal(j, &imported); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := newTestSerenDB(t)
+
+	// a track already in the destination, with a local path the dump doesn't have
+	err = dest.TxSaveSoundCloudTracks(ctx, []SoundcloudTrack{{ExternalID: i(1), Name: s("Old name"), LocalPath: s("/music/track 1.mp3")}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// importing twice updates rather than duplicates
+	for n := 0; n < 2; n++ {
+		if err := dest.TxImportDump(ctx, imported); err != nil {
+			t.Fatalf("importing dump: %v", err)
+		}
+	}
+
+	got, err := dest.Export(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the local path in the destination is kept, everything else comes from the dump
+	want := dump
+	for i := range want.SoundCloudTracks {
+		if want.SoundCloudTracks[i].ExternalID == 1 {
+			want.SoundCloudTracks[i].LocalPath = "/music/track 1.mp3"
+		}
+	}
+
+	opts := []cmp.Option{
+		cmpopts.IgnoreFields(Dump{}, "ExportedAt"),
+		cmpopts.SortSlices(func(a, b DumpSoundCloudTrack) bool { return a.ExternalID < b.ExternalID }),
+		cmpopts.SortSlices(func(a, b int64) bool { return a < b }),
+	}
+
+	if diff := cmp.Diff(want, got, opts...); diff != "" {
+		t.Errorf("exported dump after import mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestImportDumpNewerFormat(t *testing.T) {
+
+	sDB := newTestSerenDB(t)
+
+	err := sDB.TxImportDump(context.Background(), Dump{FormatVersion: DumpFormatVersion + 1})
+
+	if !helpers.ErrorContains(err, helpers.ErrUnsupportedDumpVersion) {
+		t.Errorf("got error %v, want %v", err, helpers.ErrUnsupportedDumpVersion)
+	}
+}
+
+func TestBackup(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+
+	err := sDB.TxUpsertSoundCloudTracks(ctx, []SoundcloudTrack{
+		{ExternalID: sql.NullInt64{Valid: true, Int64: 1}, Name: sql.NullString{Valid: true, String: "Track 1"}},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backupPath := filepath.Join(t.TempDir(), "backup.db")
+
+	if err := sDB.Backup(ctx, backupPath); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := sql.Open("sqlite3", backupPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+
+	tracks, err := New(backup).ListSoundCloudTracks(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracks) != 1 || tracks[0].Name.String != "Track 1" {
+		t.Errorf("got tracks %+v in backup, want Track 1", tracks)
+	}
+
+	err = sDB.Backup(ctx, backupPath)
+
+	if !helpers.ErrorContains(err, helpers.ErrBackupExists) {
+		t.Errorf("got error %v backing up over an existing file, want %v", err, helpers.ErrBackupExists)
+	}
+}
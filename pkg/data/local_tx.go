@@ -0,0 +1,63 @@
+package data
+
+import (
+	"context"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+)
+
+/*
+Contains a series of functions used to interface with the local file queries generated by sqlc
+for the purpose of processing multiple records at once
+*/
+
+func (sDB *SerenDB) TxUpsertLocalFiles(ctx context.Context, files []LocalFile) error {
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
+
+	defer tx.Rollback()
+
+	qtx := sDB.Queries.WithTx(tx)
+
+	for _, f := range files {
+
+		_, err := qtx.UpsertLocalFile(ctx, UpsertLocalFileParams{
+			Path:        f.Path,
+			Size:        f.Size,
+			ModifiedAt:  f.ModifiedAt,
+			ContentHash: f.ContentHash,
+			Title:       f.Title,
+			Artist:      f.Artist,
+			Album:       f.Album,
+			Genre:       f.Genre,
+			Duration:    f.Duration,
+			Fingerprint: f.Fingerprint,
+			Missing:     f.Missing,
+		})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error inserting local file"),
+			)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+)
+
+/*
+Contains a series of functions used to interface with the track matching queries generated by sqlc
+for the purpose of processing multiple records at once
+*/
+
+/*
+TxSetSoundCloudTrackMatches stores the given matches, and removes any existing
+match for the tracks in unmatchedTrackIDs
+*/
+func (sDB *SerenDB) TxSetSoundCloudTrackMatches(ctx context.Context, matches []SoundcloudTrackMatch, unmatchedTrackIDs []int64) error {
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
+
+	defer tx.Rollback()
+
+	qtx := sDB.Queries.WithTx(tx)
+
+	for _, m := range matches {
+
+		_, err := qtx.UpsertSoundCloudTrackMatch(ctx, UpsertSoundCloudTrackMatchParams{
+			SoundcloudTrackID: m.SoundcloudTrackID,
+			Source:            m.Source,
+			Path:              m.Path,
+			Confidence:        m.Confidence,
+			Reason:            m.Reason,
+		})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error inserting track match"),
+			)
+		}
+	}
+
+	for _, id := range unmatchedTrackIDs {
+
+		err := qtx.DeleteSoundCloudTrackMatchByTrackID(ctx, sql.NullInt64{Valid: true, Int64: id})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error deleting track match"),
+			)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return nil
+}
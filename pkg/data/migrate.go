@@ -0,0 +1,170 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/db"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/pressly/goose/v3"
+	"go.uber.org/zap"
+)
+
+/*
+Applies the migrations embedded from db/migrations to the database when connecting, so a new install
+has its tables created and an existing database is upgraded along with the application
+*/
+
+/*
+newMigrationProvider returns a goose provider for the embedded migrations, along with the go migrations
+which can't be written in SQL
+*/
+func newMigrationProvider(sqlDB *sql.DB) (*goose.Provider, error) {
+
+	migrations, err := fs.Sub(db.Migrations, "migrations")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return goose.NewProvider(
+		goose.DialectSQLite3,
+		sqlDB,
+		migrations,
+		goose.WithGoMigrations(newSearchIndexMigration()),
+	)
+}
+
+/*
+SchemaVersion returns the schema version of the database, and the latest version the embedded
+migrations would migrate it to
+*/
+func SchemaVersion(ctx context.Context, sqlDB *sql.DB) (current int64, latest int64, err error) {
+
+	provider, err := newMigrationProvider(sqlDB)
+
+	if err != nil {
+		return 0, 0, fault.Wrap(
+			err,
+			fmsg.With("Error loading migrations"),
+		)
+	}
+
+	current, err = provider.GetDBVersion(ctx)
+
+	if err != nil {
+		return 0, 0, fault.Wrap(
+			err,
+			fmsg.With("Error getting database schema version"),
+		)
+	}
+
+	sources := provider.ListSources()
+
+	if len(sources) > 0 {
+		latest = sources[len(sources)-1].Version
+	}
+
+	return current, latest, nil
+}
+
+/*
+Migrate applies any embedded migrations which haven't been applied to the database
+
+An existing database at dbPath is backed up next to it before migrating, dbPath can be empty for
+databases which aren't stored in a file. A database with a schema version newer than the latest
+migration was created by a newer version of the application, rather than guess how to downgrade it
+helpers.ErrDatabaseNewerThanApp is returned
+*/
+func Migrate(ctx context.Context, sqlDB *sql.DB, dbPath string, l zap.SugaredLogger) error {
+
+	current, latest, err := SchemaVersion(ctx, sqlDB)
+
+	if err != nil {
+		return err
+	}
+
+	if current > latest {
+		return fault.Wrap(
+			helpers.ErrDatabaseNewerThanApp,
+			fmsg.WithDesc(
+				fmt.Sprintf("database schema version %d is newer than latest migration %d", current, latest),
+				fmt.Sprintf(
+					"Your database (schema version %d) was last used by a newer version of Seren, this version only supports up to schema version %d. Update Seren, or restore a backup of the database taken before upgrading",
+					current,
+					latest,
+				),
+			),
+		)
+	}
+
+	if current == latest {
+		return nil
+	}
+
+	if current > 0 && dbPath != "" {
+		backupPath := fmt.Sprintf("%s.v%d-%s.bak", dbPath, current, time.Now().Format("20060102150405"))
+
+		err := backupDatabase(ctx, sqlDB, backupPath)
+
+		if err != nil {
+			return err
+		}
+
+		l.Infof("Backed up database to %s before migrating", backupPath)
+	}
+
+	provider, err := newMigrationProvider(sqlDB)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error loading migrations"),
+		)
+	}
+
+	results, err := provider.Up(ctx)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error applying migrations",
+				"There was an error upgrading your database, a backup was taken before upgrading",
+			),
+		)
+	}
+
+	for _, r := range results {
+		l.Infof("Applied migration %d in %s", r.Source.Version, r.Duration)
+	}
+
+	l.Infof("Migrated database from schema version %d to %d", current, latest)
+
+	return nil
+}
+
+/*
+backupDatabase writes a consistent copy of the database to backupPath, which mustn't already exist
+*/
+func backupDatabase(ctx context.Context, sqlDB *sql.DB, backupPath string) error {
+
+	_, err := sqlDB.ExecContext(ctx, "VACUUM INTO ?", backupPath)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error backing up database",
+				"There was an error backing up your database before upgrading it, so it wasn't upgraded",
+			),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+/*
+Contains the migration creating the full-text search index over the library, it covers SoundCloud
+tracks, local files and collection entries and is kept up to date by triggers on their tables
+
+This is a go migration rather than a file in db/migrations as FTS5 is only available when go-sqlite3 is
+built with the 'sqlite_fts5' tag, without it the index falls back to FTS4 and results aren't ranked. An
+FTS4 index is rebuilt with FTS5 when the database is next opened by a build which includes it
+*/
+
+const searchIndexMigrationVersion = 20240108120000
+
+const createSearchIndexFTS5 = `CREATE VIRTUAL TABLE search_index USING fts5(
+    source UNINDEXED,
+    source_id UNINDEXED,
+    title,
+    artist,
+    label,
+    genre,
+    tags,
+    comments,
+    tokenize = 'unicode61'
+)`
+
+const createSearchIndexFTS4 = `CREATE VIRTUAL TABLE search_index USING fts4(
+    source,
+    source_id,
+    title,
+    artist,
+    label,
+    genre,
+    tags,
+    comments,
+    notindexed=source,
+    notindexed=source_id,
+    tokenize=unicode61
+)`
+
+/*
+searchIndexSources holds how the rows of each table are indexed, the rowid of a row in the index is the
+ID of the row in its table * 3 + offset, so the triggers can find it without scanning the index
+*/
+var searchIndexSources = []struct {
+	source  string
+	table   string
+	offset  int
+	columns string // title, artist, label, genre, tags, comments, %[1]s is the row being indexed
+}{
+	{
+		source:  SearchSourceSoundCloud,
+		table:   "soundcloud_tracks",
+		offset:  0,
+		columns: "%[1]s.name, trim(coalesce(%[1]s.publisher_artist, '') || ' ' || coalesce(%[1]s.sound_cloud_user, '')), NULL, %[1]s.genre, %[1]s.tag_list, NULL",
+	},
+	{
+		source:  SearchSourceLocal,
+		table:   "local_files",
+		offset:  1,
+		columns: "%[1]s.title, %[1]s.artist, NULL, %[1]s.genre, NULL, NULL",
+	},
+	{
+		source:  SearchSourceCollection,
+		table:   "collection_tracks",
+		offset:  2,
+		columns: "%[1]s.title, %[1]s.artist, %[1]s.label, %[1]s.genre, NULL, %[1]s.comments",
+	},
+}
+
+func newSearchIndexMigration() *goose.Migration {
+	return goose.NewGoMigration(
+		searchIndexMigrationVersion,
+		&goose.GoFunc{RunTx: upSearchIndex},
+		&goose.GoFunc{RunTx: downSearchIndex},
+	)
+}
+
+/*
+upSearchIndex creates the search index, filled with the rows already in the database, and the triggers
+which keep it up to date
+*/
+func upSearchIndex(ctx context.Context, tx *sql.Tx) error {
+
+	err := createSearchIndex(ctx, tx, createSearchIndexFTS5)
+
+	if err != nil && strings.Contains(err.Error(), "no such module: fts5") {
+		return createSearchIndex(ctx, tx, createSearchIndexFTS4)
+	}
+
+	return err
+}
+
+/*
+createSearchIndex creates the search index with the given create statement, then fills it and creates
+its triggers
+*/
+func createSearchIndex(ctx context.Context, tx *sql.Tx, create string) error {
+
+	_, err := tx.ExecContext(ctx, create)
+
+	if err != nil {
+		return err
+	}
+
+	for _, s := range searchIndexSources {
+
+		// inserts the row r into the index
+		insert := func(r string) string {
+			return fmt.Sprintf(
+				"INSERT INTO search_index (rowid, source, source_id, title, artist, label, genre, tags, comments)\n"+
+					"SELECT %[1]s.id * 3 + %[2]d, '%[3]s', %[1]s.id, ",
+				r, s.offset, s.source,
+			) + fmt.Sprintf(s.columns, r)
+		}
+
+		remove := fmt.Sprintf("DELETE FROM search_index WHERE rowid = old.id * 3 + %d", s.offset)
+
+		stmts := []string{
+			insert("r") + "\nFROM " + s.table + " r",
+			fmt.Sprintf("CREATE TRIGGER %[1]s_search_insert AFTER INSERT ON %[1]s BEGIN\n%[2]s;\nEND", s.table, insert("new")),
+			fmt.Sprintf("CREATE TRIGGER %[1]s_search_update AFTER UPDATE ON %[1]s BEGIN\n%[2]s;\n%[3]s;\nEND", s.table, remove, insert("new")),
+			fmt.Sprintf("CREATE TRIGGER %[1]s_search_delete AFTER DELETE ON %[1]s BEGIN\n%[2]s;\nEND", s.table, remove),
+		}
+
+		for _, stmt := range stmts {
+			_, err = tx.ExecContext(ctx, stmt)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func downSearchIndex(ctx context.Context, tx *sql.Tx) error {
+
+	for _, s := range searchIndexSources {
+		for _, trigger := range []string{"insert", "update", "delete"} {
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER %s_search_%s", s.table, trigger))
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, "DROP TABLE search_index")
+
+	return err
+}
+
+/*
+rebuildSearchIndex drops the search index and creates it again from the tables it covers, so an index
+created by a build without FTS5 is upgraded once the application is built with it
+*/
+func rebuildSearchIndex(ctx context.Context, sqlDB *sql.DB) error {
+
+	tx, err := sqlDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = downSearchIndex(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	err = upSearchIndex(ctx, tx)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
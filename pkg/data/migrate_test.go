@@ -0,0 +1,152 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"go.uber.org/zap"
+)
+
+func openTestDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "seren.db")
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=rwc")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return db, path
+}
+
+/*
+newTestSerenDB returns a migrated database in a temporary directory
+*/
+func newTestSerenDB(t *testing.T) *SerenDB {
+	t.Helper()
+
+	db, path := openTestDB(t)
+
+	if err := Migrate(context.Background(), db, path, *zap.NewNop().Sugar()); err != nil {
+		t.Fatal(err)
+	}
+
+	return &SerenDB{DB: db, Queries: New(db), path: path}
+}
+
+func TestMigrate(t *testing.T) {
+
+	ctx := context.Background()
+	l := *zap.NewNop().Sugar()
+	db, path := openTestDB(t)
+
+	err := Migrate(ctx, db, path, l)
+
+	if err != nil {
+		t.Fatalf("migrating new database: %v", err)
+	}
+
+	current, latest, err := SchemaVersion(ctx, db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if current != latest || latest == 0 {
+		t.Fatalf("got schema version %d, want %d", current, latest)
+	}
+
+	backups, _ := filepath.Glob(path + ".*.bak")
+
+	if len(backups) != 0 {
+		t.Errorf("got backups %v of a new database, want none", backups)
+	}
+
+	// roll back the latest migration so the database has one to apply
+	provider, err := newMigrationProvider(db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Migrate(ctx, db, path, l)
+
+	if err != nil {
+		t.Fatalf("migrating existing database: %v", err)
+	}
+
+	backups, _ = filepath.Glob(path + ".*.bak")
+
+	if len(backups) != 1 {
+		t.Fatalf("got backups %v, want one", backups)
+	}
+
+	backup, err := sql.Open("sqlite3", backups[0])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+
+	backupVersion, _, err := SchemaVersion(ctx, backup)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if backupVersion >= latest {
+		t.Errorf("got backup schema version %d, want the version before migrating", backupVersion)
+	}
+
+	// running again has nothing to apply, so nothing is backed up
+	err = Migrate(ctx, db, path, l)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backups, _ = filepath.Glob(path + ".*.bak")
+
+	if len(backups) != 1 {
+		t.Errorf("got backups %v, want one", backups)
+	}
+}
+
+func TestMigrateNewerDatabase(t *testing.T) {
+
+	ctx := context.Background()
+	l := *zap.NewNop().Sugar()
+	db, path := openTestDB(t)
+
+	err := Migrate(ctx, db, path, l)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, latest, _ := SchemaVersion(ctx, db)
+
+	// a migration added by a newer version of the application
+	_, err = db.Exec("INSERT INTO goose_db_version (version_id, is_applied) VALUES (?, 1)", latest+1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Migrate(ctx, db, path, l)
+
+	if !helpers.ErrorContains(err, helpers.ErrDatabaseNewerThanApp) {
+		t.Errorf("got error %v, want %v", err, helpers.ErrDatabaseNewerThanApp)
+	}
+}
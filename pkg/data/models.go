@@ -8,6 +8,66 @@ import (
 	"database/sql"
 )
 
+type CollectionTrack struct {
+	ID        int64
+	CreatedAt sql.NullTime
+	UpdatedAt sql.NullTime
+	Platform  string
+	Path      string
+	Title     sql.NullString
+	Artist    sql.NullString
+	Album     sql.NullString
+	Genre     sql.NullString
+	Label     sql.NullString
+	Comments  sql.NullString
+	Key       sql.NullString
+	Bpm       sql.NullFloat64
+	Duration  sql.NullFloat64
+}
+
+type LocalFile struct {
+	ID          int64
+	CreatedAt   sql.NullTime
+	UpdatedAt   sql.NullTime
+	Path        sql.NullString
+	Size        sql.NullInt64
+	ModifiedAt  sql.NullTime
+	ContentHash sql.NullString
+	Title       sql.NullString
+	Artist      sql.NullString
+	Album       sql.NullString
+	Genre       sql.NullString
+	Duration    sql.NullFloat64
+	Fingerprint sql.NullString
+	Missing     sql.NullBool
+}
+
+type Purchase struct {
+	ID              int64
+	CreatedAt       sql.NullTime
+	UpdatedAt       sql.NullTime
+	Store           string
+	ExternalID      string
+	OrderID         sql.NullString
+	PurchasedAt     sql.NullTime
+	Kind            sql.NullString
+	Artist          sql.NullString
+	Title           sql.NullString
+	Album           sql.NullString
+	Label           sql.NullString
+	Isrc            sql.NullString
+	Url             sql.NullString
+	Duration        sql.NullFloat64
+	LocalPath       sql.NullString
+	MatchConfidence sql.NullFloat64
+}
+
+type SoundcloudClientID struct {
+	ID        int64
+	CreatedAt sql.NullTime
+	ClientID  sql.NullString
+}
+
 type SoundcloudPlaylist struct {
 	ID           int64
 	CreatedAt    sql.NullTime
@@ -16,11 +76,33 @@ type SoundcloudPlaylist struct {
 	Name         sql.NullString
 	SearchUrl    sql.NullString
 	PermalinkUrl sql.NullString
+	SourceType   string
+}
+
+type SoundcloudPlaylistChange struct {
+	ID                   int64
+	CreatedAt            sql.NullTime
+	SoundcloudPlaylistID sql.NullInt64
+	SoundcloudTrackID    sql.NullInt64
+	ChangeType           sql.NullString
+	OldValue             sql.NullString
+	NewValue             sql.NullString
 }
 
 type SoundcloudPlaylistTrack struct {
 	SoundcloudTrackID    sql.NullInt64
 	SoundcloudPlaylistID sql.NullInt64
+	Position             sql.NullInt64
+	AddedAt              sql.NullTime
+	RemovedAt            sql.NullTime
+}
+
+type SoundcloudPlaylistTrackHistory struct {
+	ID                   int64
+	SoundcloudPlaylistID sql.NullInt64
+	SoundcloudTrackID    sql.NullInt64
+	AddedAt              sql.NullTime
+	RemovedAt            sql.NullTime
 }
 
 type SoundcloudTrack struct {
@@ -41,4 +123,93 @@ type SoundcloudTrack struct {
 	LocalPath           sql.NullString
 	LocalPathBroken     sql.NullBool
 	RemovedFromPlaylist sql.NullBool
+	Duration            sql.NullFloat64
+	PurchaseCategory    sql.NullString
+}
+
+type SoundcloudTrackMatch struct {
+	ID                int64
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+	SoundcloudTrackID sql.NullInt64
+	Source            sql.NullString
+	Path              sql.NullString
+	Confidence        sql.NullFloat64
+	Reason            sql.NullString
+}
+
+type SpotifyPlaylist struct {
+	ID           int64
+	CreatedAt    sql.NullTime
+	UpdatedAt    sql.NullTime
+	ExternalID   sql.NullString
+	Name         sql.NullString
+	Owner        sql.NullString
+	SearchUrl    sql.NullString
+	PermalinkUrl sql.NullString
+}
+
+type SpotifyPlaylistTrack struct {
+	SpotifyTrackID    sql.NullInt64
+	SpotifyPlaylistID sql.NullInt64
+	Position          sql.NullInt64
+	AddedAt           sql.NullTime
+	RemovedAt         sql.NullTime
+}
+
+type SpotifyTrack struct {
+	ID                  int64
+	CreatedAt           sql.NullTime
+	UpdatedAt           sql.NullTime
+	ExternalID          sql.NullString
+	Name                sql.NullString
+	PermalinkUrl        sql.NullString
+	Artists             sql.NullString
+	Album               sql.NullString
+	Isrc                sql.NullString
+	Duration            sql.NullFloat64
+	RemovedFromPlaylist sql.NullBool
+}
+
+type StreamingPlaylist struct {
+	Platform     string
+	ID           int64
+	CreatedAt    sql.NullTime
+	UpdatedAt    sql.NullTime
+	ExternalID   sql.NullString
+	SourceType   string
+	Name         sql.NullString
+	Owner        sql.NullString
+	SearchUrl    sql.NullString
+	PermalinkUrl sql.NullString
+}
+
+type StreamingPlaylistTrack struct {
+	Platform   string
+	PlaylistID sql.NullInt64
+	TrackID    sql.NullInt64
+	Position   sql.NullInt64
+	RemovedAt  sql.NullTime
+}
+
+type StreamingTrack struct {
+	Platform            string
+	ID                  int64
+	CreatedAt           sql.NullTime
+	UpdatedAt           sql.NullTime
+	ExternalID          sql.NullString
+	Name                sql.NullString
+	Artists             sql.NullString
+	Album               sql.NullString
+	Genre               sql.NullString
+	Isrc                sql.NullString
+	PermalinkUrl        sql.NullString
+	ArtworkUrl          sql.NullString
+	PurchaseUrl         sql.NullString
+	PurchaseTitle       sql.NullString
+	PurchaseCategory    sql.NullString
+	Downloadable        sql.NullBool
+	LocalPath           sql.NullString
+	RemovedFromPlaylist sql.NullBool
+	Duration            sql.NullFloat64
 }
@@ -0,0 +1,106 @@
+package data
+
+import (
+	"context"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+)
+
+/*
+TxUpsertPurchases upserts purchases read from a store's export, returning the number upserted
+*/
+func (sDB *SerenDB) TxUpsertPurchases(ctx context.Context, purchases []Purchase) (int, error) {
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return 0, fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
+	defer tx.Rollback()
+
+	qtx := sDB.Queries.WithTx(tx)
+
+	for _, p := range purchases {
+
+		_, err := qtx.UpsertPurchase(ctx, UpsertPurchaseParams{
+			Store:       p.Store,
+			ExternalID:  p.ExternalID,
+			OrderID:     p.OrderID,
+			PurchasedAt: p.PurchasedAt,
+			Kind:        p.Kind,
+			Artist:      p.Artist,
+			Title:       p.Title,
+			Album:       p.Album,
+			Label:       p.Label,
+			Isrc:        p.Isrc,
+			Url:         p.Url,
+			Duration:    p.Duration,
+		})
+
+		if err != nil {
+			return 0, fault.Wrap(
+				err,
+				fmsg.With("Error inserting purchase"),
+			)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return 0, fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return len(purchases), nil
+}
+
+/*
+TxSetPurchaseMatches stores the local path and match confidence of each purchase by its ID, purchases
+with no local path are marked as not downloaded
+*/
+func (sDB *SerenDB) TxSetPurchaseMatches(ctx context.Context, purchases []Purchase) error {
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
+	defer tx.Rollback()
+
+	qtx := sDB.Queries.WithTx(tx)
+
+	for _, p := range purchases {
+
+		err := qtx.SetPurchaseMatch(ctx, SetPurchaseMatchParams{
+			LocalPath:       p.LocalPath,
+			MatchConfidence: p.MatchConfidence,
+			ID:              p.ID,
+		})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error setting purchase match"),
+			)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"go.uber.org/zap"
+)
+
+/*
+Contains the queries against the full-text search index, the index is a virtual table which sqlc can't
+generate code for so they are written here, see migrate_search_index.go for how it is created
+*/
+
+// Sources of the tracks in the search index
+const (
+	SearchSourceSoundCloud = "soundcloud"
+	SearchSourceLocal      = "local"
+	SearchSourceCollection = "collection"
+)
+
+/*
+SearchParams holds what to search the index for, every condition which is set must match
+*/
+type SearchParams struct {
+	Match  string          // an FTS query against the indexed columns
+	Source string          // one of the SearchSource constants
+	MinBpm sql.NullFloat64 // inclusive, only collection entries have a bpm
+	MaxBpm sql.NullFloat64 // exclusive
+	Key    sql.NullString  // compared case insensitively, only collection entries have a key
+	Limit  int64
+}
+
+/*
+SearchResult is a track found in the search index
+*/
+type SearchResult struct {
+	Source             string
+	SourceID           int64
+	Title              sql.NullString
+	Artist             sql.NullString
+	Path               sql.NullString // the local path of SoundCloud tracks, and the path of files and entries
+	ExternalID         sql.NullInt64  // SoundCloud tracks only
+	PermalinkUrl       sql.NullString
+	PlaylistExternalID sql.NullInt64 // a SoundCloud playlist the track is in
+	PlaylistSourceType sql.NullString
+	Bpm                sql.NullFloat64
+	Key                sql.NullString
+}
+
+const searchSelect = `SELECT
+    search_index.source,
+    search_index.source_id,
+    search_index.title,
+    search_index.artist,
+    coalesce(t.local_path, f.path, c.path),
+    t.external_id,
+    t.permalink_url,
+    p.external_id,
+    p.source_type,
+    c.bpm,
+    c.key
+FROM search_index
+LEFT JOIN soundcloud_tracks t
+    ON search_index.source = 'soundcloud' AND t.id = search_index.source_id
+LEFT JOIN local_files f
+    ON search_index.source = 'local' AND f.id = search_index.source_id
+LEFT JOIN collection_tracks c
+    ON search_index.source = 'collection' AND c.id = search_index.source_id
+LEFT JOIN soundcloud_playlists p
+    ON p.id = (
+        SELECT pt.soundcloud_playlist_id
+        FROM soundcloud_playlist_tracks pt
+        WHERE pt.soundcloud_track_id = t.id
+            AND pt.removed_at IS NULL
+        ORDER BY pt.soundcloud_playlist_id
+        LIMIT 1
+    )
+WHERE coalesce(f.missing, false) = false`
+
+/*
+Search returns the tracks in the search index matching the params, best matches first when the index
+uses FTS5 and by title otherwise
+*/
+func (sDB *SerenDB) Search(ctx context.Context, arg SearchParams) ([]SearchResult, error) {
+
+	ranked, err := searchIndexUsesFTS5(ctx, sDB.DB)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := searchSelect
+	var args []any
+
+	if arg.Match != "" {
+		query += "\nAND search_index MATCH ?"
+		args = append(args, arg.Match)
+	}
+
+	if arg.Source != "" {
+		query += "\nAND search_index.source = ?"
+		args = append(args, arg.Source)
+	}
+
+	if arg.MinBpm.Valid {
+		query += "\nAND c.bpm >= ?"
+		args = append(args, arg.MinBpm)
+	}
+
+	if arg.MaxBpm.Valid {
+		query += "\nAND c.bpm < ?"
+		args = append(args, arg.MaxBpm)
+	}
+
+	if arg.Key.Valid {
+		query += "\nAND upper(c.key) = upper(?)"
+		args = append(args, arg.Key)
+	}
+
+	if arg.Match != "" && ranked {
+		query += "\nORDER BY rank"
+	} else {
+		query += "\nORDER BY search_index.title COLLATE NOCASE, search_index.rowid"
+	}
+
+	if arg.Limit > 0 {
+		query += "\nLIMIT ?"
+		args = append(args, arg.Limit)
+	}
+
+	rows, err := sDB.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"Error searching",
+				"There was an error searching your library, check the search is valid",
+			),
+		)
+	}
+	defer rows.Close()
+
+	var items []SearchResult
+
+	for rows.Next() {
+		var i SearchResult
+		if err := rows.Scan(
+			&i.Source,
+			&i.SourceID,
+			&i.Title,
+			&i.Artist,
+			&i.Path,
+			&i.ExternalID,
+			&i.PermalinkUrl,
+			&i.PlaylistExternalID,
+			&i.PlaylistSourceType,
+			&i.Bpm,
+			&i.Key,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+/*
+searchIndexUsesFTS5 returns whether the search index was created with FTS5, rather than falling back
+to FTS4 which can't rank results
+*/
+func searchIndexUsesFTS5(ctx context.Context, sqlDB *sql.DB) (bool, error) {
+
+	var ddl string
+
+	err := sqlDB.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE name = 'search_index'").Scan(&ddl)
+
+	if err != nil {
+		return false, fault.Wrap(
+			err,
+			fmsg.With("Error checking search index"),
+		)
+	}
+
+	return strings.Contains(strings.ToLower(ddl), "fts5"), nil
+}
+
+/*
+checkSearchIndex checks the search index can be used by this build of the application, an index created
+with FTS5 can't be written to without it, so every change to a track would fail
+
+An FTS4 index works but can't rank results, so it's rebuilt with FTS5 if this build includes it,
+otherwise a warning is logged
+*/
+func checkSearchIndex(ctx context.Context, sqlDB *sql.DB, l zap.SugaredLogger) error {
+
+	usesFTS5, err := searchIndexUsesFTS5(ctx, sqlDB)
+
+	if err != nil {
+		return err
+	}
+
+	var hasFTS5 bool
+
+	err = sqlDB.QueryRowContext(ctx, "SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&hasFTS5)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error checking for FTS5"),
+		)
+	}
+
+	switch {
+	case usesFTS5 && !hasFTS5:
+		return fault.Wrap(
+			helpers.ErrSearchIndexNeedsFTS5,
+			fmsg.WithDesc(
+				"search index uses FTS5 which this build doesn't include",
+				"Your database was last used by a version of Seren built with FTS5, this version was built without it. Use a version built with '-tags sqlite_fts5'",
+			),
+		)
+	case !usesFTS5 && hasFTS5:
+		l.Info("Search index was created by a build without FTS5, rebuilding it so search results are ranked")
+
+		err = rebuildSearchIndex(ctx, sqlDB)
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.WithDesc(
+					"error rebuilding search index with FTS5",
+					"There was an error rebuilding the search index",
+				),
+			)
+		}
+	case !usesFTS5:
+		l.Warn("Built without FTS5 so search results aren't ranked, build with '-tags sqlite_fts5' to rank them")
+	}
+
+	return nil
+}
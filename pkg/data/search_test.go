@@ -0,0 +1,270 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// searchResultKey identifies a result by where it came from and its title
+type searchResultKey struct {
+	Source string
+	Title  string
+}
+
+func search(t *testing.T, sDB *SerenDB, arg SearchParams) []searchResultKey {
+	t.Helper()
+
+	results, err := sDB.Search(context.Background(), arg)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []searchResultKey{}
+	for _, r := range results {
+		keys = append(keys, searchResultKey{Source: r.Source, Title: r.Title.String})
+	}
+
+	return keys
+}
+
+func TestSearch(t *testing.T) {
+
+	ctx := context.Background()
+	s := func(v string) sql.NullString { return sql.NullString{Valid: true, String: v} }
+	f := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Valid: true, Float64: v} }
+
+	db, path := openTestDB(t)
+
+	provider, err := newMigrationProvider(db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.UpTo(ctx, searchIndexMigrationVersion-1); err != nil {
+		t.Fatal(err)
+	}
+
+	sDB := &SerenDB{DB: db, Queries: New(db), path: path}
+
+	// tracks saved before the index is created are indexed when it is
+	err = sDB.TxUpsertSoundCloudPlaylistAndTracks(ctx,
+		SoundcloudPlaylist{ExternalID: sql.NullInt64{Valid: true, Int64: 10}, Name: s("Warm up"), SourceType: "playlist"},
+		[]SoundcloudTrack{
+			{ExternalID: sql.NullInt64{Valid: true, Int64: 1}, Name: s("Rolling Hills"), PublisherArtist: s("Bicep"), Genre: s("House"), TagList: s("deep dub")},
+		},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	err = sDB.TxUpsertLocalFiles(ctx, []LocalFile{
+		{Path: s("/music/glue.mp3"), Title: s("Glue"), Artist: s("Bicep"), Genre: s("Breaks")},
+		{Path: s("/music/gone.mp3"), Title: s("Gone"), Artist: s("Bicep"), Missing: sql.NullBool{Valid: true, Bool: true}},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sDB.TxSyncCollectionTracks(ctx, "traktor", []CollectionTrack{
+		{Path: "/music/glue.mp3", Title: s("Glue"), Artist: s("Bicep"), Label: s("Ninja Tune"), Key: s("8A"), Bpm: f(127.9)},
+		{Path: "/music/opal.mp3", Title: s("Opal"), Artist: s("Four Tet"), Label: s("Text"), Comments: s("peak time"), Key: s("3A"), Bpm: f(122)},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// renamed after being indexed
+	err = sDB.TxUpsertSoundCloudTracks(ctx, []SoundcloudTrack{
+		{ExternalID: sql.NullInt64{Valid: true, Int64: 1}, Name: s("Rolling Hills (Edit)")},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		arg  SearchParams
+		want []searchResultKey
+	}{
+		{
+			name: "any column",
+			arg:  SearchParams{Match: "bicep"},
+			want: []searchResultKey{{"local", "Glue"}, {"collection", "Glue"}, {"soundcloud", "Rolling Hills (Edit)"}},
+		},
+		{
+			name: "column and prefix",
+			arg:  SearchParams{Match: "title:roll*"},
+			want: []searchResultKey{{"soundcloud", "Rolling Hills (Edit)"}},
+		},
+		{
+			name: "old title",
+			arg:  SearchParams{Match: "title:hills title:rolling title:edit"},
+			want: []searchResultKey{{"soundcloud", "Rolling Hills (Edit)"}},
+		},
+		{
+			name: "tags",
+			arg:  SearchParams{Match: "tags:dub"},
+			want: []searchResultKey{{"soundcloud", "Rolling Hills (Edit)"}},
+		},
+		{
+			name: "label and comments",
+			arg:  SearchParams{Match: "label:text comments:peak"},
+			want: []searchResultKey{{"collection", "Opal"}},
+		},
+		{
+			name: "source",
+			arg:  SearchParams{Match: "glue", Source: SearchSourceLocal},
+			want: []searchResultKey{{"local", "Glue"}},
+		},
+		{
+			name: "bpm and key without text",
+			arg:  SearchParams{MinBpm: f(127.5), MaxBpm: f(128.5), Key: s("8a")},
+			want: []searchResultKey{{"collection", "Glue"}},
+		},
+		{
+			name: "missing files",
+			arg:  SearchParams{Match: "gone"},
+			want: []searchResultKey{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := search(t, sDB, tt.arg)
+
+			less := func(a, b searchResultKey) bool { return a.Source+a.Title < b.Source+b.Title }
+
+			if diff := cmp.Diff(tt.want, got, cmpopts.SortSlices(less)); diff != "" {
+				t.Errorf("results mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// entries removed from the collection are removed from the index
+	if _, err := sDB.TxSyncCollectionTracks(ctx, "traktor", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := search(t, sDB, SearchParams{Source: SearchSourceCollection}); len(got) != 0 {
+		t.Errorf("got %v, want no collection entries", got)
+	}
+
+	results, err := sDB.Search(ctx, SearchParams{Match: "rolling", Limit: 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].ExternalID.Int64 != 1 || results[0].PlaylistExternalID.Int64 != 10 || results[0].PlaylistSourceType.String != "playlist" {
+		t.Errorf("got results %+v, want track 1 in playlist 10", results)
+	}
+}
+
+func TestCheckSearchIndex(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+
+	var hasFTS5 bool
+
+	if err := sDB.QueryRowContext(ctx, "SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&hasFTS5); err != nil {
+		t.Fatal(err)
+	}
+
+	core, logs := observer.New(zap.WarnLevel)
+
+	if err := checkSearchIndex(ctx, sDB.DB, *zap.New(core).Sugar()); err != nil {
+		t.Fatal(err)
+	}
+
+	// an index created without FTS5 is warned about, as results aren't ranked
+	want := 0
+	if !hasFTS5 {
+		want = 1
+	}
+
+	if logs.Len() != want {
+		t.Errorf("got %d warnings, want %d", logs.Len(), want)
+	}
+}
+
+func TestCheckSearchIndexRebuildsFTS4Index(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+
+	var hasFTS5 bool
+
+	if err := sDB.QueryRowContext(ctx, "SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&hasFTS5); err != nil {
+		t.Fatal(err)
+	}
+
+	if !hasFTS5 {
+		t.Skip("built without FTS5, run with '-tags sqlite_fts5'")
+	}
+
+	_, err := sDB.UpsertLocalFile(ctx, UpsertLocalFileParams{
+		Path:  sql.NullString{Valid: true, String: "/music/bicep - glue.mp3"},
+		Title: sql.NullString{Valid: true, String: "Glue"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// recreate the index as a build without FTS5 would have
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := downSearchIndex(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := createSearchIndex(ctx, tx, createSearchIndexFTS4); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkSearchIndex(ctx, sDB.DB, *zap.NewNop().Sugar()); err != nil {
+		t.Fatal(err)
+	}
+
+	usesFTS5, err := searchIndexUsesFTS5(ctx, sDB.DB)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !usesFTS5 {
+		t.Errorf("got search index using FTS4 after checking it, want FTS5")
+	}
+
+	got := search(t, sDB, SearchParams{Match: "glue", Limit: 10})
+	want := []searchResultKey{{Source: SearchSourceLocal, Title: "Glue"}}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("search results mismatch after rebuilding (-want +got):\n%s", diff)
+	}
+}
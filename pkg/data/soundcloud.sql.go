@@ -15,11 +15,19 @@ SELECT COUNT(*)
 FROM soundcloud_tracks t
 JOIN soundcloud_playlist_tracks pt 
     ON t.id = pt.soundcloud_track_id
-WHERE pt.soundcloud_playlist_id = ?1
+JOIN soundcloud_playlists p 
+    ON pt.soundcloud_playlist_id = p.id
+WHERE p.external_id = ?1
+AND p.source_type = ?2
 `
 
-func (q *Queries) CountSoundCloudTracksByExternalID(ctx context.Context, playlistExternalID sql.NullInt64) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countSoundCloudTracksByExternalID, playlistExternalID)
+type CountSoundCloudTracksByExternalIDParams struct {
+	PlaylistExternalID sql.NullInt64
+	SourceType         string
+}
+
+func (q *Queries) CountSoundCloudTracksByExternalID(ctx context.Context, arg CountSoundCloudTracksByExternalIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSoundCloudTracksByExternalID, arg.PlaylistExternalID, arg.SourceType)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -44,10 +52,16 @@ const getNumSoundCloudPlaylistByExternalID = `-- name: GetNumSoundCloudPlaylistB
 SELECT count(*)
 FROM soundcloud_playlists
 WHERE external_id = ?1
+AND source_type = ?2
 `
 
-func (q *Queries) GetNumSoundCloudPlaylistByExternalID(ctx context.Context, externalID sql.NullInt64) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getNumSoundCloudPlaylistByExternalID, externalID)
+type GetNumSoundCloudPlaylistByExternalIDParams struct {
+	ExternalID sql.NullInt64
+	SourceType string
+}
+
+func (q *Queries) GetNumSoundCloudPlaylistByExternalID(ctx context.Context, arg GetNumSoundCloudPlaylistByExternalIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getNumSoundCloudPlaylistByExternalID, arg.ExternalID, arg.SourceType)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -66,8 +80,31 @@ func (q *Queries) GetNumSoundCloudPlaylistByURL(ctx context.Context, url sql.Nul
 	return count, err
 }
 
+const insertRemovedSoundCloudPlaylistTrack = `-- name: InsertRemovedSoundCloudPlaylistTrack :exec
+INSERT INTO soundcloud_playlist_tracks (
+    soundcloud_playlist_id,
+    soundcloud_track_id,
+    removed_at
+) VALUES (
+    ?1,
+    ?2,
+    CURRENT_TIMESTAMP
+) ON CONFLICT (soundcloud_playlist_id, soundcloud_track_id) DO NOTHING
+`
+
+type InsertRemovedSoundCloudPlaylistTrackParams struct {
+	SoundcloudPlaylistID sql.NullInt64
+	SoundcloudTrackID    sql.NullInt64
+}
+
+// Links a track to the playlist as already removed, unless it's already linked
+func (q *Queries) InsertRemovedSoundCloudPlaylistTrack(ctx context.Context, arg InsertRemovedSoundCloudPlaylistTrackParams) error {
+	_, err := q.db.ExecContext(ctx, insertRemovedSoundCloudPlaylistTrack, arg.SoundcloudPlaylistID, arg.SoundcloudTrackID)
+	return err
+}
+
 const listSoundCloudPlaylists = `-- name: ListSoundCloudPlaylists :many
-SELECT id, created_at, updated_at, external_id, name, search_url, permalink_url 
+SELECT id, created_at, updated_at, external_id, name, search_url, permalink_url, source_type 
 FROM soundcloud_playlists
 `
 
@@ -88,6 +125,7 @@ func (q *Queries) ListSoundCloudPlaylists(ctx context.Context) ([]SoundcloudPlay
 			&i.Name,
 			&i.SearchUrl,
 			&i.PermalinkUrl,
+			&i.SourceType,
 		); err != nil {
 			return nil, err
 		}
@@ -102,18 +140,13 @@ func (q *Queries) ListSoundCloudPlaylists(ctx context.Context) ([]SoundcloudPlay
 	return items, nil
 }
 
-const listSoundCloudTracksByPlaylistExternalID = `-- name: ListSoundCloudTracksByPlaylistExternalID :many
-SELECT t.id, t.created_at, t.updated_at, t.external_id, t.name, t.permalink_url, t.purchase_title, t.purchase_url, t.has_downloads_left, t.genre, t.artwork_url, t.tag_list, t.publisher_artist, t.sound_cloud_user, t.local_path, t.local_path_broken, t.removed_from_playlist
-FROM soundcloud_tracks t
-JOIN soundcloud_playlist_tracks pt 
-    ON t.id = pt.soundcloud_track_id
-JOIN soundcloud_playlists p 
-    ON pt.soundcloud_playlist_id = p.id
-WHERE p.external_id = ?1
+const listSoundCloudTracks = `-- name: ListSoundCloudTracks :many
+SELECT id, created_at, updated_at, external_id, name, permalink_url, purchase_title, purchase_url, has_downloads_left, genre, artwork_url, tag_list, publisher_artist, sound_cloud_user, local_path, local_path_broken, removed_from_playlist, duration, purchase_category
+FROM soundcloud_tracks
 `
 
-func (q *Queries) ListSoundCloudTracksByPlaylistExternalID(ctx context.Context, playlistExternalID sql.NullInt64) ([]SoundcloudTrack, error) {
-	rows, err := q.db.QueryContext(ctx, listSoundCloudTracksByPlaylistExternalID, playlistExternalID)
+func (q *Queries) ListSoundCloudTracks(ctx context.Context) ([]SoundcloudTrack, error) {
+	rows, err := q.db.QueryContext(ctx, listSoundCloudTracks)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +172,83 @@ func (q *Queries) ListSoundCloudTracksByPlaylistExternalID(ctx context.Context,
 			&i.LocalPath,
 			&i.LocalPathBroken,
 			&i.RemovedFromPlaylist,
+			&i.Duration,
+			&i.PurchaseCategory,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSoundCloudTracksByPlaylistExternalID = `-- name: ListSoundCloudTracksByPlaylistExternalID :many
+SELECT
+    t.id, t.created_at, t.updated_at, t.external_id, t.name, t.permalink_url, t.purchase_title, t.purchase_url, t.has_downloads_left, t.genre, t.artwork_url, t.tag_list, t.publisher_artist, t.sound_cloud_user, t.local_path, t.local_path_broken, t.removed_from_playlist, t.duration, t.purchase_category,
+    pt.position,
+    pt.added_at,
+    pt.removed_at
+FROM soundcloud_tracks t
+JOIN soundcloud_playlist_tracks pt 
+    ON t.id = pt.soundcloud_track_id
+JOIN soundcloud_playlists p 
+    ON pt.soundcloud_playlist_id = p.id
+WHERE p.external_id = ?1
+AND p.source_type = ?2
+ORDER BY pt.position, t.id
+`
+
+type ListSoundCloudTracksByPlaylistExternalIDParams struct {
+	PlaylistExternalID sql.NullInt64
+	SourceType         string
+}
+
+type ListSoundCloudTracksByPlaylistExternalIDRow struct {
+	SoundcloudTrack SoundcloudTrack
+	Position        sql.NullInt64
+	AddedAt         sql.NullTime
+	RemovedAt       sql.NullTime
+}
+
+// Lists the tracks in playlist order, along with when each was added to and removed from the playlist
+func (q *Queries) ListSoundCloudTracksByPlaylistExternalID(ctx context.Context, arg ListSoundCloudTracksByPlaylistExternalIDParams) ([]ListSoundCloudTracksByPlaylistExternalIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSoundCloudTracksByPlaylistExternalID, arg.PlaylistExternalID, arg.SourceType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSoundCloudTracksByPlaylistExternalIDRow
+	for rows.Next() {
+		var i ListSoundCloudTracksByPlaylistExternalIDRow
+		if err := rows.Scan(
+			&i.SoundcloudTrack.ID,
+			&i.SoundcloudTrack.CreatedAt,
+			&i.SoundcloudTrack.UpdatedAt,
+			&i.SoundcloudTrack.ExternalID,
+			&i.SoundcloudTrack.Name,
+			&i.SoundcloudTrack.PermalinkUrl,
+			&i.SoundcloudTrack.PurchaseTitle,
+			&i.SoundcloudTrack.PurchaseUrl,
+			&i.SoundcloudTrack.HasDownloadsLeft,
+			&i.SoundcloudTrack.Genre,
+			&i.SoundcloudTrack.ArtworkUrl,
+			&i.SoundcloudTrack.TagList,
+			&i.SoundcloudTrack.PublisherArtist,
+			&i.SoundcloudTrack.SoundCloudUser,
+			&i.SoundcloudTrack.LocalPath,
+			&i.SoundcloudTrack.LocalPathBroken,
+			&i.SoundcloudTrack.RemovedFromPlaylist,
+			&i.SoundcloudTrack.Duration,
+			&i.SoundcloudTrack.PurchaseCategory,
+			&i.Position,
+			&i.AddedAt,
+			&i.RemovedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -154,11 +264,12 @@ func (q *Queries) ListSoundCloudTracksByPlaylistExternalID(ctx context.Context,
 }
 
 const listSoundCloudTracksByPlaylistID = `-- name: ListSoundCloudTracksByPlaylistID :many
-SELECT t.id, t.created_at, t.updated_at, t.external_id, t.name, t.permalink_url, t.purchase_title, t.purchase_url, t.has_downloads_left, t.genre, t.artwork_url, t.tag_list, t.publisher_artist, t.sound_cloud_user, t.local_path, t.local_path_broken, t.removed_from_playlist
+SELECT t.id, t.created_at, t.updated_at, t.external_id, t.name, t.permalink_url, t.purchase_title, t.purchase_url, t.has_downloads_left, t.genre, t.artwork_url, t.tag_list, t.publisher_artist, t.sound_cloud_user, t.local_path, t.local_path_broken, t.removed_from_playlist, t.duration, t.purchase_category
 FROM soundcloud_tracks t
 JOIN soundcloud_playlist_tracks pt 
     ON t.id = pt.soundcloud_track_id
 WHERE soundcloud_playlist_id = ?1
+ORDER BY pt.position, t.id
 `
 
 func (q *Queries) ListSoundCloudTracksByPlaylistID(ctx context.Context, playlistID sql.NullInt64) ([]SoundcloudTrack, error) {
@@ -188,6 +299,8 @@ func (q *Queries) ListSoundCloudTracksByPlaylistID(ctx context.Context, playlist
 			&i.LocalPath,
 			&i.LocalPathBroken,
 			&i.RemovedFromPlaylist,
+			&i.Duration,
+			&i.PurchaseCategory,
 		); err != nil {
 			return nil, err
 		}
@@ -203,7 +316,7 @@ func (q *Queries) ListSoundCloudTracksByPlaylistID(ctx context.Context, playlist
 }
 
 const listSoundCloudTracksHasLocalPath = `-- name: ListSoundCloudTracksHasLocalPath :many
-SELECT t.id, t.created_at, t.updated_at, t.external_id, t.name, t.permalink_url, t.purchase_title, t.purchase_url, t.has_downloads_left, t.genre, t.artwork_url, t.tag_list, t.publisher_artist, t.sound_cloud_user, t.local_path, t.local_path_broken, t.removed_from_playlist
+SELECT t.id, t.created_at, t.updated_at, t.external_id, t.name, t.permalink_url, t.purchase_title, t.purchase_url, t.has_downloads_left, t.genre, t.artwork_url, t.tag_list, t.publisher_artist, t.sound_cloud_user, t.local_path, t.local_path_broken, t.removed_from_playlist, t.duration, t.purchase_category
 FROM soundcloud_tracks t
 WHERE local_path IS NOT NULL
 `
@@ -235,6 +348,8 @@ func (q *Queries) ListSoundCloudTracksHasLocalPath(ctx context.Context) ([]Sound
 			&i.LocalPath,
 			&i.LocalPathBroken,
 			&i.RemovedFromPlaylist,
+			&i.Duration,
+			&i.PurchaseCategory,
 		); err != nil {
 			return nil, err
 		}
@@ -249,6 +364,82 @@ func (q *Queries) ListSoundCloudTracksHasLocalPath(ctx context.Context) ([]Sound
 	return items, nil
 }
 
+const removeSoundCloudPlaylistTrack = `-- name: RemoveSoundCloudPlaylistTrack :exec
+UPDATE soundcloud_playlist_tracks
+SET removed_at = coalesce(removed_at, CURRENT_TIMESTAMP)
+WHERE soundcloud_playlist_id = ?1
+    AND soundcloud_track_id = ?2
+`
+
+type RemoveSoundCloudPlaylistTrackParams struct {
+	SoundcloudPlaylistID sql.NullInt64
+	SoundcloudTrackID    sql.NullInt64
+}
+
+func (q *Queries) RemoveSoundCloudPlaylistTrack(ctx context.Context, arg RemoveSoundCloudPlaylistTrackParams) error {
+	_, err := q.db.ExecContext(ctx, removeSoundCloudPlaylistTrack, arg.SoundcloudPlaylistID, arg.SoundcloudTrackID)
+	return err
+}
+
+const setSoundCloudTrackLocalPath = `-- name: SetSoundCloudTrackLocalPath :exec
+UPDATE soundcloud_tracks
+SET updated_at = CURRENT_TIMESTAMP,
+    local_path = ?1,
+    local_path_broken = ?2
+WHERE external_id = ?3
+`
+
+type SetSoundCloudTrackLocalPathParams struct {
+	LocalPath       sql.NullString
+	LocalPathBroken sql.NullBool
+	ExternalID      sql.NullInt64
+}
+
+func (q *Queries) SetSoundCloudTrackLocalPath(ctx context.Context, arg SetSoundCloudTrackLocalPathParams) error {
+	_, err := q.db.ExecContext(ctx, setSoundCloudTrackLocalPath, arg.LocalPath, arg.LocalPathBroken, arg.ExternalID)
+	return err
+}
+
+const setSoundCloudTrackRemovedFromPlaylist = `-- name: SetSoundCloudTrackRemovedFromPlaylist :exec
+UPDATE soundcloud_tracks
+SET updated_at = CURRENT_TIMESTAMP,
+    removed_from_playlist = ?1
+WHERE external_id = ?2
+`
+
+type SetSoundCloudTrackRemovedFromPlaylistParams struct {
+	RemovedFromPlaylist sql.NullBool
+	ExternalID          sql.NullInt64
+}
+
+func (q *Queries) SetSoundCloudTrackRemovedFromPlaylist(ctx context.Context, arg SetSoundCloudTrackRemovedFromPlaylistParams) error {
+	_, err := q.db.ExecContext(ctx, setSoundCloudTrackRemovedFromPlaylist, arg.RemovedFromPlaylist, arg.ExternalID)
+	return err
+}
+
+const setSoundCloudTracksRemovedFromPlaylistByPlaylistID = `-- name: SetSoundCloudTracksRemovedFromPlaylistByPlaylistID :exec
+UPDATE soundcloud_tracks
+SET updated_at = CURRENT_TIMESTAMP,
+    removed_from_playlist = NOT EXISTS (
+        SELECT 1
+        FROM soundcloud_playlist_tracks pt
+        WHERE pt.soundcloud_track_id = soundcloud_tracks.id
+            AND pt.removed_at IS NULL
+    )
+WHERE id IN (
+    SELECT soundcloud_track_id
+    FROM soundcloud_playlist_tracks
+    WHERE soundcloud_playlist_id = ?1
+)
+`
+
+// Sets removed_from_playlist on each track in the playlist, a track is only removed once it has been
+// removed from every playlist it was in
+func (q *Queries) SetSoundCloudTracksRemovedFromPlaylistByPlaylistID(ctx context.Context, playlistID sql.NullInt64) error {
+	_, err := q.db.ExecContext(ctx, setSoundCloudTracksRemovedFromPlaylistByPlaylistID, playlistID)
+	return err
+}
+
 const upsertSoundCloudPlaylist = `-- name: UpsertSoundCloudPlaylist :one
 INSERT INTO soundcloud_playlists (
     created_at,
@@ -256,22 +447,24 @@ INSERT INTO soundcloud_playlists (
     external_id,
     name,
     search_url,
-    permalink_url
+    permalink_url,
+    source_type
 ) VALUES (
     CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP,
     ?1,
     ?2,
     ?3,
-    ?4
-) ON CONFLICT (external_id) DO UPDATE SET
+    ?4,
+    ?5
+) ON CONFLICT (source_type, external_id) DO UPDATE SET
     updated_at = CURRENT_TIMESTAMP,
 
     name = coalesce(?2, name),
     search_url = coalesce(?3, search_url),
     permalink_url = coalesce(?4, permalink_url)
 
-RETURNING id, created_at, updated_at, external_id, name, search_url, permalink_url
+RETURNING id, created_at, updated_at, external_id, name, search_url, permalink_url, source_type
 `
 
 type UpsertSoundCloudPlaylistParams struct {
@@ -279,6 +472,7 @@ type UpsertSoundCloudPlaylistParams struct {
 	Name         sql.NullString
 	SearchUrl    sql.NullString
 	PermalinkUrl sql.NullString
+	SourceType   string
 }
 
 func (q *Queries) UpsertSoundCloudPlaylist(ctx context.Context, arg UpsertSoundCloudPlaylistParams) (SoundcloudPlaylist, error) {
@@ -287,6 +481,7 @@ func (q *Queries) UpsertSoundCloudPlaylist(ctx context.Context, arg UpsertSoundC
 		arg.Name,
 		arg.SearchUrl,
 		arg.PermalinkUrl,
+		arg.SourceType,
 	)
 	var i SoundcloudPlaylist
 	err := row.Scan(
@@ -297,6 +492,7 @@ func (q *Queries) UpsertSoundCloudPlaylist(ctx context.Context, arg UpsertSoundC
 		&i.Name,
 		&i.SearchUrl,
 		&i.PermalinkUrl,
+		&i.SourceType,
 	)
 	return i, err
 }
@@ -304,23 +500,42 @@ func (q *Queries) UpsertSoundCloudPlaylist(ctx context.Context, arg UpsertSoundC
 const upsertSoundCloudPlaylistTrack = `-- name: UpsertSoundCloudPlaylistTrack :one
 INSERT INTO soundcloud_playlist_tracks (
     soundcloud_playlist_id,
-    soundcloud_track_id
+    soundcloud_track_id,
+    position,
+    added_at
 ) VALUES (
     ?1,
-    ?2
-) ON CONFLICT (soundcloud_playlist_id, soundcloud_track_id) DO NOTHING
-RETURNING soundcloud_track_id, soundcloud_playlist_id
+    ?2,
+    ?3,
+    CURRENT_TIMESTAMP
+) ON CONFLICT (soundcloud_playlist_id, soundcloud_track_id) DO UPDATE SET
+    position = coalesce(?3, position),
+    added_at = CASE
+        WHEN removed_at IS NULL THEN coalesce(added_at, CURRENT_TIMESTAMP)
+        ELSE CURRENT_TIMESTAMP
+    END,
+    removed_at = NULL
+RETURNING soundcloud_track_id, soundcloud_playlist_id, position, added_at, removed_at
 `
 
 type UpsertSoundCloudPlaylistTrackParams struct {
 	SoundcloudPlaylistID sql.NullInt64
 	SoundcloudTrackID    sql.NullInt64
+	Position             sql.NullInt64
 }
 
+// Adds the track to the playlist, or moves it if it's already there. A track which had been removed
+// from the playlist is added again
 func (q *Queries) UpsertSoundCloudPlaylistTrack(ctx context.Context, arg UpsertSoundCloudPlaylistTrackParams) (SoundcloudPlaylistTrack, error) {
-	row := q.db.QueryRowContext(ctx, upsertSoundCloudPlaylistTrack, arg.SoundcloudPlaylistID, arg.SoundcloudTrackID)
+	row := q.db.QueryRowContext(ctx, upsertSoundCloudPlaylistTrack, arg.SoundcloudPlaylistID, arg.SoundcloudTrackID, arg.Position)
 	var i SoundcloudPlaylistTrack
-	err := row.Scan(&i.SoundcloudTrackID, &i.SoundcloudPlaylistID)
+	err := row.Scan(
+		&i.SoundcloudTrackID,
+		&i.SoundcloudPlaylistID,
+		&i.Position,
+		&i.AddedAt,
+		&i.RemovedAt,
+	)
 	return i, err
 }
 
@@ -339,9 +554,8 @@ INSERT INTO soundcloud_tracks (
     tag_list,
     publisher_artist,
     sound_cloud_user,
-    local_path,
-    local_path_broken,
-    removed_from_playlist
+    duration,
+    purchase_category
 ) VALUES (
     CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP,
@@ -357,8 +571,7 @@ INSERT INTO soundcloud_tracks (
     ?10,
     ?11,
     ?12,
-    ?13,
-    ?14
+    ?13
 ) ON CONFLICT (external_id) DO UPDATE SET
     updated_at = CURRENT_TIMESTAMP,
 
@@ -372,30 +585,30 @@ INSERT INTO soundcloud_tracks (
     tag_list = coalesce(?9, tag_list),
     publisher_artist = coalesce(?10, publisher_artist),
     sound_cloud_user = coalesce(?11, sound_cloud_user),
-    local_path = coalesce(?12, local_path),
-    local_path_broken = coalesce(?13, local_path_broken),
-    removed_from_playlist = coalesce(?14, removed_from_playlist)
+    duration = coalesce(?12, duration),
+    purchase_category = coalesce(?13, purchase_category)
 
-RETURNING id, created_at, updated_at, external_id, name, permalink_url, purchase_title, purchase_url, has_downloads_left, genre, artwork_url, tag_list, publisher_artist, sound_cloud_user, local_path, local_path_broken, removed_from_playlist
+RETURNING id, created_at, updated_at, external_id, name, permalink_url, purchase_title, purchase_url, has_downloads_left, genre, artwork_url, tag_list, publisher_artist, sound_cloud_user, local_path, local_path_broken, removed_from_playlist, duration, purchase_category
 `
 
 type UpsertSoundCloudTrackParams struct {
-	ExternalID          sql.NullInt64
-	Name                sql.NullString
-	PermalinkUrl        sql.NullString
-	PurchaseTitle       sql.NullString
-	PurchaseUrl         sql.NullString
-	HasDownloadsLeft    sql.NullBool
-	Genre               sql.NullString
-	ArtworkUrl          sql.NullString
-	TagList             sql.NullString
-	PublisherArtist     sql.NullString
-	SoundCloudUser      sql.NullString
-	LocalPath           sql.NullString
-	LocalPathBroken     sql.NullBool
-	RemovedFromPlaylist sql.NullBool
+	ExternalID       sql.NullInt64
+	Name             sql.NullString
+	PermalinkUrl     sql.NullString
+	PurchaseTitle    sql.NullString
+	PurchaseUrl      sql.NullString
+	HasDownloadsLeft sql.NullBool
+	Genre            sql.NullString
+	ArtworkUrl       sql.NullString
+	TagList          sql.NullString
+	PublisherArtist  sql.NullString
+	SoundCloudUser   sql.NullString
+	Duration         sql.NullFloat64
+	PurchaseCategory sql.NullString
 }
 
+// Only sets the fields which come from SoundCloud, local_path, local_path_broken and
+// removed_from_playlist are set with their own queries so they aren't overwritten
 func (q *Queries) UpsertSoundCloudTrack(ctx context.Context, arg UpsertSoundCloudTrackParams) (SoundcloudTrack, error) {
 	row := q.db.QueryRowContext(ctx, upsertSoundCloudTrack,
 		arg.ExternalID,
@@ -409,9 +622,8 @@ func (q *Queries) UpsertSoundCloudTrack(ctx context.Context, arg UpsertSoundClou
 		arg.TagList,
 		arg.PublisherArtist,
 		arg.SoundCloudUser,
-		arg.LocalPath,
-		arg.LocalPathBroken,
-		arg.RemovedFromPlaylist,
+		arg.Duration,
+		arg.PurchaseCategory,
 	)
 	var i SoundcloudTrack
 	err := row.Scan(
@@ -432,6 +644,8 @@ func (q *Queries) UpsertSoundCloudTrack(ctx context.Context, arg UpsertSoundClou
 		&i.LocalPath,
 		&i.LocalPathBroken,
 		&i.RemovedFromPlaylist,
+		&i.Duration,
+		&i.PurchaseCategory,
 	)
 	return i, err
 }
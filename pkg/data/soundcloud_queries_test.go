@@ -0,0 +1,438 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+/*
+Tests for the queries in db/queries/soundcloud.sql, run against a migrated in-memory database
+*/
+
+// soundCloudQueriesFixture holds the IDs of the playlists and tracks saved by seedSoundCloudQueries
+type soundCloudQueriesFixture struct {
+	warmUpID int64 // playlist 10, tracks 1 and 2
+	likesID  int64 // the likes of user 10, track 3
+	trackIDs map[int64]int64
+}
+
+/*
+seedSoundCloudQueries saves a playlist and a user's likes which share an external ID, so queries which
+confuse the ID of a playlist with its external ID find the wrong tracks
+*/
+func seedSoundCloudQueries(t *testing.T, ctx context.Context, sDB *SerenDB) soundCloudQueriesFixture {
+	t.Helper()
+
+	s := func(v string) sql.NullString { return sql.NullString{Valid: true, String: v} }
+
+	f := soundCloudQueriesFixture{trackIDs: map[int64]int64{}}
+
+	// the likes are saved first so their ID differs from the playlist's
+	for _, p := range []UpsertSoundCloudPlaylistParams{
+		{ExternalID: sql.NullInt64{Valid: true, Int64: 10}, Name: s("Likes"), SearchUrl: s("https://soundcloud.com/dj/likes"), SourceType: "likes"},
+		{ExternalID: sql.NullInt64{Valid: true, Int64: 10}, Name: s("Warm up"), SearchUrl: s("https://soundcloud.com/dj/sets/warm-up"), SourceType: "playlist"},
+	} {
+		inserted, err := sDB.UpsertSoundCloudPlaylist(ctx, p)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if p.SourceType == "likes" {
+			f.likesID = inserted.ID
+		} else {
+			f.warmUpID = inserted.ID
+		}
+	}
+
+	for _, id := range []int64{1, 2, 3} {
+		inserted, err := sDB.UpsertSoundCloudTrack(ctx, upsertSoundCloudTrackParams(fetchedSoundCloudTrack(id, "Track", false)))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f.trackIDs[id] = inserted.ID
+	}
+
+	links := []UpsertSoundCloudPlaylistTrackParams{
+		{SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: f.warmUpID}, SoundcloudTrackID: sql.NullInt64{Valid: true, Int64: f.trackIDs[2]}, Position: sql.NullInt64{Valid: true, Int64: 0}},
+		{SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: f.warmUpID}, SoundcloudTrackID: sql.NullInt64{Valid: true, Int64: f.trackIDs[1]}, Position: sql.NullInt64{Valid: true, Int64: 1}},
+		{SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: f.likesID}, SoundcloudTrackID: sql.NullInt64{Valid: true, Int64: f.trackIDs[3]}, Position: sql.NullInt64{Valid: true, Int64: 0}},
+	}
+
+	for _, l := range links {
+		if _, err := sDB.UpsertSoundCloudPlaylistTrack(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return f
+}
+
+func externalIDs(tracks []SoundcloudTrack) []int64 {
+	ids := make([]int64, len(tracks))
+	for i, t := range tracks {
+		ids[i] = t.ExternalID.Int64
+	}
+	return ids
+}
+
+func TestSoundCloudPlaylistQueries(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+	f := seedSoundCloudQueries(t, ctx, sDB)
+
+	playlists, err := sDB.ListSoundCloudPlaylists(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(playlists) != 2 {
+		t.Fatalf("got %d playlists, want 2", len(playlists))
+	}
+
+	// upserting without a name keeps the saved name
+	updated, err := sDB.UpsertSoundCloudPlaylist(ctx, UpsertSoundCloudPlaylistParams{
+		ExternalID:   sql.NullInt64{Valid: true, Int64: 10},
+		PermalinkUrl: sql.NullString{Valid: true, String: "https://soundcloud.com/dj/sets/warm-up"},
+		SourceType:   "playlist",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.ID != f.warmUpID || updated.Name.String != "Warm up" || updated.PermalinkUrl.String == "" {
+		t.Errorf("upsert updated playlist to %+v", updated)
+	}
+
+	counts := []struct {
+		name  string
+		count func() (int64, error)
+		want  int64
+	}{
+		{
+			name: "by url",
+			count: func() (int64, error) {
+				return sDB.GetNumSoundCloudPlaylistByURL(ctx, sql.NullString{Valid: true, String: "https://soundcloud.com/dj/likes"})
+			},
+			want: 1,
+		},
+		{
+			name: "by unknown url",
+			count: func() (int64, error) {
+				return sDB.GetNumSoundCloudPlaylistByURL(ctx, sql.NullString{Valid: true, String: "https://soundcloud.com/dj/reposts"})
+			},
+			want: 0,
+		},
+		{
+			name: "by external id",
+			count: func() (int64, error) {
+				return sDB.GetNumSoundCloudPlaylistByExternalID(ctx, GetNumSoundCloudPlaylistByExternalIDParams{
+					ExternalID: sql.NullInt64{Valid: true, Int64: 10},
+					SourceType: "playlist",
+				})
+			},
+			want: 1,
+		},
+		{
+			name: "by external id of another source type",
+			count: func() (int64, error) {
+				return sDB.GetNumSoundCloudPlaylistByExternalID(ctx, GetNumSoundCloudPlaylistByExternalIDParams{
+					ExternalID: sql.NullInt64{Valid: true, Int64: 10},
+					SourceType: "reposts",
+				})
+			},
+			want: 0,
+		},
+	}
+
+	for _, c := range counts {
+		got, err := c.count()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != c.want {
+			t.Errorf("%s: got %d playlists, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestListSoundCloudTracksQueries(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+	f := seedSoundCloudQueries(t, ctx, sDB)
+
+	all, err := sDB.ListSoundCloudTracks(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]int64{1, 2, 3}, externalIDs(all)); diff != "" {
+		t.Errorf("ListSoundCloudTracks mismatch (-want +got):\n%s", diff)
+	}
+
+	byID, err := sDB.ListSoundCloudTracksByPlaylistID(ctx, sql.NullInt64{Valid: true, Int64: f.warmUpID})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]int64{2, 1}, externalIDs(byID)); diff != "" {
+		t.Errorf("ListSoundCloudTracksByPlaylistID mismatch (-want +got):\n%s", diff)
+	}
+
+	byExternalID, err := sDB.ListSoundCloudTracksByPlaylistExternalID(ctx, ListSoundCloudTracksByPlaylistExternalIDParams{
+		PlaylistExternalID: sql.NullInt64{Valid: true, Int64: 10},
+		SourceType:         "playlist",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]int64, len(byExternalID))
+	for i, r := range byExternalID {
+		got[i] = r.SoundcloudTrack.ExternalID.Int64
+
+		if r.Position.Int64 != int64(i) || !r.AddedAt.Valid || r.RemovedAt.Valid {
+			t.Errorf("track %d has membership %+v", got[i], r)
+		}
+	}
+
+	if diff := cmp.Diff([]int64{2, 1}, got); diff != "" {
+		t.Errorf("ListSoundCloudTracksByPlaylistExternalID mismatch (-want +got):\n%s", diff)
+	}
+
+	err = sDB.SetSoundCloudTrackLocalPath(ctx, SetSoundCloudTrackLocalPathParams{
+		LocalPath:       sql.NullString{Valid: true, String: "/music/track 3.mp3"},
+		LocalPathBroken: sql.NullBool{Valid: true},
+		ExternalID:      sql.NullInt64{Valid: true, Int64: 3},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasLocalPath, err := sDB.ListSoundCloudTracksHasLocalPath(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]int64{3}, externalIDs(hasLocalPath)); diff != "" {
+		t.Errorf("ListSoundCloudTracksHasLocalPath mismatch (-want +got):\n%s", diff)
+	}
+
+	if hasLocalPath[0].LocalPath.String != "/music/track 3.mp3" {
+		t.Errorf("got local path %q", hasLocalPath[0].LocalPath.String)
+	}
+}
+
+func TestCountSoundCloudTracksQueries(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+	f := seedSoundCloudQueries(t, ctx, sDB)
+
+	counts := []struct {
+		name  string
+		count func() (int64, error)
+		want  int64
+	}{
+		{
+			name: "by playlist id",
+			count: func() (int64, error) {
+				return sDB.CountSoundCloudTracksByPlaylistID(ctx, sql.NullInt64{Valid: true, Int64: f.warmUpID})
+			},
+			want: 2,
+		},
+		{
+			name: "by playlist external id",
+			count: func() (int64, error) {
+				return sDB.CountSoundCloudTracksByExternalID(ctx, CountSoundCloudTracksByExternalIDParams{
+					PlaylistExternalID: sql.NullInt64{Valid: true, Int64: 10},
+					SourceType:         "playlist",
+				})
+			},
+			want: 2,
+		},
+		{
+			name: "by likes external id",
+			count: func() (int64, error) {
+				return sDB.CountSoundCloudTracksByExternalID(ctx, CountSoundCloudTracksByExternalIDParams{
+					PlaylistExternalID: sql.NullInt64{Valid: true, Int64: 10},
+					SourceType:         "likes",
+				})
+			},
+			want: 1,
+		},
+		{
+			name: "by playlist id as external id",
+			count: func() (int64, error) {
+				return sDB.CountSoundCloudTracksByExternalID(ctx, CountSoundCloudTracksByExternalIDParams{
+					PlaylistExternalID: sql.NullInt64{Valid: true, Int64: f.likesID},
+					SourceType:         "likes",
+				})
+			},
+			want: 0,
+		},
+	}
+
+	for _, c := range counts {
+		got, err := c.count()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != c.want {
+			t.Errorf("%s: got %d tracks, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestUpsertSoundCloudTrackQuery(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+	seedSoundCloudQueries(t, ctx, sDB)
+
+	// null fields keep the saved value
+	updated, err := sDB.UpsertSoundCloudTrack(ctx, UpsertSoundCloudTrackParams{
+		ExternalID: sql.NullInt64{Valid: true, Int64: 1},
+		Genre:      sql.NullString{Valid: true, String: "Techno"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.Name.String != "Track" || updated.Genre.String != "Techno" {
+		t.Errorf("upsert updated track to %+v", updated)
+	}
+
+	tracks, err := sDB.ListSoundCloudTracks(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracks) != 3 {
+		t.Errorf("got %d tracks, want 3", len(tracks))
+	}
+}
+
+func TestSoundCloudPlaylistTrackQueries(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+	f := seedSoundCloudQueries(t, ctx, sDB)
+
+	playlistID := sql.NullInt64{Valid: true, Int64: f.warmUpID}
+	track := func(id int64) sql.NullInt64 { return sql.NullInt64{Valid: true, Int64: f.trackIDs[id]} }
+
+	err := sDB.RemoveSoundCloudPlaylistTrack(ctx, RemoveSoundCloudPlaylistTrackParams{
+		SoundcloudPlaylistID: playlistID,
+		SoundcloudTrackID:    track(2),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// already linked, so left as it is
+	err = sDB.InsertRemovedSoundCloudPlaylistTrack(ctx, InsertRemovedSoundCloudPlaylistTrackParams{
+		SoundcloudPlaylistID: playlistID,
+		SoundcloudTrackID:    track(1),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sDB.InsertRemovedSoundCloudPlaylistTrack(ctx, InsertRemovedSoundCloudPlaylistTrackParams{
+		SoundcloudPlaylistID: playlistID,
+		SoundcloudTrackID:    track(3),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sDB.SetSoundCloudTracksRemovedFromPlaylistByPlaylistID(ctx, playlistID); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := sDB.ListSoundCloudTracksByPlaylistExternalID(ctx, ListSoundCloudTracksByPlaylistExternalIDParams{
+		PlaylistExternalID: sql.NullInt64{Valid: true, Int64: 10},
+		SourceType:         "playlist",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type membership struct {
+		Removed             bool
+		RemovedFromPlaylist bool
+	}
+
+	got := map[int64]membership{}
+	for _, r := range rows {
+		got[r.SoundcloudTrack.ExternalID.Int64] = membership{
+			Removed:             r.RemovedAt.Valid,
+			RemovedFromPlaylist: r.SoundcloudTrack.RemovedFromPlaylist.Bool,
+		}
+	}
+
+	// track 3 is still in the likes, so isn't removed from every playlist
+	want := map[int64]membership{
+		1: {},
+		2: {Removed: true, RemovedFromPlaylist: true},
+		3: {Removed: true},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("membership mismatch (-want +got):\n%s", diff)
+	}
+
+	readded, err := sDB.UpsertSoundCloudPlaylistTrack(ctx, UpsertSoundCloudPlaylistTrackParams{
+		SoundcloudPlaylistID: playlistID,
+		SoundcloudTrackID:    track(2),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no position keeps the one it was removed at
+	if readded.RemovedAt.Valid || readded.Position.Int64 != 0 {
+		t.Errorf("re-added track has membership %+v", readded)
+	}
+
+	err = sDB.SetSoundCloudTrackRemovedFromPlaylist(ctx, SetSoundCloudTrackRemovedFromPlaylistParams{
+		RemovedFromPlaylist: sql.NullBool{Valid: true, Bool: true},
+		ExternalID:          sql.NullInt64{Valid: true, Int64: 1},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	states := listSoundCloudTrackStates(t, sDB)
+
+	if !states[1].RemovedFromPlaylist.Bool {
+		t.Errorf("track 1 not marked as removed from playlist")
+	}
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/Southclaws/fault"
 	"github.com/Southclaws/fault/fmsg"
@@ -13,8 +14,60 @@ Contains a series of functions used to interface with the queries generated by s
 for the purpose of processing multiple records at once
 */
 
-func (sDB *SerenDB) TxUpsertSoundCloudPlaylistAndTracks(p SoundcloudPlaylist, tracks []SoundcloudTrack) error {
-	tx, err := sDB.Begin()
+/*
+TxUpsertSoundCloudPlaylistAndTracks saves a playlist and the fields of its tracks which come from
+SoundCloud, the local path and removed flag of tracks already saved are kept
+*/
+func (sDB *SerenDB) TxUpsertSoundCloudPlaylistAndTracks(ctx context.Context, p SoundcloudPlaylist, tracks []SoundcloudTrack) error {
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
+	defer tx.Rollback()
+
+	qtx := sDB.Queries.WithTx(tx)
+
+	_, _, err = upsertSoundCloudPlaylistAndTracks(ctx, qtx, p, tracks)
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return nil
+}
+
+/*
+SoundCloudTrackChange is a change to a track found when refreshing a playlist, the track is
+identified by its external ID as it may not have been saved yet
+*/
+type SoundCloudTrackChange struct {
+	TrackExternalID int64
+	ChangeType      string
+	OldValue        sql.NullString
+	NewValue        sql.NullString
+}
+
+/*
+TxSaveSoundCloudPlaylistRefresh saves a refreshed playlist and its tracks, and records the changes
+found in the refresh against the playlist
+
+Tracks with RemovedFromPlaylist set are marked as removed from the playlist, their local paths are kept
+*/
+func (sDB *SerenDB) TxSaveSoundCloudPlaylistRefresh(ctx context.Context, p SoundcloudPlaylist, tracks []SoundcloudTrack, changes []SoundCloudTrackChange) error {
+	tx, err := sDB.BeginTx(ctx, nil)
 
 	if err != nil {
 		return fault.Wrap(
@@ -26,16 +79,69 @@ func (sDB *SerenDB) TxUpsertSoundCloudPlaylistAndTracks(p SoundcloudPlaylist, tr
 
 	qtx := sDB.Queries.WithTx(tx)
 
-	insertedP, err := qtx.UpsertSoundCloudPlaylist(context.Background(), UpsertSoundCloudPlaylistParams{
+	playlistID, trackIDs, err := upsertSoundCloudPlaylistAndTracks(ctx, qtx, p, tracks)
+
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		trackID, ok := trackIDs[c.TrackExternalID]
+
+		if !ok {
+			return fault.Wrap(
+				fault.New(fmt.Sprintf("track %d was changed but not saved", c.TrackExternalID)),
+				fmsg.With("Error inserting playlist change"),
+			)
+		}
+
+		_, err = qtx.InsertSoundCloudPlaylistChange(ctx, InsertSoundCloudPlaylistChangeParams{
+			SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: playlistID},
+			SoundcloudTrackID:    sql.NullInt64{Valid: true, Int64: trackID},
+			ChangeType:           sql.NullString{Valid: true, String: c.ChangeType},
+			OldValue:             c.OldValue,
+			NewValue:             c.NewValue,
+		})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error inserting playlist change"),
+			)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return nil
+}
+
+/*
+upsertSoundCloudPlaylistAndTracks upserts a playlist and its tracks, the tracks are linked to the
+playlist in the order given, apart from tracks with RemovedFromPlaylist set which are marked as
+removed from the playlist
+
+The ID of the playlist is returned, along with the IDs of the tracks keyed by their external ID
+*/
+func upsertSoundCloudPlaylistAndTracks(ctx context.Context, qtx *Queries, p SoundcloudPlaylist, tracks []SoundcloudTrack) (int64, map[int64]int64, error) {
+
+	insertedP, err := qtx.UpsertSoundCloudPlaylist(ctx, UpsertSoundCloudPlaylistParams{
 		ExternalID:   p.ExternalID,
 		Name:         p.Name,
 		SearchUrl:    p.SearchUrl,
 		PermalinkUrl: p.PermalinkUrl,
+		SourceType:   p.SourceType,
 	})
 
 	if err != nil && err != sql.ErrNoRows {
-		tx.Rollback()
-		return fault.Wrap(
+		return 0, nil, fault.Wrap(
 			err,
 			fmsg.WithDesc(
 				"Error inserting playlist",
@@ -44,54 +150,165 @@ func (sDB *SerenDB) TxUpsertSoundCloudPlaylistAndTracks(p SoundcloudPlaylist, tr
 		)
 	}
 
+	trackIDs := make(map[int64]int64, len(tracks))
+	position := int64(0)
+
 	for _, t := range tracks {
 
-		insertedT, err := qtx.UpsertSoundCloudTrack(context.Background(), UpsertSoundCloudTrackParams{
-			ExternalID:          t.ExternalID,
-			Name:                t.Name,
-			PermalinkUrl:        t.PermalinkUrl,
-			PurchaseTitle:       t.PurchaseTitle,
-			PurchaseUrl:         t.PurchaseUrl,
-			HasDownloadsLeft:    t.HasDownloadsLeft,
-			Genre:               t.Genre,
-			ArtworkUrl:          t.ArtworkUrl,
-			TagList:             t.TagList,
-			PublisherArtist:     t.PublisherArtist,
-			SoundCloudUser:      t.SoundCloudUser,
-			LocalPath:           t.LocalPath,
-			LocalPathBroken:     t.LocalPathBroken,
-			RemovedFromPlaylist: t.RemovedFromPlaylist,
-		})
+		insertedT, err := qtx.UpsertSoundCloudTrack(ctx, upsertSoundCloudTrackParams(t))
 
 		if err != nil {
-			tx.Rollback()
-			return fault.Wrap(
+			return 0, nil, fault.Wrap(
 				err,
 				fmsg.With("Error inserting track"),
 			)
 		}
 
-		_, err = qtx.UpsertSoundCloudPlaylistTrack(context.Background(), UpsertSoundCloudPlaylistTrackParams{
-			SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: insertedP.ID},
-			SoundcloudTrackID:    sql.NullInt64{Valid: true, Int64: insertedT.ID},
-		})
+		trackIDs[t.ExternalID.Int64] = insertedT.ID
+
+		// removed tracks keep the position they were last at
+		if t.RemovedFromPlaylist.Bool {
+			err = unlinkSoundCloudPlaylistTrack(ctx, qtx, insertedP.ID, insertedT.ID)
+		} else {
+			err = linkSoundCloudPlaylistTrack(ctx, qtx, insertedP.ID, insertedT.ID, position)
+			position++
+		}
+
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	err = qtx.SetSoundCloudTracksRemovedFromPlaylistByPlaylistID(
+		ctx,
+		sql.NullInt64{Valid: true, Int64: insertedP.ID},
+	)
+
+	if err != nil {
+		return 0, nil, fault.Wrap(
+			err,
+			fmsg.With("Error setting tracks removed from playlist"),
+		)
+	}
+
+	return insertedP.ID, trackIDs, nil
+}
+
+/*
+linkSoundCloudPlaylistTrack links the track to the playlist at the position, starting a period in the
+playlist's history if the track wasn't already in the playlist
+*/
+func linkSoundCloudPlaylistTrack(ctx context.Context, qtx *Queries, playlistID int64, trackID int64, position int64) error {
+
+	_, err := qtx.UpsertSoundCloudPlaylistTrack(ctx, UpsertSoundCloudPlaylistTrackParams{
+		SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: playlistID},
+		SoundcloudTrackID:    sql.NullInt64{Valid: true, Int64: trackID},
+		Position:             sql.NullInt64{Valid: true, Int64: position},
+	})
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error inserting playlist track"),
+		)
+	}
+
+	err = qtx.InsertSoundCloudPlaylistTrackHistory(ctx, InsertSoundCloudPlaylistTrackHistoryParams{
+		SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: playlistID},
+		SoundcloudTrackID:    sql.NullInt64{Valid: true, Int64: trackID},
+	})
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error inserting playlist track history"),
+		)
+	}
+
+	return nil
+}
 
-		if err != nil && err != sql.ErrNoRows {
-			tx.Rollback()
+/*
+unlinkSoundCloudPlaylistTrack marks the track as removed from the playlist, ending its period in the
+playlist's history
+*/
+func unlinkSoundCloudPlaylistTrack(ctx context.Context, qtx *Queries, playlistID int64, trackID int64) error {
+
+	err := qtx.RemoveSoundCloudPlaylistTrack(ctx, RemoveSoundCloudPlaylistTrackParams{
+		SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: playlistID},
+		SoundcloudTrackID:    sql.NullInt64{Valid: true, Int64: trackID},
+	})
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error removing playlist track"),
+		)
+	}
+
+	err = qtx.EndSoundCloudPlaylistTrackHistory(ctx, EndSoundCloudPlaylistTrackHistoryParams{
+		SoundcloudPlaylistID: sql.NullInt64{Valid: true, Int64: playlistID},
+		SoundcloudTrackID:    sql.NullInt64{Valid: true, Int64: trackID},
+	})
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error ending playlist track history"),
+		)
+	}
+
+	return nil
+}
+
+/*
+TxUpsertSoundCloudTracks saves the fields of the tracks which come from SoundCloud, the local path and
+removed flag of tracks already saved are kept. Use TxSetSoundCloudTrackLocalPaths to save local paths
+*/
+func (sDB *SerenDB) TxUpsertSoundCloudTracks(ctx context.Context, t []SoundcloudTrack) error {
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
+
+	defer tx.Rollback()
+
+	qtx := sDB.Queries.WithTx(tx)
+
+	for _, t := range t {
+
+		_, err := qtx.UpsertSoundCloudTrack(ctx, upsertSoundCloudTrackParams(t))
+
+		if err != nil {
 			return fault.Wrap(
 				err,
-				fmsg.With("Error inserting playlist track"),
+				fmsg.With("Error inserting track"),
 			)
 		}
 	}
 
-	tx.Commit()
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
 
 	return nil
 }
 
-func (sDB *SerenDB) TxUpsertSoundCloudTracks(t []SoundcloudTrack) error {
-	tx, err := sDB.Begin()
+/*
+TxSetSoundCloudTrackLocalPaths saves the local path of each track, and whether the path is broken,
+leaving the rest of the track as it is. Tracks which haven't been saved are skipped
+*/
+func (sDB *SerenDB) TxSetSoundCloudTrackLocalPaths(ctx context.Context, tracks []SoundcloudTrack) error {
+	tx, err := sDB.BeginTx(ctx, nil)
 
 	if err != nil {
 		return fault.Wrap(
@@ -99,32 +316,48 @@ func (sDB *SerenDB) TxUpsertSoundCloudTracks(t []SoundcloudTrack) error {
 			fmsg.With("Error starting transaction"),
 		)
 	}
+	defer tx.Rollback()
+
+	err = setSoundCloudTrackLocalPaths(ctx, sDB.Queries.WithTx(tx), tracks)
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return nil
+}
+
+/*
+TxSaveSoundCloudTracks saves the tracks along with their local paths, for tracks which may not have
+been saved yet, i.e. a track downloaded straight from SoundCloud
+*/
+func (sDB *SerenDB) TxSaveSoundCloudTracks(ctx context.Context, tracks []SoundcloudTrack) error {
+	tx, err := sDB.BeginTx(ctx, nil)
 
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
 	defer tx.Rollback()
 
 	qtx := sDB.Queries.WithTx(tx)
 
-	for _, t := range t {
+	for _, t := range tracks {
 
-		_, err := qtx.UpsertSoundCloudTrack(context.Background(), UpsertSoundCloudTrackParams{
-			ExternalID:          t.ExternalID,
-			Name:                t.Name,
-			PermalinkUrl:        t.PermalinkUrl,
-			PurchaseTitle:       t.PurchaseTitle,
-			PurchaseUrl:         t.PurchaseUrl,
-			HasDownloadsLeft:    t.HasDownloadsLeft,
-			Genre:               t.Genre,
-			ArtworkUrl:          t.ArtworkUrl,
-			TagList:             t.TagList,
-			PublisherArtist:     t.PublisherArtist,
-			SoundCloudUser:      t.SoundCloudUser,
-			LocalPath:           t.LocalPath,
-			LocalPathBroken:     t.LocalPathBroken,
-			RemovedFromPlaylist: t.RemovedFromPlaylist,
-		})
+		_, err := qtx.UpsertSoundCloudTrack(ctx, upsertSoundCloudTrackParams(t))
 
 		if err != nil {
-			tx.Rollback()
 			return fault.Wrap(
 				err,
 				fmsg.With("Error inserting track"),
@@ -132,7 +365,63 @@ func (sDB *SerenDB) TxUpsertSoundCloudTracks(t []SoundcloudTrack) error {
 		}
 	}
 
-	tx.Commit()
+	err = setSoundCloudTrackLocalPaths(ctx, qtx, tracks)
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return nil
+}
+
+func setSoundCloudTrackLocalPaths(ctx context.Context, qtx *Queries, tracks []SoundcloudTrack) error {
+
+	for _, t := range tracks {
+
+		err := qtx.SetSoundCloudTrackLocalPath(ctx, SetSoundCloudTrackLocalPathParams{
+			LocalPath:       t.LocalPath,
+			LocalPathBroken: t.LocalPathBroken,
+			ExternalID:      t.ExternalID,
+		})
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error setting track local path"),
+			)
+		}
+	}
 
 	return nil
 }
+
+/*
+upsertSoundCloudTrackParams returns the params to upsert the track with, these only hold the fields
+which come from SoundCloud
+*/
+func upsertSoundCloudTrackParams(t SoundcloudTrack) UpsertSoundCloudTrackParams {
+	return UpsertSoundCloudTrackParams{
+		ExternalID:       t.ExternalID,
+		Name:             t.Name,
+		PermalinkUrl:     t.PermalinkUrl,
+		PurchaseTitle:    t.PurchaseTitle,
+		PurchaseUrl:      t.PurchaseUrl,
+		HasDownloadsLeft: t.HasDownloadsLeft,
+		Genre:            t.Genre,
+		ArtworkUrl:       t.ArtworkUrl,
+		TagList:          t.TagList,
+		PublisherArtist:  t.PublisherArtist,
+		SoundCloudUser:   t.SoundCloudUser,
+		Duration:         t.Duration,
+		PurchaseCategory: t.PurchaseCategory,
+	}
+}
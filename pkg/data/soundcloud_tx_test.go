@@ -0,0 +1,360 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// soundCloudTrackState is the part of a stored track the tests check
+type soundCloudTrackState struct {
+	Name                string
+	HasDownloadsLeft    bool
+	LocalPath           sql.NullString
+	LocalPathBroken     sql.NullBool
+	RemovedFromPlaylist sql.NullBool
+}
+
+func listSoundCloudTrackStates(t *testing.T, sDB *SerenDB) map[int64]soundCloudTrackState {
+	t.Helper()
+
+	tracks, err := sDB.ListSoundCloudTracks(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	states := make(map[int64]soundCloudTrackState, len(tracks))
+	for _, st := range tracks {
+		states[st.ExternalID.Int64] = soundCloudTrackState{
+			Name:                st.Name.String,
+			HasDownloadsLeft:    st.HasDownloadsLeft.Bool,
+			LocalPath:           st.LocalPath,
+			LocalPathBroken:     st.LocalPathBroken,
+			RemovedFromPlaylist: st.RemovedFromPlaylist,
+		}
+	}
+
+	return states
+}
+
+/*
+fetchedSoundCloudTrack returns a track as it is built from SoundCloud, with the local fields set to
+their zero values rather than null
+*/
+func fetchedSoundCloudTrack(externalID int64, name string, hasDownloadsLeft bool) SoundcloudTrack {
+	return SoundcloudTrack{
+		ExternalID:          sql.NullInt64{Valid: true, Int64: externalID},
+		Name:                sql.NullString{Valid: true, String: name},
+		HasDownloadsLeft:    sql.NullBool{Valid: true, Bool: hasDownloadsLeft},
+		LocalPath:           sql.NullString{Valid: true},
+		LocalPathBroken:     sql.NullBool{Valid: true},
+		RemovedFromPlaylist: sql.NullBool{Valid: true},
+	}
+}
+
+func TestUpsertSoundCloudTracksKeepsLocalState(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+	s := func(v string) sql.NullString { return sql.NullString{Valid: true, String: v} }
+	b := func(v bool) sql.NullBool { return sql.NullBool{Valid: true, Bool: v} }
+
+	playlist := SoundcloudPlaylist{
+		ExternalID: sql.NullInt64{Valid: true, Int64: 10},
+		Name:       s("Warm up"),
+		SourceType: "playlist",
+	}
+
+	err := sDB.TxUpsertSoundCloudPlaylistAndTracks(ctx, playlist, []SoundcloudTrack{
+		fetchedSoundCloudTrack(1, "Track 1", true),
+		fetchedSoundCloudTrack(2, "Track 2", false),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downloaded := fetchedSoundCloudTrack(1, "Track 1", true)
+	downloaded.LocalPath = s("/music/track 1.mp3")
+	downloaded.LocalPathBroken = b(true)
+
+	if err := sDB.TxSetSoundCloudTrackLocalPaths(ctx, []SoundcloudTrack{downloaded}); err != nil {
+		t.Fatal(err)
+	}
+
+	// saving the playlist again as it comes from SoundCloud
+	err = sDB.TxUpsertSoundCloudPlaylistAndTracks(ctx, playlist, []SoundcloudTrack{
+		fetchedSoundCloudTrack(1, "Track 1 (renamed)", false),
+		fetchedSoundCloudTrack(2, "Track 2", true),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sDB.TxUpsertSoundCloudTracks(ctx, []SoundcloudTrack{fetchedSoundCloudTrack(1, "Track 1 (renamed)", false)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int64]soundCloudTrackState{
+		1: {Name: "Track 1 (renamed)", LocalPath: s("/music/track 1.mp3"), LocalPathBroken: b(true), RemovedFromPlaylist: b(false)},
+		2: {Name: "Track 2", HasDownloadsLeft: true, RemovedFromPlaylist: b(false)},
+	}
+
+	if diff := cmp.Diff(want, listSoundCloudTrackStates(t, sDB)); diff != "" {
+		t.Errorf("tracks mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSaveSoundCloudPlaylistRefreshKeepsLocalState(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+	s := func(v string) sql.NullString { return sql.NullString{Valid: true, String: v} }
+	b := func(v bool) sql.NullBool { return sql.NullBool{Valid: true, Bool: v} }
+
+	playlist := SoundcloudPlaylist{
+		ExternalID: sql.NullInt64{Valid: true, Int64: 10},
+		Name:       s("Warm up"),
+		SourceType: "playlist",
+	}
+
+	err := sDB.TxUpsertSoundCloudPlaylistAndTracks(ctx, playlist, []SoundcloudTrack{
+		fetchedSoundCloudTrack(1, "Track 1", true),
+		fetchedSoundCloudTrack(2, "Track 2", true),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downloaded := []SoundcloudTrack{
+		fetchedSoundCloudTrack(1, "Track 1", true),
+		fetchedSoundCloudTrack(2, "Track 2", true),
+	}
+	downloaded[0].LocalPath = s("/music/track 1.mp3")
+	downloaded[1].LocalPath = s("/music/track 2.mp3")
+
+	if err := sDB.TxSetSoundCloudTrackLocalPaths(ctx, downloaded); err != nil {
+		t.Fatal(err)
+	}
+
+	// track 2 has been removed from the playlist on SoundCloud, and track 3 added
+	removed := fetchedSoundCloudTrack(2, "Track 2", true)
+	removed.RemovedFromPlaylist = b(true)
+
+	err = sDB.TxSaveSoundCloudPlaylistRefresh(ctx,
+		playlist,
+		[]SoundcloudTrack{
+			fetchedSoundCloudTrack(1, "Track 1", false),
+			removed,
+			fetchedSoundCloudTrack(3, "Track 3", true),
+		},
+		[]SoundCloudTrackChange{
+			{TrackExternalID: 2, ChangeType: "removed"},
+			{TrackExternalID: 3, ChangeType: "added"},
+		},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int64]soundCloudTrackState{
+		1: {Name: "Track 1", LocalPath: s("/music/track 1.mp3"), LocalPathBroken: b(false), RemovedFromPlaylist: b(false)},
+		2: {Name: "Track 2", HasDownloadsLeft: true, LocalPath: s("/music/track 2.mp3"), LocalPathBroken: b(false), RemovedFromPlaylist: b(true)},
+		3: {Name: "Track 3", HasDownloadsLeft: true, RemovedFromPlaylist: b(false)},
+	}
+
+	if diff := cmp.Diff(want, listSoundCloudTrackStates(t, sDB)); diff != "" {
+		t.Errorf("tracks mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetSoundCloudTrackLocalPaths(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+	s := func(v string) sql.NullString { return sql.NullString{Valid: true, String: v} }
+	b := func(v bool) sql.NullBool { return sql.NullBool{Valid: true, Bool: v} }
+
+	saved := fetchedSoundCloudTrack(1, "Track 1", true)
+	saved.LocalPath = s("/music/track 1.mp3")
+
+	if err := sDB.TxSaveSoundCloudTracks(ctx, []SoundcloudTrack{saved}); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the local path is set, so the name isn't cleared
+	cleared := SoundcloudTrack{ExternalID: sql.NullInt64{Valid: true, Int64: 1}, LocalPathBroken: b(false)}
+	unsaved := fetchedSoundCloudTrack(2, "Track 2", true)
+	unsaved.LocalPath = s("/music/track 2.mp3")
+
+	if err := sDB.TxSetSoundCloudTrackLocalPaths(ctx, []SoundcloudTrack{cleared, unsaved}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int64]soundCloudTrackState{
+		1: {Name: "Track 1", HasDownloadsLeft: true, LocalPathBroken: b(false)},
+	}
+
+	if diff := cmp.Diff(want, listSoundCloudTrackStates(t, sDB)); diff != "" {
+		t.Errorf("tracks mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSoundCloudPlaylistMembership(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+	b := func(v bool) sql.NullBool { return sql.NullBool{Valid: true, Bool: v} }
+
+	warmUp := SoundcloudPlaylist{ExternalID: sql.NullInt64{Valid: true, Int64: 10}, SourceType: "playlist"}
+	peakTime := SoundcloudPlaylist{ExternalID: sql.NullInt64{Valid: true, Int64: 20}, SourceType: "playlist"}
+
+	err := sDB.TxUpsertSoundCloudPlaylistAndTracks(ctx, warmUp, []SoundcloudTrack{
+		fetchedSoundCloudTrack(3, "Track 3", false),
+		fetchedSoundCloudTrack(1, "Track 1", false),
+		fetchedSoundCloudTrack(2, "Track 2", false),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sDB.TxUpsertSoundCloudPlaylistAndTracks(ctx, peakTime, []SoundcloudTrack{fetchedSoundCloudTrack(1, "Track 1", false)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed := fetchedSoundCloudTrack(1, "Track 1", false)
+	removed.RemovedFromPlaylist = b(true)
+
+	refresh := func(tracks ...SoundcloudTrack) {
+		t.Helper()
+		if err := sDB.TxSaveSoundCloudPlaylistRefresh(ctx, warmUp, tracks, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type member struct {
+		ExternalID int64
+		Position   int64
+		Removed    bool
+	}
+
+	listMembers := func(p SoundcloudPlaylist) []member {
+		t.Helper()
+
+		rows, err := sDB.ListSoundCloudTracksByPlaylistExternalID(ctx, ListSoundCloudTracksByPlaylistExternalIDParams{
+			PlaylistExternalID: p.ExternalID,
+			SourceType:         p.SourceType,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		members := []member{}
+		for _, r := range rows {
+			if !r.AddedAt.Valid {
+				t.Errorf("track %d has no added_at", r.SoundcloudTrack.ExternalID.Int64)
+			}
+			members = append(members, member{r.SoundcloudTrack.ExternalID.Int64, r.Position.Int64, r.RemovedAt.Valid})
+		}
+
+		return members
+	}
+
+	// track 1 is removed from one playlist, and track 2 moved to the start
+	refresh(fetchedSoundCloudTrack(2, "Track 2", false), fetchedSoundCloudTrack(3, "Track 3", false), removed)
+
+	want := []member{{2, 0, false}, {3, 1, false}, {1, 1, true}}
+	if diff := cmp.Diff(want, listMembers(warmUp)); diff != "" {
+		t.Errorf("warm up tracks mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]member{{1, 0, false}}, listMembers(peakTime)); diff != "" {
+		t.Errorf("peak time tracks mismatch (-want +got):\n%s", diff)
+	}
+
+	// the track is still in the other playlist, so isn't removed everywhere
+	if got := listSoundCloudTrackStates(t, sDB)[1].RemovedFromPlaylist; got != b(false) {
+		t.Errorf("got track 1 removed_from_playlist %v while still in a playlist, want false", got)
+	}
+
+	err = sDB.TxSaveSoundCloudPlaylistRefresh(ctx, peakTime, []SoundcloudTrack{removed}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := listSoundCloudTrackStates(t, sDB)[1].RemovedFromPlaylist; got != b(true) {
+		t.Errorf("got track 1 removed_from_playlist %v after removing it from every playlist, want true", got)
+	}
+
+	// track 1 is added back to the first playlist
+	refresh(fetchedSoundCloudTrack(1, "Track 1", false), fetchedSoundCloudTrack(2, "Track 2", false), fetchedSoundCloudTrack(3, "Track 3", false))
+
+	want = []member{{1, 0, false}, {2, 1, false}, {3, 2, false}}
+	if diff := cmp.Diff(want, listMembers(warmUp)); diff != "" {
+		t.Errorf("warm up tracks mismatch after adding track back (-want +got):\n%s", diff)
+	}
+
+	history, err := sDB.ListSoundCloudPlaylistTrackHistory(ctx, ListSoundCloudPlaylistTrackHistoryParams{
+		PlaylistExternalID: warmUp.ExternalID,
+		SourceType:         warmUp.SourceType,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type period struct {
+		ExternalID int64
+		Removed    bool
+	}
+
+	gotPeriods := map[period]int{}
+	for _, h := range history {
+		if !h.AddedAt.Valid {
+			t.Errorf("period of track %d has no added_at", h.TrackExternalID.Int64)
+		}
+		gotPeriods[period{h.TrackExternalID.Int64, h.RemovedAt.Valid}]++
+	}
+
+	wantPeriods := map[period]int{
+		{1, true}:  1,
+		{1, false}: 1,
+		{2, false}: 1,
+		{3, false}: 1,
+	}
+
+	if diff := cmp.Diff(wantPeriods, gotPeriods); diff != "" {
+		t.Errorf("history mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTxUpsertSoundCloudTracksCancelledContext(t *testing.T) {
+
+	sDB := newTestSerenDB(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := sDB.TxUpsertSoundCloudTracks(ctx, []SoundcloudTrack{fetchedSoundCloudTrack(1, "Track 1", false)})
+
+	if err == nil {
+		t.Fatal("expected an error upserting with a cancelled context")
+	}
+
+	if states := listSoundCloudTrackStates(t, sDB); len(states) != 0 {
+		t.Errorf("got %d tracks saved, want 0", len(states))
+	}
+}
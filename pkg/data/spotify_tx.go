@@ -0,0 +1,152 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+)
+
+/*
+TxUpsertSpotifyPlaylistAndTracks upserts a Spotify playlist and its tracks, the tracks are linked to the
+playlist in the order given, apart from tracks with RemovedFromPlaylist set which are marked as removed
+from the playlist
+*/
+func (sDB *SerenDB) TxUpsertSpotifyPlaylistAndTracks(ctx context.Context, p SpotifyPlaylist, tracks []SpotifyTrack) error {
+	tx, err := sDB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error starting transaction"),
+		)
+	}
+	defer tx.Rollback()
+
+	qtx := sDB.Queries.WithTx(tx)
+
+	insertedP, err := qtx.UpsertSpotifyPlaylist(ctx, UpsertSpotifyPlaylistParams{
+		ExternalID:   p.ExternalID,
+		Name:         p.Name,
+		Owner:        p.Owner,
+		SearchUrl:    p.SearchUrl,
+		PermalinkUrl: p.PermalinkUrl,
+	})
+
+	if err != nil && err != sql.ErrNoRows {
+		return fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"Error inserting playlist",
+				"There was an error inserting Spotify playlist into database",
+			),
+		)
+	}
+
+	position := int64(0)
+
+	for _, t := range tracks {
+
+		insertedT, err := qtx.UpsertSpotifyTrack(ctx, upsertSpotifyTrackParams(t))
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("Error inserting track"),
+			)
+		}
+
+		// removed tracks keep the position they were last at
+		if t.RemovedFromPlaylist.Bool {
+			err = unlinkSpotifyPlaylistTrack(ctx, qtx, insertedP.ID, insertedT.ID)
+		} else {
+			err = linkSpotifyPlaylistTrack(ctx, qtx, insertedP.ID, insertedT.ID, position)
+			position++
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err = qtx.SetSpotifyTracksRemovedFromPlaylistByPlaylistID(
+		ctx,
+		sql.NullInt64{Valid: true, Int64: insertedP.ID},
+	)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error setting tracks removed from playlist"),
+		)
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error committing transaction"),
+		)
+	}
+
+	return nil
+}
+
+/*
+linkSpotifyPlaylistTrack links the track to the playlist at the position
+*/
+func linkSpotifyPlaylistTrack(ctx context.Context, qtx *Queries, playlistID int64, trackID int64, position int64) error {
+
+	_, err := qtx.UpsertSpotifyPlaylistTrack(ctx, UpsertSpotifyPlaylistTrackParams{
+		SpotifyPlaylistID: sql.NullInt64{Valid: true, Int64: playlistID},
+		SpotifyTrackID:    sql.NullInt64{Valid: true, Int64: trackID},
+		Position:          sql.NullInt64{Valid: true, Int64: position},
+	})
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error inserting playlist track"),
+		)
+	}
+
+	return nil
+}
+
+/*
+unlinkSpotifyPlaylistTrack marks the track as removed from the playlist
+*/
+func unlinkSpotifyPlaylistTrack(ctx context.Context, qtx *Queries, playlistID int64, trackID int64) error {
+
+	err := qtx.RemoveSpotifyPlaylistTrack(ctx, RemoveSpotifyPlaylistTrackParams{
+		SpotifyPlaylistID: sql.NullInt64{Valid: true, Int64: playlistID},
+		SpotifyTrackID:    sql.NullInt64{Valid: true, Int64: trackID},
+	})
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error removing playlist track"),
+		)
+	}
+
+	return nil
+}
+
+/*
+upsertSpotifyTrackParams returns the params to upsert the track with
+*/
+func upsertSpotifyTrackParams(t SpotifyTrack) UpsertSpotifyTrackParams {
+	return UpsertSpotifyTrackParams{
+		ExternalID:          t.ExternalID,
+		Name:                t.Name,
+		PermalinkUrl:        t.PermalinkUrl,
+		Artists:             t.Artists,
+		Album:               t.Album,
+		Isrc:                t.Isrc,
+		Duration:            t.Duration,
+		RemovedFromPlaylist: t.RemovedFromPlaylist,
+	}
+}
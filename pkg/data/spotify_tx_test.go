@@ -0,0 +1,111 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func fetchedSpotifyTrack(externalID string, removed bool) SpotifyTrack {
+	return SpotifyTrack{
+		ExternalID:          sql.NullString{Valid: true, String: externalID},
+		Name:                sql.NullString{Valid: true, String: "Track " + externalID},
+		RemovedFromPlaylist: sql.NullBool{Valid: true, Bool: removed},
+	}
+}
+
+func TestSpotifyPlaylistMembership(t *testing.T) {
+
+	ctx := context.Background()
+	sDB := newTestSerenDB(t)
+
+	warmUp := SpotifyPlaylist{ExternalID: sql.NullString{Valid: true, String: "warm-up"}}
+	peakTime := SpotifyPlaylist{ExternalID: sql.NullString{Valid: true, String: "peak-time"}}
+
+	save := func(p SpotifyPlaylist, tracks ...SpotifyTrack) {
+		t.Helper()
+		if err := sDB.TxUpsertSpotifyPlaylistAndTracks(ctx, p, tracks); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type member struct {
+		ExternalID string
+		Position   int64
+		Removed    bool
+	}
+
+	listMembers := func(p SpotifyPlaylist) []member {
+		t.Helper()
+
+		rows, err := sDB.ListSpotifyTracksByPlaylistExternalID(ctx, p.ExternalID)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		members := []member{}
+		for _, r := range rows {
+			if !r.AddedAt.Valid {
+				t.Errorf("track %s has no added_at", r.SpotifyTrack.ExternalID.String)
+			}
+			members = append(members, member{r.SpotifyTrack.ExternalID.String, r.Position.Int64, r.RemovedAt.Valid})
+		}
+
+		return members
+	}
+
+	save(warmUp, fetchedSpotifyTrack("c", false), fetchedSpotifyTrack("a", false), fetchedSpotifyTrack("b", false))
+	save(peakTime, fetchedSpotifyTrack("a", false))
+
+	want := []member{{"c", 0, false}, {"a", 1, false}, {"b", 2, false}}
+	if diff := cmp.Diff(want, listMembers(warmUp)); diff != "" {
+		t.Errorf("warm up tracks mismatch (-want +got):\n%s", diff)
+	}
+
+	// a is removed from one playlist, and b moved to the start
+	save(warmUp, fetchedSpotifyTrack("b", false), fetchedSpotifyTrack("c", false), fetchedSpotifyTrack("a", true))
+
+	want = []member{{"b", 0, false}, {"c", 1, false}, {"a", 1, true}}
+	if diff := cmp.Diff(want, listMembers(warmUp)); diff != "" {
+		t.Errorf("warm up tracks mismatch after refresh (-want +got):\n%s", diff)
+	}
+
+	removedFromPlaylist := func() bool {
+		t.Helper()
+		for _, m := range []SpotifyPlaylist{warmUp, peakTime} {
+			rows, err := sDB.ListSpotifyTracksByPlaylistExternalID(ctx, m.ExternalID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range rows {
+				if r.SpotifyTrack.ExternalID.String == "a" {
+					return r.SpotifyTrack.RemovedFromPlaylist.Bool
+				}
+			}
+		}
+		t.Fatal("track a not found")
+		return false
+	}
+
+	// the track is still in the other playlist, so isn't removed everywhere
+	if removedFromPlaylist() {
+		t.Errorf("got track a removed_from_playlist while still in a playlist, want false")
+	}
+
+	save(peakTime, fetchedSpotifyTrack("a", true))
+
+	if !removedFromPlaylist() {
+		t.Errorf("got track a not removed_from_playlist after removing it from every playlist, want true")
+	}
+
+	// a is added back to the first playlist
+	save(warmUp, fetchedSpotifyTrack("a", false), fetchedSpotifyTrack("b", false), fetchedSpotifyTrack("c", false))
+
+	want = []member{{"a", 0, false}, {"b", 1, false}, {"c", 2, false}}
+	if diff := cmp.Diff(want, listMembers(warmUp)); diff != "" {
+		t.Errorf("warm up tracks mismatch after adding track back (-want +got):\n%s", diff)
+	}
+}
@@ -47,7 +47,7 @@ func Entry() {
 		mainWindow.SetContent(
 			container.NewStack(
 				container.New(e.resizeEvents, container.NewStack()),
-				split,
+				container.NewBorder(e.makeSearchBar(), nil, nil, nil, split),
 			),
 		)
 		mainWindow.SetMaster()
@@ -0,0 +1,200 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/operations"
+)
+
+/*
+Provides the duplicates view, used to review groups of duplicate files found in the
+local file index, and to choose which file of each group to keep
+*/
+
+var duplicateMethodOptions = map[string]operations.DuplicateMethod{
+	"Identical files":       operations.DuplicateContentHash,
+	"Same artist and title": operations.DuplicateArtistTitle,
+	"Sounds the same":       operations.DuplicateFingerprint,
+}
+
+func (e *guiEnv) duplicatesView() fyne.CanvasObject {
+	ok, canvas := e.checkConfig([]func() (bool, string){
+		e.Config.CheckBaseDir,
+	})
+
+	if !ok {
+		return canvas
+	}
+
+	indexOpts := operations.IndexLocalFilesOpts{}
+	findOpts := operations.FindDuplicatesOpts{}
+
+	opEnv, runningOperation := e.prepareTrackOperation()
+
+	indexButton := widget.NewButton("Index library", func() {
+		e.executeTrackOperation(&execTrackOperationOpts{
+			opEnv:            opEnv,
+			runningOperation: runningOperation,
+			execFunc: func(ctx context.Context) {
+				opEnv.IndexLocalFiles(ctx, indexOpts)
+			},
+		})
+	})
+
+	fingerprintCheck := widget.NewCheck("Calculate fingerprints (slow, requires fpcalc)", func(b bool) {
+		indexOpts.Fingerprint = b
+	})
+
+	groupsContainer := container.NewVBox()
+
+	findButton := widget.NewButton("Find duplicates", func() {
+		if e.isBusy() {
+			return
+		}
+		e.findDuplicates(findOpts, groupsContainer)
+	})
+	findButton.Disable()
+
+	methodSelect := widget.NewSelect(
+		[]string{"Identical files", "Same artist and title", "Sounds the same"},
+		func(s string) {
+			findOpts.Method = duplicateMethodOptions[s]
+			enableBtnIfOptsOkay(findOpts, findButton)
+		},
+	)
+	methodSelect.PlaceHolder = "Please select how files should be compared"
+
+	return container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil, indexButton, fingerprintCheck),
+			container.NewBorder(nil, nil, nil, findButton, methodSelect),
+			widget.NewSeparator(),
+		), nil, nil, nil,
+		container.NewVSplit(
+			container.NewVScroll(groupsContainer),
+			runningOperation,
+		),
+	)
+}
+
+/*
+findDuplicates runs the FindDuplicates operation, and displays each group of
+duplicates found inside of groupsContainer
+*/
+func (e *guiEnv) findDuplicates(opts operations.FindDuplicatesOpts, groupsContainer *fyne.Container) {
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(
+		func(i float64) {},
+		func(d map[string]any) {
+			groups, ok := d["groups"].([][]data.LocalFile)
+			if !ok {
+				e.showErrorDialog(fault.Wrap(
+					fault.New("error casting groups to [][]data.LocalFile"),
+					fmsg.WithDesc(
+						"error parsing groups from operation data",
+						"Error parsing duplicate results",
+					),
+				), true)
+				return
+			}
+
+			groupsContainer.RemoveAll()
+
+			if len(groups) == 0 {
+				groupsContainer.Add(widget.NewLabel("No duplicates found"))
+				return
+			}
+
+			for _, g := range groups {
+				groupsContainer.Add(e.newDuplicateGroupCard(g, groupsContainer))
+			}
+		},
+		func(err error) {
+			e.showErrorDialog(err, true)
+		},
+	)
+
+	go opEnv.FindDuplicates(context.Background(), opts)
+}
+
+/*
+newDuplicateGroupCard builds a card for a single group of duplicates, allowing the user to select
+which file to keep and merge the others into it
+*/
+func (e *guiEnv) newDuplicateGroupCard(group []data.LocalFile, groupsContainer *fyne.Container) fyne.CanvasObject {
+
+	// radio options must be unique, map the displayed option back to the file path
+	optionPaths := make(map[string]string, len(group))
+	options := make([]string, len(group))
+
+	for i, f := range group {
+		option := fmt.Sprintf("%s (%.1f MB)", f.Path.String, float64(f.Size.Int64)/1024/1024)
+		optionPaths[option] = f.Path.String
+		options[i] = option
+	}
+
+	keeperRadio := widget.NewRadioGroup(options, func(string) {})
+	keeperRadio.Required = true
+	keeperRadio.SetSelected(options[0])
+
+	deleteCheck := widget.NewCheck("Delete other files", func(bool) {})
+
+	var card *widget.Card
+
+	mergeButton := widget.NewButton("Keep selected", func() {
+		keeperPath := optionPaths[keeperRadio.Selected]
+
+		var duplicatePaths []string
+		for _, f := range group {
+			if f.Path.String != keeperPath {
+				duplicatePaths = append(duplicatePaths, f.Path.String)
+			}
+		}
+
+		opEnv := e.opEnv()
+		opEnv.BuildOperationHandler(
+			func(i float64) {},
+			func(_ map[string]any) {
+				groupsContainer.Remove(card)
+				e.showInfoDialog(
+					"Merged Duplicates",
+					fmt.Sprintf("Merged %v duplicates into %s", len(duplicatePaths), keeperPath),
+				)
+			},
+			func(err error) {
+				e.showErrorDialog(err, true)
+			},
+		)
+
+		go opEnv.MergeDuplicates(context.Background(), operations.MergeDuplicatesOpts{
+			KeeperPath:     keeperPath,
+			DuplicatePaths: duplicatePaths,
+			DeleteFiles:    deleteCheck.Checked,
+		})
+	})
+
+	title := group[0].Title.String
+	if title == "" {
+		title = filepath.Base(group[0].Path.String)
+	}
+
+	card = widget.NewCard(
+		title,
+		fmt.Sprintf("%v files", len(group)),
+		container.NewVBox(
+			keeperRadio,
+			container.NewBorder(nil, nil, nil, mergeButton, deleteCheck),
+		),
+	)
+
+	return card
+}
@@ -1,12 +1,12 @@
 package iwidget
 
 import (
+	"fmt"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
-	"fyne.io/fyne/v2/data/validation"
 	"fyne.io/fyne/v2/widget"
-	"github.com/billiem/seren-management/pkg/data"
 	"github.com/billiem/seren-management/pkg/helpers"
 	"github.com/billiem/seren-management/pkg/streaming"
 )
@@ -91,14 +91,11 @@ func (i *PlaylistBindingList) Append(p *PlaylistBindingItem) {
 }
 
 /*
-Load loads all playlists from the database into the PlaylistBindingList
+Load loads playlists from any platform into the PlaylistBindingList
 */
-func (i *PlaylistBindingList) Load(playlists []data.SoundcloudPlaylist) {
-
-	for _, playlist := range playlists {
+func (i *PlaylistBindingList) Load(playlists []streaming.SourcePlaylist) {
 
-		p := streaming.SoundCloudPlaylist{}
-		p.LoadFromDB(playlist, nil)
+	for _, p := range playlists {
 
 		pbi := &PlaylistBindingItem{Base: i.Base}
 		pbi.SetFound(p)
@@ -117,7 +114,7 @@ type PlaylistBindingItem struct {
 	bindBase
 
 	// may want a context in here ?? later problem...
-	playlist streaming.SoundCloudPlaylist
+	playlist streaming.SourcePlaylist
 	state    PlaylistState
 	err      error
 }
@@ -134,7 +131,7 @@ func (i *PlaylistBindingItem) RemoveListener(l binding.DataListener) {
 	i.listeners.Delete(l)
 }
 
-func (i *PlaylistBindingItem) SetFinding(playlist streaming.SoundCloudPlaylist) {
+func (i *PlaylistBindingItem) SetFinding(playlist streaming.SourcePlaylist) {
 	i.Lock()
 	defer i.Unlock()
 
@@ -144,7 +141,7 @@ func (i *PlaylistBindingItem) SetFinding(playlist streaming.SoundCloudPlaylist)
 	i.Logger.Debugf("set playlist to finding: %s", i.playlist)
 }
 
-func (i *PlaylistBindingItem) SetFound(playlist streaming.SoundCloudPlaylist) {
+func (i *PlaylistBindingItem) SetFound(playlist streaming.SourcePlaylist) {
 	i.Lock()
 	defer i.Unlock()
 
@@ -183,7 +180,7 @@ type Playlist struct {
 	name            *widget.Label
 	openPlaylistBtn *widget.Button
 
-	playlistData streaming.SoundCloudPlaylist
+	playlistData streaming.SourcePlaylist
 
 	ctxCancel func() // used to cancel a downloading context
 }
@@ -191,7 +188,7 @@ type Playlist struct {
 /*
 NewPlaylist returns a new instance of the Playlist widget
 */
-func NewPlaylist(openPlaylistFunc func(streaming.SoundCloudPlaylist)) *Playlist {
+func NewPlaylist(openPlaylistFunc func(streaming.SourcePlaylist)) *Playlist {
 
 	i := &Playlist{
 		name:            widget.NewLabel(""),
@@ -236,7 +233,7 @@ func (i *Playlist) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(c)
 }
 
-func (i *Playlist) SetFinding(playlist streaming.SoundCloudPlaylist) {
+func (i *Playlist) SetFinding(playlist streaming.SourcePlaylist) {
 	i.playlistData = playlist
 
 	i.findingContent.Show()
@@ -244,13 +241,13 @@ func (i *Playlist) SetFinding(playlist streaming.SoundCloudPlaylist) {
 	i.failedContent.Hide()
 }
 
-func (i *Playlist) SetFound(playlist streaming.SoundCloudPlaylist) {
+func (i *Playlist) SetFound(playlist streaming.SourcePlaylist) {
 	i.playlistData = playlist
 
 	i.findingContent.Hide()
 	i.foundContent.Show()
 	i.failedContent.Hide()
-	i.name.SetText(playlist.Name)
+	i.name.SetText(fmt.Sprintf("[%s] %s", playlist.Platform, playlist.Name))
 }
 
 func (i *Playlist) SetFailed(err error) {
@@ -274,13 +271,14 @@ type AddPlaylist struct {
 }
 
 /*
-NewAddPlaylist returns a new instance of AddPlaylist widget
+NewAddPlaylist returns a new instance of AddPlaylist widget, validate checks the URL is a playlist
+from a supported platform before addPlaylist is called with it
 */
-func NewAddPlaylist(addPlaylist func(string)) *AddPlaylist {
+func NewAddPlaylist(addPlaylist func(string), validate func(string) error) *AddPlaylist {
 
 	urlEntry := widget.NewEntry()
-	urlEntry.SetPlaceHolder("SoundCloud Playlist URL")
-	urlEntry.Validator = validation.NewRegexp(`soundcloud\.com\/.*\/sets`, "not a valid SoundCloud playlist url")
+	urlEntry.SetPlaceHolder("SoundCloud or Spotify playlist URL")
+	urlEntry.Validator = validate
 
 	urlEntry.OnSubmitted = func(s string) {
 		if s == "" {
@@ -30,7 +30,7 @@ type TrackListSection struct {
 
 func NewTrackListSection(widgetBase *Base, tlb *TrackListBinding, selectedTrack *SelectedTrackBinding, trackListFuncs TrackListFuncs) *TrackListSection {
 
-	tlb.FilterSortInfo = &FilterSortInfo{}
+	tlb.FilterSortInfo = &streaming.FilterSortInfo{}
 
 	trackListSection := &TrackListSection{
 		Base:                    widgetBase,
@@ -56,9 +56,6 @@ func (t *TrackListSection) CreateRenderer() fyne.WidgetRenderer {
 	)
 }
 
-type FilterSortInfo struct {
-}
-
 /*
 TrackListControls contains controls for filtering and sorting a TrackList
 */
@@ -109,21 +106,56 @@ func (i *TrackListControls) CreateRenderer() fyne.WidgetRenderer {
 	)
 }
 
+var sortFieldOptions = map[string]streaming.SoundCloudTrackSortField{
+	"Default":         streaming.SortDefault,
+	"Name":            streaming.SortName,
+	"Genre":           streaming.SortGenre,
+	"Tags":            streaming.SortTags,
+	"Publisher":       streaming.SortPublisher,
+	"SoundCloud User": streaming.SortSoundCloudUser,
+}
+
+var sortFieldOptionNames = []string{"Default", "Name", "Genre", "Tags", "Publisher", "SoundCloud User"}
+
+/*
+TrackListSortControls sorts a TrackList by up to two fields, the second
+is used when tracks have the same value for the first
+*/
 type TrackListSortControls struct {
 	*Base
 	widget.BaseWidget
 
-	SortBy *widget.Select
-	Desc   *widget.Check
+	SortBy     *widget.Select
+	Desc       *widget.Check
+	ThenBy     *widget.Select
+	ThenByDesc *widget.Check
 }
 
-func NewTrackSortControls(widgetBase *Base, fsi *FilterSortInfo, callback func()) *TrackListSortControls {
+func NewTrackSortControls(widgetBase *Base, fsi *streaming.FilterSortInfo, callback func()) *TrackListSortControls {
 	tlsc := &TrackListSortControls{
-		Base:   widgetBase,
-		SortBy: widget.NewSelect([]string{"Default", "Name", "Genre", "Tags", "Publisher", "SoundCloud User"}, nil),
-		Desc:   widget.NewCheck("Descending", nil),
+		Base:       widgetBase,
+		SortBy:     widget.NewSelect(sortFieldOptionNames, nil),
+		Desc:       widget.NewCheck("Descending", nil),
+		ThenBy:     widget.NewSelect(sortFieldOptionNames, nil),
+		ThenByDesc: widget.NewCheck("Descending", nil),
 	}
 
+	tlsc.SortBy.SetSelected("Default")
+	tlsc.ThenBy.SetSelected("Default")
+
+	update := func() {
+		fsi.Sort = []streaming.SoundCloudTrackSortKey{
+			{Field: sortFieldOptions[tlsc.SortBy.Selected], Desc: tlsc.Desc.Checked},
+			{Field: sortFieldOptions[tlsc.ThenBy.Selected], Desc: tlsc.ThenByDesc.Checked},
+		}
+		callback()
+	}
+
+	tlsc.SortBy.OnChanged = func(string) { update() }
+	tlsc.Desc.OnChanged = func(bool) { update() }
+	tlsc.ThenBy.OnChanged = func(string) { update() }
+	tlsc.ThenByDesc.OnChanged = func(bool) { update() }
+
 	tlsc.ExtendBaseWidget(tlsc)
 
 	return tlsc
@@ -132,23 +164,93 @@ func NewTrackSortControls(widgetBase *Base, fsi *FilterSortInfo, callback func()
 func (i *TrackListSortControls) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(
 		container.NewVBox(
-			i.SortBy,
-			i.Desc,
+			container.NewBorder(nil, nil, nil, i.Desc, i.SortBy),
+			container.NewBorder(nil, nil, widget.NewLabel("then"), i.ThenByDesc, i.ThenBy),
 		),
 	)
 }
 
+var filterStateOptions = map[string]streaming.FilterState{
+	"Any": streaming.FilterAny,
+	"Yes": streaming.FilterYes,
+	"No":  streaming.FilterNo,
+}
+
+/*
+newFilterStateSelect builds a select for filtering on a yes/no property of a track
+*/
+func newFilterStateSelect(state *streaming.FilterState, callback func()) *widget.Select {
+	s := widget.NewSelect([]string{"Any", "Yes", "No"}, nil)
+	s.SetSelected("Any")
+	s.OnChanged = func(v string) {
+		*state = filterStateOptions[v]
+		callback()
+	}
+	return s
+}
+
+var purchaseCategoryOptions = map[string]streaming.PurchaseCategory{
+	"Any":        "",
+	"Store":      streaming.PurchaseStore,
+	"Free gate":  streaming.PurchaseFreeGate,
+	"Label site": streaming.PurchaseLabelSite,
+	"None":       streaming.PurchaseNone,
+}
+
+/*
+newPurchaseCategorySelect builds a select for filtering on the category of a track's purchase link
+*/
+func newPurchaseCategorySelect(category *streaming.PurchaseCategory, callback func()) *widget.Select {
+	s := widget.NewSelect([]string{"Any", "Store", "Free gate", "Label site", "None"}, nil)
+	s.SetSelected("Any")
+	s.OnChanged = func(v string) {
+		*category = purchaseCategoryOptions[v]
+		callback()
+	}
+	return s
+}
+
+/*
+TrackListFilterControls filters a TrackList by text, genre and the
+download status of each track
+*/
 type TrackListFilterControls struct {
 	*Base
 	widget.BaseWidget
 
-	ShowLinked *widget.Check
+	Text                *widget.Entry
+	Genre               *widget.Entry
+	HasDownloadsLeft    *widget.Select
+	HasPurchaseLink     *widget.Select
+	PurchaseCategory    *widget.Select
+	Downloaded          *widget.Select
+	BrokenPath          *widget.Select
+	RemovedFromPlaylist *widget.Select
 }
 
-func NewTrackListFilterControls(widgetBase *Base, fsi *FilterSortInfo, callback func()) *TrackListFilterControls {
+func NewTrackListFilterControls(widgetBase *Base, fsi *streaming.FilterSortInfo, callback func()) *TrackListFilterControls {
 	tlfc := &TrackListFilterControls{
-		Base:       widgetBase,
-		ShowLinked: widget.NewCheck("Show Linked", nil),
+		Base:                widgetBase,
+		Text:                widget.NewEntry(),
+		Genre:               widget.NewEntry(),
+		HasDownloadsLeft:    newFilterStateSelect(&fsi.Filter.HasDownloadsLeft, callback),
+		HasPurchaseLink:     newFilterStateSelect(&fsi.Filter.HasPurchaseLink, callback),
+		PurchaseCategory:    newPurchaseCategorySelect(&fsi.Filter.PurchaseCategory, callback),
+		Downloaded:          newFilterStateSelect(&fsi.Filter.Downloaded, callback),
+		BrokenPath:          newFilterStateSelect(&fsi.Filter.BrokenPath, callback),
+		RemovedFromPlaylist: newFilterStateSelect(&fsi.Filter.RemovedFromPlaylist, callback),
+	}
+
+	tlfc.Text.SetPlaceHolder("Search name, artist, user or tags")
+	tlfc.Text.OnChanged = func(s string) {
+		fsi.Filter.Text = s
+		callback()
+	}
+
+	tlfc.Genre.SetPlaceHolder("Genre")
+	tlfc.Genre.OnChanged = func(s string) {
+		fsi.Filter.Genre = s
+		callback()
 	}
 
 	tlfc.ExtendBaseWidget(tlfc)
@@ -159,7 +261,16 @@ func NewTrackListFilterControls(widgetBase *Base, fsi *FilterSortInfo, callback
 func (i *TrackListFilterControls) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(
 		container.NewVBox(
-			i.ShowLinked,
+			container.NewGridWithColumns(2, i.Text, i.Genre),
+			container.NewGridWithColumns(
+				4,
+				widget.NewLabel("Downloads left"), i.HasDownloadsLeft,
+				widget.NewLabel("Purchase link"), i.HasPurchaseLink,
+				widget.NewLabel("Purchase type"), i.PurchaseCategory,
+				widget.NewLabel("Downloaded"), i.Downloaded,
+				widget.NewLabel("Broken path"), i.BrokenPath,
+				widget.NewLabel("Removed"), i.RemovedFromPlaylist,
+			),
 		),
 	)
 }
@@ -172,7 +283,10 @@ type TrackListImportExportControls struct {
 	*Base
 	widget.BaseWidget
 
-	refreshButton *widget.Button
+	refreshButton  *widget.Button
+	downloadButton *widget.Button
+	artworkButton  *widget.Button
+	downloadBar    *widget.ProgressBar
 
 	lTemp *widget.Label
 }
@@ -184,8 +298,38 @@ func NewTrackListImportExportControls(widgetBase *Base, trackListFuncs TrackList
 		refreshButton: widget.NewButtonWithIcon("Refresh playlist", theme.ViewRefreshIcon(), func() {
 			trackListFuncs.RefreshSoundCloudPlaylist()
 		}),
+		downloadBar: widget.NewProgressBar(),
+	}
+
+	tliec.downloadBar.Hide()
+
+	// both operations share the progress bar, so only one can run at a time
+	runWithProgress := func(run func(func(float64), func())) {
+		tliec.downloadButton.Disable()
+		tliec.artworkButton.Disable()
+		tliec.downloadBar.SetValue(0)
+		tliec.downloadBar.Show()
+
+		go run(
+			func(f float64) {
+				tliec.downloadBar.SetValue(f)
+			},
+			func() {
+				tliec.downloadBar.Hide()
+				tliec.downloadButton.Enable()
+				tliec.artworkButton.Enable()
+			},
+		)
 	}
 
+	tliec.downloadButton = widget.NewButtonWithIcon("Download free tracks", theme.DownloadIcon(), func() {
+		runWithProgress(trackListFuncs.DownloadSoundCloudPlaylist)
+	})
+
+	tliec.artworkButton = widget.NewButtonWithIcon("Embed artwork", theme.MediaPhotoIcon(), func() {
+		runWithProgress(trackListFuncs.EmbedSoundCloudArtwork)
+	})
+
 	tliec.ExtendBaseWidget(tliec)
 
 	return tliec
@@ -196,6 +340,9 @@ func (i *TrackListImportExportControls) CreateRenderer() fyne.WidgetRenderer {
 		container.NewVBox(
 			i.lTemp,
 			i.refreshButton,
+			i.downloadButton,
+			i.artworkButton,
+			i.downloadBar,
 		),
 	)
 }
@@ -279,7 +426,7 @@ type bindBase struct {
 type TrackListBinding struct {
 	bindBase
 
-	FilterSortInfo *FilterSortInfo
+	FilterSortInfo *streaming.FilterSortInfo
 
 	Tracks        []*streaming.SoundCloudTrack
 	VisibleTracks []*TrackBinding
@@ -325,20 +472,47 @@ func (i *TrackListBinding) Set(p []*streaming.SoundCloudTrack) {
 	i.Tracks = p
 }
 
+/*
+IndexOfTrack returns the index of the visible track with the external ID, or -1 if it isn't visible
+*/
+func (i *TrackListBinding) IndexOfTrack(externalID int64) int {
+	i.Lock()
+	defer i.Unlock()
+	for j, t := range i.VisibleTracks {
+		if t.Track.ExternalID == externalID {
+			return j
+		}
+	}
+	return -1
+}
+
 /*
 ApplyFilterSort applies the current filter and sort settings to the list of tracks
 
 This uses the list of tracks (i.Tracks) attached to the widget, placing the filtered and sorted
-tracks into i.VisibleTracks
+tracks into i.VisibleTracks, then notifies any listeners so the list is redrawn
 */
 func (i *TrackListBinding) ApplyFilterSort() {
 	i.Lock()
-	defer i.Unlock()
-	// TODO
-	i.VisibleTracks = []*TrackBinding{}
-	for _, t := range i.Tracks {
-		i.VisibleTracks = append(i.VisibleTracks, &TrackBinding{Track: t})
+
+	fsi := streaming.FilterSortInfo{}
+	if i.FilterSortInfo != nil {
+		fsi = *i.FilterSortInfo
+	}
+
+	visible := fsi.Apply(i.Tracks)
+
+	i.VisibleTracks = make([]*TrackBinding, len(visible))
+	for j, t := range visible {
+		i.VisibleTracks[j] = &TrackBinding{Track: t}
 	}
+
+	i.Unlock()
+
+	i.listeners.Range(func(key, _ interface{}) bool {
+		key.(binding.DataListener).DataChanged()
+		return true
+	})
 }
 
 /*
@@ -364,5 +538,7 @@ func (i *TrackBinding) RemoveListener(l binding.DataListener) {
 }
 
 type TrackListFuncs struct {
-	RefreshSoundCloudPlaylist func()
+	RefreshSoundCloudPlaylist  func()
+	DownloadSoundCloudPlaylist func(onProgress func(float64), onDone func())
+	EmbedSoundCloudArtwork     func(onProgress func(float64), onDone func())
 }
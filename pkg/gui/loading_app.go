@@ -29,5 +29,7 @@ func (e *guiEnv) loadApp(appLoaded func()) {
 
 	wg.Wait()
 
+	go e.scheduleRefreshAllSoundCloudPlaylists()
+
 	appLoaded()
 }
@@ -0,0 +1,149 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/gui/iwidget"
+	"github.com/billiem/seren-management/pkg/operations"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+Provides the playlist matching view, used to match SoundCloud tracks to the local library
+and to list the tracks which are still missing from it
+*/
+
+func (e *guiEnv) syncView() fyne.CanvasObject {
+
+	var unmatched []streaming.SoundCloudTrack
+
+	summary := widget.NewLabel("")
+
+	unmatchedList := widget.NewList(
+		func() int {
+			return len(unmatched)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(
+				nil, nil, nil,
+				iwidget.NewOpenInBrowserButton(e.getWidgetBase(), "", ""),
+				widget.NewLabel(""),
+			)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			t := unmatched[i]
+			row := o.(*fyne.Container)
+
+			row.Objects[0].(*widget.Label).SetText(fmt.Sprintf("%s (%s)", t.Name, t.SoundCloudUser))
+
+			purchaseButton := row.Objects[1].(*iwidget.OpenInBrowserButton)
+			if t.PurchaseURL == "" {
+				purchaseButton.Hide()
+				return
+			}
+			purchaseButton.SetContent("Buy", t.PurchaseURL)
+			purchaseButton.Show()
+		},
+	)
+
+	setUnmatched := func(tracks []streaming.SoundCloudTrack) {
+		unmatched = tracks
+		summary.SetText(fmt.Sprintf("%v SoundCloud tracks are not in your library", len(unmatched)))
+		unmatchedList.Refresh()
+	}
+
+	loading := iwidget.NewViewLoading("Loading unmatched tracks...")
+	go func() {
+		tracks, err := e.loadUnmatchedSoundCloudTracks()
+
+		if err != nil {
+			e.showErrorDialog(err, true)
+			return
+		}
+
+		setUnmatched(tracks)
+		loading.Hide()
+	}()
+
+	matchButton := widget.NewButton("Match tracks", func() {
+		if e.isBusy() {
+			return
+		}
+		e.matchSoundCloudTracks(setUnmatched)
+	})
+
+	return container.NewStack(
+		container.NewBorder(
+			container.NewVBox(
+				container.NewBorder(nil, nil, nil, matchButton, summary),
+				widget.NewSeparator(),
+			), nil, nil, nil,
+			unmatchedList,
+		),
+		loading,
+	)
+}
+
+/*
+loadUnmatchedSoundCloudTracks gets the SoundCloud tracks without a match from the database
+*/
+func (e *guiEnv) loadUnmatchedSoundCloudTracks() ([]streaming.SoundCloudTrack, error) {
+
+	tracks, err := e.SerenDB.ListUnmatchedSoundCloudTracks(context.Background())
+
+	if err != nil {
+		return nil, fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error listing unmatched tracks",
+				"Error loading unmatched tracks",
+			),
+		)
+	}
+
+	unmatched := make([]streaming.SoundCloudTrack, len(tracks))
+
+	for i, track := range tracks {
+		unmatched[i].LoadFromDB(track)
+	}
+
+	return unmatched, nil
+}
+
+/*
+matchSoundCloudTracks runs the MatchSoundCloudTracks operation, and passes the tracks left
+unmatched to onMatched
+*/
+func (e *guiEnv) matchSoundCloudTracks(onMatched func([]streaming.SoundCloudTrack)) {
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(
+		func(i float64) {},
+		func(d map[string]any) {
+			unmatched, ok := d["unmatched"].([]streaming.SoundCloudTrack)
+			if !ok {
+				e.showErrorDialog(fault.Wrap(
+					fault.New("error casting unmatched to []streaming.SoundCloudTrack"),
+					fmsg.WithDesc(
+						"error parsing unmatched from operation data",
+						"Error parsing matching results",
+					),
+				), true)
+				return
+			}
+
+			onMatched(unmatched)
+		},
+		func(err error) {
+			e.showErrorDialog(err, true)
+		},
+	)
+
+	go opEnv.MatchSoundCloudTracks(context.Background(), operations.MatchSoundCloudTracksOpts{})
+}
@@ -2,20 +2,67 @@ package gui
 
 import (
 	"fmt"
+	"net/url"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
 	"github.com/billiem/seren-management/pkg/gui/iwidget"
 	"github.com/billiem/seren-management/pkg/gui/uihelpers"
 	"github.com/billiem/seren-management/pkg/streaming"
 )
 
 /*
-openPlaylistPopup opens a popup window for a given playlist
+openSourcePlaylist opens a playlist from any platform, SoundCloud playlists are opened in the playlist
+popup and other platforms, which have no tracks to download, are opened in the browser
+*/
+func (e *guiEnv) openSourcePlaylist(playlist streaming.SourcePlaylist) {
+
+	if playlist.Platform == streaming.PlatformSoundCloud {
+		var p streaming.SoundCloudPlaylist
+
+		err := p.LoadFromSource(playlist)
+
+		if err != nil {
+			e.showErrorDialog(fault.Wrap(
+				err,
+				fmsg.WithDesc(
+					"error loading soundcloud playlist",
+					"There was an error opening the playlist",
+				),
+			), true)
+			return
+		}
+
+		e.openPlaylistPopup(p, 0)
+		return
+	}
+
+	u, err := url.Parse(playlist.PermalinkUrl)
+
+	if err == nil {
+		err = fyne.CurrentApp().OpenURL(u)
+	}
+
+	if err != nil {
+		e.showErrorDialog(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error opening playlist",
+				"There was an error opening the playlist in your browser",
+			),
+		), true)
+	}
+}
+
+/*
+openPlaylistPopup opens a popup window for a given playlist, selecting the track with selectExternalID
+once the tracks are loaded if it isn't 0
 
 This is called when a user clicks the 'open playlist' button on a playlist
 */
-func (e *guiEnv) openPlaylistPopup(playlist streaming.SoundCloudPlaylist) {
+func (e *guiEnv) openPlaylistPopup(playlist streaming.SoundCloudPlaylist, selectExternalID int64) {
 
 	loading := iwidget.NewViewLoading(fmt.Sprintf("Loading tracks for %s...", playlist.Name))
 
@@ -28,7 +75,9 @@ func (e *guiEnv) openPlaylistPopup(playlist streaming.SoundCloudPlaylist) {
 		&trackListBinding,
 		selectedTrack,
 		iwidget.TrackListFuncs{
-			RefreshSoundCloudPlaylist: e.getRefreshSoundCloudPlaylistFunc(playlist, &trackListBinding),
+			RefreshSoundCloudPlaylist:  e.getRefreshSoundCloudPlaylistFunc(playlist, &trackListBinding),
+			DownloadSoundCloudPlaylist: e.getDownloadSoundCloudPlaylistFunc(playlist, &trackListBinding),
+			EmbedSoundCloudArtwork:     e.getEmbedSoundCloudArtworkFunc(playlist),
 		},
 	)
 
@@ -69,13 +118,19 @@ func (e *guiEnv) openPlaylistPopup(playlist streaming.SoundCloudPlaylist) {
 	// Load the tracks in the background, hide the loading screen when done
 	// This should be quick as it only requires a database query
 	go func(tlb *iwidget.TrackListBinding) {
-		err := e.loadSoundCloudPlaylistTracks(playlist.ExternalID, tlb)
+		err := e.loadSoundCloudPlaylistTracks(playlist, tlb)
 		if err != nil {
 			playlistPopup.Hide()
 			e.showErrorDialog(err, true)
 			return
 		}
 		loading.Hide()
+
+		if selectExternalID != 0 {
+			if i := tlb.IndexOfTrack(selectExternalID); i >= 0 {
+				trackListSection.List.Select(i)
+			}
+		}
 	}(&trackListBinding)
 
 	e.guiState.busy = true
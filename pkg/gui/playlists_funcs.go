@@ -8,6 +8,7 @@ import (
 	"github.com/Southclaws/fault"
 	"github.com/Southclaws/fault/fctx"
 	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
 	"github.com/billiem/seren-management/pkg/gui/iwidget"
 	"github.com/billiem/seren-management/pkg/operations"
 	"github.com/billiem/seren-management/pkg/streaming"
@@ -21,106 +22,89 @@ through to widget constructors
 /*
 getAddPlaylistCallback returns a function that can be used to add a playlist.
 
-This function calls SoundCloud, and adds the playlist to the database, it is attached to the
-'add playlist' button
+This function gets the playlist from the streaming platform its URL belongs to, and adds the playlist
+to the database, it is attached to the 'add playlist' button
 */
 func (e *guiEnv) getAddPlaylistCallback(playlistBindVals *iwidget.PlaylistBindingList, onAdd func()) func(string) {
-	ctx := context.Background()
-	ctx, ctxClose := context.WithCancel(ctx)
-	opEnv := e.opEnv()
-	opEnv.BuildOperationHandler(
-		func(i float64) {},
-		func(_ map[string]any) {
-			ctxClose()
-		},
-		func(err error) {
-			ctxClose()
-			e.logger.NonFatalError(err)
-		},
-	)
 
 	return func(urlRaw string) {
 
-		ctx = fctx.WithMeta(
-			ctx,
+		ctx := fctx.WithMeta(
+			context.Background(),
 			"url_raw", urlRaw,
 		)
 
-		pbi := iwidget.PlaylistBindingItem{
-			Base: e.getWidgetBase(),
+		// the url has already been validated, share links have tracking query params which aren't needed
+		if netUrl, err := url.Parse(urlRaw); err == nil {
+			netUrl.RawQuery = ""
+			urlRaw = netUrl.String()
 		}
 
-		netUrl, err := url.Parse(urlRaw)
-
-		if err != nil {
-			err = fault.Wrap(
-				err,
-				fctx.With(ctx),
-				fmsg.WithDesc(
-					"error parsing url",
-					"Could not parse the URL, please check it is correct",
-				),
-			)
-
-			e.logger.NonFatalError(err)
-			pbi.SetFailed(err)
-
-			playlistBindVals.Append(&pbi)
-			onAdd()
-			return
+		pbi := iwidget.PlaylistBindingItem{
+			Base: e.getWidgetBase(),
 		}
 
-		netUrl.RawQuery = ""
-
-		ctx = fctx.WithMeta(
-			ctx,
-			"parsed_url", netUrl.String(),
-		)
-
 		pbi.SetFinding(
-			streaming.SoundCloudPlaylist{
-				SearchUrl: netUrl.String(),
+			streaming.SourcePlaylist{
+				SearchUrl: urlRaw,
 			},
 		)
 		playlistBindVals.Append(&pbi)
 		onAdd()
 
-		go opEnv.GetSoundCloudPlaylist(ctx, operations.GetSoundCloudPlaylistOpts{
-			PlaylistURL: netUrl.String(),
-		}, func(p streaming.SoundCloudPlaylist, err error) {
-			if err != nil {
+		opEnv := e.opEnv()
+		opEnv.BuildOperationHandler(
+			func(i float64) {},
+			func(d map[string]any) {
+				p, _ := d["playlist"].(streaming.SourcePlaylist)
+				pbi.SetFound(p)
+				onAdd()
+			},
+			func(err error) {
 				err = fault.Wrap(
 					err,
 					fctx.With(ctx),
 					fmsg.WithDesc(
 						"error getting playlist",
-						"There was an error getting the playlist from SoundCloud",
+						"There was an error getting the playlist",
 					),
 				)
 
 				pbi.SetFailed(err)
 				e.logger.NonFatalError(err)
+				onAdd()
+			},
+		)
 
-				return
-			}
-
-			pbi.SetFound(p)
-			onAdd()
+		go opEnv.ImportPlaylist(ctx, operations.ImportPlaylistOpts{
+			PlaylistURL: urlRaw,
 		})
 	}
 }
 
-func (e *guiEnv) loadSoundCloudPlaylists(playlistBindingList *iwidget.PlaylistBindingList) error {
-	playlists, err := e.SerenDB.ListSoundCloudPlaylists(context.Background())
+/*
+loadPlaylists loads the playlists imported from every streaming platform
+*/
+func (e *guiEnv) loadPlaylists(playlistBindingList *iwidget.PlaylistBindingList) error {
 
-	if err != nil {
-		return fault.Wrap(
-			err,
-			fmsg.WithDesc(
-				"error getting soundcloud playlists from db",
-				"There was an error getting the SoundCloud playlists from the database",
-			),
-		)
+	var playlists []streaming.SourcePlaylist
+	var loadErr error
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(
+		func(i float64) {},
+		func(d map[string]any) {
+			playlists, _ = d["playlists"].([]streaming.SourcePlaylist)
+		},
+		func(err error) {
+			loadErr = err
+		},
+	)
+
+	opEnv.ListPlaylists(context.Background(), operations.ListPlaylistsOpts{})
+
+	if loadErr != nil {
+		return loadErr
 	}
 
 	e.logger.Debugf("successfully got %v playlists from db, loading into gui", len(playlists))
@@ -130,10 +114,13 @@ func (e *guiEnv) loadSoundCloudPlaylists(playlistBindingList *iwidget.PlaylistBi
 	return nil
 }
 
-func (e *guiEnv) loadSoundCloudPlaylistTracks(playlistExtID int64, trackListBinding *iwidget.TrackListBinding) error {
+func (e *guiEnv) loadSoundCloudPlaylistTracks(playlist streaming.SoundCloudPlaylist, trackListBinding *iwidget.TrackListBinding) error {
 	tracks, err := e.SerenDB.ListSoundCloudTracksByPlaylistExternalID(
 		context.Background(),
-		sql.NullInt64{Valid: true, Int64: playlistExtID},
+		data.ListSoundCloudTracksByPlaylistExternalIDParams{
+			PlaylistExternalID: sql.NullInt64{Valid: true, Int64: playlist.ExternalID},
+			SourceType:         string(playlist.SourceType),
+		},
 	)
 	if err != nil {
 		return fault.Wrap(
@@ -147,7 +134,7 @@ func (e *guiEnv) loadSoundCloudPlaylistTracks(playlistExtID int64, trackListBind
 	streamTracks := make([]*streaming.SoundCloudTrack, len(tracks))
 	for i, track := range tracks {
 		streamTrack := streaming.SoundCloudTrack{}
-		streamTrack.LoadFromDB(track)
+		streamTrack.LoadFromPlaylistDB(track)
 		streamTracks[i] = &streamTrack
 	}
 	trackListBinding.Set(streamTracks)
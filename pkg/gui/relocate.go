@@ -0,0 +1,151 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/operations"
+)
+
+/*
+Provides the relocate view, used to review proposed new locations for broken
+SoundCloud local paths and missing collection entries before applying them
+*/
+
+func (e *guiEnv) relocateView() fyne.CanvasObject {
+
+	matchesContainer := container.NewVBox()
+
+	// matches the user has selected to apply, keyed by the old path
+	selected := make(map[string]operations.RelocationMatch)
+
+	applyButton := widget.NewButton("Apply selected", func() {
+		if e.isBusy() || len(selected) == 0 {
+			return
+		}
+		e.applyRelocations(selected, matchesContainer)
+	})
+	applyButton.Disable()
+
+	findButton := widget.NewButton("Find new locations", func() {
+		if e.isBusy() {
+			return
+		}
+		e.findRelocations(selected, matchesContainer, applyButton)
+	})
+
+	return container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(
+				nil, nil, nil,
+				container.NewHBox(findButton, applyButton),
+				widget.NewLabel("Search the local file index for files which have been moved"),
+			),
+			widget.NewSeparator(),
+		), nil, nil, nil,
+		container.NewVScroll(matchesContainer),
+	)
+}
+
+/*
+findRelocations runs the FindRelocations operation, and displays each proposed match inside
+of matchesContainer with a check to select it, matches with high confidence are selected by default
+*/
+func (e *guiEnv) findRelocations(
+	selected map[string]operations.RelocationMatch,
+	matchesContainer *fyne.Container,
+	applyButton *widget.Button,
+) {
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(
+		func(i float64) {},
+		func(d map[string]any) {
+			matches, ok := d["matches"].([]operations.RelocationMatch)
+			if !ok {
+				e.showErrorDialog(fault.Wrap(
+					fault.New("error casting matches to []operations.RelocationMatch"),
+					fmsg.WithDesc(
+						"error parsing matches from operation data",
+						"Error parsing relocation results",
+					),
+				), true)
+				return
+			}
+
+			for k := range selected {
+				delete(selected, k)
+			}
+			matchesContainer.RemoveAll()
+
+			if len(matches) == 0 {
+				matchesContainer.Add(widget.NewLabel("No new locations found"))
+				applyButton.Disable()
+				return
+			}
+
+			for _, m := range matches {
+				m := m
+				check := widget.NewCheck(
+					fmt.Sprintf("%s\n-> %s", m.OldPath, m.NewPath),
+					func(b bool) {
+						if b {
+							selected[m.OldPath] = m
+						} else {
+							delete(selected, m.OldPath)
+						}
+					},
+				)
+				check.SetChecked(m.Confidence >= 0.9)
+
+				matchesContainer.Add(container.NewBorder(
+					nil, nil, nil,
+					widget.NewLabel(fmt.Sprintf("%s, %.0f%% (%s)", m.Source, m.Confidence*100, m.Reason)),
+					check,
+				))
+			}
+
+			applyButton.Enable()
+		},
+		func(err error) {
+			e.showErrorDialog(err, true)
+		},
+	)
+
+	go opEnv.FindRelocations(context.Background(), operations.FindRelocationsOpts{})
+}
+
+/*
+applyRelocations runs the ApplyRelocations operation for the selected matches
+*/
+func (e *guiEnv) applyRelocations(selected map[string]operations.RelocationMatch, matchesContainer *fyne.Container) {
+
+	var matches []operations.RelocationMatch
+	for _, m := range selected {
+		matches = append(matches, m)
+	}
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(
+		func(i float64) {},
+		func(d map[string]any) {
+			matchesContainer.RemoveAll()
+			e.showInfoDialog(
+				"Relocated Files",
+				fmt.Sprintf("Relocated %v SoundCloud tracks and %v collection entries", d["soundcloud"], d["traktor"]),
+			)
+		},
+		func(err error) {
+			e.showErrorDialog(err, true)
+		},
+	)
+
+	go opEnv.ApplyRelocations(context.Background(), operations.ApplyRelocationsOpts{
+		Matches: matches,
+	})
+}
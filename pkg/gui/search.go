@@ -0,0 +1,228 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/gui/uihelpers"
+	"github.com/billiem/seren-management/pkg/operations"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+Provides the search bar shown above every view, used to search SoundCloud tracks, local files and
+collection entries and jump to the result
+*/
+
+func (e *guiEnv) makeSearchBar() fyne.CanvasObject {
+
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(`Search, i.e. bicep label:"ninja tune" bpm:120-128 key:8A source:collection`)
+
+	search := func() {
+		if strings.TrimSpace(entry.Text) == "" || e.isBusy() {
+			return
+		}
+		e.searchLibrary(entry.Text)
+	}
+
+	entry.OnSubmitted = func(string) {
+		search()
+	}
+
+	return container.NewBorder(
+		nil, widget.NewSeparator(), nil,
+		widget.NewButton("Search", search),
+		entry,
+	)
+}
+
+/*
+searchLibrary runs the SearchLibrary operation, showing the results in a popup
+*/
+func (e *guiEnv) searchLibrary(query string) {
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(
+		func(i float64) {},
+		func(d map[string]any) {
+			results, ok := d["results"].([]data.SearchResult)
+			if !ok {
+				e.showErrorDialog(fault.Wrap(
+					fault.New("error casting results to []data.SearchResult"),
+					fmsg.WithDesc(
+						"error parsing results from operation data",
+						"Error parsing search results",
+					),
+				), true)
+				return
+			}
+
+			e.openSearchResultsPopup(query, results)
+		},
+		func(err error) {
+			e.showErrorDialog(err, true)
+		},
+	)
+
+	// collections are indexed when the app loads
+	go opEnv.SearchLibrary(context.Background(), operations.SearchLibraryOpts{Query: query})
+}
+
+/*
+openSearchResultsPopup lists the results of a search, selecting a result opens it
+*/
+func (e *guiEnv) openSearchResultsPopup(query string, results []data.SearchResult) {
+
+	var resultsPopup *widget.PopUp
+
+	resultList := widget.NewList(
+		func() int {
+			return len(results)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(describeSearchResult(results[i]))
+		},
+	)
+
+	resultList.OnSelected = func(i widget.ListItemID) {
+		resultList.Unselect(i)
+		e.openSearchResult(results[i], resultsPopup.Hide)
+	}
+
+	content := container.NewBorder(
+		widget.NewLabel(fmt.Sprintf("%v results, select a result to open it", len(results))),
+		nil, nil, nil,
+		resultList,
+	)
+
+	resultsPopup = uihelpers.NewPercentagePopup(
+		fmt.Sprintf("Search: %s", query),
+		content,
+		e.mainWindow,
+		e.resizeEvents,
+		0.7, 0.7,
+		fyne.NewSize(1000, 0),
+		nil,
+	)
+
+	resultsPopup.Show()
+}
+
+func describeSearchResult(r data.SearchResult) string {
+
+	text := fmt.Sprintf("[%s] %s - %s", r.Source, r.Artist.String, r.Title.String)
+
+	var info []string
+	if r.Bpm.Valid {
+		info = append(info, fmt.Sprintf("%.0f", r.Bpm.Float64))
+	}
+	if r.Key.Valid {
+		info = append(info, r.Key.String)
+	}
+	if len(info) > 0 {
+		text += fmt.Sprintf(" (%s)", strings.Join(info, " "))
+	}
+
+	return text
+}
+
+/*
+openSearchResult opens a SoundCloud track in its playlist, falling back to its SoundCloud page, and
+opens the folder containing a local file or collection entry
+
+closeResults is called before opening a playlist, as it opens in a popup of its own
+*/
+func (e *guiEnv) openSearchResult(r data.SearchResult, closeResults func()) {
+
+	if r.Source == data.SearchSourceSoundCloud {
+
+		if r.PlaylistExternalID.Valid {
+			playlist, err := e.getSoundCloudPlaylist(r.PlaylistExternalID.Int64, r.PlaylistSourceType.String)
+
+			if err != nil {
+				e.showErrorDialog(err, true)
+				return
+			}
+
+			closeResults()
+			e.openPlaylistPopup(playlist, r.ExternalID.Int64)
+			return
+		}
+
+		if r.PermalinkUrl.Valid {
+			e.openSearchResultURL(r.PermalinkUrl.String)
+			return
+		}
+	}
+
+	if r.Path.Valid {
+		u := &url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Dir(r.Path.String))}
+		e.openSearchResultURL(u.String())
+	}
+}
+
+func (e *guiEnv) openSearchResultURL(rawURL string) {
+
+	u, err := url.Parse(rawURL)
+
+	if err == nil {
+		err = fyne.CurrentApp().OpenURL(u)
+	}
+
+	if err != nil {
+		e.showErrorDialog(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error opening search result",
+				"There was an error opening the search result",
+			),
+		), true)
+	}
+}
+
+/*
+getSoundCloudPlaylist returns the stored playlist with the external ID and source type
+*/
+func (e *guiEnv) getSoundCloudPlaylist(externalID int64, sourceType string) (streaming.SoundCloudPlaylist, error) {
+
+	playlists, err := e.SerenDB.ListSoundCloudPlaylists(context.Background())
+
+	if err != nil {
+		return streaming.SoundCloudPlaylist{}, fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error listing soundcloud playlists",
+				"There was an error getting the SoundCloud playlists from the database",
+			),
+		)
+	}
+
+	for _, p := range playlists {
+		if p.ExternalID.Int64 == externalID && p.SourceType == sourceType {
+			playlist := streaming.SoundCloudPlaylist{}
+			playlist.LoadFromDB(p, nil)
+			return playlist, nil
+		}
+	}
+
+	return streaming.SoundCloudPlaylist{}, fault.Wrap(
+		fault.New("playlist not found"),
+		fmsg.WithDesc(
+			"soundcloud playlist not found",
+			"The playlist containing this track couldn't be found, it may have been removed",
+		),
+	)
+}
@@ -2,11 +2,17 @@ package gui
 
 import (
 	"fmt"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+
+	stems "github.com/billiem/seren-management/pkg/operations/stems"
 )
 
 func (e *guiEnv) openSettingsWindow(a fyne.App) bool {
@@ -61,8 +67,78 @@ func (e *guiEnv) openSettingsWindow(a fyne.App) bool {
 }
 
 func (e *guiEnv) generalTab() *fyne.Container {
-	return container.NewVBox(
+
+	profiles, err := helpers.ListProfiles()
+
+	if err != nil {
+		e.logger.NonFatalError(err)
+		profiles = []string{e.tmpConfig.Profile}
+	}
+
+	// the database used when no path is set
+	defaultDBConfig := *e.tmpConfig
+	defaultDBConfig.DBPath = ""
+	defaultDBPath, _ := defaultDBConfig.GetDBPath()
+
+	// build input widgets
+	profileSelect := widget.NewSelect(profiles, func(s string) {
+		e.tmpConfig.Profile = s
+	})
+	newProfileEntry := widget.NewEntry()
+	newProfileEntry.SetPlaceHolder("New profile name")
+	newProfileEntry.Validator = func(s string) error {
+		if s != "" && !helpers.CheckProfileName(s) {
+			return helpers.ErrInvalidProfileName
+		}
+		return nil
+	}
+	newProfileButton := widget.NewButton("Create", func() {
+		name := newProfileEntry.Text
+
+		_, err := helpers.CreateProfile(name)
+
+		if err != nil {
+			e.showErrorDialog(fault.Wrap(
+				err,
+				fmsg.WithDesc("error creating profile", fmt.Sprintf("There was an error creating the profile '%s'", name)),
+			), true)
+			return
+		}
+
+		if profiles, err := helpers.ListProfiles(); err == nil {
+			profileSelect.Options = profiles
+		}
+		profileSelect.SetSelected(name)
+		newProfileEntry.SetText("")
+	})
+	dbPathEntry := widget.NewEntry()
+	dbPathEntry.SetPlaceHolder(defaultDBPath)
+	dbPathEntry.OnChanged = func(s string) {
+		e.tmpConfig.DBPath = strings.TrimSpace(s)
+	}
+
+	// build form items
+	profileFormItem := widget.NewFormItem("Library profile", profileSelect)
+	newProfileFormItem := widget.NewFormItem("", container.NewBorder(nil, nil, nil, newProfileButton, newProfileEntry))
+	dbPathFormItem := widget.NewFormItem("Database", dbPathEntry)
+
+	// set form item tooltips
+	profileFormItem.HintText = "Each profile is a separate library with its own settings and database. Applied on restart."
+	newProfileFormItem.HintText = "Create a profile with the default settings."
+	dbPathFormItem.HintText = "Path to this profile's database, leave empty to use the default. Applied on restart."
+
+	// set form item values
+	profileSelect.SetSelected(e.tmpConfig.Profile)
+	dbPathEntry.SetText(e.tmpConfig.DBPath)
+
+	return container.NewBorder(
 		widget.NewLabel("General Settings"),
+		nil, nil, nil,
+		widget.NewForm(
+			profileFormItem,
+			newProfileFormItem,
+			dbPathFormItem,
+		),
 	)
 }
 
@@ -128,8 +204,71 @@ func (e *guiEnv) stemsTab() *fyne.Container {
 }
 
 func (e *guiEnv) soundCloudTab() *fyne.Container {
-	return container.NewVBox(
-		widget.NewLabel("SoundCloud settings"),
+
+	stemOptions := []string{"Don't separate", stems.Traktor.String(), stems.FourTrack.String()}
+	refreshOptions := []string{"Never", "Every hour", "Every 6 hours", "Every day"}
+	refreshMinutes := []int{0, 60, 360, 1440}
+
+	// build input widgets
+	convertCheckbox := widget.NewCheck("", func(convert bool) {
+		e.tmpConfig.PostDownloadConvertMp3 = convert
+	})
+	tagsCheckbox := widget.NewCheck("", func(writeTags bool) {
+		e.tmpConfig.PostDownloadWriteTags = writeTags
+	})
+	stemSelect := widget.NewSelect(stemOptions, func(s string) {
+		switch s {
+		case stems.Traktor.String():
+			e.tmpConfig.PostDownloadStemType = int(stems.Traktor)
+		case stems.FourTrack.String():
+			e.tmpConfig.PostDownloadStemType = int(stems.FourTrack)
+		default:
+			e.tmpConfig.PostDownloadStemType = int(stems.NotSelected)
+		}
+	})
+	refreshSelect := widget.NewSelect(refreshOptions, func(s string) {
+		for i, o := range refreshOptions {
+			if o == s {
+				e.tmpConfig.PlaylistRefreshMinutes = refreshMinutes[i]
+			}
+		}
+	})
+
+	// build form items
+	convertFormItem := widget.NewFormItem("Convert downloads to mp3", convertCheckbox)
+	tagsFormItem := widget.NewFormItem("Write SoundCloud tags", tagsCheckbox)
+	stemFormItem := widget.NewFormItem("Separate downloads into stems", stemSelect)
+	refreshFormItem := widget.NewFormItem("Refresh all playlists", refreshSelect)
+
+	// set form item tooltips
+	convertFormItem.HintText = "Convert downloaded files with an extension in the mp3 conversion list to mp3, the original file is kept."
+	tagsFormItem.HintText = "Write the title, artist, genre, tags, artwork and permalink from SoundCloud into downloaded files."
+	stemFormItem.HintText = "Separate downloaded files into stems once all downloads have finished."
+	refreshFormItem.HintText = "Refresh every SoundCloud playlist in the background, changes are listed under What's New. Applied on restart."
+
+	// set form item values
+	convertCheckbox.SetChecked(e.tmpConfig.PostDownloadConvertMp3)
+	tagsCheckbox.SetChecked(e.tmpConfig.PostDownloadWriteTags)
+	if t := stems.StemSeparationType(e.tmpConfig.PostDownloadStemType); t.Check() {
+		stemSelect.SetSelected(t.String())
+	} else {
+		stemSelect.SetSelected(stemOptions[0])
+	}
+	for i, m := range refreshMinutes {
+		if m == e.tmpConfig.PlaylistRefreshMinutes {
+			refreshSelect.SetSelected(refreshOptions[i])
+		}
+	}
+
+	return container.NewBorder(
+		widget.NewLabel("Processing run on tracks downloaded from SoundCloud"),
+		nil, nil, nil,
+		widget.NewForm(
+			convertFormItem,
+			tagsFormItem,
+			stemFormItem,
+			refreshFormItem,
+		),
 	)
 }
 
@@ -151,6 +290,15 @@ and then saves the Config struct to the config file
 */
 func (e *guiEnv) saveButton(w fyne.Window) *widget.Button {
 	btn := widget.NewButton("Save", func() {
+		// the profile in use doesn't change until restart, the choice is saved for the next launch
+		if e.tmpConfig.Profile != e.Config.Profile {
+			err := helpers.SetActiveProfile(e.tmpConfig.Profile)
+			if err != nil {
+				e.showErrorDialog(err, true)
+				return
+			}
+			e.tmpConfig.Profile = e.Config.Profile
+		}
 		e.Config = e.tmpConfig
 		err := e.Config.SaveConfig()
 		if err != nil {
@@ -65,8 +65,9 @@ func (e *guiEnv) getDownloadSoundCloudTrackFunc(selectedTrack *iwidget.SelectedT
 				), true)
 			},
 		)
+		opEnv.AttachDefaultPostDownloadHooks()
 
-		opEnv.DownloadSoundCloudFile(*track, playlistName)
+		opEnv.DownloadSoundCloudFile(context.Background(), *track, playlistName)
 	}
 }
 
@@ -84,7 +85,7 @@ func (e *guiEnv) getSaveSoundCloudTrackFunc(selectedTrack *iwidget.SelectedTrack
 			"track_local_path", track.LocalPath,
 		)
 
-		err := e.SerenDB.TxUpsertSoundCloudTracks([]data.SoundcloudTrack{track.ToDB()})
+		err := e.SerenDB.TxSaveSoundCloudTracks(ctx, []data.SoundcloudTrack{track.ToDB()})
 		if err != nil {
 			e.showErrorDialog(
 				fault.Wrap(
@@ -113,105 +114,159 @@ func (e *guiEnv) getRefreshSoundCloudPlaylistFunc(playlist streaming.SoundCloudP
 		"playlist_external_id", fmt.Sprintf("%d", playlist.ExternalID),
 	)
 
-	processResultsFunc := func(p streaming.SoundCloudPlaylist, err error) {
-
-		if err != nil {
-			e.showErrorDialog(fault.Wrap(
-				err,
-				fctx.With(ctx),
-				fmsg.WithDesc(
-					"err refreshing SoundCloud playlist",
-					"Error refreshing SoundCloud playlist",
-				),
-			), true)
-			return
-		}
-
-		currentTracksMap := make(map[int64]streaming.SoundCloudTrack)
-		existingTracksMap := make(map[int64]streaming.SoundCloudTrack)
-
-		for _, t := range p.Tracks {
-			currentTracksMap[t.ExternalID] = t
-		}
+	return func() {
 
-		var tracksToSave []streaming.SoundCloudTrack
-
-		/*
-			store all tracks prior to updating inside the existingTracksMap,
-				we can use this to figure out which tracks are new
-
-			we also set the RemovedFromPlaylist flag to true if a track is no longer in the playlist,
-				and then add to the tracksToSave slice to be updated in the database
-		*/
-		for _, t := range trackListBinding.Tracks {
-			existingTracksMap[t.ExternalID] = *t
-			v, ok := currentTracksMap[t.ExternalID]
-			if !ok {
-				t.RemovedFromPlaylist = true
-				tracksToSave = append(tracksToSave, *t)
-			}
-			*t = v
-		}
+		opEnv := e.opEnv()
+		opEnv.BuildOperationHandler(
+			func(i float64) {},
+			func(d map[string]any) {
+				p, ok := d["playlist"].(streaming.SoundCloudPlaylist)
+				if !ok {
+					e.showErrorDialog(fault.Wrap(
+						fault.New("error casting playlist to streaming.SoundCloudPlaylist"),
+						fctx.With(ctx),
+						fmsg.WithDesc(
+							"error parsing playlist from operation data",
+							"Error refreshing SoundCloud playlist",
+						),
+					), true)
+					return
+				}
 
-		/*
-			iterate through the tracks in the playlist,
-				if the track does not exist in the existingTracksMap,
-				it is new, so we add it to the trackListBinding list (to be displayed in the UI)
-				we do not save it to the database, as this was already done inside operations.go
-					(though i may want to change this, but it will require changing the refresh playlist func too)
-		*/
-		for _, t := range p.Tracks {
-			_, ok := existingTracksMap[t.ExternalID]
-			if !ok {
-				// track does not exist in current list, so we add it & save it
-				t.Playlists = append(t.Playlists, playlist)
-				newT := t
-				trackListBinding.Tracks = append(trackListBinding.Tracks, &newT)
-				tracksToSave = append(tracksToSave, newT)
-			}
-		}
+				changes, _ := d["changes"].([]operations.SoundCloudPlaylistChange)
 
-		if len(tracksToSave) > 0 {
+				var added, removed int
+				for _, c := range changes {
+					switch c.Type {
+					case operations.SoundCloudChangeAdded:
+						added++
+					case operations.SoundCloudChangeRemoved:
+						removed++
+					}
+				}
 
-			dataT := make([]data.SoundcloudTrack, len(tracksToSave))
-			for i, t := range tracksToSave {
-				dataT[i] = t.ToDB()
-			}
+				tracks := make([]*streaming.SoundCloudTrack, len(p.Tracks))
+				for i := range p.Tracks {
+					t := p.Tracks[i]
+					if len(t.Playlists) == 0 {
+						t.Playlists = []streaming.SoundCloudPlaylist{playlist}
+					}
+					tracks[i] = &t
+				}
 
-			err = e.SerenDB.TxUpsertSoundCloudTracks(dataT)
+				trackListBinding.Set(tracks)
+				trackListBinding.ApplyFilterSort()
 
-			if err != nil {
+				e.showInfoDialog(
+					"Refresh Successful",
+					fmt.Sprintf("Refreshed %s, %d new tracks, %d removed tracks, %d changes", playlist.Name, added, removed, len(changes)),
+				)
+			},
+			func(err error) {
 				e.showErrorDialog(fault.Wrap(
 					err,
 					fctx.With(ctx),
 					fmsg.WithDesc(
-						"error saving tracks to database",
-						"Error saving tracks to database",
+						"err refreshing SoundCloud playlist",
+						"Error refreshing SoundCloud playlist",
 					),
 				), true)
-				return
-			}
-		}
-
-		e.showInfoDialog("Refresh Successful", fmt.Sprintf("Refreshed %s, %d new tracks", playlist.Name, len(tracksToSave)))
+			},
+		)
 
-		trackListBinding.ApplyFilterSort()
+		opEnv.RefreshSoundCloudPlaylist(ctx, operations.RefreshSoundCloudPlaylistOpts{Playlist: playlist})
 	}
+}
 
-	return func() {
+/*
+getDownloadSoundCloudPlaylistFunc returns a function that can be used to download every free download
+track in a SoundCloud playlist, updating the local path of each downloaded track in the track list
+*/
+func (e *guiEnv) getDownloadSoundCloudPlaylistFunc(playlist streaming.SoundCloudPlaylist, trackListBinding *iwidget.TrackListBinding) func(func(float64), func()) {
+	return func(onProgress func(float64), onDone func()) {
 
 		opEnv := e.opEnv()
 		opEnv.BuildOperationHandler(
-			func(i float64) {},
-			func(_ map[string]any) {},
-			func(err error) {},
+			onProgress,
+			func(d map[string]any) {
+				defer onDone()
+
+				tracks, ok := d["tracks"].([]streaming.SoundCloudTrack)
+				if !ok {
+					e.showErrorDialog(fault.Wrap(
+						fault.New("error casting tracks to []streaming.SoundCloudTrack"),
+						fmsg.WithDesc(
+							"error parsing tracks from operation data",
+							"Error parsing playlist download results",
+						),
+					), true)
+					return
+				}
+
+				downloaded := make(map[int64]string, len(tracks))
+				for _, t := range tracks {
+					downloaded[t.ExternalID] = t.LocalPath
+				}
+
+				for _, t := range trackListBinding.Tracks {
+					if localPath, ok := downloaded[t.ExternalID]; ok {
+						t.LocalPath = localPath
+						t.LocalPathBroken = false
+					}
+				}
+
+				trackListBinding.ApplyFilterSort()
+
+				e.showInfoDialog(
+					"Download Finished",
+					fmt.Sprintf("Downloaded %v tracks from %s, %v failed", len(tracks), playlist.Name, d["failed"]),
+				)
+			},
+			func(err error) {
+				defer onDone()
+				e.showErrorDialog(err, true)
+			},
 		)
+		opEnv.AttachDefaultPostDownloadHooks()
 
-		opts := operations.GetSoundCloudPlaylistOpts{
-			PlaylistURL: playlist.PermalinkUrl,
-			Refresh:     true,
-		}
+		opEnv.DownloadSoundCloudPlaylist(context.Background(), operations.DownloadSoundCloudPlaylistOpts{
+			PlaylistExternalID: playlist.ExternalID,
+			PlaylistSourceType: playlist.SourceType,
+			PlaylistName:       playlist.Name,
+		})
+	}
+}
+
+/*
+getEmbedSoundCloudArtworkFunc returns a function that can be used to embed the SoundCloud artwork of every
+downloaded track in a SoundCloud playlist into its file
+*/
+func (e *guiEnv) getEmbedSoundCloudArtworkFunc(playlist streaming.SoundCloudPlaylist) func(func(float64), func()) {
+	return func(onProgress func(float64), onDone func()) {
+
+		opEnv := e.opEnv()
+		opEnv.BuildOperationHandler(
+			onProgress,
+			func(d map[string]any) {
+				defer onDone()
+
+				e.showInfoDialog(
+					"Artwork Embedded",
+					fmt.Sprintf(
+						"Embedded artwork into %v tracks from %s, %v skipped, %v failed",
+						d["embedded"], playlist.Name, d["skipped"], d["failed"],
+					),
+				)
+			},
+			func(err error) {
+				defer onDone()
+				e.showErrorDialog(err, true)
+			},
+		)
 
-		opEnv.GetSoundCloudPlaylist(ctx, opts, processResultsFunc)
+		opEnv.EmbedSoundCloudArtwork(context.Background(), operations.EmbedSoundCloudArtworkOpts{
+			PlaylistExternalID: playlist.ExternalID,
+			PlaylistSourceType: playlist.SourceType,
+		})
 	}
 }
@@ -77,24 +77,36 @@ func (e *guiEnv) getViewList() map[string]guiView {
 			name:   "Conversion",
 			render: e.conversionView,
 		},
+		"library": {
+			name:   "Library",
+			render: e.libraryView,
+		},
+		"duplicates": {
+			name:   "Duplicates",
+			render: e.duplicatesView,
+		},
+		"relocate": {
+			name:   "Relocate Files",
+			render: e.relocateView,
+		},
 		"sync": {
 			name:   "Playlist Matching",
 			render: e.syncView,
 		},
-		"syncSoundCloud": {
-			name:   "SoundCloud",
-			render: e.syncSoundCloudView,
+		"playlists": {
+			name:   "Playlists",
+			render: e.playlistsView,
 		},
-		"syncSpotify": {
-			name:   "Spotify",
-			render: e.syncSpotifyView,
+		"whatsNew": {
+			name:   "What's New",
+			render: e.whatsNewView,
 		},
 	}
 }
 
 func (e *guiEnv) getViewIndex() map[string][]string {
 	return map[string][]string{
-		"": {"home", "stems", "mp3s", "tags", "conversion", "sync"},
+		"": {"home", "stems", "mp3s", "tags", "conversion", "library", "sync"},
 		"stems": {
 			"separateTrack",
 			"separateFolder",
@@ -109,9 +121,13 @@ func (e *guiEnv) getViewIndex() map[string][]string {
 			"rereadTags",
 			"cleanTags",
 		},
+		"library": {
+			"duplicates",
+			"relocate",
+		},
 		"sync": {
-			"syncSoundCloud",
-			"syncSpotify",
+			"playlists",
+			"whatsNew",
 		},
 	}
 }
@@ -408,11 +424,17 @@ func (e *guiEnv) conversionView() fyne.CanvasObject {
 	return widget.NewLabel("conversionView")
 }
 
-func (e *guiEnv) syncView() fyne.CanvasObject {
-	return widget.NewLabel("syncView")
+func (e *guiEnv) libraryView() fyne.CanvasObject {
+	content := widget.NewLabel("Contains a selection of utilities for managing the files in your library.")
+
+	return container.NewVBox(content)
 }
 
-func (e *guiEnv) syncSoundCloudView() fyne.CanvasObject {
+/*
+playlistsView lists the playlists imported from every streaming platform, any platform's playlist can be
+added by its URL
+*/
+func (e *guiEnv) playlistsView() fyne.CanvasObject {
 
 	playlistBindVals := iwidget.PlaylistBindingList{
 		Base:  e.getWidgetBase(),
@@ -425,11 +447,11 @@ func (e *guiEnv) syncSoundCloudView() fyne.CanvasObject {
 		&playlistBindVals,
 		func() fyne.CanvasObject {
 			return iwidget.NewPlaylist(
-				func(playlistData streaming.SoundCloudPlaylist) {
+				func(playlistData streaming.SourcePlaylist) {
 					if e.isBusy() {
 						return
 					}
-					e.openPlaylistPopup(playlistData)
+					e.openSourcePlaylist(playlistData)
 				},
 			)
 		},
@@ -445,9 +467,9 @@ func (e *guiEnv) syncSoundCloudView() fyne.CanvasObject {
 	// Load the playlists into the view in the background
 	// This should be quick as it only requires a database query
 	// Also show a loading screen, which will be hidden when the playlists are loaded
-	loading := iwidget.NewViewLoading("Loading SoundCloud playlists...")
+	loading := iwidget.NewViewLoading("Loading playlists...")
 	go func() {
-		err := e.loadSoundCloudPlaylists(&playlistBindVals)
+		err := e.loadPlaylists(&playlistBindVals)
 
 		if err != nil {
 			e.showErrorDialog(err, true)
@@ -467,6 +489,10 @@ func (e *guiEnv) syncSoundCloudView() fyne.CanvasObject {
 				playlistsList.ScrollToBottom()
 			},
 		),
+		func(s string) error {
+			_, err := operations.ImportPlaylistOpts{PlaylistURL: s}.Check()
+			return err
+		},
 	)
 
 	return container.NewStack(
@@ -476,7 +502,3 @@ func (e *guiEnv) syncSoundCloudView() fyne.CanvasObject {
 		loading,
 	)
 }
-
-func (e *guiEnv) syncSpotifyView() fyne.CanvasObject {
-	return widget.NewLabel("syncView")
-}
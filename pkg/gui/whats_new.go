@@ -0,0 +1,204 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/gui/iwidget"
+	"github.com/billiem/seren-management/pkg/operations"
+)
+
+/*
+Provides the "What's new" view, used to list the changes found when refreshing SoundCloud playlists,
+along with the scheduled refresh of every playlist which runs in the background
+*/
+
+func (e *guiEnv) whatsNewView() fyne.CanvasObject {
+
+	var changes []operations.SoundCloudPlaylistChange
+
+	summary := widget.NewLabel("")
+
+	changeList := widget.NewList(
+		func() int {
+			return len(changes)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(
+				nil, nil, nil,
+				iwidget.NewOpenInBrowserButton(e.getWidgetBase(), "", ""),
+				widget.NewLabel(""),
+			)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			c := changes[i]
+			row := o.(*fyne.Container)
+
+			row.Objects[0].(*widget.Label).SetText(fmt.Sprintf(
+				"%s  %s  %s: %s",
+				c.CreatedAt.Local().Format(time.DateTime),
+				c.PlaylistName,
+				c.TrackName,
+				describePlaylistChange(c),
+			))
+
+			openButton := row.Objects[1].(*iwidget.OpenInBrowserButton)
+			switch {
+			case c.Type == operations.SoundCloudChangePurchaseURL && c.NewValue != "":
+				openButton.SetContent("Buy", c.NewValue)
+				openButton.Show()
+			case c.TrackPermalinkUrl != "":
+				openButton.SetContent("Open", c.TrackPermalinkUrl)
+				openButton.Show()
+			default:
+				openButton.Hide()
+			}
+		},
+	)
+
+	setChanges := func(c []operations.SoundCloudPlaylistChange) {
+		changes = c
+		summary.SetText(fmt.Sprintf("%v changes found in your SoundCloud playlists", len(changes)))
+		changeList.Refresh()
+	}
+
+	loading := iwidget.NewViewLoading("Loading changes...")
+	go func() {
+		e.listSoundCloudPlaylistChanges(func(c []operations.SoundCloudPlaylistChange) {
+			setChanges(c)
+			loading.Hide()
+		})
+	}()
+
+	refreshButton := widget.NewButton("Refresh all playlists", func() {
+		if e.isBusy() {
+			return
+		}
+		e.refreshAllSoundCloudPlaylists(func() {
+			e.listSoundCloudPlaylistChanges(setChanges)
+		})
+	})
+
+	return container.NewStack(
+		container.NewBorder(
+			container.NewVBox(
+				container.NewBorder(nil, nil, nil, refreshButton, summary),
+				widget.NewSeparator(),
+			), nil, nil, nil,
+			changeList,
+		),
+		loading,
+	)
+}
+
+func describePlaylistChange(c operations.SoundCloudPlaylistChange) string {
+	switch c.Type {
+	case operations.SoundCloudChangeAdded:
+		return "added"
+	case operations.SoundCloudChangeRemoved:
+		return "removed"
+	case operations.SoundCloudChangeDownloadEnabled:
+		return "free download available"
+	case operations.SoundCloudChangePurchaseURL:
+		if c.NewValue == "" {
+			return "purchase link removed"
+		}
+		return "purchase link changed"
+	}
+	return c.Type
+}
+
+/*
+listSoundCloudPlaylistChanges runs the ListSoundCloudPlaylistChanges operation, and passes the changes to onListed
+*/
+func (e *guiEnv) listSoundCloudPlaylistChanges(onListed func([]operations.SoundCloudPlaylistChange)) {
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(
+		func(i float64) {},
+		func(d map[string]any) {
+			changes, ok := d["changes"].([]operations.SoundCloudPlaylistChange)
+			if !ok {
+				e.showErrorDialog(fault.Wrap(
+					fault.New("error casting changes to []operations.SoundCloudPlaylistChange"),
+					fmsg.WithDesc(
+						"error parsing changes from operation data",
+						"Error parsing playlist changes",
+					),
+				), true)
+				return
+			}
+
+			onListed(changes)
+		},
+		func(err error) {
+			e.showErrorDialog(err, true)
+		},
+	)
+
+	opEnv.ListSoundCloudPlaylistChanges(context.Background(), operations.ListSoundCloudPlaylistChangesOpts{})
+}
+
+/*
+refreshAllSoundCloudPlaylists runs the RefreshAllSoundCloudPlaylists operation once, calling onDone when
+it has finished successfully
+*/
+func (e *guiEnv) refreshAllSoundCloudPlaylists(onDone func()) {
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(
+		func(i float64) {},
+		func(d map[string]any) {
+			e.showInfoDialog(
+				"Refresh Finished",
+				fmt.Sprintf("Refreshed %v playlists, %v failed", d["refreshed"], d["failed"]),
+			)
+			onDone()
+		},
+		func(err error) {
+			e.showErrorDialog(err, true)
+		},
+	)
+
+	go opEnv.RefreshAllSoundCloudPlaylists(context.Background(), operations.RefreshAllSoundCloudPlaylistsOpts{})
+}
+
+/*
+scheduleRefreshAllSoundCloudPlaylists refreshes every SoundCloud playlist in the background every
+PlaylistRefreshMinutes, sending a notification when changes are found. It returns straight away if
+scheduled refreshes are disabled, otherwise it runs until the application exits
+*/
+func (e *guiEnv) scheduleRefreshAllSoundCloudPlaylists() {
+
+	if e.Config.PlaylistRefreshMinutes <= 0 {
+		return
+	}
+
+	opEnv := e.opEnv()
+	opEnv.BuildOperationHandler(
+		func(i float64) {},
+		func(d map[string]any) {
+			changes, _ := d["changes"].([]operations.SoundCloudPlaylistChange)
+			if len(changes) == 0 {
+				return
+			}
+			e.app.SendNotification(fyne.NewNotification(
+				"What's New",
+				fmt.Sprintf("%v changes found in your SoundCloud playlists", len(changes)),
+			))
+		},
+		func(err error) {
+			e.logger.NonFatalError(err)
+		},
+	)
+
+	opEnv.RefreshAllSoundCloudPlaylists(context.Background(), operations.RefreshAllSoundCloudPlaylistsOpts{
+		Interval: time.Duration(e.Config.PlaylistRefreshMinutes) * time.Minute,
+	})
+}
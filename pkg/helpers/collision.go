@@ -0,0 +1,21 @@
+package helpers
+
+/*
+CollisionStrategy determines what happens when a file is moved or written to a path
+which is already taken by another file
+*/
+type CollisionStrategy string
+
+const (
+	CollisionSuffix     CollisionStrategy = "suffix" // append an incrementing suffix to the file name, i.e. 'song (1).mp3'
+	CollisionSkip       CollisionStrategy = "skip"   // leave the file where it is
+	CollisionHashDedupe CollisionStrategy = "hash"   // remove the file if its contents match the existing file, otherwise suffix
+)
+
+func (c CollisionStrategy) Check() bool {
+	switch c {
+	case CollisionSuffix, CollisionSkip, CollisionHashDedupe:
+		return true
+	}
+	return false
+}
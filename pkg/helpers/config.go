@@ -2,12 +2,15 @@ package helpers
 
 import (
 	"encoding/json"
-	"errors"
 	"os"
+	"path/filepath"
 
 	"github.com/billiem/seren-management/pkg/projectpath"
 )
 
+// DefaultOrganiseTemplate is the template used to build library paths when organising files
+const DefaultOrganiseTemplate = "{genre}/{year}/{artist} - {title}"
+
 /*
 Config is the main config struct for the application
 
@@ -24,21 +27,35 @@ type Config struct {
 	DemucsBatchSize             int      `json:"demucsBatchSize"`
 	MergeWorkers                int      `json:"mergeWorkers"`
 	CleanUpWorkers              int      `json:"cleanUpWorkers"`
+	DownloadWorkers             int      `json:"downloadWorkers"`
+	OrganiseTemplate            string   `json:"organiseTemplate"`
+	PostDownloadConvertMp3      bool     `json:"postDownloadConvertMp3"`
+	PostDownloadWriteTags       bool     `json:"postDownloadWriteTags"`
+	PostDownloadStemType        int      `json:"postDownloadStemType"`   // 0 to disable, otherwise a stems.StemSeparationType
+	PlaylistRefreshMinutes      int      `json:"playlistRefreshMinutes"` // 0 to disable scheduled refreshes of every playlist
+	DBPath                      string   `json:"dbPath"`                 // empty to use seren.db in the profile's directory
 
 	// these are not stored in config.json
+	Profile               string `json:"-"`
 	SoundCloudClientID    string `json:"-"`
 	SoundCloudSecretToken string `json:"-"`
+	SpotifyClientID       string `json:"-"`
+	SpotifyClientSecret   string `json:"-"`
+
+	configPath string // the file the config was loaded from, and is saved to
 }
 
-// buildDefaultConfig builds default config values and saves them to config.json
-// this is called when the application is first run or when the config file is deleted
-func buildDefaultConfig() (*Config, error) {
+// buildDefaultConfig builds default config values and saves them to configPath
+// this is called when the application is first run, when the config file is deleted or a profile is created
+func buildDefaultConfig(configPath string) (*Config, error) {
 	cfg := &Config{
 		TraktorCollectionPath:       "",
 		BaseDir:                     "",
 		DownloadDir:                 "",
 		ExtensionsToConvertToMp3:    []string{"wav", "aiff", "flac", "ogg", "m4a"},
 		ExtensionsToSeparateToStems: []string{"mp3", "wav"},
+		OrganiseTemplate:            DefaultOrganiseTemplate,
+		configPath:                  configPath,
 	}
 
 	cfg.loadEnvConfig()
@@ -51,18 +68,37 @@ func buildDefaultConfig() (*Config, error) {
 	return cfg, nil
 }
 
+func defaultConfigPath() string {
+	return JoinFilepathToSlash(projectpath.Root, "config.json")
+}
+
 /*
-LoadCLIConfig loads the config from the given path (if given) or from the default path
+LoadCLIConfig loads the config from the given path (if given) or from the profile's config, the
+active profile is used if no profile is given
 
 No reference to the config is required for the CLI
 */
-func LoadCLIConfig(configPath string) (Config, error) {
+func LoadCLIConfig(configPath string, profile string) (Config, error) {
+
+	if profile == "" {
+		activeProfile, err := GetActiveProfile()
+		if err != nil {
+			return Config{}, err
+		}
+		profile = activeProfile
+	}
 
-	// load default config if no config path is given
+	if !CheckProfileName(profile) {
+		return Config{}, ErrInvalidProfileName
+	}
+
+	// load the profile's config if no config path is given
 	if configPath == "" {
-		configPath := JoinFilepathToSlash(projectpath.Root, "config.json")
-		cfg, err := loadConfig(configPath)
-		return *cfg, err
+		cfg, err := LoadProfileConfig(profile)
+		if err != nil {
+			return Config{}, err
+		}
+		return *cfg, nil
 	}
 
 	configPath, err := GetAbsOrWdPath(configPath)
@@ -72,28 +108,60 @@ func LoadCLIConfig(configPath string) (Config, error) {
 	}
 
 	cfg, err := loadConfig(configPath)
-	return *cfg, err
+
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg.Profile = profile
+
+	return *cfg, nil
 }
 
+/*
+LoadGUIConfig loads the config of the active profile
+*/
 func LoadGUIConfig() (*Config, error) {
 
-	configPath := JoinFilepathToSlash(projectpath.Root, "config.json")
+	profile, err := GetActiveProfile()
 
-	_, err := os.Stat(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadProfileConfig(profile)
+}
+
+/*
+LoadProfileConfig loads the config of the profile, the default profile's config is built from
+defaults if it doesn't exist
+*/
+func LoadProfileConfig(profile string) (*Config, error) {
+
+	configPath, err := profileConfigPath(profile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *Config
+
+	if DoesFileExist(configPath) {
+		cfg, err = loadConfig(configPath)
+	} else if profile == DefaultProfile {
+		// build config from defaults if it doesn't exist
+		cfg, err = buildDefaultConfig(configPath)
+	} else {
+		return nil, ErrProfileDoesNotExist
+	}
 
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			// build config from defaults if it doesn't exist
-			cfg, err := buildDefaultConfig()
-			if err != nil {
-				return nil, err
-			}
-			return cfg, nil
-		}
 		return nil, err
 	}
 
-	return loadConfig(configPath)
+	cfg.Profile = profile
+
+	return cfg, nil
 }
 
 func loadConfig(configPath string) (*Config, error) {
@@ -116,6 +184,7 @@ func loadConfig(configPath string) (*Config, error) {
 	}
 
 	config.loadEnvConfig()
+	config.configPath = configPath
 
 	return &config, nil
 }
@@ -126,7 +195,12 @@ func (c *Config) SaveConfig() error {
 		return err
 	}
 
-	err = os.WriteFile(JoinFilepathToSlash(projectpath.Root, "config.json"), data, 0644)
+	configPath := c.configPath
+	if configPath == "" {
+		configPath = defaultConfigPath()
+	}
+
+	err = os.WriteFile(configPath, data, 0644)
 	if err != nil {
 		return err
 	}
@@ -134,6 +208,30 @@ func (c *Config) SaveConfig() error {
 	return nil
 }
 
+/*
+GetDBPath returns the path of the database, DBPath if it is set otherwise seren.db in the profile's
+directory
+*/
+func (c *Config) GetDBPath() (string, error) {
+
+	if c.DBPath != "" {
+		return c.DBPath, nil
+	}
+
+	profile := c.Profile
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	dir, err := ProfileDir(profile)
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, dbFileName), nil
+}
+
 /*
 loadEnvConfig loads config values stored in environment variables
 
@@ -146,6 +244,8 @@ func (c *Config) loadEnvConfig() {
 	c.Development = isDev
 	c.SoundCloudClientID = os.Getenv("SOUNDCLOUD_CLIENT_ID")
 	c.SoundCloudSecretToken = os.Getenv("SOUNDCLOUD_SECRET_TOKEN")
+	c.SpotifyClientID = os.Getenv("SPOTIFY_CLIENT_ID")
+	c.SpotifyClientSecret = os.Getenv("SPOTIFY_CLIENT_SECRET")
 }
 
 /*
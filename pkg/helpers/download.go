@@ -0,0 +1,317 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*
+Provides functionality for downloading files over HTTP to a temporary '.part' file, which is
+resumed if it already exists, verified, and then renamed to its final path
+*/
+
+// PartFileExtension is appended to the name of a file whilst it is being downloaded
+const PartFileExtension = ".part"
+
+// PartValidatorExtension is appended to the path of a '.part' file to store the ETag or Last-Modified
+// of the download it holds, used to check the file hasn't changed before resuming it
+const PartValidatorExtension = ".validator"
+
+/*
+DownloadFile downloads the file at url into dirPath, using the file name from the
+Content-Disposition header of the response
+
+The file is written to '<id>.part' first, id identifying the file being downloaded so downloads
+of different files with the same name don't share a '.part' file. If a '.part' file from a previous
+attempt exists, the download is resumed from the end of it using a Range request, with an If-Range
+header holding the ETag or Last-Modified of the response it was started from, so the server sends
+the whole file again if it has changed. Once finished, the size of the file is checked against the
+Content-Length of the response and the file is renamed to its final path, an incomplete download is
+left in place to be resumed later
+
+If a file already exists at the final path, the collision strategy decides what happens, the
+skip strategy returns the existing path without downloading anything
+
+Returns the path to the downloaded file
+*/
+func DownloadFile(client *http.Client, url string, dirPath string, id string, collision CollisionStrategy) (string, error) {
+
+	if !collision.Check() {
+		return "", ErrInvalidCollisionStrategy
+	}
+
+	// signed download urls are often only valid for GET requests, so rather than a HEAD request
+	// the headers of the full download are used to work out where the file should go
+	resp, err := client.Get(url)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s", ErrUnexpectedStatusCode, resp.Status)
+	}
+
+	filename, err := GetFileNameFromContentDisposition(resp.Header.Get("Content-Disposition"))
+
+	if err != nil {
+		return "", err
+	}
+
+	err = CreateDirIfNotExists(dirPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	filePath := JoinFilepathToSlash(dirPath, filename)
+	existingPath := ""
+
+	if DoesFileExist(filePath) {
+		switch collision {
+		case CollisionSkip:
+			return filePath, nil
+		case CollisionHashDedupe:
+			existingPath = filePath
+		}
+		filePath = GetNextAvailableFilePath(filePath)
+	}
+
+	partPath := JoinFilepathToSlash(dirPath, id+PartFileExtension)
+
+	err = downloadToPartFile(client, url, partPath, resp)
+
+	if err != nil {
+		return "", err
+	}
+
+	err = removeIfExists(partPath + PartValidatorExtension)
+
+	if err != nil {
+		return "", err
+	}
+
+	if existingPath != "" {
+		same, err := isSameFileContents(partPath, existingPath)
+
+		if err != nil {
+			return "", err
+		}
+
+		if same {
+			return existingPath, os.Remove(partPath)
+		}
+	}
+
+	err = os.Rename(partPath, filePath)
+
+	if err != nil {
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+/*
+downloadToPartFile writes the body of resp into partPath, if partPath already contains part of
+the same version of the file, resp is discarded and the rest of the file is requested using a
+Range request instead
+
+The size of partPath is then checked against the expected size of the download
+*/
+func downloadToPartFile(client *http.Client, url string, partPath string, resp *http.Response) error {
+
+	expectedSize := resp.ContentLength
+	validatorPath := partPath + PartValidatorExtension
+
+	var offset int64
+
+	if fi, err := os.Stat(partPath); err == nil {
+		offset = fi.Size()
+	}
+
+	// a part file can only be resumed if it was started from the same version of the file
+	stored, _ := os.ReadFile(validatorPath)
+	validator := string(stored)
+
+	if validator == "" || validator != responseValidator(resp) {
+		offset = 0
+	}
+
+	if expectedSize > 0 {
+		switch {
+		case offset > expectedSize:
+			offset = 0
+		case offset == expectedSize:
+			// a part file the size of the download isn't trusted to be complete, its last byte is
+			// requested again so the server checks it against the validator
+			offset--
+
+			err := os.Truncate(partPath, offset)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	body := resp.Body
+
+	if offset > 0 {
+		resp.Body.Close()
+
+		req, err := http.NewRequest("GET", url, nil)
+
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+
+		rangeResp, err := client.Do(req)
+
+		if err != nil {
+			return err
+		}
+
+		defer rangeResp.Body.Close()
+
+		switch rangeResp.StatusCode {
+		case http.StatusPartialContent:
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+			if total := contentRangeTotal(rangeResp.Header.Get("Content-Range")); total > 0 {
+				expectedSize = total
+			}
+		case http.StatusOK:
+			// the file has changed or the server ignored the range, start again from scratch
+			offset = 0
+			expectedSize = rangeResp.ContentLength
+			resp = rangeResp
+		default:
+			return fmt.Errorf("%w: %s", ErrUnexpectedStatusCode, rangeResp.Status)
+		}
+
+		body = rangeResp.Body
+	}
+
+	if offset == 0 {
+		err := writePartValidator(validatorPath, responseValidator(resp))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(partPath, flags, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	written, err := io.Copy(f, body)
+
+	closeErr := f.Close()
+
+	// a connection closed early is reported as an incomplete download below
+	if err != nil && !(errors.Is(err, io.ErrUnexpectedEOF) && expectedSize > 0) {
+		return err
+	}
+
+	if closeErr != nil {
+		return closeErr
+	}
+
+	if expectedSize > 0 && offset+written != expectedSize {
+		return fmt.Errorf(
+			"%w: got %d of %d bytes",
+			ErrDownloadIncomplete,
+			offset+written,
+			expectedSize,
+		)
+	}
+
+	return nil
+}
+
+/*
+responseValidator returns the validator to send in an If-Range header to resume the download in
+resp, this is its ETag, or its Last-Modified if it has no ETag or only a weak one, which If-Range
+doesn't allow. If the response has neither, an empty string is returned and the download can't be
+resumed
+*/
+func responseValidator(resp *http.Response) string {
+	etag := resp.Header.Get("ETag")
+
+	if etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return resp.Header.Get("Last-Modified")
+}
+
+/*
+writePartValidator stores the validator of the download written to a '.part' file, an empty
+validator removes the stored one, as the download can't be resumed
+*/
+func writePartValidator(validatorPath string, validator string) error {
+	if validator == "" {
+		return removeIfExists(validatorPath)
+	}
+
+	return os.WriteFile(validatorPath, []byte(validator), 0644)
+}
+
+func removeIfExists(path string) error {
+	err := os.Remove(path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+/*
+contentRangeTotal returns the total size from a Content-Range header (i.e. 'bytes 100-199/200'),
+or -1 if it's unknown
+*/
+func contentRangeTotal(contentRange string) int64 {
+	i := strings.LastIndex(contentRange, "/")
+
+	if i == -1 {
+		return -1
+	}
+
+	total, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+
+	if err != nil {
+		return -1
+	}
+
+	return total
+}
+
+func isSameFileContents(a string, b string) (bool, error) {
+	hashA, err := HashFile(a)
+
+	if err != nil {
+		return false, err
+	}
+
+	hashB, err := HashFile(b)
+
+	if err != nil {
+		return false, err
+	}
+
+	return hashA == hashB, nil
+}
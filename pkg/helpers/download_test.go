@@ -0,0 +1,256 @@
+package helpers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+func TestDownloadFile(t *testing.T) {
+	content := []byte("some audio data which is downloaded from the server")
+
+	tests := []struct {
+		name      string
+		collision helpers.CollisionStrategy
+		existing  []byte // contents of an existing 'song.wav', if any
+		part      []byte // contents of an existing '42.part', if any
+		validator string // validator stored for the existing '42.part', if any
+		wantFile  string
+		wantFiles []string
+	}{
+		{
+			name:      "fresh download",
+			collision: helpers.CollisionSuffix,
+			wantFile:  "song.wav",
+			wantFiles: []string{"song.wav"},
+		},
+		{
+			name:      "resume part file",
+			collision: helpers.CollisionSuffix,
+			part:      content[:10],
+			validator: `"v1"`,
+			wantFile:  "song.wav",
+			wantFiles: []string{"song.wav"},
+		},
+		{
+			name:      "restart part file of a changed file",
+			collision: helpers.CollisionSuffix,
+			part:      []byte("an older version"),
+			validator: `"v0"`,
+			wantFile:  "song.wav",
+			wantFiles: []string{"song.wav"},
+		},
+		{
+			name:      "restart part file without a validator",
+			collision: helpers.CollisionSuffix,
+			part:      []byte("an older version"),
+			wantFile:  "song.wav",
+			wantFiles: []string{"song.wav"},
+		},
+		{
+			name:      "verify complete part file",
+			collision: helpers.CollisionSuffix,
+			part:      content,
+			validator: `"v1"`,
+			wantFile:  "song.wav",
+			wantFiles: []string{"song.wav"},
+		},
+		{
+			name:      "restart same size part file of a changed file",
+			collision: helpers.CollisionSuffix,
+			part:      bytes.Repeat([]byte("x"), len(content)),
+			validator: `"v0"`,
+			wantFile:  "song.wav",
+			wantFiles: []string{"song.wav"},
+		},
+		{
+			name:      "skip existing file",
+			collision: helpers.CollisionSkip,
+			existing:  []byte("different"),
+			wantFile:  "song.wav",
+			wantFiles: []string{"song.wav"},
+		},
+		{
+			name:      "suffix existing file",
+			collision: helpers.CollisionSuffix,
+			existing:  content,
+			wantFile:  "song (1).wav",
+			wantFiles: []string{"song (1).wav", "song.wav"},
+		},
+		{
+			name:      "hash dedupe identical file",
+			collision: helpers.CollisionHashDedupe,
+			existing:  content,
+			wantFile:  "song.wav",
+			wantFiles: []string{"song.wav"},
+		},
+		{
+			name:      "hash dedupe different file",
+			collision: helpers.CollisionHashDedupe,
+			existing:  []byte("different"),
+			wantFile:  "song (1).wav",
+			wantFiles: []string{"song (1).wav", "song.wav"},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="song.wav"`)
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "song.wav", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.ToSlash(t.TempDir())
+
+			if tt.existing != nil {
+				if err := os.WriteFile(dir+"/song.wav", tt.existing, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.part != nil {
+				if err := os.WriteFile(dir+"/42"+helpers.PartFileExtension, tt.part, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.validator != "" {
+				if err := os.WriteFile(dir+"/42"+helpers.PartFileExtension+helpers.PartValidatorExtension, []byte(tt.validator), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := helpers.DownloadFile(server.Client(), server.URL, dir, "42", tt.collision)
+
+			if err != nil {
+				t.Fatalf("DownloadFile() error = %v", err)
+			}
+
+			if got != dir+"/"+tt.wantFile {
+				t.Errorf("DownloadFile() = %v, want %v", got, dir+"/"+tt.wantFile)
+			}
+
+			want := content
+			if tt.collision == helpers.CollisionSkip {
+				want = tt.existing
+			}
+
+			b, err := os.ReadFile(got)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(b, want) {
+				t.Errorf("DownloadFile() contents = %q, want %q", b, want)
+			}
+
+			entries, err := os.ReadDir(dir)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var files []string
+			for _, e := range entries {
+				files = append(files, e.Name())
+			}
+
+			if len(files) != len(tt.wantFiles) {
+				t.Fatalf("files = %v, want %v", files, tt.wantFiles)
+			}
+			for i := range files {
+				if files[i] != tt.wantFiles[i] {
+					t.Errorf("files = %v, want %v", files, tt.wantFiles)
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadFileIncomplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="song.wav"`)
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("too short"))
+	}))
+	defer server.Close()
+
+	dir := filepath.ToSlash(t.TempDir())
+
+	_, err := helpers.DownloadFile(server.Client(), server.URL, dir, "42", helpers.CollisionSuffix)
+
+	if !errors.Is(err, helpers.ErrDownloadIncomplete) {
+		t.Fatalf("DownloadFile() error = %v, want %v", err, helpers.ErrDownloadIncomplete)
+	}
+
+	if helpers.DoesFileExist(dir + "/song.wav") {
+		t.Errorf("incomplete download was renamed to its final path")
+	}
+
+	if !helpers.DoesFileExist(dir + "/42" + helpers.PartFileExtension) {
+		t.Errorf("part file was removed, it should be kept to resume from")
+	}
+}
+
+func TestDownloadFileChangedWhilstResuming(t *testing.T) {
+	oldContent := []byte("the first version of the file")
+	newContent := []byte("the second version of the file, which is longer")
+
+	var ifRange string
+	requests := 0
+
+	// the file changes between the first request and the range request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Disposition", `attachment; filename="song.wav"`)
+
+		if requests == 1 {
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "song.wav", time.Time{}, bytes.NewReader(oldContent))
+			return
+		}
+
+		ifRange = r.Header.Get("If-Range")
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "song.wav", time.Time{}, bytes.NewReader(newContent))
+	}))
+	defer server.Close()
+
+	dir := filepath.ToSlash(t.TempDir())
+	partPath := dir + "/42" + helpers.PartFileExtension
+
+	if err := os.WriteFile(partPath, oldContent[:10], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(partPath+helpers.PartValidatorExtension, []byte(`"v1"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := helpers.DownloadFile(server.Client(), server.URL, dir, "42", helpers.CollisionSuffix)
+
+	if err != nil {
+		t.Fatalf("DownloadFile() error = %v", err)
+	}
+
+	if ifRange != `"v1"` {
+		t.Errorf("If-Range = %q, want %q", ifRange, `"v1"`)
+	}
+
+	b, err := os.ReadFile(got)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(b, newContent) {
+		t.Errorf("DownloadFile() contents = %q, want %q", b, newContent)
+	}
+}
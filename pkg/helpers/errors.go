@@ -23,47 +23,102 @@ func ErrorContains(out error, want error) bool {
 Contains a series of errors used throughout the application
 */
 var (
-	ErrBuildingConvertTrack      = errors.New("error building convert track")
-	ErrClosestDirUnknown         = errors.New("something went very wrong getting the closest dir") // Should never happen
-	ErrConvertedFileExists       = errors.New("converted file already exists")
-	ErrConvertingTrack           = errors.New("error converting track")
-	ErrConvertTrack              = errors.New("error converting track")
-	ErrConvertTrackEmpty         = errors.New("convert track is empty")
-	ErrFileAlreadyProcessed      = errors.New("file has already been processed")
-	ErrGettingClosestDir         = errors.New("error getting closest dir")
-	ErrGettingListableURI        = errors.New("error getting listable URI")
-	ErrIndexOutOfBounds          = errors.New("index out of bounds")
-	ErrInDirPathRequired         = errors.New("InDirPath required")
-	ErrInFilePathRequired        = errors.New("InFilePath required")
-	ErrMissingRequiredFields     = errors.New("missing required fields")
-	ErrNoDirPath                 = errors.New("no directory path found")
-	ErrNoFileExtension           = errors.New("no file extension found")
-	ErrNoFileName                = errors.New("no file name found")
-	ErrNoMatchesFound            = errors.New("no matches found")
-	ErrOperationFinished         = errors.New("operation finished")
-	ErrOperationNotFound         = errors.New("operation not found")
-	ErrBusyPleaseFinishFirst     = errors.New("please finish what you're doing first")
-	ErrUserStoppedProcess        = errors.New("user stopped process")
-	ErrBuildingStemTrack         = errors.New("error building stem track")
-	ErrStemOutputExists          = errors.New("stem extraction output already exists")
-	ErrStemTrackEmpty            = errors.New("stem track is empty")
-	ErrDemucsSepStep             = errors.New("error running demucs seperation step")
-	ErrMergeM4AStep              = errors.New("error running merge m4a step")
-	ErrAddMetadataStep           = errors.New("error running add metadata step")
-	ErrCleanupStep               = errors.New("error running cleanup step")
-	ErrInvalidStemSeparationType = errors.New("invalid stem separation type")
-	ErrInvalidPlatform           = errors.New("platform is invalid")
-	ErrConfigDoesNotExist        = errors.New("config does not exist")
-	ErrMissingPlaylistURL        = errors.New("missing playlist URL")
-	ErrExtractingHydrationString = errors.New("error extracting hydration string")
-	ErrHydratableKeyNotFound     = errors.New("hydratable key not found")
-	ErrTrackMissingID            = errors.New("track missing ID")
-	ErrPlaylistAlreadyExists     = errors.New("playlist already exists")
-	ErrMissingRedirectURI        = errors.New("missing redirect URI")
-	ErrRequestingPlaylist        = errors.New("error requesting playlist")
-	ErrUserCancelled             = errors.New("user cancelled operation")
-	ErrSoundCloudClientIDNotSet  = errors.New("soundcloud client ID not set")
-	ErrPleaseWaitForDownload     = errors.New("please wait for the track to finish downloading")
+	ErrBuildingConvertTrack        = errors.New("error building convert track")
+	ErrClosestDirUnknown           = errors.New("something went very wrong getting the closest dir") // Should never happen
+	ErrConvertedFileExists         = errors.New("converted file already exists")
+	ErrConvertingTrack             = errors.New("error converting track")
+	ErrConvertTrack                = errors.New("error converting track")
+	ErrConvertTrackEmpty           = errors.New("convert track is empty")
+	ErrFileAlreadyProcessed        = errors.New("file has already been processed")
+	ErrGettingClosestDir           = errors.New("error getting closest dir")
+	ErrGettingListableURI          = errors.New("error getting listable URI")
+	ErrIndexOutOfBounds            = errors.New("index out of bounds")
+	ErrInDirPathRequired           = errors.New("InDirPath required")
+	ErrInFilePathRequired          = errors.New("InFilePath required")
+	ErrMissingRequiredFields       = errors.New("missing required fields")
+	ErrNoDirPath                   = errors.New("no directory path found")
+	ErrNoFileExtension             = errors.New("no file extension found")
+	ErrNoFileName                  = errors.New("no file name found")
+	ErrNoMatchesFound              = errors.New("no matches found")
+	ErrOperationFinished           = errors.New("operation finished")
+	ErrOperationNotFound           = errors.New("operation not found")
+	ErrBusyPleaseFinishFirst       = errors.New("please finish what you're doing first")
+	ErrUserStoppedProcess          = errors.New("user stopped process")
+	ErrBuildingStemTrack           = errors.New("error building stem track")
+	ErrStemOutputExists            = errors.New("stem extraction output already exists")
+	ErrStemTrackEmpty              = errors.New("stem track is empty")
+	ErrDemucsSepStep               = errors.New("error running demucs seperation step")
+	ErrMergeM4AStep                = errors.New("error running merge m4a step")
+	ErrAddMetadataStep             = errors.New("error running add metadata step")
+	ErrCleanupStep                 = errors.New("error running cleanup step")
+	ErrInvalidStemSeparationType   = errors.New("invalid stem separation type")
+	ErrInvalidPlatform             = errors.New("platform is invalid")
+	ErrConfigDoesNotExist          = errors.New("config does not exist")
+	ErrMissingPlaylistURL          = errors.New("missing playlist URL")
+	ErrExtractingHydrationString   = errors.New("error extracting hydration string")
+	ErrHydratableKeyNotFound       = errors.New("hydratable key not found")
+	ErrTrackMissingID              = errors.New("track missing ID")
+	ErrPlaylistAlreadyExists       = errors.New("playlist already exists")
+	ErrMissingRedirectURI          = errors.New("missing redirect URI")
+	ErrRequestingPlaylist          = errors.New("error requesting playlist")
+	ErrUserCancelled               = errors.New("user cancelled operation")
+	ErrSoundCloudClientIDNotSet    = errors.New("soundcloud client ID not set")
+	ErrPleaseWaitForDownload       = errors.New("please wait for the track to finish downloading")
+	ErrOutDirPathRequired          = errors.New("OutDirPath required")
+	ErrMissingOrganiseTemplate     = errors.New("missing organise template")
+	ErrUnknownTemplateField        = errors.New("unknown template field")
+	ErrInvalidCollisionStrategy    = errors.New("invalid collision strategy")
+	ErrNoFlattenJournalFound       = errors.New("no flatten journal found")
+	ErrInvalidDuplicateMethod      = errors.New("invalid duplicate method")
+	ErrMissingKeeperPath           = errors.New("missing keeper path")
+	ErrMissingDuplicatePaths       = errors.New("missing duplicate paths")
+	ErrKeeperIsDuplicate           = errors.New("keeper can't also be a duplicate")
+	ErrInvalidMinConfidence        = errors.New("min confidence must be between 0 and 1")
+	ErrMissingRelocationMatches    = errors.New("missing relocation matches")
+	ErrMissingPlaylistID           = errors.New("missing playlist ID")
+	ErrPlaylistNotFound            = errors.New("playlist not found")
+	ErrUnexpectedStatusCode        = errors.New("unexpected status code")
+	ErrDownloadIncomplete          = errors.New("download incomplete")
+	ErrInvalidWorkers              = errors.New("workers must be at least 1")
+	ErrInvalidRequestsPerSecond    = errors.New("requests per second must be greater than 0")
+	ErrMissingArtworkTracks        = errors.New("missing playlist ID or tracks to embed artwork into")
+	ErrNoArtworkFound              = errors.New("no artwork found")
+	ErrNotSoundCloudURL            = errors.New("URL is not a SoundCloud URL")
+	ErrUnsupportedSoundCloudURL    = errors.New("URL is not a SoundCloud playlist, track, or user likes, tracks or reposts")
+	ErrMissingSoundCloudUser       = errors.New("missing SoundCloud user")
+	ErrInvalidSoundCloudSourceType = errors.New("invalid SoundCloud source type")
+	ErrRefreshIntervalTooShort     = errors.New("refresh interval must be at least 15 minutes")
+	ErrInvalidLimit                = errors.New("limit must be at least 1")
+	ErrInvalidPurchaseCategory     = errors.New("invalid purchase category")
+	ErrNotSpotifyURL               = errors.New("not a Spotify URL")
+	ErrUnsupportedSpotifyURL       = errors.New("Spotify URL must be a playlist")
+	ErrMissingSpotifyCredentials   = errors.New("missing Spotify client ID or client secret")
+	ErrSpotifyAuthFailed           = errors.New("error getting Spotify access token")
+	ErrUnsupportedURL              = errors.New("URL isn't from a supported platform")
+	ErrDownloadNotSupported        = errors.New("platform doesn't support downloading tracks")
+	ErrTrackNotDownloadable        = errors.New("track doesn't have downloads enabled")
+	ErrInvalidStore                = errors.New("store must be beatport or bandcamp")
+	ErrMissingPurchaseFile         = errors.New("missing purchase export file")
+	ErrEmptyPurchaseExport         = errors.New("purchase export is empty")
+	ErrMissingPurchaseColumns      = errors.New("purchase export is missing the title or artist column")
+	ErrInvalidPurchaseStatus       = errors.New("invalid purchase status")
+	ErrMissingCollectionPath       = errors.New("missing collection path")
+	ErrDatabaseNewerThanApp        = errors.New("database was created by a newer version of the app")
+	ErrInvalidProfileName          = errors.New("profile name may only contain letters, numbers, spaces, '-' and '_'")
+	ErrProfileDoesNotExist         = errors.New("profile does not exist")
+	ErrProfileAlreadyExists        = errors.New("profile already exists")
+	ErrDatabaseNotFile             = errors.New("database isn't stored in a file")
+	ErrBackupExists                = errors.New("backup already exists")
+	ErrInvalidBackupsKept          = errors.New("backups kept must be at least 1")
+	ErrInvalidExportFormat         = errors.New("export format must be json")
+	ErrMissingExportPath           = errors.New("missing export path")
+	ErrMissingImportPath           = errors.New("missing import path")
+	ErrUnsupportedDumpVersion      = errors.New("export was made by a newer version of the app")
+	ErrMissingSearchQuery          = errors.New("missing search query")
+	ErrInvalidSearchBpm            = errors.New("bpm must be a number or a range, i.e. 128 or 120-128")
+	ErrInvalidSearchSource         = errors.New("search source must be one of 'soundcloud', 'local' or 'collection'")
+	ErrSearchIndexNeedsFTS5        = errors.New("search index needs a build with FTS5")
+	ErrDuplicateNoLongerIdentical  = errors.New("duplicate is no longer identical to the file it duplicates")
 )
 
 var (
@@ -100,4 +155,7 @@ var (
 	GenErrCleanupStep = func(name string, err error) error {
 		return fmt.Errorf("%s %s: %w", ErrCleanupStep, name, err)
 	}
+	GenErrUnknownTemplateField = func(field string) error {
+		return fmt.Errorf("%w: %s", ErrUnknownTemplateField, field)
+	}
 )
@@ -1,12 +1,17 @@
 package helpers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"mime"
 	"os"
 	"path/filepath"
+	"runtime"
+	"syscall"
 
 	"github.com/billiem/seren-management/pkg/projectpath"
 )
@@ -251,3 +256,178 @@ func GetAudioExtensions() []string {
 		"m4a",
 	}
 }
+
+/*
+MoveFile moves a file from srcPath to dstPath, creating the destination directory if required
+
+os.Rename fails when moving between volumes, in which case we fall back to copying the file
+and removing the original, any other error is returned
+*/
+func MoveFile(srcPath string, dstPath string) error {
+	dstDir, err := GetDirPathFromFilePath(dstPath)
+
+	if err != nil {
+		return err
+	}
+
+	err = CreateDirIfNotExists(dstDir)
+
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(srcPath, dstPath)
+
+	if err == nil || !isCrossDeviceError(err) {
+		return err
+	}
+
+	err = CopyFile(srcPath, dstPath)
+
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(srcPath)
+}
+
+/*
+isCrossDeviceError returns whether an error from os.Rename was caused by moving between volumes
+*/
+func isCrossDeviceError(err error) bool {
+	var linkErr *os.LinkError
+
+	if !errors.As(err, &linkErr) {
+		return false
+	}
+
+	if errors.Is(linkErr.Err, syscall.EXDEV) {
+		return true
+	}
+
+	// ERROR_NOT_SAME_DEVICE, windows doesn't return EXDEV
+	return runtime.GOOS == "windows" && errors.Is(linkErr.Err, syscall.Errno(17))
+}
+
+/*
+CopyFile copies the file at srcPath to dstPath, any existing file at dstPath is overwritten
+*/
+func CopyFile(srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+
+	if err != nil {
+		return err
+	}
+
+	defer src.Close()
+
+	dst, err := os.Create(dstPath)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+
+	if err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		return err
+	}
+
+	return dst.Close()
+}
+
+/*
+GetNextAvailableFilePath returns the given path if no file exists there, otherwise it appends
+an incrementing suffix to the file name (i.e. 'song (1).mp3') until a free path is found
+*/
+func GetNextAvailableFilePath(path string) string {
+	if !DoesFileExist(path) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := path[:len(path)-len(ext)]
+
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if !DoesFileExist(candidate) {
+			return candidate
+		}
+	}
+}
+
+/*
+RemoveEmptyDirs removes all empty directories inside of the given directory, the given
+directory itself is never removed
+
+Directories are processed deepest first so that directories only containing empty
+directories are also removed
+
+Returns the paths of the removed directories
+*/
+func RemoveEmptyDirs(dirPath string) ([]string, error) {
+	var dirPaths []string
+
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dirPath {
+			dirPaths = append(dirPaths, path)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+
+	for i := len(dirPaths) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirPaths[i])
+
+		if err != nil {
+			return removed, err
+		}
+
+		if len(entries) > 0 {
+			continue
+		}
+
+		err = os.Remove(dirPaths[i])
+
+		if err != nil {
+			return removed, err
+		}
+
+		removed = append(removed, filepath.ToSlash(dirPaths[i]))
+	}
+
+	return removed, nil
+}
+
+/*
+HashFile returns the hex encoded sha256 hash of the contents of the file at the given path
+*/
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, f)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
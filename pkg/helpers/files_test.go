@@ -1,6 +1,7 @@
 package helpers_test
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -421,3 +422,159 @@ func TestGetAbsOrProjPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGetNextAvailableFilePath(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"song.mp3", "song (1).mp3", "other.mp3"} {
+		err := os.WriteFile(helpers.JoinFilepathToSlash(dir, name), []byte{}, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "free path",
+			path: helpers.JoinFilepathToSlash(dir, "new.mp3"),
+			want: helpers.JoinFilepathToSlash(dir, "new.mp3"),
+		},
+		{
+			name: "single collision",
+			path: helpers.JoinFilepathToSlash(dir, "other.mp3"),
+			want: helpers.JoinFilepathToSlash(dir, "other (1).mp3"),
+		},
+		{
+			name: "multiple collisions",
+			path: helpers.JoinFilepathToSlash(dir, "song.mp3"),
+			want: helpers.JoinFilepathToSlash(dir, "song (2).mp3"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := helpers.GetNextAvailableFilePath(tt.path); got != tt.want {
+				t.Errorf("GetNextAvailableFilePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveEmptyDirs(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, d := range []string{"empty", "nested/empty", "full"} {
+		err := os.MkdirAll(helpers.JoinFilepathToSlash(dir, d), os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := os.WriteFile(helpers.JoinFilepathToSlash(dir, "full/song.mp3"), []byte{}, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := helpers.RemoveEmptyDirs(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(removed) != 3 {
+		t.Errorf("expected 3 removed directories, got %v", removed)
+	}
+
+	for _, d := range []string{"empty", "nested", "nested/empty"} {
+		if helpers.DoesFileExist(helpers.JoinFilepathToSlash(dir, d)) {
+			t.Errorf("expected %s to be removed", d)
+		}
+	}
+
+	if !helpers.DoesFileExist(helpers.JoinFilepathToSlash(dir, "full/song.mp3")) {
+		t.Errorf("expected full/song.mp3 to remain")
+	}
+
+	if !helpers.DoesFileExist(dir) {
+		t.Errorf("expected root directory to remain")
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+
+	src := helpers.JoinFilepathToSlash(dir, "in/song.mp3")
+	dst := helpers.JoinFilepathToSlash(dir, "out/nested/song.mp3")
+
+	err := os.MkdirAll(helpers.JoinFilepathToSlash(dir, "in"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(src, []byte("song"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = helpers.MoveFile(src, dst)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if helpers.DoesFileExist(src) || !helpers.DoesFileExist(dst) {
+		t.Errorf("expected song.mp3 to be moved to %s", dst)
+	}
+
+	// errors other than moving between volumes aren't retried as a copy
+	missingDst := helpers.JoinFilepathToSlash(dir, "out/missing.mp3")
+
+	err = helpers.MoveFile(helpers.JoinFilepathToSlash(dir, "in/missing.mp3"), missingDst)
+
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist error moving missing file, got %v", err)
+	}
+
+	if helpers.DoesFileExist(missingDst) {
+		t.Errorf("expected nothing to be created at %s", missingDst)
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{"a.mp3": "same", "b.mp3": "same", "c.mp3": "different"} {
+		err := os.WriteFile(helpers.JoinFilepathToSlash(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hashes := make(map[string]string)
+
+	for _, name := range []string{"a.mp3", "b.mp3", "c.mp3"} {
+		h, err := helpers.HashFile(helpers.JoinFilepathToSlash(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes[name] = h
+	}
+
+	if hashes["a.mp3"] != hashes["b.mp3"] {
+		t.Errorf("expected identical files to have the same hash")
+	}
+
+	if hashes["a.mp3"] == hashes["c.mp3"] {
+		t.Errorf("expected different files to have different hashes")
+	}
+
+	_, err := helpers.HashFile(helpers.JoinFilepathToSlash(dir, "missing.mp3"))
+
+	if err == nil {
+		t.Errorf("expected error hashing missing file")
+	}
+}
@@ -0,0 +1,144 @@
+package helpers
+
+import (
+	"encoding/json"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+)
+
+/*
+Fingerprint is a raw Chromaprint acoustic fingerprint, each value represents
+a short frame (~0.12s) of audio
+
+Fingerprints are calculated using fpcalc, which ships with Chromaprint
+*/
+type Fingerprint []uint32
+
+// fingerprintMaxOffset is the max number of frames fingerprints are shifted by when comparing
+const fingerprintMaxOffset = 16
+
+/*
+CalcFingerprint calculates the acoustic fingerprint of the audio file at the given path
+*/
+func CalcFingerprint(path string) (Fingerprint, error) {
+	out, err := CmdExec(
+		"fpcalc",
+		"-raw",
+		"-json",
+		path,
+	)
+
+	if err != nil {
+		return nil, fault.Wrap(
+			err,
+			fmsg.With("error running fpcalc"),
+		)
+	}
+
+	return parseFPCalcOutput([]byte(out))
+}
+
+type fpcalcOutput struct {
+	Duration    float64  `json:"duration"`
+	Fingerprint []uint32 `json:"fingerprint"`
+}
+
+func parseFPCalcOutput(b []byte) (Fingerprint, error) {
+	var out fpcalcOutput
+
+	err := json.Unmarshal(b, &out)
+
+	if err != nil {
+		return nil, fault.Wrap(
+			err,
+			fmsg.With("error unmarshalling fpcalc output"),
+		)
+	}
+
+	return Fingerprint(out.Fingerprint), nil
+}
+
+/*
+String encodes the fingerprint as a comma separated string, used for storage in the database
+*/
+func (f Fingerprint) String() string {
+	parts := make([]string, len(f))
+	for i, v := range f {
+		parts[i] = strconv.FormatUint(uint64(v), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+/*
+ParseFingerprint decodes a fingerprint encoded with Fingerprint.String
+*/
+func ParseFingerprint(s string) (Fingerprint, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	f := make(Fingerprint, len(parts))
+
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 32)
+
+		if err != nil {
+			return nil, err
+		}
+
+		f[i] = uint32(v)
+	}
+
+	return f, nil
+}
+
+/*
+Similarity returns a score between 0 and 1 of how acoustically similar two fingerprints are,
+where 1 means the fingerprints are identical
+
+The score is the proportion of matching bits between the overlapping frames of the fingerprints,
+the fingerprints are shifted against each other slightly to account for differing amounts of
+leading silence, and the best score is returned
+*/
+func (f Fingerprint) Similarity(o Fingerprint) float64 {
+	var best float64
+
+	for offset := -fingerprintMaxOffset; offset <= fingerprintMaxOffset; offset++ {
+		a, b := f, o
+		if offset < 0 {
+			if -offset >= len(a) {
+				continue
+			}
+			a = a[-offset:]
+		} else {
+			if offset >= len(b) {
+				continue
+			}
+			b = b[offset:]
+		}
+
+		n := min(len(a), len(b))
+
+		if n == 0 {
+			continue
+		}
+
+		var diff int
+		for i := 0; i < n; i++ {
+			diff += bits.OnesCount32(a[i] ^ b[i])
+		}
+
+		score := 1 - float64(diff)/float64(n*32)
+
+		if score > best {
+			best = score
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseFPCalcOutput(t *testing.T) {
+	out := []byte(`{"duration": 215.47, "fingerprint": [3279226118, 3279226374, 3287614982]}`)
+
+	got, err := parseFPCalcOutput(out)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Fingerprint{3279226118, 3279226374, 3287614982}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseFPCalcOutput() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFingerprintStringRoundTrip(t *testing.T) {
+	f := Fingerprint{0, 1, 4294967295}
+
+	got, err := ParseFingerprint(f.String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(f, got); diff != "" {
+		t.Errorf("ParseFingerprint() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFingerprintSimilarity(t *testing.T) {
+	base := make(Fingerprint, 64)
+	for i := range base {
+		base[i] = uint32(i) * 2654435761
+	}
+
+	shifted := append(Fingerprint{1, 2, 3}, base...)
+
+	inverted := make(Fingerprint, len(base))
+	for i, v := range base {
+		inverted[i] = ^v
+	}
+
+	tests := []struct {
+		name string
+		a    Fingerprint
+		b    Fingerprint
+		min  float64
+		max  float64
+	}{
+		{name: "identical", a: base, b: base, min: 1, max: 1},
+		{name: "leading silence", a: base, b: shifted, min: 1, max: 1},
+		{name: "completely different", a: base, b: inverted, min: 0, max: 0.7},
+		{name: "empty", a: base, b: Fingerprint{}, min: 0, max: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.a.Similarity(tt.b)
+			if got < tt.min || got > tt.max {
+				t.Errorf("Similarity() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package helpers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+/*
+Profiles are separate libraries, each with their own config and database, i.e. one for a home
+library and one for preparing a USB for a club
+
+The default profile keeps its config in config.json at the project root, other profiles are kept in
+their own directory in the data directory
+*/
+
+// DefaultProfile is the profile used when no other profile has been chosen
+const DefaultProfile = "default"
+
+// dbFileName is the name of the database file in a profile's directory
+const dbFileName = "seren.db"
+
+// activeProfileFile stores the name of the profile to use when none is given, in the data directory
+const activeProfileFile = "active_profile"
+
+var profileNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _-]{0,63}$`)
+
+/*
+SerenDataDir returns the directory the application stores its data in, this is 'seren' in the
+user's data directory unless SEREN_DATA_DIR is set
+*/
+func SerenDataDir() (string, error) {
+
+	if dir := os.Getenv("SEREN_DATA_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	var dataDir string
+
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "plan9":
+		// %AppData% and ~/Library/Application Support hold data as well as config
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = dir
+	default:
+		dataDir = os.Getenv("XDG_DATA_HOME")
+		if dataDir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dataDir = filepath.Join(home, ".local", "share")
+		}
+	}
+
+	return filepath.Join(dataDir, "seren"), nil
+}
+
+/*
+CheckProfileName returns true if the name can be used for a profile, names are used as directory
+names so may only contain letters, numbers, spaces, '-' and '_'
+*/
+func CheckProfileName(name string) bool {
+	return profileNameRegex.MatchString(name) && strings.TrimSpace(name) == name
+}
+
+/*
+ProfileDir returns the directory holding the database of the profile, and the config of any
+profile other than the default
+*/
+func ProfileDir(name string) (string, error) {
+
+	if !CheckProfileName(name) {
+		return "", ErrInvalidProfileName
+	}
+
+	dataDir, err := SerenDataDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	if name == DefaultProfile {
+		return dataDir, nil
+	}
+
+	return filepath.Join(dataDir, "profiles", name), nil
+}
+
+/*
+profileConfigPath returns the path of the profile's config file
+*/
+func profileConfigPath(name string) (string, error) {
+
+	if name == DefaultProfile {
+		return defaultConfigPath(), nil
+	}
+
+	dir, err := ProfileDir(name)
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "config.json"), nil
+}
+
+/*
+ListProfiles returns the name of every profile, the default profile first and the rest sorted
+*/
+func ListProfiles() ([]string, error) {
+
+	dataDir, err := SerenDataDir()
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dataDir, "profiles"))
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	profiles := []string{}
+
+	for _, e := range entries {
+		if e.IsDir() && CheckProfileName(e.Name()) && e.Name() != DefaultProfile {
+			profiles = append(profiles, e.Name())
+		}
+	}
+
+	sort.Strings(profiles)
+
+	return append([]string{DefaultProfile}, profiles...), nil
+}
+
+/*
+CreateProfile creates a new profile with the default config
+*/
+func CreateProfile(name string) (*Config, error) {
+
+	configPath, err := profileConfigPath(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if DoesFileExist(configPath) {
+		return nil, ErrProfileAlreadyExists
+	}
+
+	err = os.MkdirAll(filepath.Dir(configPath), 0755)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := buildDefaultConfig(configPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Profile = name
+
+	return cfg, nil
+}
+
+/*
+GetActiveProfile returns the profile chosen with SetActiveProfile, or the default profile if one
+hasn't been chosen
+*/
+func GetActiveProfile() (string, error) {
+
+	dataDir, err := SerenDataDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	b, err := os.ReadFile(filepath.Join(dataDir, activeProfileFile))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultProfile, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSpace(string(b))
+
+	if name == "" {
+		return DefaultProfile, nil
+	}
+
+	return name, nil
+}
+
+/*
+SetActiveProfile sets the profile used by the CLI and GUI when a profile isn't given
+*/
+func SetActiveProfile(name string) error {
+
+	configPath, err := profileConfigPath(name)
+
+	if err != nil {
+		return err
+	}
+
+	if name != DefaultProfile && !DoesFileExist(configPath) {
+		return ErrProfileDoesNotExist
+	}
+
+	dataDir, err := SerenDataDir()
+
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dataDir, 0755)
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dataDir, activeProfileFile), []byte(name), 0644)
+}
@@ -0,0 +1,126 @@
+package helpers_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckProfileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "default", want: true},
+		{name: "club USB prep", want: true},
+		{name: "home_2024-01", want: true},
+		{name: "", want: false},
+		{name: " home", want: false},
+		{name: "home ", want: false},
+		{name: "../home", want: false},
+		{name: "home/club", want: false},
+		{name: `home\club`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := helpers.CheckProfileName(tt.name); got != tt.want {
+				t.Errorf("CheckProfileName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+
+	dataDir := t.TempDir()
+	t.Setenv("SEREN_DATA_DIR", dataDir)
+
+	profiles, err := helpers.ListProfiles()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{helpers.DefaultProfile}, profiles); diff != "" {
+		t.Errorf("ListProfiles() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, name := range []string{"home", "club USB prep"} {
+		if _, err := helpers.CreateProfile(name); err != nil {
+			t.Fatalf("CreateProfile(%q): %v", name, err)
+		}
+	}
+
+	_, err = helpers.CreateProfile("home")
+
+	if !helpers.ErrorContains(err, helpers.ErrProfileAlreadyExists) {
+		t.Errorf("got error %v creating an existing profile, want %v", err, helpers.ErrProfileAlreadyExists)
+	}
+
+	profiles, err = helpers.ListProfiles()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{helpers.DefaultProfile, "club USB prep", "home"}, profiles); diff != "" {
+		t.Errorf("ListProfiles() mismatch (-want +got):\n%s", diff)
+	}
+
+	active, err := helpers.GetActiveProfile()
+
+	if err != nil || active != helpers.DefaultProfile {
+		t.Errorf("got active profile %q, %v, want %q", active, err, helpers.DefaultProfile)
+	}
+
+	err = helpers.SetActiveProfile("missing")
+
+	if !helpers.ErrorContains(err, helpers.ErrProfileDoesNotExist) {
+		t.Errorf("got error %v using a missing profile, want %v", err, helpers.ErrProfileDoesNotExist)
+	}
+
+	if err := helpers.SetActiveProfile("club USB prep"); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err = helpers.GetActiveProfile()
+
+	if err != nil || active != "club USB prep" {
+		t.Errorf("got active profile %q, %v, want %q", active, err, "club USB prep")
+	}
+
+	cfg, err := helpers.LoadGUIConfig()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbPath, err := cfg.GetDBPath()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join(dataDir, "profiles", "club USB prep", "seren.db"); dbPath != want {
+		t.Errorf("got db path %s, want %s", dbPath, want)
+	}
+
+	cfg.DBPath = "/music/seren.db"
+
+	if err := cfg.SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err = helpers.LoadProfileConfig("club USB prep")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dbPath, _ := cfg.GetDBPath(); dbPath != "/music/seren.db" {
+		t.Errorf("got db path %s after saving, want /music/seren.db", dbPath)
+	}
+}
@@ -0,0 +1,192 @@
+package helpers
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+)
+
+/*
+AudioTags contains the metadata tags of an audio file which are used throughout the application
+
+Tags are read using ffprobe, which ships alongside ffmpeg
+*/
+type AudioTags struct {
+	Title    string
+	Artist   string
+	Album    string
+	Genre    string
+	Year     string
+	Label    string
+	Comment  string
+	Grouping string
+	Duration float64 // in seconds
+}
+
+/*
+ReadAudioTags reads the metadata tags of the audio file at the given path
+*/
+func ReadAudioTags(path string) (AudioTags, error) {
+	out, err := CmdExec(
+		"ffprobe",
+		"-v", "quiet",
+		"-print_format", "json",
+		"-show_format",
+		path,
+	)
+
+	if err != nil {
+		return AudioTags{}, fault.Wrap(
+			err,
+			fmsg.With("error running ffprobe"),
+		)
+	}
+
+	return parseFFProbeOutput([]byte(out))
+}
+
+type ffprobeOutput struct {
+	Format struct {
+		Duration string            `json:"duration"`
+		Tags     map[string]string `json:"tags"`
+	} `json:"format"`
+}
+
+/*
+parseFFProbeOutput parses the json output of ffprobe into an AudioTags struct
+
+Tag keys differ in case between containers (i.e. TITLE for flac, title for mp3),
+so all keys are lowercased before lookup
+*/
+func parseFFProbeOutput(b []byte) (AudioTags, error) {
+	var out ffprobeOutput
+
+	err := json.Unmarshal(b, &out)
+
+	if err != nil {
+		return AudioTags{}, fault.Wrap(
+			err,
+			fmsg.With("error unmarshalling ffprobe output"),
+		)
+	}
+
+	tags := make(map[string]string)
+	for k, v := range out.Format.Tags {
+		tags[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+
+	firstOf := func(keys ...string) string {
+		for _, k := range keys {
+			if v := tags[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	year := firstOf("date", "year", "tdrc", "tyer")
+	if len(year) > 4 {
+		year = year[:4]
+	}
+
+	duration, _ := strconv.ParseFloat(out.Format.Duration, 64)
+
+	return AudioTags{
+		Title:    firstOf("title"),
+		Artist:   firstOf("artist", "album_artist"),
+		Album:    firstOf("album"),
+		Genre:    firstOf("genre"),
+		Year:     year,
+		Label:    firstOf("label", "publisher", "organization"),
+		Comment:  firstOf("comment", "description"),
+		Grouping: firstOf("grouping", "tit1"),
+		Duration: duration,
+	}, nil
+}
+
+// artworkExtensions are the file types which can have artwork embedded by ffmpeg
+var artworkExtensions = []string{"mp3", "flac", "m4a"}
+
+/*
+SupportsArtwork returns true if artwork can be embedded into the audio file at the given path
+*/
+func SupportsArtwork(path string) bool {
+	return IsExtensionInArray(path, artworkExtensions)
+}
+
+/*
+WriteAudioTags writes the non-empty tags to the audio file at the given path, replacing any
+existing values, the duration is ignored. If artworkPath is given and the file type supports
+it, the image is embedded as the cover art
+
+ffmpeg can't edit files in place, so the file is rewritten to a temporary file next to it which
+then replaces the original
+*/
+func WriteAudioTags(path string, tags AudioTags, artworkPath string) error {
+	ext, err := GetFileExtensionFromFilePath(path)
+
+	if err != nil {
+		return err
+	}
+
+	tmpPath := strings.TrimSuffix(path, ext) + ".tags" + ext
+
+	_, err = CmdExec(buildWriteTagsArgs(path, tmpPath, tags, artworkPath)...)
+
+	if err != nil {
+		os.Remove(tmpPath)
+		return fault.Wrap(
+			err,
+			fmsg.With("error running ffmpeg"),
+		)
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+/*
+buildWriteTagsArgs builds the ffmpeg command used to copy the audio file at inPath to outPath
+with the given tags and artwork
+*/
+func buildWriteTagsArgs(inPath string, outPath string, tags AudioTags, artworkPath string) []string {
+	args := []string{"ffmpeg", "-y", "-i", inPath}
+
+	if artworkPath != "" && SupportsArtwork(inPath) {
+		args = append(args,
+			"-i", artworkPath,
+			"-map", "0:a",
+			"-map", "1:v",
+			"-disposition:v:0", "attached_pic",
+		)
+	}
+
+	args = append(args, "-map_metadata", "0", "-c", "copy")
+
+	for _, t := range []struct {
+		key   string
+		value string
+	}{
+		{"title", tags.Title},
+		{"artist", tags.Artist},
+		{"album", tags.Album},
+		{"genre", tags.Genre},
+		{"date", tags.Year},
+		{"publisher", tags.Label},
+		{"comment", tags.Comment},
+		{"grouping", tags.Grouping},
+	} {
+		if t.value != "" {
+			args = append(args, "-metadata", t.key+"="+t.value)
+		}
+	}
+
+	if IsExtensionInArray(inPath, []string{"mp3"}) {
+		args = append(args, "-id3v2_version", "3")
+	}
+
+	return append(args, outPath)
+}
@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseFFProbeOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   AudioTags
+	}{
+		{
+			name: "mp3 tags",
+			output: `{"format": {"duration": "326.948571", "tags": {
+				"title": "Track 10", "artist": "Coolman", "album": "Pop 2",
+				"genre": "Pop", "date": "2017-01-01", "publisher": "Atlantic UK"
+			}}}`,
+			want: AudioTags{
+				Title:    "Track 10",
+				Artist:   "Coolman",
+				Album:    "Pop 2",
+				Genre:    "Pop",
+				Year:     "2017",
+				Label:    "Atlantic UK",
+				Duration: 326.948571,
+			},
+		},
+		{
+			name: "uppercase vorbis tags",
+			output: `{"format": {"duration": "12.5", "tags": {
+				"TITLE": "Track 10", "ARTIST": "Coolman", "DATE": "2021"
+			}}}`,
+			want: AudioTags{
+				Title:    "Track 10",
+				Artist:   "Coolman",
+				Year:     "2021",
+				Duration: 12.5,
+			},
+		},
+		{
+			name:   "no tags",
+			output: `{"format": {}}`,
+			want:   AudioTags{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFFProbeOutput([]byte(tt.output))
+
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("parseFFProbeOutput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildWriteTagsArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		inPath      string
+		outPath     string
+		tags        AudioTags
+		artworkPath string
+		want        []string
+	}{
+		{
+			name:        "mp3 with artwork",
+			inPath:      "song.mp3",
+			outPath:     "song.tags.mp3",
+			tags:        AudioTags{Title: "Track 10", Artist: "Coolman", Comment: "https://soundcloud.com/coolman/track-10"},
+			artworkPath: "cover.jpg",
+			want: []string{
+				"ffmpeg", "-y", "-i", "song.mp3",
+				"-i", "cover.jpg", "-map", "0:a", "-map", "1:v", "-disposition:v:0", "attached_pic",
+				"-map_metadata", "0", "-c", "copy",
+				"-metadata", "title=Track 10",
+				"-metadata", "artist=Coolman",
+				"-metadata", "comment=https://soundcloud.com/coolman/track-10",
+				"-id3v2_version", "3",
+				"song.tags.mp3",
+			},
+		},
+		{
+			name:        "wav ignores artwork",
+			inPath:      "song.wav",
+			outPath:     "song.tags.wav",
+			tags:        AudioTags{Genre: "House", Grouping: "deep, minimal"},
+			artworkPath: "cover.jpg",
+			want: []string{
+				"ffmpeg", "-y", "-i", "song.wav",
+				"-map_metadata", "0", "-c", "copy",
+				"-metadata", "genre=House",
+				"-metadata", "grouping=deep, minimal",
+				"song.tags.wav",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildWriteTagsArgs(tt.inPath, tt.outPath, tt.tags, tt.artworkPath)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("buildWriteTagsArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/projectpath"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+artworkCacheDirPath is the directory SoundCloud artwork is cached in, keyed by track ID
+*/
+var artworkCacheDirPath = helpers.JoinFilepathToSlash(projectpath.Root, "cache", "artwork")
+
+/*
+EmbedSoundCloudArtwork embeds the SoundCloud artwork of each track into its local file, either
+for the tracks given in opts or for every track in a playlist
+
+The highest resolution artwork available is used, and is cached on disk so it's only fetched
+once per track. Tracks without a working local path or artwork, or whose file type doesn't
+support artwork, are skipped
+
+The number of files with artwork embedded is returned under the 'embedded' key, the number
+skipped under the 'skipped' key and the number which failed under the 'failed' key
+*/
+func (e *OpEnv) EmbedSoundCloudArtwork(ctx context.Context, opts EmbedSoundCloudArtworkOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	tracks := opts.Tracks
+
+	if opts.PlaylistExternalID != 0 {
+		dataTracks, err := e.SerenDB.ListSoundCloudTracksByPlaylistExternalID(
+			ctx,
+			data.ListSoundCloudTracksByPlaylistExternalIDParams{
+				PlaylistExternalID: sql.NullInt64{Valid: true, Int64: opts.PlaylistExternalID},
+				SourceType:         string(opts.PlaylistSourceType),
+			},
+		)
+
+		if err != nil {
+			e.FinishError(fault.Wrap(
+				err,
+				fmsg.WithDesc(
+					"error getting soundcloud tracks from db",
+					"There was an error getting the SoundCloud tracks from the database for this playlist",
+				),
+			))
+			return
+		}
+
+		tracks = make([]streaming.SoundCloudTrack, len(dataTracks))
+		for i, dt := range dataTracks {
+			tracks[i].LoadFromPlaylistDB(dt)
+		}
+	}
+
+	var toEmbed []streaming.SoundCloudTrack
+	var skipped int
+
+	for _, t := range tracks {
+		if t.LocalPath == "" || t.LocalPathBroken || t.ArtworkURL == "" || !helpers.SupportsArtwork(t.LocalPath) {
+			skipped++
+			continue
+		}
+		toEmbed = append(toEmbed, t)
+	}
+
+	e.Logger.Infof("Embedding artwork into %v files, %v skipped", len(toEmbed), skipped)
+
+	if len(toEmbed) > 0 {
+		e.BuildProgressTracker(len(toEmbed), 1)
+	}
+
+	var embedded, failed int
+
+	for i, t := range toEmbed {
+		if ctx.Err() != nil {
+			e.Logger.Info("Operation cancelled, stopping")
+			break
+		}
+
+		err := e.embedSoundCloudArtwork(ctx, t)
+		e.ProcessComplete(i)
+
+		if err != nil {
+			failed++
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fctx.With(fctx.WithMeta(ctx, "track_name", t.Name, "path", t.LocalPath)),
+				fmsg.With("error embedding artwork"),
+			))
+			continue
+		}
+
+		embedded++
+	}
+
+	e.Logger.Infof("Embedded artwork into %v files, %v failed", embedded, failed)
+
+	e.FinishSuccess(map[string]any{
+		"embedded": embedded,
+		"skipped":  skipped,
+		"failed":   failed,
+	})
+}
+
+func (e *OpEnv) embedSoundCloudArtwork(ctx context.Context, t streaming.SoundCloudTrack) error {
+	artworkPath, err := getCachedSoundCloudArtwork(ctx, t)
+
+	if err != nil {
+		return err
+	}
+
+	return helpers.WriteAudioTags(t.LocalPath, helpers.AudioTags{}, artworkPath)
+}
+
+/*
+getCachedSoundCloudArtwork returns the path to the highest resolution artwork for the track,
+downloading it to the artwork cache if it isn't already there
+*/
+func getCachedSoundCloudArtwork(ctx context.Context, t streaming.SoundCloudTrack) (string, error) {
+	ext := path.Ext(t.ArtworkURL)
+	if ext == "" {
+		ext = ".jpg"
+	}
+
+	cachePath := helpers.JoinFilepathToSlash(artworkCacheDirPath, fmt.Sprintf("%d%s", t.ExternalID, ext))
+
+	if helpers.DoesFileExist(cachePath) {
+		return cachePath, nil
+	}
+
+	err := helpers.CreateDirIfNotExists(artworkCacheDirPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, url := range streaming.ArtworkURLVariants(t.ArtworkURL) {
+		err = downloadArtwork(ctx, url, cachePath)
+
+		if err == nil {
+			return cachePath, nil
+		}
+
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+	}
+
+	return "", fault.Wrap(
+		helpers.ErrNoArtworkFound,
+		fmsg.With(err.Error()),
+	)
+}
+
+/*
+downloadArtwork downloads the image at url to filePath, the file is only created if the
+download succeeds
+*/
+func downloadArtwork(ctx context.Context, url string, filePath string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s", helpers.ErrUnexpectedStatusCode, resp.Status)
+	}
+
+	partPath := filePath + helpers.PartFileExtension
+
+	f, err := os.Create(partPath)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, resp.Body)
+	closeErr := f.Close()
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(partPath)
+		return err
+	}
+
+	return os.Rename(partPath, filePath)
+}
@@ -0,0 +1,163 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+const (
+	ClientIDSourceConfig   = "config"   // set by the SOUNDCLOUD_CLIENT_ID environment variable
+	ClientIDSourceDatabase = "database" // generated and stored in the database
+	ClientIDSourceNone     = "none"     // no client ID has been generated yet
+)
+
+/*
+soundCloudClient returns a SoundCloud client using the current client_id, which is replaced
+with a newly generated client_id if SoundCloud rejects it
+*/
+func (e *OpEnv) soundCloudClient(ctx context.Context) (streaming.SoundCloud, error) {
+
+	clientID, err := e.getSoundCloudClientID(ctx)
+
+	if err != nil {
+		return streaming.SoundCloud{}, err
+	}
+
+	return streaming.NewSoundCloud(clientID).WithClientIDRefresh(func(ctx context.Context) (string, error) {
+		e.Logger.Info("SoundCloud client ID rejected, generating a new one")
+		return e.generateSoundCloudClientID(ctx)
+	}), nil
+}
+
+/*
+getSoundCloudClientID returns the client_id set in config, otherwise the latest client_id stored
+in the database. If there isn't one, a client_id is generated and stored
+*/
+func (e *OpEnv) getSoundCloudClientID(ctx context.Context) (string, error) {
+
+	if e.Config.SoundCloudClientID != "" {
+		return e.Config.SoundCloudClientID, nil
+	}
+
+	stored, err := e.SerenDB.GetLatestSoundCloudClientID(ctx)
+
+	if err == nil && stored.ClientID.String != "" {
+		return stored.ClientID.String, nil
+	}
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fault.Wrap(
+			err,
+			fmsg.With("error getting SoundCloud client ID from db"),
+		)
+	}
+
+	return e.generateSoundCloudClientID(ctx)
+}
+
+/*
+generateSoundCloudClientID scrapes a new client_id from SoundCloud and stores it in the database
+*/
+func (e *OpEnv) generateSoundCloudClientID(ctx context.Context) (string, error) {
+
+	clientID, err := streaming.NewSoundCloud("").GenerateClientID(ctx)
+
+	if err != nil {
+		return "", fault.Wrap(
+			err,
+			fmsg.With("error generating SoundCloud client ID"),
+		)
+	}
+
+	_, err = e.SerenDB.InsertSoundCloudClientID(ctx, sql.NullString{Valid: true, String: clientID})
+
+	if err != nil {
+		return "", fault.Wrap(
+			err,
+			fmsg.With("error saving SoundCloud client ID to db"),
+		)
+	}
+
+	return clientID, nil
+}
+
+/*
+SoundCloudClientIDStatus gets the client_id currently used for SoundCloud requests, without
+generating one if there isn't one
+
+The client_id is returned under the 'client_id' key, where it came from under the 'source' key
+(one of the ClientIDSource values), and when it was generated under the 'created_at' key as a
+time.Time if it's stored in the database
+*/
+func (e *OpEnv) SoundCloudClientIDStatus(ctx context.Context) {
+
+	if e.Config.SoundCloudClientID != "" {
+		e.FinishSuccess(map[string]any{
+			"client_id": e.Config.SoundCloudClientID,
+			"source":    ClientIDSourceConfig,
+		})
+		return
+	}
+
+	stored, err := e.SerenDB.GetLatestSoundCloudClientID(ctx)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		e.FinishSuccess(map[string]any{
+			"client_id": "",
+			"source":    ClientIDSourceNone,
+		})
+		return
+	}
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error getting SoundCloud client ID from db",
+				"There was an error getting the SoundCloud client ID from the database",
+			),
+		))
+		return
+	}
+
+	e.FinishSuccess(map[string]any{
+		"client_id":  stored.ClientID.String,
+		"source":     ClientIDSourceDatabase,
+		"created_at": stored.CreatedAt.Time,
+	})
+}
+
+/*
+RefreshSoundCloudClientID generates a new client_id and stores it in the database, it's
+returned under the 'client_id' key
+
+A client_id set in config is still used over the stored client_id
+*/
+func (e *OpEnv) RefreshSoundCloudClientID(ctx context.Context) {
+
+	clientID, err := e.generateSoundCloudClientID(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error refreshing SoundCloud client ID",
+				"There was an error generating a new SoundCloud client ID",
+			),
+		))
+		return
+	}
+
+	if e.Config.SoundCloudClientID != "" {
+		e.Logger.Info("SOUNDCLOUD_CLIENT_ID is set, so it will be used instead of the new client ID")
+	}
+
+	e.FinishSuccess(map[string]any{
+		"client_id": clientID,
+	})
+}
@@ -0,0 +1,304 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+)
+
+/*
+Provides operations for backing up the database, and moving its playlists and tracks between
+databases
+*/
+
+// DefaultBackupsKept is the number of backups kept when backing up the database
+const DefaultBackupsKept = 10
+
+/*
+DumpFormat is the format the database is exported to
+*/
+type DumpFormat string
+
+const (
+	DumpFormatJSON DumpFormat = "json"
+)
+
+func (f DumpFormat) Check() bool {
+	return f == DumpFormatJSON
+}
+
+/*
+BackupDatabase copies the database to a timestamped file while it is in use, then removes the oldest
+backups so only the newest are kept
+
+The path of the backup is returned under the 'path' key, and the paths of the removed backups under the
+'removed' key
+*/
+func (e *OpEnv) BackupDatabase(ctx context.Context, opts BackupDatabaseOpts) {
+
+	opts = opts.build(e.SerenDB)
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	backupPath, removed, err := e.backupDatabase(ctx, opts.Dir, opts.Keep)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(fctx.WithMeta(ctx, "dir", opts.Dir)),
+		))
+		return
+	}
+
+	e.FinishSuccess(map[string]any{
+		"path":    backupPath,
+		"removed": removed,
+	})
+}
+
+/*
+backupDatabase backs up the database into dir, keeping the newest keep backups
+*/
+func (e *OpEnv) backupDatabase(ctx context.Context, dir string, keep int) (string, []string, error) {
+
+	err := os.MkdirAll(dir, 0755)
+
+	if err != nil {
+		return "", nil, fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error creating backup dir",
+				"There was an error creating the directory to store backups in",
+			),
+		)
+	}
+
+	backupPath := filepath.Join(dir, internal.BackupFileName(time.Now()))
+
+	err = e.SerenDB.Backup(ctx, backupPath)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	e.Logger.Infof("Backed up database to %s", backupPath)
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return "", nil, fault.Wrap(
+			err,
+			fmsg.With("Error reading backup dir"),
+		)
+	}
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+
+	removed := []string{}
+
+	for _, name := range internal.ExpiredBackups(names, keep) {
+		p := filepath.Join(dir, name)
+
+		// a backup left behind isn't worth failing the backup over
+		if err := os.Remove(p); err != nil {
+			e.Logger.NonFatalError(fault.Wrap(err, fctx.With(fctx.WithMeta(ctx, "path", p)), fmsg.With("Error removing old backup")))
+			continue
+		}
+
+		removed = append(removed, p)
+	}
+
+	return backupPath, removed, nil
+}
+
+/*
+ExportDatabase writes the SoundCloud and Spotify playlists and tracks in the database, along with the
+links between them, to a file which can be imported into another database with ImportDatabase
+
+The dump is returned under the 'dump' key as a data.Dump
+*/
+func (e *OpEnv) ExportDatabase(ctx context.Context, opts ExportDatabaseOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	ctx = fctx.WithMeta(ctx, "out_path", opts.OutPath)
+
+	dump, err := e.SerenDB.Export(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error exporting database",
+				"There was an error reading your playlists and tracks from the database",
+			),
+		))
+		return
+	}
+
+	b, err := json.MarshalIndent(dump, "", "  ")
+
+	if err == nil {
+		err = os.WriteFile(opts.OutPath, b, 0644)
+	}
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error writing export",
+				"There was an error writing the export, check the path can be written to",
+			),
+		))
+		return
+	}
+
+	e.Logger.Infof("Exported database to %s", opts.OutPath)
+
+	e.FinishSuccess(map[string]any{
+		"dump": dump,
+	})
+}
+
+/*
+ImportDatabase merges a file written by ExportDatabase into the database, playlists and tracks already
+in the database are updated with the values in the file. The database is backed up first
+
+The imported dump is returned under the 'dump' key as a data.Dump, and the path of the backup under the
+'backup' key
+*/
+func (e *OpEnv) ImportDatabase(ctx context.Context, opts ImportDatabaseOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	ctx = fctx.WithMeta(ctx, "in_path", opts.InPath)
+
+	b, err := os.ReadFile(opts.InPath)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error reading import",
+				"There was an error reading the file to import",
+			),
+		))
+		return
+	}
+
+	var dump data.Dump
+
+	err = json.Unmarshal(b, &dump)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error unmarshalling import",
+				"The file to import isn't a database export",
+			),
+		))
+		return
+	}
+
+	backupPath, _, err := e.backupDatabase(ctx, defaultBackupDir(e.SerenDB), DefaultBackupsKept)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error backing up before import",
+				"There was an error backing up your database, so nothing was imported",
+			),
+		))
+		return
+	}
+
+	err = e.SerenDB.TxImportDump(ctx, dump)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error importing dump",
+				"There was an error importing the export into your database, nothing was imported",
+			),
+		))
+		return
+	}
+
+	e.Logger.Infof(
+		"Imported %v SoundCloud playlists, %v SoundCloud tracks, %v Spotify playlists and %v Spotify tracks",
+		len(dump.SoundCloudPlaylists),
+		len(dump.SoundCloudTracks),
+		len(dump.SpotifyPlaylists),
+		len(dump.SpotifyTracks),
+	)
+
+	e.FinishSuccess(map[string]any{
+		"dump":   dump,
+		"backup": backupPath,
+	})
+}
+
+/*
+defaultBackupDir returns the 'backups' directory next to the database
+*/
+func defaultBackupDir(sDB *data.SerenDB) string {
+	if sDB.Path() == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(sDB.Path()), "backups")
+}
@@ -0,0 +1,248 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+const (
+	DefaultDownloadWorkers           = 4 // number of tracks downloaded at once
+	DefaultDownloadRequestsPerSecond = 1 // max number of downloads started per second
+)
+
+/*
+DownloadSoundCloudPlaylist downloads every track in a SoundCloud playlist which has free downloads
+left and hasn't already been downloaded, running any post download hooks on each track
+
+Tracks are downloaded concurrently by opts.Workers workers, with downloads started at no more
+than opts.RequestsPerSecond, into a folder named after the playlist inside of the download directory.
+The local path of every downloaded track is saved in a single transaction once all downloads have finished
+
+The downloaded tracks are returned under the 'tracks' key as a []streaming.SoundCloudTrack, and the
+number of failed downloads under the 'failed' key
+*/
+func (e *OpEnv) DownloadSoundCloudPlaylist(ctx context.Context, opts DownloadSoundCloudPlaylistOpts) {
+
+	opts = opts.build(e.Config)
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	ctx = fctx.WithMeta(ctx, "playlist_name", opts.PlaylistName)
+
+	dataTracks, err := e.SerenDB.ListSoundCloudTracksByPlaylistExternalID(
+		ctx,
+		data.ListSoundCloudTracksByPlaylistExternalIDParams{
+			PlaylistExternalID: sql.NullInt64{Valid: true, Int64: opts.PlaylistExternalID},
+			SourceType:         string(opts.PlaylistSourceType),
+		},
+	)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error getting soundcloud tracks from db",
+				"There was an error getting the SoundCloud tracks from the database for this playlist",
+			),
+		))
+		return
+	}
+
+	var tracks []streaming.SoundCloudTrack
+
+	for _, dt := range dataTracks {
+		t := streaming.SoundCloudTrack{}
+		t.LoadFromPlaylistDB(dt)
+
+		if t.HasDownloadsLeft && t.LocalPath == "" && !t.RemovedFromPlaylist {
+			tracks = append(tracks, t)
+		}
+	}
+
+	e.Logger.Infof("Found %v tracks to download", len(tracks))
+
+	if len(tracks) == 0 {
+		e.FinishSuccess(map[string]any{
+			"tracks": []streaming.SoundCloudTrack{},
+			"failed": 0,
+		})
+		return
+	}
+
+	s, err := e.streamingSource(ctx, streaming.PlatformSoundCloud)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.With("error getting SoundCloud client ID"),
+		))
+		return
+	}
+
+	downloadDir := e.playlistDownloadDir(opts.PlaylistName)
+
+	e.BuildProgressTracker(len(tracks), 1)
+
+	type downloadResult struct {
+		id    int
+		track streaming.SoundCloudTrack
+		err   error
+	}
+
+	jobs := make(chan int)
+	results := make(chan downloadResult)
+
+	// each worker waits for a tick before starting a download, limiting the rate across all workers
+	limiter := time.NewTicker(time.Duration(float64(time.Second) / opts.RequestsPerSecond))
+	defer limiter.Stop()
+
+	// cancelling ctx only stops new downloads being started, downloads which have started, and saving
+	// their local paths, run without it so they aren't aborted part way through
+	runCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				select {
+				case <-ctx.Done():
+					return
+				case <-limiter.C:
+				}
+
+				t := tracks[i]
+
+				e.Logger.Infof("Downloading: %s", t.Name)
+
+				filePath, err := s.DownloadTrack(runCtx, t.ToSourceTrack(), downloadDir, opts.Collision)
+
+				if err == nil {
+					t.LocalPath = filePath
+					t.LocalPathBroken = false
+
+					// a failing hook doesn't fail the download
+					if hookErr := e.runPostDownloadHooks(runCtx, &t); hookErr != nil {
+						e.Logger.NonFatalError(fault.Wrap(
+							hookErr,
+							fctx.With(fctx.WithMeta(ctx, "track_name", t.Name)),
+						))
+					}
+				}
+
+				results <- downloadResult{id: i, track: t, err: err}
+			}
+		}()
+	}
+
+	// stop sending jobs if cancelled, downloads already started are allowed to finish
+	go func() {
+		defer close(jobs)
+		for i := range tracks {
+			select {
+			case <-ctx.Done():
+				e.Logger.Info("Operation cancelled, finishing running downloads")
+				return
+			case jobs <- i:
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var downloaded []streaming.SoundCloudTrack
+	var failed int
+
+	// progress is only updated from here, as the progress tracker isn't safe for concurrent use
+	for r := range results {
+		e.ProcessComplete(r.id)
+
+		if r.err != nil {
+			failed++
+			e.Logger.NonFatalError(fault.Wrap(
+				r.err,
+				fctx.With(fctx.WithMeta(ctx, "track_name", r.track.Name)),
+				fmsg.With("error downloading file from SoundCloud"),
+			))
+			continue
+		}
+
+		downloaded = append(downloaded, r.track)
+	}
+
+	if len(downloaded) > 0 {
+		dataT := make([]data.SoundcloudTrack, len(downloaded))
+		for i, t := range downloaded {
+			dataT[i] = t.ToDB()
+		}
+
+		err = e.SerenDB.TxSetSoundCloudTrackLocalPaths(runCtx, dataT)
+
+		if err != nil {
+			e.FinishError(fault.Wrap(
+				err,
+				fctx.With(ctx),
+				fmsg.WithDesc(
+					"error saving tracks to database",
+					"The tracks were downloaded, but there was an error saving their local paths to the database",
+				),
+			))
+			return
+		}
+	}
+
+	e.Logger.Infof("Downloaded %v tracks, %v failed", len(downloaded), failed)
+
+	e.separateQueuedStems(ctx)
+
+	e.FinishSuccess(map[string]any{
+		"tracks": downloaded,
+		"failed": failed,
+	})
+}
+
+/*
+playlistDownloadDir returns the folder a playlist's tracks are downloaded into, named after the playlist
+inside of the download directory, or the download directory itself if playlistName is empty
+
+Playlist names can contain slashes and characters windows doesn't allow in directory names, so these
+are replaced
+*/
+func (e *OpEnv) playlistDownloadDir(playlistName string) string {
+
+	playlistDir := strings.TrimRight(internal.SanitisePathSegment(playlistName), ". ")
+
+	if playlistDir == "" {
+		return e.Config.DownloadDir
+	}
+
+	return helpers.JoinFilepathToSlash(e.Config.DownloadDir, playlistDir)
+}
@@ -0,0 +1,182 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"os"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/collection"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+)
+
+/*
+DuplicateMethod determines how files are compared when looking for duplicates
+*/
+type DuplicateMethod string
+
+const (
+	DuplicateContentHash DuplicateMethod = "hash"        // files with identical contents
+	DuplicateArtistTitle DuplicateMethod = "name"        // files with the same normalised artist and title
+	DuplicateFingerprint DuplicateMethod = "fingerprint" // files which sound the same, requires fingerprints in the index
+)
+
+// DefaultFingerprintThreshold is the default min similarity for two fingerprints to be considered duplicates
+const DefaultFingerprintThreshold = 0.85
+
+func (m DuplicateMethod) Check() bool {
+	switch m {
+	case DuplicateContentHash, DuplicateArtistTitle, DuplicateFingerprint:
+		return true
+	}
+	return false
+}
+
+/*
+FindDuplicates groups files in the local file index which are likely to be the same track,
+using the method given in opts, the local file index should be built with IndexLocalFiles first
+
+The groups are returned under the 'groups' key as a [][]data.LocalFile
+*/
+func (e *OpEnv) FindDuplicates(ctx context.Context, opts FindDuplicatesOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	files, err := e.SerenDB.ListLocalFiles(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error listing local files in db",
+				"There was an error getting the local file index from the database",
+			),
+		))
+		return
+	}
+
+	e.Logger.Infof("Checking %v indexed files for duplicates", len(files))
+
+	var groups [][]data.LocalFile
+
+	switch opts.Method {
+	case DuplicateContentHash:
+		groups = internal.GroupByContentHash(files)
+	case DuplicateArtistTitle:
+		groups = internal.GroupByArtistTitle(files)
+	case DuplicateFingerprint:
+		groups = internal.GroupByFingerprint(files, opts.Threshold)
+	}
+
+	e.Logger.Infof("Found %v groups of duplicates", len(groups))
+
+	e.FinishSuccess(map[string]any{
+		"groups": groups,
+	})
+}
+
+/*
+MergeDuplicates merges a group of duplicate files into the single file being kept
+
+Collection entries for the duplicates are merged into the keepers entry (cues, play count),
+and SoundCloud tracks linked to a duplicate are linked to the keeper instead
+
+If opts.DeleteFiles is set, the duplicate files are deleted and removed from the local file index
+*/
+func (e *OpEnv) MergeDuplicates(ctx context.Context, opts MergeDuplicatesOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	var merged int
+
+	if e.Config.TraktorCollectionPath != "" {
+		c := collection.ReadTraktorOpts{}.Build(e.Config)
+
+		merged, err = c.MergeTracks(opts.KeeperPath, opts.DuplicatePaths)
+
+		if err != nil {
+			e.FinishError(fault.Wrap(
+				err,
+				fmsg.WithDesc(
+					"error merging tracks in collection",
+					"There was an error merging the duplicates in your collection",
+				),
+			))
+			return
+		}
+
+		e.Logger.Infof("Merged %v collection entries into %s", merged, opts.KeeperPath)
+	}
+
+	// duplicates are 'moved' to the keeper, so anything pointing at them points at the keeper instead
+	moves := make(map[string]string, len(opts.DuplicatePaths))
+	for _, p := range opts.DuplicatePaths {
+		moves[p] = opts.KeeperPath
+	}
+
+	e.updateMovedLocalPaths(ctx, moves)
+
+	var deleted int
+
+	if opts.DeleteFiles {
+		for _, p := range opts.DuplicatePaths {
+			err := os.Remove(p)
+
+			if err != nil && !os.IsNotExist(err) {
+				e.Logger.NonFatalError(fault.Wrap(
+					err,
+					fctx.With(fctx.WithMeta(ctx, "path", p)),
+					fmsg.With("error deleting duplicate file"),
+				))
+				continue
+			}
+
+			err = e.SerenDB.DeleteLocalFileByPath(ctx, sql.NullString{Valid: true, String: p})
+
+			if err != nil {
+				e.Logger.NonFatalError(fault.Wrap(
+					err,
+					fctx.With(fctx.WithMeta(ctx, "path", p)),
+					fmsg.With("error removing duplicate file from index"),
+				))
+				continue
+			}
+
+			deleted++
+		}
+
+		e.Logger.Infof("Deleted %v duplicate files", deleted)
+	}
+
+	e.FinishSuccess(map[string]any{
+		"merged":  merged,
+		"deleted": deleted,
+	})
+}
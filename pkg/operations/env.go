@@ -26,6 +26,9 @@ type OpEnv struct {
 
 	Mp3EnvBuilder  func() Mp3Env
 	StemEnvBuilder func() StemEnv
+
+	PostDownloadHooks []PostDownloadHook // run in order on each track downloaded from SoundCloud
+	stemQueue         *stemQueue
 }
 
 func (e *OpEnv) BuildOperationHandler(
@@ -50,6 +53,7 @@ type Mp3Env interface {
 	GetMp3Paths(string, bool) ([]string, error)
 	GetMp3Tracks([]string, string) ([]mp3.ConvertTrack, int, []error)
 	ConvertMp3Tracks(context.Context, []mp3.ConvertTrack)
+	ConvertMp3Track(mp3.ConvertTrack) (mp3.ConvertTrack, error)
 }
 
 /*
@@ -0,0 +1,367 @@
+package operations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+)
+
+/*
+flattenJournalDirPath returns the directory flatten journals are written to, inside of the data directory
+*/
+func flattenJournalDirPath() (string, error) {
+
+	dataDir, err := helpers.SerenDataDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return helpers.JoinFilepathToSlash(filepath.ToSlash(dataDir), "journal"), nil
+}
+
+/*
+FlattenPlan is the steps a flatten will take, as returned by a dry run of FlattenDirectory
+*/
+type FlattenPlan = internal.FlattenPlan
+
+/*
+FlattenDirectory iterates through a directory recursively and moves all files to the root of the directory
+
+Files which collide on name are handled using the collision strategy given in opts, and any
+directories left empty are removed. Each step taken is recorded in a journal, allowing the
+flatten to be undone with UndoFlattenDirectory
+
+If opts.DryRun is set, no files are moved and the plan is returned under the 'plan' key, passing it
+back in opts.Plan carries out exactly the steps which were planned
+*/
+func (e *OpEnv) FlattenDirectory(ctx context.Context, opts FlattenDirectoryOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	plan := opts.Plan
+
+	if plan == nil {
+		plan, err = e.buildFlattenPlan(opts)
+
+		if err != nil {
+			e.FinishError(err)
+			return
+		}
+	}
+
+	e.Logger.Infof(
+		"%v files to move, %v to skip, %v duplicates to remove",
+		plan.Count(internal.FlattenMove),
+		plan.Count(internal.FlattenSkip),
+		plan.Count(internal.FlattenDedupe),
+	)
+
+	if opts.DryRun {
+		e.FinishSuccess(map[string]any{
+			"plan": plan,
+		})
+		return
+	}
+
+	if len(plan) > 0 {
+		e.BuildProgressTracker(len(plan), 1)
+	}
+
+	var journal *internal.FlattenJournalWriter
+	var moved, skipped, deduped int
+
+	for i, step := range plan {
+		if ctx.Err() != nil {
+			e.Logger.Info("Operation cancelled, stopping")
+			break
+		}
+
+		if step.Action == internal.FlattenSkip {
+			skipped++
+			e.ProcessComplete(i)
+			continue
+		}
+
+		// each step is journaled before it is carried out, so it can be undone if we're stopped part way
+		if journal == nil {
+			var journalDirPath string
+			journalDirPath, err = flattenJournalDirPath()
+
+			if err == nil {
+				journal, err = internal.CreateFlattenJournal(journalDirPath, internal.FlattenJournal{
+					DirPath:   opts.InDirPath,
+					CreatedAt: time.Now(),
+				})
+			}
+
+			if err == nil {
+				defer journal.Close()
+				e.Logger.Infof("Writing journal to %s", journal.Path)
+			}
+		}
+
+		if err == nil {
+			err = journal.Append(step)
+		}
+
+		if err != nil {
+			e.FinishError(fault.Wrap(
+				err,
+				fmsg.WithDesc(
+					"error writing flatten journal",
+					"There was an error writing the journal used to undo the flatten, so no more files were moved",
+				),
+			))
+			return
+		}
+
+		err := e.flattenStep(step)
+		e.ProcessComplete(i)
+
+		if err != nil {
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fctx.With(fctx.WithMeta(ctx, "path", step.SrcPath)),
+				fmsg.With("error flattening file"),
+			))
+			continue
+		}
+
+		switch step.Action {
+		case internal.FlattenMove:
+			moved++
+		case internal.FlattenDedupe:
+			deduped++
+		}
+	}
+
+	removedDirs, err := helpers.RemoveEmptyDirs(opts.InDirPath)
+
+	if err != nil {
+		e.Logger.NonFatalError(fault.Wrap(
+			err,
+			fmsg.With("error removing empty directories"),
+		))
+	}
+
+	e.Logger.Infof("Removed %v empty directories", len(removedDirs))
+
+	var journalPath string
+
+	if journal != nil {
+		journalPath = journal.Path
+	}
+
+	e.FinishSuccess(map[string]any{
+		"moved":   moved,
+		"skipped": skipped,
+		"deduped": deduped,
+		"journal": journalPath,
+	})
+}
+
+/*
+buildFlattenPlan finds the files in opts.InDirPath and plans where to move them
+*/
+func (e *OpEnv) buildFlattenPlan(opts FlattenDirectoryOpts) (FlattenPlan, error) {
+
+	e.Logger.Info("Finding files to flatten")
+	filePaths, err := helpers.GetFilesInDir(opts.InDirPath, true)
+
+	if err != nil {
+		return nil, fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error getting file paths",
+				"There was an error getting the paths of the files to flatten",
+			),
+		)
+	}
+
+	plan, err := internal.BuildFlattenPlan(opts.InDirPath, filePaths, opts.Collision)
+
+	if err != nil {
+		return nil, fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error building flatten plan",
+				"There was an error working out where to move files to",
+			),
+		)
+	}
+
+	return plan, nil
+}
+
+/*
+flattenStep carries out a single step of a flatten plan
+*/
+func (e *OpEnv) flattenStep(step internal.FlattenStep) error {
+	switch step.Action {
+	case internal.FlattenMove:
+		// the plan may be stale if files have been added since it was built
+		if helpers.DoesFileExist(step.DstPath) {
+			return fault.Wrap(
+				os.ErrExist,
+				fmsg.With("destination file already exists"),
+			)
+		}
+		e.Logger.Debugf("Moving %s to %s", step.SrcPath, step.DstPath)
+		return helpers.MoveFile(step.SrcPath, step.DstPath)
+	case internal.FlattenDedupe:
+		// removing a file which no longer has an identical copy would lose it
+		err := internal.CheckDedupeStep(step)
+
+		if err != nil {
+			return err
+		}
+
+		e.Logger.Debugf("Removing %s, duplicate of %s", step.SrcPath, step.DstPath)
+		return os.Remove(step.SrcPath)
+	}
+	return nil
+}
+
+/*
+UndoFlattenDirectory reverses the steps recorded in a flatten journal, moving files back
+to their original locations
+
+Removed duplicates are restored by copying the file they were identical to
+*/
+func (e *OpEnv) UndoFlattenDirectory(ctx context.Context, opts UndoFlattenDirectoryOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	journalPath := opts.JournalPath
+
+	if journalPath == "" {
+		var journalDirPath string
+		journalDirPath, err = flattenJournalDirPath()
+
+		if err == nil {
+			journalPath, err = internal.GetLatestFlattenJournalPath(journalDirPath)
+		}
+
+		if err != nil {
+			e.FinishError(fault.Wrap(
+				err,
+				fmsg.WithDesc(
+					"error finding latest flatten journal",
+					"Unable to find a journal to undo",
+				),
+			))
+			return
+		}
+	}
+
+	journal, err := internal.ReadFlattenJournal(journalPath)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error reading flatten journal",
+				"There was an error reading the journal to undo",
+			),
+		))
+		return
+	}
+
+	e.Logger.Infof("Undoing %v steps from %s", len(journal.Steps), journalPath)
+
+	if len(journal.Steps) > 0 {
+		e.BuildProgressTracker(len(journal.Steps), 1)
+	}
+
+	var restored int
+
+	// steps are undone in reverse, so that duplicates are restored before the file
+	// they were copied from is moved back
+	for i := len(journal.Steps) - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			e.Logger.Info("Operation cancelled, stopping")
+			break
+		}
+
+		step := journal.Steps[i]
+
+		err := undoFlattenStep(step)
+		e.ProcessComplete(i)
+
+		if err != nil {
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fctx.With(fctx.WithMeta(ctx, "path", step.SrcPath)),
+				fmsg.With("error undoing flatten step"),
+			))
+			continue
+		}
+
+		restored++
+	}
+
+	e.FinishSuccess(map[string]any{
+		"restored": restored,
+	})
+}
+
+func undoFlattenStep(step internal.FlattenStep) error {
+	if helpers.DoesFileExist(step.SrcPath) {
+		return fault.Wrap(
+			os.ErrExist,
+			fmsg.With("original file path is taken"),
+		)
+	}
+
+	switch step.Action {
+	case internal.FlattenMove:
+		return helpers.MoveFile(step.DstPath, step.SrcPath)
+	case internal.FlattenDedupe:
+		dirPath, err := helpers.GetDirPathFromFilePath(step.SrcPath)
+
+		if err != nil {
+			return err
+		}
+
+		err = helpers.CreateDirIfNotExists(dirPath)
+
+		if err != nil {
+			return err
+		}
+
+		return helpers.CopyFile(step.DstPath, step.SrcPath)
+	}
+	return nil
+}
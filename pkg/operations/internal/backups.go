@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+Provides the naming and retention of database backups
+*/
+
+const (
+	backupPrefix     = "seren-"
+	backupExt        = ".db"
+	backupTimeLayout = "20060102-150405"
+)
+
+/*
+BackupFileName returns the file name of a backup taken at t, names sort in the order the backups
+were taken
+*/
+func BackupFileName(t time.Time) string {
+	return backupPrefix + t.UTC().Format(backupTimeLayout) + backupExt
+}
+
+/*
+ExpiredBackups returns the names of the backups to remove so that only the newest keep backups are
+left, names which aren't backups are ignored
+*/
+func ExpiredBackups(names []string, keep int) []string {
+
+	backups := []string{}
+
+	for _, n := range names {
+		ts := strings.TrimSuffix(strings.TrimPrefix(n, backupPrefix), backupExt)
+		if len(ts) != len(n)-len(backupPrefix)-len(backupExt) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeLayout, ts); err != nil {
+			continue
+		}
+		backups = append(backups, n)
+	}
+
+	if len(backups) <= keep {
+		return []string{}
+	}
+
+	// newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	return backups[keep:]
+}
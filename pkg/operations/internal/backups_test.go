@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBackupFileName(t *testing.T) {
+	got := BackupFileName(time.Date(2024, 1, 5, 12, 30, 1, 0, time.UTC))
+
+	if want := "seren-20240105-123001.db"; got != want {
+		t.Errorf("BackupFileName() = %v, want %v", got, want)
+	}
+}
+
+func TestExpiredBackups(t *testing.T) {
+	names := []string{
+		"seren-20240103-120000.db",
+		"seren-20240105-120000.db",
+		"notes.txt",
+		"seren-20240101-120000.db",
+		"seren-latest.db",
+		"seren-20240104-120000.db",
+		"seren-20240102-120000.db.bak",
+	}
+
+	tests := []struct {
+		name string
+		keep int
+		want []string
+	}{
+		{name: "keep newest two", keep: 2, want: []string{"seren-20240103-120000.db", "seren-20240101-120000.db"}},
+		{name: "keep all", keep: 4, want: []string{}},
+		{name: "keep more than exist", keep: 10, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, ExpiredBackups(names, tt.keep)); diff != "" {
+				t.Errorf("ExpiredBackups() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
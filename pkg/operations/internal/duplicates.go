@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides functionality for grouping indexed local files which are likely to be the same track
+*/
+
+// durationTolerance is the max difference in seconds between the durations of two files for them
+// to be considered the same track when comparing fingerprints
+const durationTolerance = 3.0
+
+/*
+GroupByContentHash groups files which have identical contents
+*/
+func GroupByContentHash(files []data.LocalFile) [][]data.LocalFile {
+	return groupByKey(files, func(f data.LocalFile) string {
+		return f.ContentHash.String
+	})
+}
+
+/*
+GroupByArtistTitle groups files which have the same normalised artist and title
+
+If a file is missing artist or title tags, they are taken from the file name instead (i.e. 'Artist - Title.mp3')
+*/
+func GroupByArtistTitle(files []data.LocalFile) [][]data.LocalFile {
+	return groupByKey(files, func(f data.LocalFile) string {
+		artist, title := f.Artist.String, f.Title.String
+
+		if artist == "" || title == "" {
+			fileName := helpers.RemoveFileExtension(filepath.Base(f.Path.String))
+			artist, title = SplitArtistTitle(fileName)
+		}
+
+		artist, title = NormaliseTrackName(artist), NormaliseTrackName(title)
+
+		if artist == "" || title == "" {
+			return ""
+		}
+
+		return artist + " - " + title
+	})
+}
+
+/*
+GroupByFingerprint groups files whose acoustic fingerprints have a similarity of at least threshold,
+files must also have a similar duration
+
+Files are grouped transitively, if a is similar to b, and b is similar to c, all three are grouped
+*/
+func GroupByFingerprint(files []data.LocalFile, threshold float64) [][]data.LocalFile {
+
+	var candidates []data.LocalFile
+	var fingerprints []helpers.Fingerprint
+
+	for _, f := range files {
+		fp, err := helpers.ParseFingerprint(f.Fingerprint.String)
+		if err != nil || len(fp) == 0 {
+			continue
+		}
+		candidates = append(candidates, f)
+		fingerprints = append(fingerprints, fp)
+	}
+
+	// union find, parent[i] is the index of the file i is grouped with
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := 0; i < len(candidates); i++ {
+		for j := i + 1; j < len(candidates); j++ {
+			if find(i) == find(j) {
+				continue
+			}
+			if candidates[i].Duration.Valid && candidates[j].Duration.Valid &&
+				math.Abs(candidates[i].Duration.Float64-candidates[j].Duration.Float64) > durationTolerance {
+				continue
+			}
+			if fingerprints[i].Similarity(fingerprints[j]) >= threshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	// paths are unique within the index, so can be used to look up the group of each file
+	keys := make(map[string]string, len(candidates))
+	for i, c := range candidates {
+		keys[c.Path.String] = candidates[find(i)].Path.String
+	}
+
+	return groupByKey(candidates, func(f data.LocalFile) string {
+		return keys[f.Path.String]
+	})
+}
+
+/*
+groupByKey groups files by the key returned from keyFunc, files with an empty key are ignored
+
+Only groups containing more than one file are returned, files inside of a group
+and the groups themselves are sorted by path
+*/
+func groupByKey(files []data.LocalFile, keyFunc func(data.LocalFile) string) [][]data.LocalFile {
+
+	groupMap := make(map[string][]data.LocalFile)
+
+	for _, f := range files {
+		key := keyFunc(f)
+		if key == "" {
+			continue
+		}
+		groupMap[key] = append(groupMap[key], f)
+	}
+
+	var groups [][]data.LocalFile
+
+	for _, g := range groupMap {
+		if len(g) < 2 {
+			continue
+		}
+		sort.Slice(g, func(i, j int) bool {
+			return g[i].Path.String < g[j].Path.String
+		})
+		groups = append(groups, g)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0].Path.String < groups[j][0].Path.String
+	})
+
+	return groups
+}
@@ -0,0 +1,96 @@
+package internal_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/google/go-cmp/cmp"
+)
+
+func localFile(path string, hash string, artist string, title string) data.LocalFile {
+	return data.LocalFile{
+		Path:        sql.NullString{Valid: true, String: path},
+		ContentHash: sql.NullString{Valid: hash != "", String: hash},
+		Artist:      sql.NullString{Valid: artist != "", String: artist},
+		Title:       sql.NullString{Valid: title != "", String: title},
+	}
+}
+
+func groupPaths(groups [][]data.LocalFile) [][]string {
+	var paths [][]string
+	for _, g := range groups {
+		var p []string
+		for _, f := range g {
+			p = append(p, f.Path.String)
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+func TestGroupByContentHash(t *testing.T) {
+	files := []data.LocalFile{
+		localFile("/music/b.mp3", "aaa", "", ""),
+		localFile("/music/a.mp3", "aaa", "", ""),
+		localFile("/music/c.mp3", "bbb", "", ""),
+		localFile("/music/d.mp3", "", "", ""),
+		localFile("/music/e.mp3", "", "", ""),
+	}
+
+	want := [][]string{{"/music/a.mp3", "/music/b.mp3"}}
+
+	if diff := cmp.Diff(want, groupPaths(internal.GroupByContentHash(files))); diff != "" {
+		t.Errorf("GroupByContentHash() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGroupByArtistTitle(t *testing.T) {
+	files := []data.LocalFile{
+		localFile("/music/wav/Coolman - Funky Song.wav", "", "", ""),
+		localFile("/music/mp3/01 - funky song.mp3", "", "Coolman", "Funky Song (Original Mix)"),
+		localFile("/music/other.mp3", "", "Coolman", "Other Song"),
+		localFile("/music/untagged.mp3", "", "", ""),
+	}
+
+	want := [][]string{{"/music/mp3/01 - funky song.mp3", "/music/wav/Coolman - Funky Song.wav"}}
+
+	if diff := cmp.Diff(want, groupPaths(internal.GroupByArtistTitle(files))); diff != "" {
+		t.Errorf("GroupByArtistTitle() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGroupByFingerprint(t *testing.T) {
+	base := make(helpers.Fingerprint, 64)
+	for i := range base {
+		base[i] = uint32(i) * 2654435761
+	}
+
+	other := make(helpers.Fingerprint, len(base))
+	for i, v := range base {
+		other[i] = ^v
+	}
+
+	withFingerprint := func(path string, fp helpers.Fingerprint, duration float64) data.LocalFile {
+		f := localFile(path, "", "", "")
+		f.Fingerprint = sql.NullString{Valid: true, String: fp.String()}
+		f.Duration = sql.NullFloat64{Valid: true, Float64: duration}
+		return f
+	}
+
+	files := []data.LocalFile{
+		withFingerprint("/music/a.mp3", base, 200),
+		withFingerprint("/music/b.wav", append(helpers.Fingerprint{7}, base...), 201),
+		withFingerprint("/music/c.mp3", other, 200),
+		withFingerprint("/music/d.mp3", base, 300),
+		localFile("/music/e.mp3", "", "", ""),
+	}
+
+	want := [][]string{{"/music/a.mp3", "/music/b.wav"}}
+
+	if diff := cmp.Diff(want, groupPaths(internal.GroupByFingerprint(files, 0.85))); diff != "" {
+		t.Errorf("GroupByFingerprint() mismatch (-want +got):\n%s", diff)
+	}
+}
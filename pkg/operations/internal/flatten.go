@@ -0,0 +1,358 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides functionality for planning a directory flatten, and for recording the steps
+taken whilst flattening in a journal so that they can be undone later
+*/
+
+/*
+FlattenAction is the action taken for a single file when flattening a directory
+*/
+type FlattenAction string
+
+const (
+	FlattenMove   FlattenAction = "move"   // file is moved to DstPath
+	FlattenSkip   FlattenAction = "skip"   // file collides and is left where it is
+	FlattenDedupe FlattenAction = "dedupe" // file is identical to the file at DstPath and is removed
+)
+
+/*
+FlattenStep is a single planned (or journaled) change to a file
+*/
+type FlattenStep struct {
+	Action  FlattenAction `json:"action"`
+	SrcPath string        `json:"srcPath"`
+	DstPath string        `json:"dstPath"`
+}
+
+func (s FlattenStep) String() string {
+	switch s.Action {
+	case FlattenSkip:
+		return fmt.Sprintf("skip    %s (collides with %s)", s.SrcPath, s.DstPath)
+	case FlattenDedupe:
+		return fmt.Sprintf("dedupe  %s (identical to %s)", s.SrcPath, s.DstPath)
+	default:
+		return fmt.Sprintf("move    %s -> %s", s.SrcPath, s.DstPath)
+	}
+}
+
+/*
+FlattenPlan is the list of steps required to flatten a directory
+*/
+type FlattenPlan []FlattenStep
+
+func (p FlattenPlan) String() string {
+	var sb strings.Builder
+	for _, s := range p {
+		sb.WriteString(s.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+/*
+Count returns the number of steps in the plan with the given action
+*/
+func (p FlattenPlan) Count(action FlattenAction) int {
+	var n int
+	for _, s := range p {
+		if s.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+/*
+BuildFlattenPlan builds the steps required to move all of the given files (found inside of dirPath)
+to the root of dirPath
+
+Files already at the root of dirPath are left in place and take priority over their names, any other
+files which collide on name are handled using the given collision strategy
+*/
+func BuildFlattenPlan(dirPath string, filePaths []string, strategy helpers.CollisionStrategy) (FlattenPlan, error) {
+
+	dirPath = filepath.ToSlash(filepath.Clean(dirPath))
+
+	paths := make([]string, len(filePaths))
+	for i, p := range filePaths {
+		paths[i] = filepath.ToSlash(filepath.Clean(p))
+	}
+	sort.Strings(paths)
+
+	// map of destination paths to the path of the file that will end up there
+	taken := make(map[string]string)
+
+	for _, p := range paths {
+		if filepath.ToSlash(filepath.Dir(p)) == dirPath {
+			taken[p] = p
+		}
+	}
+
+	var plan FlattenPlan
+
+	for _, p := range paths {
+		if _, ok := taken[p]; ok {
+			continue
+		}
+
+		dstPath := helpers.JoinFilepathToSlash(dirPath, filepath.Base(p))
+
+		owner, collides := taken[dstPath]
+
+		if !collides {
+			taken[dstPath] = p
+			plan = append(plan, FlattenStep{Action: FlattenMove, SrcPath: p, DstPath: dstPath})
+			continue
+		}
+
+		switch strategy {
+		case helpers.CollisionSkip:
+			plan = append(plan, FlattenStep{Action: FlattenSkip, SrcPath: p, DstPath: dstPath})
+			continue
+		case helpers.CollisionHashDedupe:
+			same, err := isSameFileContent(p, owner)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if same {
+				plan = append(plan, FlattenStep{Action: FlattenDedupe, SrcPath: p, DstPath: dstPath})
+				continue
+			}
+		}
+
+		dstPath = nextAvailablePath(dstPath, taken)
+		taken[dstPath] = p
+		plan = append(plan, FlattenStep{Action: FlattenMove, SrcPath: p, DstPath: dstPath})
+	}
+
+	return plan, nil
+}
+
+/*
+CheckDedupeStep checks the file a dedupe step would remove is still identical to the file at its
+DstPath, as the plan may be stale if either file has changed since it was built
+*/
+func CheckDedupeStep(step FlattenStep) error {
+
+	if !helpers.DoesFileExist(step.DstPath) {
+		return fault.Wrap(
+			os.ErrNotExist,
+			fmsg.With("file the duplicate is identical to no longer exists"),
+		)
+	}
+
+	same, err := isSameFileContent(step.SrcPath, step.DstPath)
+
+	if err != nil {
+		return err
+	}
+
+	if !same {
+		return helpers.ErrDuplicateNoLongerIdentical
+	}
+
+	return nil
+}
+
+/*
+nextAvailablePath appends an incrementing suffix to the file name of the given path
+until a path is found which isn't already taken by the plan
+*/
+func nextAvailablePath(path string, taken map[string]string) string {
+	ext := filepath.Ext(path)
+	base := path[:len(path)-len(ext)]
+
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+func isSameFileContent(a string, b string) (bool, error) {
+	hashA, err := helpers.HashFile(a)
+
+	if err != nil {
+		return false, fault.Wrap(err, fmsg.With("error hashing file"))
+	}
+
+	hashB, err := helpers.HashFile(b)
+
+	if err != nil {
+		return false, fault.Wrap(err, fmsg.With("error hashing file"))
+	}
+
+	return hashA == hashB, nil
+}
+
+/*
+FlattenJournal records the steps which were carried out whilst flattening a directory
+
+Skipped files are not recorded, as nothing happened to them
+*/
+type FlattenJournal struct {
+	DirPath   string        `json:"dirPath"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Steps     []FlattenStep `json:"steps,omitempty"`
+}
+
+/*
+FlattenJournalWriter writes a journal as the flatten runs, the journal is written as its dir path and
+creation time followed by one line per step, so each step can be appended before it is carried out
+*/
+type FlattenJournalWriter struct {
+	Path string
+
+	f   *os.File
+	enc *json.Encoder
+}
+
+/*
+CreateFlattenJournal creates a new journal file inside of journalDirPath, writing the dir path and
+creation time of j, steps are added with Append
+*/
+func CreateFlattenJournal(journalDirPath string, j FlattenJournal) (*FlattenJournalWriter, error) {
+
+	err := helpers.CreateDirIfNotExists(journalDirPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	path := helpers.JoinFilepathToSlash(
+		journalDirPath,
+		fmt.Sprintf("flatten_%s.json", j.CreatedAt.Format("20060102_150405.000")),
+	)
+	path = helpers.GetNextAvailableFilePath(path)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	w := &FlattenJournalWriter{Path: path, f: f, enc: json.NewEncoder(f)}
+
+	err = w.enc.Encode(FlattenJournal{DirPath: j.DirPath, CreatedAt: j.CreatedAt})
+
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	for _, step := range j.Steps {
+		err = w.Append(step)
+
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+/*
+Append adds a step to the journal, it is synced to disk before returning so the step can be undone
+even if the flatten is stopped part way through
+*/
+func (w *FlattenJournalWriter) Append(step FlattenStep) error {
+
+	err := w.enc.Encode(step)
+
+	if err != nil {
+		return err
+	}
+
+	return w.f.Sync()
+}
+
+func (w *FlattenJournalWriter) Close() error {
+	return w.f.Close()
+}
+
+/*
+ReadFlattenJournal reads the journal at the given path
+*/
+func ReadFlattenJournal(path string) (FlattenJournal, error) {
+
+	f, err := os.Open(path)
+
+	if err != nil {
+		return FlattenJournal{}, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+
+	var j FlattenJournal
+
+	err = dec.Decode(&j)
+
+	if err != nil {
+		return FlattenJournal{}, err
+	}
+
+	for {
+		var step FlattenStep
+
+		err = dec.Decode(&step)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			// the last step may be cut short if the flatten was killed whilst writing it
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return FlattenJournal{}, err
+		}
+
+		j.Steps = append(j.Steps, step)
+	}
+
+	return j, nil
+}
+
+/*
+GetLatestFlattenJournalPath returns the path of the most recently written journal inside of journalDirPath
+*/
+func GetLatestFlattenJournalPath(journalDirPath string) (string, error) {
+
+	paths, err := filepath.Glob(helpers.JoinFilepathToSlash(journalDirPath, "flatten_*.json"))
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(paths) == 0 {
+		return "", helpers.ErrNoFlattenJournalFound
+	}
+
+	// journal names contain a sortable timestamp
+	sort.Strings(paths)
+
+	return filepath.ToSlash(paths[len(paths)-1]), nil
+}
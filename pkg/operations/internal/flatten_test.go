@@ -0,0 +1,245 @@
+package internal_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildFlattenPlan(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"song.mp3":         "a",
+		"one/song.mp3":     "a",
+		"two/song.mp3":     "b",
+		"two/other.mp3":    "c",
+		"three/four/x.wav": "d",
+	}
+
+	var filePaths []string
+
+	for name, content := range files {
+		path := helpers.JoinFilepathToSlash(dir, name)
+		err := os.MkdirAll(helpers.JoinFilepathToSlash(path, ".."), os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filePaths = append(filePaths, path)
+	}
+
+	p := func(name string) string {
+		return helpers.JoinFilepathToSlash(dir, name)
+	}
+
+	tests := []struct {
+		name     string
+		strategy helpers.CollisionStrategy
+		want     internal.FlattenPlan
+	}{
+		{
+			name:     "suffix",
+			strategy: helpers.CollisionSuffix,
+			want: internal.FlattenPlan{
+				{Action: internal.FlattenMove, SrcPath: p("one/song.mp3"), DstPath: p("song (1).mp3")},
+				{Action: internal.FlattenMove, SrcPath: p("three/four/x.wav"), DstPath: p("x.wav")},
+				{Action: internal.FlattenMove, SrcPath: p("two/other.mp3"), DstPath: p("other.mp3")},
+				{Action: internal.FlattenMove, SrcPath: p("two/song.mp3"), DstPath: p("song (2).mp3")},
+			},
+		},
+		{
+			name:     "skip",
+			strategy: helpers.CollisionSkip,
+			want: internal.FlattenPlan{
+				{Action: internal.FlattenSkip, SrcPath: p("one/song.mp3"), DstPath: p("song.mp3")},
+				{Action: internal.FlattenMove, SrcPath: p("three/four/x.wav"), DstPath: p("x.wav")},
+				{Action: internal.FlattenMove, SrcPath: p("two/other.mp3"), DstPath: p("other.mp3")},
+				{Action: internal.FlattenSkip, SrcPath: p("two/song.mp3"), DstPath: p("song.mp3")},
+			},
+		},
+		{
+			name:     "hash dedupe",
+			strategy: helpers.CollisionHashDedupe,
+			want: internal.FlattenPlan{
+				{Action: internal.FlattenDedupe, SrcPath: p("one/song.mp3"), DstPath: p("song.mp3")},
+				{Action: internal.FlattenMove, SrcPath: p("three/four/x.wav"), DstPath: p("x.wav")},
+				{Action: internal.FlattenMove, SrcPath: p("two/other.mp3"), DstPath: p("other.mp3")},
+				{Action: internal.FlattenMove, SrcPath: p("two/song.mp3"), DstPath: p("song (1).mp3")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := internal.BuildFlattenPlan(dir, filePaths, tt.strategy)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("BuildFlattenPlan() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFlattenJournal(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := internal.GetLatestFlattenJournalPath(dir)
+
+	if !helpers.ErrorContains(err, helpers.ErrNoFlattenJournalFound) {
+		t.Fatalf("expected %v, got %v", helpers.ErrNoFlattenJournalFound, err)
+	}
+
+	createdAt := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	older := internal.FlattenJournal{
+		DirPath:   "/music",
+		CreatedAt: createdAt,
+	}
+
+	newer := internal.FlattenJournal{
+		DirPath:   "/music",
+		CreatedAt: createdAt.Add(time.Hour),
+		Steps: []internal.FlattenStep{
+			{Action: internal.FlattenMove, SrcPath: "/music/a/song.mp3", DstPath: "/music/song.mp3"},
+		},
+	}
+
+	for _, j := range []internal.FlattenJournal{newer, older} {
+		w, err := internal.CreateFlattenJournal(dir, j)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+	}
+
+	path, err := internal.GetLatestFlattenJournalPath(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := internal.ReadFlattenJournal(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(newer, got); diff != "" {
+		t.Errorf("ReadFlattenJournal() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFlattenJournalAppend(t *testing.T) {
+	dir := t.TempDir()
+
+	j := internal.FlattenJournal{
+		DirPath:   "/music",
+		CreatedAt: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	w, err := internal.CreateFlattenJournal(dir, j)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	steps := []internal.FlattenStep{
+		{Action: internal.FlattenMove, SrcPath: "/music/a/song.mp3", DstPath: "/music/song.mp3"},
+		{Action: internal.FlattenDedupe, SrcPath: "/music/b/song.mp3", DstPath: "/music/song.mp3"},
+	}
+
+	// each step can be read back as soon as it is appended, before the journal is closed
+	for i, step := range steps {
+		if err := w.Append(step); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := internal.ReadFlattenJournal(w.Path)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		j.Steps = steps[:i+1]
+
+		if diff := cmp.Diff(j, got); diff != "" {
+			t.Errorf("ReadFlattenJournal() after %d steps mismatch (-want +got):\n%s", i+1, diff)
+		}
+	}
+
+	// a step cut short by the flatten being killed is ignored
+	f, err := os.OpenFile(w.Path, os.O_APPEND|os.O_WRONLY, 0644)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.WriteString(`{"action":"move","srcPa`)
+	f.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := internal.ReadFlattenJournal(w.Path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Steps) != len(steps) {
+		t.Errorf("got %d steps, want %d", len(got.Steps), len(steps))
+	}
+}
+
+func TestCheckDedupeStep(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name string, content string) string {
+		t.Helper()
+		path := helpers.JoinFilepathToSlash(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	src := write("duplicate.mp3", "a")
+
+	tests := []struct {
+		name    string
+		dst     string
+		wantErr bool
+	}{
+		{name: "still identical", dst: write("same.mp3", "a")},
+		{name: "changed since planned", dst: write("changed.mp3", "b"), wantErr: true},
+		{name: "removed since planned", dst: helpers.JoinFilepathToSlash(dir, "removed.mp3"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := internal.CheckDedupeStep(internal.FlattenStep{
+				Action:  internal.FlattenDedupe,
+				SrcPath: src,
+				DstPath: tt.dst,
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckDedupeStep() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package internal
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides functionality for matching tracks from streaming platforms to files in the local
+library, by fuzzy comparison of artist and title, and duration
+*/
+
+// names are only compared if they share at least one word, these words are too common to count
+var matchStopWords = map[string]bool{
+	"the":      true,
+	"and":      true,
+	"mix":      true,
+	"remix":    true,
+	"edit":     true,
+	"original": true,
+	"extended": true,
+	"version":  true,
+	"dub":      true,
+	"vip":      true,
+}
+
+/*
+MatchQuery contains everything known about the track being matched
+*/
+type MatchQuery struct {
+	Artist        string
+	Title         string
+	PurchaseTitle string  // Optional - alternative title, often the release title of the track
+	Duration      float64 // Optional - in seconds, 0 if unknown
+}
+
+/*
+MatchCandidate is a track in the library which the query may match
+*/
+type MatchCandidate struct {
+	Source   string // where the candidate is from (i.e. local, traktor)
+	Path     string
+	Artist   string
+	Title    string
+	Duration float64 // Optional - in seconds, 0 if unknown
+}
+
+/*
+TrackMatch is the best candidate found for a query
+*/
+type TrackMatch struct {
+	Source     string
+	Path       string
+	Confidence float64 // 0-1
+	Reason     string
+}
+
+/*
+NewMatchCandidate builds a candidate, if the artist or title are missing they are
+taken from the file name (i.e. 'Artist - Title.mp3')
+*/
+func NewMatchCandidate(source string, path string, artist string, title string, duration float64) MatchCandidate {
+	if artist == "" || title == "" {
+		fileArtist, fileTitle := SplitArtistTitle(helpers.RemoveFileExtension(filepath.Base(path)))
+
+		if artist == "" {
+			artist = fileArtist
+		}
+		if title == "" {
+			title = fileTitle
+		}
+	}
+
+	return MatchCandidate{
+		Source:   source,
+		Path:     path,
+		Artist:   artist,
+		Title:    title,
+		Duration: duration,
+	}
+}
+
+/*
+TrackMatcher matches queries against a fixed set of candidates
+*/
+type TrackMatcher struct {
+	candidates []MatchCandidate
+	byWord     map[string][]int // normalised word -> index of candidates containing it
+}
+
+func NewTrackMatcher(candidates []MatchCandidate) TrackMatcher {
+	m := TrackMatcher{
+		candidates: candidates,
+		byWord:     make(map[string][]int),
+	}
+
+	for i, c := range candidates {
+		for _, w := range matchWords(c.Artist + " " + c.Title) {
+			m.byWord[w] = append(m.byWord[w], i)
+		}
+	}
+
+	return m
+}
+
+/*
+Match finds the candidate most similar to the query
+
+The confidence is mostly based on the similarity of the normalised artist and title, it's
+raised when the durations are within a few seconds of each other, and lowered when they
+differ by a lot (i.e. an extended mix vs a radio edit)
+
+Returns false if no candidate shares a word with the query
+*/
+func (m TrackMatcher) Match(q MatchQuery) (TrackMatch, bool) {
+
+	titles := []string{q.Title}
+	if q.PurchaseTitle != "" && !strings.EqualFold(q.PurchaseTitle, q.Title) {
+		titles = append(titles, q.PurchaseTitle)
+	}
+
+	// only compare against candidates sharing a word with the query
+	seen := make(map[int]bool)
+	var idxs []int
+
+	for _, t := range titles {
+		for _, w := range matchWords(q.Artist + " " + t) {
+			for _, i := range m.byWord[w] {
+				if !seen[i] {
+					seen[i] = true
+					idxs = append(idxs, i)
+				}
+			}
+		}
+	}
+
+	var best TrackMatch
+	var found bool
+
+	for _, i := range idxs {
+		c := m.candidates[i]
+
+		confidence, reason := scoreMatch(q, titles, c)
+
+		if !found || confidence > best.Confidence {
+			best = TrackMatch{
+				Source:     c.Source,
+				Path:       c.Path,
+				Confidence: confidence,
+				Reason:     reason,
+			}
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+func scoreMatch(q MatchQuery, titles []string, c MatchCandidate) (float64, string) {
+
+	cTitle := NormaliseTrackName(c.Title)
+	cArtist := NormaliseTrackName(c.Artist)
+	qArtist := NormaliseTrackName(q.Artist)
+
+	var confidence float64
+	reason := "similar artist and title"
+
+	for i, t := range titles {
+		qTitle := NormaliseTrackName(t)
+
+		// artists are often part of the title on streaming platforms, so also compare everything at once
+		score := math.Max(
+			0.6*StringSimilarity(qTitle, cTitle)+0.4*artistSimilarity(qArtist, cArtist),
+			StringSimilarity(strings.TrimSpace(qArtist+" "+qTitle), strings.TrimSpace(cArtist+" "+cTitle)),
+		)
+
+		if score > confidence {
+			confidence = score
+			if i > 0 {
+				reason = "similar artist and purchase title"
+			}
+		}
+	}
+
+	if q.Duration > 0 && c.Duration > 0 {
+		diff := math.Abs(q.Duration - c.Duration)
+
+		switch {
+		case diff <= 3:
+			confidence = math.Min(1, confidence+0.1)
+			reason += ", same duration"
+		case diff > 15:
+			confidence *= 0.7
+			reason += ", different duration"
+		}
+	}
+
+	return confidence, reason
+}
+
+/*
+artistSimilarity compares two normalised artists, a missing artist counts as a partial match
+*/
+func artistSimilarity(a string, b string) float64 {
+	if a == "" || b == "" {
+		return 0.5
+	}
+	if strings.Contains(a, b) || strings.Contains(b, a) {
+		return 1
+	}
+	return StringSimilarity(a, b)
+}
+
+/*
+matchWords returns the normalised words of s used to find candidates
+*/
+func matchWords(s string) []string {
+	var words []string
+	for _, w := range strings.Fields(NormaliseTrackName(s)) {
+		if len([]rune(w)) < 2 || matchStopWords[w] {
+			continue
+		}
+		words = append(words, w)
+	}
+	return words
+}
+
+/*
+StringSimilarity returns the similarity of two strings between 0 and 1,
+based on the levenshtein distance between them
+*/
+func StringSimilarity(a string, b string) float64 {
+	ar, br := []rune(a), []rune(b)
+
+	maxLen := max(len(ar), len(br))
+
+	if maxLen == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ar, br))/float64(maxLen)
+}
+
+func levenshtein(a []rune, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
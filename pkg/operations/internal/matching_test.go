@@ -0,0 +1,103 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/operations/internal"
+)
+
+func TestStringSimilarity(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want float64
+	}{
+		{"", "", 1},
+		{"funky song", "funky song", 1},
+		{"abcd", "abce", 0.75},
+		{"abcd", "", 0},
+	}
+
+	for _, tt := range tests {
+		if got := internal.StringSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("StringSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNewMatchCandidate(t *testing.T) {
+	c := internal.NewMatchCandidate("local", "/music/01 - Coolman - Funky Song.mp3", "", "", 0)
+
+	if c.Artist != "Coolman" || c.Title != "Funky Song" {
+		t.Errorf("NewMatchCandidate() artist = %q, title = %q, want artist and title from file name", c.Artist, c.Title)
+	}
+}
+
+func TestTrackMatcherMatch(t *testing.T) {
+	matcher := internal.NewTrackMatcher([]internal.MatchCandidate{
+		internal.NewMatchCandidate("local", "/music/Coolman - Funky Song (Original Mix).wav", "", "", 360),
+		internal.NewMatchCandidate("traktor", "/music/other.mp3", "Somebody Else", "Another Tune", 300),
+		internal.NewMatchCandidate("local", "/music/radio.mp3", "Radioman", "Long Song", 180),
+	})
+
+	tests := []struct {
+		name     string
+		query    internal.MatchQuery
+		wantPath string
+		wantOk   bool
+		minConf  float64
+		maxConf  float64
+	}{
+		{
+			name:     "same artist, title and duration",
+			query:    internal.MatchQuery{Artist: "Coolman", Title: "Funky Song", Duration: 361},
+			wantPath: "/music/Coolman - Funky Song (Original Mix).wav",
+			wantOk:   true,
+			minConf:  1,
+			maxConf:  1,
+		},
+		{
+			name:     "matched by purchase title",
+			query:    internal.MatchQuery{Artist: "Somebody Else", Title: "PREMIERE: out now!!", PurchaseTitle: "Another Tune"},
+			wantPath: "/music/other.mp3",
+			wantOk:   true,
+			minConf:  0.9,
+			maxConf:  1,
+		},
+		{
+			name:     "different duration lowers confidence",
+			query:    internal.MatchQuery{Artist: "Radioman", Title: "Long Song", Duration: 420},
+			wantPath: "/music/radio.mp3",
+			wantOk:   true,
+			minConf:  0.6,
+			maxConf:  0.8,
+		},
+		{
+			name:   "no shared words",
+			query:  internal.MatchQuery{Artist: "Nobody", Title: "Nothing"},
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := matcher.Match(tt.query)
+
+			if ok != tt.wantOk {
+				t.Fatalf("Match() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if !ok {
+				return
+			}
+
+			if got.Path != tt.wantPath {
+				t.Errorf("Match() path = %v, want %v", got.Path, tt.wantPath)
+			}
+
+			if got.Confidence < tt.minConf || got.Confidence > tt.maxConf {
+				t.Errorf("Match() confidence = %v, want between %v and %v", got.Confidence, tt.minConf, tt.maxConf)
+			}
+		})
+	}
+}
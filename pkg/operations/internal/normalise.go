@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+)
+
+/*
+Provides functionality for normalising artist and track names, so that the same
+track can be matched across differing sources (i.e. tags, file names, streaming platforms)
+*/
+
+var (
+	featuringRegex    = regexp.MustCompile(`(?i)[(\[]?\b(feat|ft|featuring)\b\.?[^)\]]*[)\]]?`)
+	originalMixRegex  = regexp.MustCompile(`(?i)[(\[]\s*original\s+mix\s*[)\]]`)
+	nonAlphaNumRegex  = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+	trackNumberRegex  = regexp.MustCompile(`^\d{1,3}\s*[-_.]\s*`)
+	artistTitleRegexp = regexp.MustCompile(`^(.+?)\s+-\s+(.+)$`)
+)
+
+/*
+NormaliseTrackName normalises an artist or track name for comparison
+
+Names are lowercased, featured artists and '(Original Mix)' are removed, and any
+punctuation is collapsed into single spaces
+*/
+func NormaliseTrackName(s string) string {
+	s = originalMixRegex.ReplaceAllString(s, " ")
+	s = featuringRegex.ReplaceAllString(s, " ")
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "&", " and ")
+	s = nonAlphaNumRegex.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+/*
+SplitArtistTitle splits a name in the form 'Artist - Title' (i.e. a file name, or a SoundCloud
+track name), leading track numbers are ignored
+
+If the name can't be split, the artist is returned empty and the title is the whole name
+*/
+func SplitArtistTitle(s string) (string, string) {
+	s = trackNumberRegex.ReplaceAllString(strings.TrimSpace(s), "")
+
+	m := artistTitleRegexp.FindStringSubmatch(s)
+
+	if m == nil {
+		return "", s
+	}
+
+	return strings.TrimSpace(m[1]), strings.TrimSpace(m[2])
+}
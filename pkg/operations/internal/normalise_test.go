@@ -0,0 +1,51 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/operations/internal"
+)
+
+func TestNormaliseTrackName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "case and punctuation", in: "Funky, Cool Song!", want: "funky cool song"},
+		{name: "original mix", in: "Funky Song (Original Mix)", want: "funky song"},
+		{name: "featuring", in: "Funky Song (feat. Coolman)", want: "funky song"},
+		{name: "ampersand", in: "Coolman & Friend", want: "coolman and friend"},
+		{name: "remix kept", in: "Funky Song [Coolman Remix]", want: "funky song coolman remix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := internal.NormaliseTrackName(tt.in); got != tt.want {
+				t.Errorf("NormaliseTrackName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitArtistTitle(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		wantArtist string
+		wantTitle  string
+	}{
+		{name: "artist and title", in: "Coolman - Funky Song", wantArtist: "Coolman", wantTitle: "Funky Song"},
+		{name: "track number", in: "01 - Coolman - Funky Song", wantArtist: "Coolman", wantTitle: "Funky Song"},
+		{name: "title only", in: "Funky Song", wantArtist: "", wantTitle: "Funky Song"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artist, title := internal.SplitArtistTitle(tt.in)
+			if artist != tt.wantArtist || title != tt.wantTitle {
+				t.Errorf("SplitArtistTitle() = %v, %v, want %v, %v", artist, title, tt.wantArtist, tt.wantTitle)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides functionality for building library paths for files from a template,
+used when organising files into the library
+*/
+
+var (
+	templateFieldRegex   = regexp.MustCompile(`\{([a-zA-Z]+)\}`)
+	illegalPathCharRegex = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]`)
+)
+
+/*
+BuildOrganisePath builds the path (relative to the library directory) for a file from a
+template such as '{genre}/{year}/{artist} - {title}' and the files tags
+
+The original file name is used in place of a missing title, other missing fields
+are replaced with 'Unknown {Field}'. The original file extension is always kept
+*/
+func BuildOrganisePath(template string, tags helpers.AudioTags, fileInfo FileInfo) (string, error) {
+
+	if template == "" {
+		return "", helpers.ErrMissingOrganiseTemplate
+	}
+
+	fields := map[string]string{
+		"title":  tags.Title,
+		"artist": tags.Artist,
+		"album":  tags.Album,
+		"genre":  tags.Genre,
+		"year":   tags.Year,
+		"label":  tags.Label,
+	}
+
+	if fields["title"] == "" {
+		fields["title"] = fileInfo.FileName
+	}
+
+	var fieldErr error
+
+	rendered := templateFieldRegex.ReplaceAllStringFunc(template, func(m string) string {
+		key := strings.ToLower(m[1 : len(m)-1])
+		v, ok := fields[key]
+		if !ok {
+			fieldErr = helpers.GenErrUnknownTemplateField(key)
+			return m
+		}
+		v = SanitisePathSegment(v)
+		if v == "" {
+			v = "Unknown " + strings.ToUpper(key[:1]) + key[1:]
+		}
+		return v
+	})
+
+	if fieldErr != nil {
+		return "", fieldErr
+	}
+
+	segments := []string{}
+	for _, s := range strings.Split(rendered, "/") {
+		s = strings.TrimRight(strings.TrimSpace(s), ".")
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	if len(segments) == 0 {
+		return "", helpers.ErrMissingOrganiseTemplate
+	}
+
+	return strings.Join(segments, "/") + fileInfo.FileExtension, nil
+}
+
+/*
+SanitisePathSegment replaces any characters which are not allowed in file
+or directory names on common file systems
+*/
+func SanitisePathSegment(s string) string {
+	return strings.TrimSpace(illegalPathCharRegex.ReplaceAllString(s, "_"))
+}
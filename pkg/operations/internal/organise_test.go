@@ -0,0 +1,101 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+)
+
+func TestBuildOrganisePath(t *testing.T) {
+	fileInfo := internal.FileInfo{
+		FullPath:      "H:/Music/to process/01 - funky cool song.wav",
+		DirPath:       "H:/Music/to process/",
+		FileName:      "01 - funky cool song",
+		FileExtension: ".wav",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		tags     helpers.AudioTags
+		want     string
+		err      error
+	}{
+		{
+			name:     "all fields present",
+			template: "{genre}/{year}/{artist} - {title}",
+			tags:     helpers.AudioTags{Title: "Funky Cool Song", Artist: "Coolman", Genre: "House", Year: "2023"},
+			want:     "House/2023/Coolman - Funky Cool Song.wav",
+		},
+		{
+			name:     "missing fields",
+			template: "{genre}/{year}/{artist} - {title}",
+			tags:     helpers.AudioTags{},
+			want:     "Unknown Genre/Unknown Year/Unknown Artist - 01 - funky cool song.wav",
+		},
+		{
+			name:     "illegal characters",
+			template: "{genre}/{artist} - {title}",
+			tags:     helpers.AudioTags{Title: "What?", Artist: "AC/DC", Genre: "Rock"},
+			want:     "Rock/AC_DC - What_.wav",
+		},
+		{
+			name:     "unknown field",
+			template: "{genre}/{bpm}/{title}",
+			tags:     helpers.AudioTags{},
+			err:      helpers.ErrUnknownTemplateField,
+		},
+		{
+			name:     "empty template",
+			template: "",
+			err:      helpers.ErrMissingOrganiseTemplate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := internal.BuildOrganisePath(tt.template, tt.tags, fileInfo)
+
+			if !helpers.ErrorContains(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("BuildOrganisePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitisePathSegment(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			name: "no illegal characters",
+			s:    "Warm up 2024",
+			want: "Warm up 2024",
+		},
+		{
+			name: "slashes",
+			s:    "house / techno \\ breaks",
+			want: "house _ techno _ breaks",
+		},
+		{
+			name: "windows reserved characters",
+			s:    ` "best of": what? *new* `,
+			want: `_best of__ what_ _new_`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := internal.SanitisePathSegment(tt.s); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"path"
+	"strings"
+)
+
+/*
+Provides matching of store purchases to the local library, and checking which files are in the collection
+*/
+
+/*
+AlbumCandidate is a file in the library, used to find albums bought without a list of their tracks
+*/
+type AlbumCandidate struct {
+	Path   string
+	Artist string
+	Album  string
+}
+
+/*
+MatchAlbum finds the files most likely to be the album, the match's path is the directory holding them
+
+Returns false if no candidate has album tags
+*/
+func MatchAlbum(candidates []AlbumCandidate, artist string, album string) (TrackMatch, bool) {
+
+	qAlbum := NormaliseTrackName(album)
+	qArtist := NormaliseTrackName(artist)
+
+	var best TrackMatch
+	var found bool
+
+	for _, c := range candidates {
+		if c.Album == "" {
+			continue
+		}
+
+		confidence := 0.6*StringSimilarity(qAlbum, NormaliseTrackName(c.Album)) +
+			0.4*artistSimilarity(qArtist, NormaliseTrackName(c.Artist))
+
+		if !found || confidence > best.Confidence {
+			best = TrackMatch{
+				Path:       path.Dir(strings.ReplaceAll(c.Path, "\\", "/")),
+				Confidence: confidence,
+				Reason:     "similar album and artist",
+			}
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+/*
+NormalisePath cleans a path so paths from the local file index and the collection can be compared,
+they are compared case insensitively as most music libraries are on case insensitive file systems
+*/
+func NormalisePath(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+	return strings.ToLower(path.Clean(p))
+}
+
+/*
+PathSet is a set of normalised file paths
+*/
+type PathSet map[string]bool
+
+func NewPathSet(paths []string) PathSet {
+	s := make(PathSet, len(paths))
+	for _, p := range paths {
+		s[NormalisePath(p)] = true
+	}
+	return s
+}
+
+/*
+Has reports whether the file is in the set
+*/
+func (s PathSet) Has(p string) bool {
+	return s[NormalisePath(p)]
+}
+
+/*
+HasUnder reports whether any file in the set is within the directory
+*/
+func (s PathSet) HasUnder(dir string) bool {
+	prefix := strings.TrimSuffix(NormalisePath(dir), "/") + "/"
+	for p := range s {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
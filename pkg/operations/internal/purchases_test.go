@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestMatchAlbum(t *testing.T) {
+	candidates := []AlbumCandidate{
+		{Path: "/music/Coolman/Funky Album/01 Funky Song.mp3", Artist: "Coolman", Album: "Funky Album"},
+		{Path: "/music/Other/Late Night EP/01 Late Night.mp3", Artist: "Third Artist", Album: "Late Night EP"},
+		{Path: "/music/untagged.mp3"},
+	}
+
+	tests := []struct {
+		name     string
+		artist   string
+		album    string
+		wantPath string
+		wantMin  float64
+	}{
+		{name: "exact", artist: "Coolman", album: "Funky Album", wantPath: "/music/Coolman/Funky Album", wantMin: 0.95},
+		{name: "different case and punctuation", artist: "Third Artist", album: "Late Night E.P.", wantPath: "/music/Other/Late Night EP", wantMin: 0.8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ok := MatchAlbum(candidates, tt.artist, tt.album)
+
+			if !ok {
+				t.Fatal("MatchAlbum() found no match")
+			}
+
+			if m.Path != tt.wantPath {
+				t.Errorf("MatchAlbum() path = %v, want %v", m.Path, tt.wantPath)
+			}
+
+			if m.Confidence < tt.wantMin {
+				t.Errorf("MatchAlbum() confidence = %v, want at least %v", m.Confidence, tt.wantMin)
+			}
+		})
+	}
+
+	if _, ok := MatchAlbum([]AlbumCandidate{{Path: "/music/untagged.mp3"}}, "Coolman", "Funky Album"); ok {
+		t.Error("MatchAlbum() matched a file without album tags")
+	}
+}
+
+func TestPathSet(t *testing.T) {
+	s := NewPathSet([]string{
+		"/Music/Coolman/Funky Song.mp3",
+		"C:\\Music\\Other\\Late Night.mp3",
+	})
+
+	tests := []struct {
+		path      string
+		wantHas   bool
+		wantUnder bool
+	}{
+		{path: "/music/coolman/funky song.mp3", wantHas: true},
+		{path: "/Music/Coolman/../Coolman/Funky Song.mp3", wantHas: true},
+		{path: "C:/Music/Other/Late Night.mp3", wantHas: true},
+		{path: "/Music/Coolman", wantUnder: true},
+		{path: "/Music/Cool", wantUnder: false},
+		{path: "/Music/Missing.mp3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := s.Has(tt.path); got != tt.wantHas {
+				t.Errorf("Has() = %v, want %v", got, tt.wantHas)
+			}
+			if got := s.HasUnder(tt.path); got != tt.wantUnder {
+				t.Errorf("HasUnder() = %v, want %v", got, tt.wantUnder)
+			}
+		})
+	}
+}
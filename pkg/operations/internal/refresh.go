@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+Provides functionality for comparing the tracks of a playlist stored in the database with the
+tracks currently in the playlist on SoundCloud
+*/
+
+const (
+	PlaylistChangeAdded           = "added"            // the track was added to the playlist
+	PlaylistChangeRemoved         = "removed"          // the track was removed from the playlist
+	PlaylistChangeDownloadEnabled = "download_enabled" // the track now has free downloads left
+	PlaylistChangePurchaseURL     = "purchase_url"     // the purchase link of the track changed
+)
+
+/*
+PlaylistChange is a single change to a track of a playlist
+*/
+type PlaylistChange struct {
+	Type     string
+	Track    streaming.SoundCloudTrack
+	OldValue string // Optional - only set for changed values
+	NewValue string // Optional - only set for changed values
+}
+
+/*
+PlaylistDiff is the result of comparing a stored playlist with its current tracks
+*/
+type PlaylistDiff struct {
+	Tracks  []streaming.SoundCloudTrack // every track of the playlist to save, tracks no longer in the playlist are flagged as removed
+	Changes []PlaylistChange
+}
+
+/*
+DiffPlaylistTracks compares the stored tracks of a playlist with the tracks currently in it
+
+Values only known locally, such as the local path, are kept from the stored tracks. A track which
+was removed and is back in the playlist is reported as added
+*/
+func DiffPlaylistTracks(stored []streaming.SoundCloudTrack, current []streaming.SoundCloudTrack) PlaylistDiff {
+
+	var diff PlaylistDiff
+
+	storedByID := make(map[int64]streaming.SoundCloudTrack, len(stored))
+	for _, t := range stored {
+		storedByID[t.ExternalID] = t
+	}
+
+	seen := make(map[int64]bool, len(current))
+
+	for _, t := range current {
+		if seen[t.ExternalID] {
+			continue
+		}
+		seen[t.ExternalID] = true
+
+		old, ok := storedByID[t.ExternalID]
+
+		switch {
+		case !ok || old.RemovedFromPlaylist:
+			diff.Changes = append(diff.Changes, PlaylistChange{Type: PlaylistChangeAdded, Track: t})
+		default:
+			if !old.HasDownloadsLeft && t.HasDownloadsLeft {
+				diff.Changes = append(diff.Changes, PlaylistChange{Type: PlaylistChangeDownloadEnabled, Track: t})
+			}
+			if old.PurchaseURL != t.PurchaseURL {
+				diff.Changes = append(diff.Changes, PlaylistChange{
+					Type:     PlaylistChangePurchaseURL,
+					Track:    t,
+					OldValue: old.PurchaseURL,
+					NewValue: t.PurchaseURL,
+				})
+			}
+		}
+
+		if ok {
+			t.LocalPath = old.LocalPath
+			t.LocalPathBroken = old.LocalPathBroken
+		}
+		t.RemovedFromPlaylist = false
+
+		diff.Tracks = append(diff.Tracks, t)
+	}
+
+	for _, t := range stored {
+		if seen[t.ExternalID] {
+			continue
+		}
+
+		if !t.RemovedFromPlaylist {
+			t.RemovedFromPlaylist = true
+			diff.Changes = append(diff.Changes, PlaylistChange{Type: PlaylistChangeRemoved, Track: t})
+		}
+
+		diff.Tracks = append(diff.Tracks, t)
+	}
+
+	return diff
+}
@@ -0,0 +1,69 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/billiem/seren-management/pkg/streaming"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffPlaylistTracks(t *testing.T) {
+	stored := []streaming.SoundCloudTrack{
+		{ExternalID: 1, Name: "unchanged", LocalPath: "/music/unchanged.wav"},
+		{ExternalID: 2, Name: "removed"},
+		{ExternalID: 3, Name: "downloads enabled"},
+		{ExternalID: 4, Name: "purchase link", PurchaseURL: "https://bandcamp.com/old"},
+		{ExternalID: 5, Name: "re-added", RemovedFromPlaylist: true},
+		{ExternalID: 6, Name: "already removed", RemovedFromPlaylist: true},
+	}
+
+	current := []streaming.SoundCloudTrack{
+		{ExternalID: 1, Name: "unchanged"},
+		{ExternalID: 3, Name: "downloads enabled", HasDownloadsLeft: true},
+		{ExternalID: 4, Name: "purchase link", PurchaseURL: "https://bandcamp.com/new"},
+		{ExternalID: 5, Name: "re-added"},
+		{ExternalID: 7, Name: "new"},
+		{ExternalID: 7, Name: "new"},
+	}
+
+	got := internal.DiffPlaylistTracks(stored, current)
+
+	wantTracks := []streaming.SoundCloudTrack{
+		{ExternalID: 1, Name: "unchanged", LocalPath: "/music/unchanged.wav"},
+		{ExternalID: 3, Name: "downloads enabled", HasDownloadsLeft: true},
+		{ExternalID: 4, Name: "purchase link", PurchaseURL: "https://bandcamp.com/new"},
+		{ExternalID: 5, Name: "re-added"},
+		{ExternalID: 7, Name: "new"},
+		{ExternalID: 2, Name: "removed", RemovedFromPlaylist: true},
+		{ExternalID: 6, Name: "already removed", RemovedFromPlaylist: true},
+	}
+
+	if diff := cmp.Diff(wantTracks, got.Tracks); diff != "" {
+		t.Errorf("DiffPlaylistTracks() tracks mismatch (-want +got):\n%s", diff)
+	}
+
+	type change struct {
+		Type     string
+		ID       int64
+		OldValue string
+		NewValue string
+	}
+
+	var gotChanges []change
+	for _, c := range got.Changes {
+		gotChanges = append(gotChanges, change{c.Type, c.Track.ExternalID, c.OldValue, c.NewValue})
+	}
+
+	wantChanges := []change{
+		{Type: internal.PlaylistChangeDownloadEnabled, ID: 3},
+		{Type: internal.PlaylistChangePurchaseURL, ID: 4, OldValue: "https://bandcamp.com/old", NewValue: "https://bandcamp.com/new"},
+		{Type: internal.PlaylistChangeAdded, ID: 5},
+		{Type: internal.PlaylistChangeAdded, ID: 7},
+		{Type: internal.PlaylistChangeRemoved, ID: 2},
+	}
+
+	if diff := cmp.Diff(wantChanges, gotChanges); diff != "" {
+		t.Errorf("DiffPlaylistTracks() changes mismatch (-want +got):\n%s", diff)
+	}
+}
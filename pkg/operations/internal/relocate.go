@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides functionality for finding the new location of files which have been moved,
+by searching the local file index
+*/
+
+/*
+RelocationTarget contains everything known about a file which can no longer be found
+*/
+type RelocationTarget struct {
+	Path          string
+	Size          int64  // Optional - 0 if unknown
+	SizeTolerance int64  // Optional - max difference in bytes for sizes to be considered equal
+	ContentHash   string // Optional - empty if unknown
+}
+
+/*
+RelocationCandidate is a file from the local file index which may be the target
+*/
+type RelocationCandidate struct {
+	Path       string
+	Confidence float64 // 0-1
+	Reason     string
+}
+
+/*
+RelocationIndex provides quick lookups of the local file index by hash and file name
+*/
+type RelocationIndex struct {
+	byHash map[string][]data.LocalFile
+	byName map[string][]data.LocalFile // lowercased file name, including extension
+	byStem map[string][]data.LocalFile // lowercased file name, without extension
+}
+
+func BuildRelocationIndex(files []data.LocalFile) RelocationIndex {
+	idx := RelocationIndex{
+		byHash: make(map[string][]data.LocalFile),
+		byName: make(map[string][]data.LocalFile),
+		byStem: make(map[string][]data.LocalFile),
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path.String < files[j].Path.String
+	})
+
+	for _, f := range files {
+		if !f.Path.Valid || f.Missing.Bool {
+			continue
+		}
+		if f.ContentHash.String != "" {
+			idx.byHash[f.ContentHash.String] = append(idx.byHash[f.ContentHash.String], f)
+		}
+		name := strings.ToLower(filepath.Base(f.Path.String))
+		idx.byName[name] = append(idx.byName[name], f)
+		stem := helpers.RemoveFileExtension(name)
+		idx.byStem[stem] = append(idx.byStem[stem], f)
+	}
+
+	return idx
+}
+
+/*
+Match finds the most likely new location of the target, in order of confidence we match on:
+
+  - identical contents
+  - the same file name and size
+  - the same file name
+  - the same file name with a different extension (i.e. converted to mp3)
+
+Confidence is reduced when more than one file matches equally well, returns false
+if no candidate was found
+*/
+func (idx RelocationIndex) Match(target RelocationTarget) (RelocationCandidate, bool) {
+
+	if target.ContentHash != "" {
+		if files := idx.excludeTarget(idx.byHash[target.ContentHash], target); len(files) > 0 {
+			return RelocationCandidate{
+				Path:       files[0].Path.String,
+				Confidence: 1,
+				Reason:     "identical contents",
+			}, true
+		}
+	}
+
+	name := strings.ToLower(filepath.Base(target.Path))
+	sameName := idx.excludeTarget(idx.byName[name], target)
+
+	if target.Size > 0 {
+		var sameSize []data.LocalFile
+		for _, f := range sameName {
+			if abs(f.Size.Int64-target.Size) <= target.SizeTolerance {
+				sameSize = append(sameSize, f)
+			}
+		}
+
+		if len(sameSize) > 0 {
+			return RelocationCandidate{
+				Path:       sameSize[0].Path.String,
+				Confidence: 0.9 / float64(len(sameSize)),
+				Reason:     "same file name and size",
+			}, true
+		}
+	}
+
+	if len(sameName) > 0 {
+		return RelocationCandidate{
+			Path:       sameName[0].Path.String,
+			Confidence: 0.7 / float64(len(sameName)),
+			Reason:     "same file name",
+		}, true
+	}
+
+	stem := helpers.RemoveFileExtension(name)
+	sameStem := idx.excludeTarget(idx.byStem[stem], target)
+
+	if len(sameStem) > 0 {
+		return RelocationCandidate{
+			Path:       sameStem[0].Path.String,
+			Confidence: 0.5 / float64(len(sameStem)),
+			Reason:     "same file name, different format",
+		}, true
+	}
+
+	return RelocationCandidate{}, false
+}
+
+/*
+excludeTarget removes the target itself from a list of files, which may still be in the
+index if it was indexed before going missing
+*/
+func (idx RelocationIndex) excludeTarget(files []data.LocalFile, target RelocationTarget) []data.LocalFile {
+	var out []data.LocalFile
+	for _, f := range files {
+		if f.Path.String != target.Path {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func abs(i int64) int64 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,84 @@
+package internal_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/google/go-cmp/cmp"
+)
+
+func sizedLocalFile(path string, hash string, size int64) data.LocalFile {
+	f := localFile(path, hash, "", "")
+	f.Size = sql.NullInt64{Valid: size != 0, Int64: size}
+	return f
+}
+
+func TestRelocationIndexMatch(t *testing.T) {
+	idx := internal.BuildRelocationIndex([]data.LocalFile{
+		sizedLocalFile("/music/new/hashed.wav", "aaa", 100),
+		sizedLocalFile("/music/new/sized.wav", "bbb", 2000),
+		sizedLocalFile("/music/other/sized.wav", "ccc", 5000),
+		sizedLocalFile("/music/a/twice.wav", "ddd", 100),
+		sizedLocalFile("/music/b/twice.wav", "eee", 200),
+		sizedLocalFile("/music/new/Converted.mp3", "fff", 100),
+		sizedLocalFile("/music/old/self.wav", "ggg", 100),
+	})
+
+	tests := []struct {
+		name   string
+		target internal.RelocationTarget
+		want   internal.RelocationCandidate
+		wantOk bool
+	}{
+		{
+			name:   "identical contents",
+			target: internal.RelocationTarget{Path: "/music/old/renamed.wav", ContentHash: "aaa"},
+			want:   internal.RelocationCandidate{Path: "/music/new/hashed.wav", Confidence: 1, Reason: "identical contents"},
+			wantOk: true,
+		},
+		{
+			name:   "same name and size within tolerance",
+			target: internal.RelocationTarget{Path: "/music/old/sized.wav", Size: 2048, SizeTolerance: 1024},
+			want:   internal.RelocationCandidate{Path: "/music/new/sized.wav", Confidence: 0.9, Reason: "same file name and size"},
+			wantOk: true,
+		},
+		{
+			name:   "ambiguous name",
+			target: internal.RelocationTarget{Path: "/music/old/twice.wav"},
+			want:   internal.RelocationCandidate{Path: "/music/a/twice.wav", Confidence: 0.35, Reason: "same file name"},
+			wantOk: true,
+		},
+		{
+			name:   "different format",
+			target: internal.RelocationTarget{Path: "/music/old/converted.wav"},
+			want:   internal.RelocationCandidate{Path: "/music/new/Converted.mp3", Confidence: 0.5, Reason: "same file name, different format"},
+			wantOk: true,
+		},
+		{
+			name:   "target is ignored",
+			target: internal.RelocationTarget{Path: "/music/old/self.wav", ContentHash: "ggg"},
+			wantOk: false,
+		},
+		{
+			name:   "no match",
+			target: internal.RelocationTarget{Path: "/music/old/unknown.wav"},
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := idx.Match(tt.target)
+
+			if ok != tt.wantOk {
+				t.Fatalf("Match() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Match() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides parsing of the searches typed into the CLI and GUI
+*/
+
+/*
+SearchQuery is a parsed search, Match is an FTS query for the text of the search, the bpm, key and
+source qualifiers are returned separately as the search index can't match them itself
+*/
+type SearchQuery struct {
+	Match  string
+	Source string
+	HasBpm bool
+	MinBpm float64 // inclusive
+	MaxBpm float64 // exclusive
+	Key    string
+}
+
+// searchFieldColumns maps the qualifiers which can be used in a search to the columns of the index
+var searchFieldColumns = map[string]string{
+	"title":    "title",
+	"name":     "title",
+	"artist":   "artist",
+	"label":    "label",
+	"genre":    "genre",
+	"tag":      "tags",
+	"tags":     "tags",
+	"comment":  "comments",
+	"comments": "comments",
+}
+
+/*
+ParseSearchQuery parses a search made of words and qualified words, i.e.
+
+	daft punk label:"virgin records" bpm:120-128 key:8A
+
+Words match the start of a word in any field, a qualifier only matches in its field. bpm matches bpms
+which round to the number or range given, key matches the key exactly and source is one of
+'soundcloud', 'local' or 'collection'
+*/
+func ParseSearchQuery(q string) (SearchQuery, error) {
+
+	var sq SearchQuery
+	var terms []string
+
+	for _, token := range splitSearchQuery(q) {
+
+		field, value, found := strings.Cut(token, ":")
+		field = strings.ToLower(field)
+
+		if !found {
+			terms = append(terms, searchTerms("", token)...)
+			continue
+		}
+
+		value = strings.Trim(value, `"`)
+
+		switch field {
+		case "bpm":
+			minBpm, maxBpm, err := parseSearchBpm(value)
+
+			if err != nil {
+				return SearchQuery{}, err
+			}
+
+			sq.HasBpm = true
+			sq.MinBpm = minBpm
+			sq.MaxBpm = maxBpm
+		case "key":
+			sq.Key = strings.TrimSpace(value)
+		case "source":
+			sq.Source = strings.ToLower(strings.TrimSpace(value))
+		default:
+			column, ok := searchFieldColumns[field]
+
+			if !ok {
+				// not a qualifier, i.e. a title with a colon in it
+				terms = append(terms, searchTerms("", token)...)
+				continue
+			}
+
+			terms = append(terms, searchTerms(column, value)...)
+		}
+	}
+
+	sq.Match = strings.Join(terms, " ")
+
+	if sq.Match == "" && !sq.HasBpm && sq.Key == "" && sq.Source == "" {
+		return SearchQuery{}, helpers.ErrMissingSearchQuery
+	}
+
+	return sq, nil
+}
+
+/*
+splitSearchQuery splits the search on whitespace, keeping text in double quotes together
+*/
+func splitSearchQuery(q string) []string {
+
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range q {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+/*
+searchTerms returns the prefix terms matching each word in the value, in the column if one is given
+
+Words are lower cased and split on anything which isn't a letter or number, so nothing the user types
+can be read as FTS syntax
+*/
+func searchTerms(column string, value string) []string {
+
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	terms := make([]string, len(words))
+
+	for i, w := range words {
+		if column != "" {
+			terms[i] = column + ":" + w + "*"
+		} else {
+			terms[i] = w + "*"
+		}
+	}
+
+	return terms
+}
+
+/*
+parseSearchBpm parses a bpm, i.e. 128, or a range of bpms, i.e. 120-128, returning the bpms which round
+into it
+*/
+func parseSearchBpm(value string) (float64, float64, error) {
+
+	from, to, isRange := strings.Cut(value, "-")
+
+	if !isRange {
+		to = from
+	}
+
+	minBpm, err := strconv.ParseFloat(strings.TrimSpace(from), 64)
+
+	if err != nil {
+		return 0, 0, helpers.ErrInvalidSearchBpm
+	}
+
+	maxBpm, err := strconv.ParseFloat(strings.TrimSpace(to), 64)
+
+	if err != nil || maxBpm < minBpm {
+		return 0, 0, helpers.ErrInvalidSearchBpm
+	}
+
+	return minBpm - 0.5, maxBpm + 0.5, nil
+}
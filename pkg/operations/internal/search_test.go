@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		q       string
+		want    SearchQuery
+		wantErr error
+	}{
+		{
+			name: "words",
+			q:    "Daft  Punk",
+			want: SearchQuery{Match: "daft* punk*"},
+		},
+		{
+			name: "qualified words",
+			q:    `artist:bicep label:"Ninja Tune" Tags:dub`,
+			want: SearchQuery{Match: "artist:bicep* label:ninja* label:tune* tags:dub*"},
+		},
+		{
+			name: "fts syntax is treated as words",
+			q:    `AND "rolling" NEAR(hills) -edit`,
+			want: SearchQuery{Match: "and* rolling* near* hills* edit*"},
+		},
+		{
+			name: "unknown qualifier",
+			q:    "remix:2024",
+			want: SearchQuery{Match: "remix* 2024*"},
+		},
+		{
+			name: "bpm range, key and source",
+			q:    "house bpm:120-128 key:8A source:Collection",
+			want: SearchQuery{Match: "house*", HasBpm: true, MinBpm: 119.5, MaxBpm: 128.5, Key: "8A", Source: "collection"},
+		},
+		{
+			name: "single bpm",
+			q:    "bpm:128",
+			want: SearchQuery{HasBpm: true, MinBpm: 127.5, MaxBpm: 128.5},
+		},
+		{
+			name:    "invalid bpm",
+			q:       "bpm:fast",
+			wantErr: helpers.ErrInvalidSearchBpm,
+		},
+		{
+			name:    "backwards bpm range",
+			q:       "bpm:128-120",
+			wantErr: helpers.ErrInvalidSearchBpm,
+		},
+		{
+			name:    "nothing to search",
+			q:       ` - "" `,
+			wantErr: helpers.ErrMissingSearchQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSearchQuery(tt.q)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseSearchQuery() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseSearchQuery() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
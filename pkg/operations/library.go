@@ -0,0 +1,237 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+IndexLocalFiles walks a directory and stores information about each audio file inside of it
+(size, modification time, content hash, tags and optionally an acoustic fingerprint) in the
+local file index
+
+Files which haven't changed since they were last indexed are not processed again, and
+previously indexed files which no longer exist are marked as missing
+*/
+func (e *OpEnv) IndexLocalFiles(ctx context.Context, opts IndexLocalFilesOpts) {
+
+	opts = opts.build(e.Config)
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	e.Logger.Info("Finding files to index")
+	filePaths, err := helpers.GetFilesInDir(opts.InDirPath, true)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error getting file paths",
+				"There was an error getting the paths of the files to index",
+			),
+		))
+		return
+	}
+
+	var audioPaths []string
+	for _, path := range filePaths {
+		if helpers.IsExtensionInArray(path, helpers.GetAudioExtensions()) {
+			audioPaths = append(audioPaths, filepath.ToSlash(path))
+		}
+	}
+
+	e.Logger.Infof("Found %v files to index", len(audioPaths))
+
+	existingFiles, err := e.SerenDB.ListLocalFiles(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error listing local files in db",
+				"There was an error getting the existing local file index from the database",
+			),
+		))
+		return
+	}
+
+	existing := make(map[string]data.LocalFile, len(existingFiles))
+	for _, f := range existingFiles {
+		existing[f.Path.String] = f
+	}
+
+	if len(audioPaths) > 0 {
+		e.BuildProgressTracker(len(audioPaths), 1)
+	}
+
+	seen := make(map[string]bool, len(audioPaths))
+	var toUpsert []data.LocalFile
+
+	for i, path := range audioPaths {
+		if ctx.Err() != nil {
+			e.Logger.Info("Operation cancelled, stopping")
+			break
+		}
+
+		seen[path] = true
+
+		f, changed, err := e.indexLocalFile(path, existing[path], opts.Fingerprint)
+		e.ProcessComplete(i)
+
+		if err != nil {
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fctx.With(fctx.WithMeta(ctx, "path", path)),
+				fmsg.With("error indexing file"),
+			))
+			continue
+		}
+
+		if changed {
+			toUpsert = append(toUpsert, f)
+		}
+	}
+
+	err = e.SerenDB.TxUpsertLocalFiles(ctx, toUpsert)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error saving local files to db",
+				"There was an error saving the local file index to the database",
+			),
+		))
+		return
+	}
+
+	// files which were previously indexed in this directory, but no longer exist
+	var missing int
+
+	if ctx.Err() == nil {
+		dirPrefix := strings.TrimSuffix(filepath.ToSlash(opts.InDirPath), "/") + "/"
+
+		for path := range existing {
+			if seen[path] || !strings.HasPrefix(path, dirPrefix) {
+				continue
+			}
+
+			err := e.SerenDB.SetLocalFileMissing(ctx, data.SetLocalFileMissingParams{
+				Missing: sql.NullBool{Valid: true, Bool: true},
+				Path:    sql.NullString{Valid: true, String: path},
+			})
+
+			if err != nil {
+				e.Logger.NonFatalError(fault.Wrap(
+					err,
+					fmsg.With("error marking local file as missing"),
+				))
+				continue
+			}
+
+			missing++
+		}
+	}
+
+	e.Logger.Infof("Indexed %v new or changed files, %v files missing", len(toUpsert), missing)
+
+	e.FinishSuccess(map[string]any{
+		"indexed": len(toUpsert),
+		"missing": missing,
+	})
+}
+
+/*
+indexLocalFile builds the index entry for a single file
+
+Returns false if the file hasn't changed since it was last indexed. Files
+previously marked as missing are always re-indexed, so they return to the index
+*/
+func (e *OpEnv) indexLocalFile(path string, prev data.LocalFile, fingerprint bool) (data.LocalFile, bool, error) {
+
+	fi, err := os.Stat(path)
+
+	if err != nil {
+		return data.LocalFile{}, false, err
+	}
+
+	unchanged := prev.Path.Valid &&
+		!prev.Missing.Bool &&
+		prev.Size.Int64 == fi.Size() &&
+		prev.ModifiedAt.Time.Unix() == fi.ModTime().Unix() &&
+		(!fingerprint || prev.Fingerprint.String != "")
+
+	if unchanged {
+		return prev, false, nil
+	}
+
+	e.Logger.Debugf("Indexing %s", path)
+
+	hash, err := helpers.HashFile(path)
+
+	if err != nil {
+		return data.LocalFile{}, false, fault.Wrap(
+			err,
+			fmsg.With("error hashing file"),
+		)
+	}
+
+	f := data.LocalFile{
+		Path:        sql.NullString{Valid: true, String: path},
+		Size:        sql.NullInt64{Valid: true, Int64: fi.Size()},
+		ModifiedAt:  sql.NullTime{Valid: true, Time: fi.ModTime()},
+		ContentHash: sql.NullString{Valid: true, String: hash},
+		Missing:     sql.NullBool{Valid: true, Bool: false},
+	}
+
+	// files without readable tags are still indexed, they can be matched by hash
+	tags, err := helpers.ReadAudioTags(path)
+
+	if err != nil {
+		e.Logger.NonFatalError(fault.Wrap(
+			err,
+			fmsg.With("error reading tags"),
+		))
+	} else {
+		f.Title = sql.NullString{Valid: true, String: tags.Title}
+		f.Artist = sql.NullString{Valid: true, String: tags.Artist}
+		f.Album = sql.NullString{Valid: true, String: tags.Album}
+		f.Genre = sql.NullString{Valid: true, String: tags.Genre}
+		f.Duration = sql.NullFloat64{Valid: true, Float64: tags.Duration}
+	}
+
+	if fingerprint {
+		fp, err := helpers.CalcFingerprint(path)
+
+		if err != nil {
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fmsg.With("error calculating fingerprint"),
+			))
+		} else {
+			f.Fingerprint = sql.NullString{Valid: true, String: fp.String()}
+		}
+	}
+
+	return f, true, nil
+}
@@ -17,9 +17,11 @@ TODO: handle all these with operation handlers/ move to subfolder
 
 func (e *OpEnv) CheckLocalPaths() {
 
+	ctx := context.Background()
+
 	var brokenPathChanged []streaming.SoundCloudTrack
 
-	tracks, err := e.SerenDB.ListSoundCloudTracksHasLocalPath(context.Background())
+	tracks, err := e.SerenDB.ListSoundCloudTracksHasLocalPath(ctx)
 	if err != nil {
 		e.Logger.Error(fault.Flatten(fault.Wrap(
 			err,
@@ -65,7 +67,7 @@ func (e *OpEnv) CheckLocalPaths() {
 		dataT[i] = t.ToDB()
 	}
 
-	err = e.SerenDB.TxUpsertSoundCloudTracks(dataT)
+	err = e.SerenDB.TxSetSoundCloudTrackLocalPaths(ctx, dataT)
 
 	if err != nil {
 		e.Logger.NonFatalError(fault.Wrap(
@@ -78,10 +80,6 @@ func (e *OpEnv) CheckLocalPaths() {
 	}
 }
 
-func (e *OpEnv) IndexCollections() {
-
-}
-
 func (e *OpEnv) IndexLocalFolders() {
 
 }
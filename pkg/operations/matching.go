@@ -0,0 +1,225 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/collection"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+const (
+	MatchSourceLocal   = "local"   // a file in the local file index
+	MatchSourceTraktor = "traktor" // an entry in the Traktor collection
+)
+
+// DefaultMinMatchConfidence is the default min confidence for a track to be considered matched
+const DefaultMinMatchConfidence = 0.8
+
+/*
+MatchSoundCloudTracks links each SoundCloud track to a file in the local file index or an entry
+in the collection, by comparing artist, title, purchase title and duration
+
+Tracks with a working local path are always matched to it. The link and its confidence are
+stored in the database, and any link for a track which no longer matches is removed
+
+The number of matched tracks is returned under the 'matched' key, and the tracks left unmatched
+under the 'unmatched' key as a []streaming.SoundCloudTrack
+*/
+func (e *OpEnv) MatchSoundCloudTracks(ctx context.Context, opts MatchSoundCloudTracksOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	tracks, err := e.SerenDB.ListSoundCloudTracks(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error listing soundcloud tracks in db",
+				"There was an error getting your SoundCloud tracks from the database",
+			),
+		))
+		return
+	}
+
+	candidates, err := e.getMatchCandidates(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error getting match candidates",
+				"There was an error getting the tracks in your library to match against",
+			),
+		))
+		return
+	}
+
+	e.Logger.Infof("Matching %v SoundCloud tracks against %v library tracks", len(tracks), len(candidates))
+
+	matcher := internal.NewTrackMatcher(candidates)
+
+	if len(tracks) > 0 {
+		e.BuildProgressTracker(len(tracks), 1)
+	}
+
+	var matches []data.SoundcloudTrackMatch
+	var unmatchedIDs []int64
+	var unmatched []streaming.SoundCloudTrack
+
+	for i, track := range tracks {
+		if ctx.Err() != nil {
+			e.Logger.Info("Operation cancelled, stopping")
+			break
+		}
+
+		t := streaming.SoundCloudTrack{}
+		t.LoadFromDB(track)
+
+		m, ok := matchSoundCloudTrack(matcher, t)
+		e.ProcessComplete(i)
+
+		if !ok || m.Confidence < opts.MinConfidence {
+			unmatchedIDs = append(unmatchedIDs, track.ID)
+			if !t.RemovedFromPlaylist {
+				unmatched = append(unmatched, t)
+			}
+			continue
+		}
+
+		e.Logger.Debugf("Matched %s to %s (%.2f)", t.Name, m.Path, m.Confidence)
+
+		matches = append(matches, data.SoundcloudTrackMatch{
+			SoundcloudTrackID: sql.NullInt64{Valid: true, Int64: track.ID},
+			Source:            sql.NullString{Valid: true, String: m.Source},
+			Path:              sql.NullString{Valid: true, String: m.Path},
+			Confidence:        sql.NullFloat64{Valid: true, Float64: m.Confidence},
+			Reason:            sql.NullString{Valid: true, String: m.Reason},
+		})
+	}
+
+	err = e.SerenDB.TxSetSoundCloudTrackMatches(ctx, matches, unmatchedIDs)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error saving track matches to db",
+				"There was an error saving the matched tracks to the database",
+			),
+		))
+		return
+	}
+
+	e.Logger.Infof("Matched %v tracks, %v tracks unmatched", len(matches), len(unmatched))
+
+	e.FinishSuccess(map[string]any{
+		"matched":   len(matches),
+		"unmatched": unmatched,
+	})
+}
+
+/*
+matchSoundCloudTrack finds the best match for a SoundCloud track, SoundCloud track names are
+usually in the form 'Artist - Title', if not the publisher or uploader is used as the artist
+*/
+func matchSoundCloudTrack(matcher internal.TrackMatcher, t streaming.SoundCloudTrack) (internal.TrackMatch, bool) {
+
+	if t.LocalPath != "" && helpers.DoesFileExist(t.LocalPath) {
+		return internal.TrackMatch{
+			Source:     MatchSourceLocal,
+			Path:       t.LocalPath,
+			Confidence: 1,
+			Reason:     "downloaded",
+		}, true
+	}
+
+	artist, title := internal.SplitArtistTitle(t.Name)
+
+	if artist == "" {
+		artist = t.PublisherArtist
+	}
+	if artist == "" {
+		artist = t.SoundCloudUser
+	}
+
+	return matcher.Match(internal.MatchQuery{
+		Artist:        artist,
+		Title:         title,
+		PurchaseTitle: t.PurchaseTitle,
+		Duration:      t.Duration,
+	})
+}
+
+/*
+getMatchCandidates returns the files in the local file index which still exist, and the
+entries in the collection if a collection path is set in config
+*/
+func (e *OpEnv) getMatchCandidates(ctx context.Context) ([]internal.MatchCandidate, error) {
+
+	files, err := e.SerenDB.ListLocalFiles(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]internal.MatchCandidate, 0, len(files))
+
+	for _, f := range files {
+		// files no longer on disk can't be used as a track's local path
+		if f.Missing.Bool {
+			continue
+		}
+
+		candidates = append(candidates, internal.NewMatchCandidate(
+			MatchSourceLocal,
+			f.Path.String,
+			f.Artist.String,
+			f.Title.String,
+			f.Duration.Float64,
+		))
+	}
+
+	if e.Config.TraktorCollectionPath == "" {
+		return candidates, nil
+	}
+
+	c := collection.ReadTraktorOpts{}.Build(e.Config)
+
+	tracks, err := c.ListTracks()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tracks {
+		candidates = append(candidates, internal.NewMatchCandidate(
+			MatchSourceTraktor,
+			t.Path,
+			t.Artist,
+			t.Title,
+			t.Duration,
+		))
+	}
+
+	return candidates, nil
+}
@@ -57,6 +57,14 @@ func (e *Mp3Env) ConvertMp3Tracks(ctx context.Context, tracks []ConvertTrack) {
 	}
 }
 
+/*
+ConvertMp3Track converts a single track to mp3 without reporting progress, for use as part of
+another operation
+*/
+func (e *Mp3Env) ConvertMp3Track(track ConvertTrack) (ConvertTrack, error) {
+	return e.convertTrack(track)
+}
+
 func (e *Mp3Env) convertTrack(track ConvertTrack) (ConvertTrack, error) {
 
 	// create dir for new file if it doesn't exist
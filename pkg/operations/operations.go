@@ -3,8 +3,6 @@ package operations
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"os"
 
 	"github.com/Southclaws/fault"
 	"github.com/Southclaws/fault/fmsg"
@@ -248,10 +246,26 @@ func (e *OpEnv) ReadCollection(ctx context.Context, opts collection.ReadCollecti
 }
 
 /*
-GetPlaylist gets a playlist for a given platform and stores it in the database
+GetSoundCloudPlaylist gets a playlist through the SoundCloud Source and stores it in the database, p is
+called with the playlist before returning
 */
+func (e *OpEnv) GetSoundCloudPlaylist(ctx context.Context, opts GetSoundCloudPlaylistOpts, p func(streaming.SourcePlaylist, error)) {
 
-func (e *OpEnv) GetSoundCloudPlaylist(ctx context.Context, opts GetSoundCloudPlaylistOpts, p func(streaming.SoundCloudPlaylist, error)) {
+	_, err := opts.Check()
+
+	if err != nil {
+		p(
+			streaming.SourcePlaylist{},
+			fault.Wrap(
+				err,
+				fmsg.WithDesc(
+					"error checking opts",
+					"The URL must be a SoundCloud playlist, track, or the likes, tracks or reposts of a user",
+				),
+			),
+		)
+		return
+	}
 
 	if !opts.Refresh {
 		// check if playlist with same url already exists in database
@@ -262,7 +276,7 @@ func (e *OpEnv) GetSoundCloudPlaylist(ctx context.Context, opts GetSoundCloudPla
 
 		if err != nil {
 			p(
-				streaming.SoundCloudPlaylist{},
+				streaming.SourcePlaylist{},
 				fault.Wrap(
 					err,
 					fmsg.WithDesc(
@@ -276,7 +290,7 @@ func (e *OpEnv) GetSoundCloudPlaylist(ctx context.Context, opts GetSoundCloudPla
 
 		if numPlaylists > 0 {
 			p(
-				streaming.SoundCloudPlaylist{},
+				streaming.SourcePlaylist{},
 				fault.Wrap(
 					helpers.ErrPlaylistAlreadyExists,
 					fmsg.WithDesc(
@@ -289,15 +303,15 @@ func (e *OpEnv) GetSoundCloudPlaylist(ctx context.Context, opts GetSoundCloudPla
 		}
 	}
 
-	clientID, err := streaming.GetAndSetSoundCloudClientID(e.Config)
+	s, err := e.streamingSource(ctx, streaming.PlatformSoundCloud)
 
 	if err != nil {
 		p(
-			streaming.SoundCloudPlaylist{},
+			streaming.SourcePlaylist{},
 			fault.Wrap(
 				err,
 				fmsg.WithDesc(
-					"error getting/ setting SoundCloud client ID",
+					"error getting SoundCloud client ID",
 					"There was an error getting or setting the SoundCloud client ID",
 				),
 			),
@@ -305,16 +319,12 @@ func (e *OpEnv) GetSoundCloudPlaylist(ctx context.Context, opts GetSoundCloudPla
 		return
 	}
 
-	s := streaming.SoundCloud{
-		ClientID: clientID,
-	}
-
 	// get playlist from SoundCloud
-	downloadedPlaylist, err := s.GetSoundCloudPlaylist(ctx, opts.PlaylistURL)
+	downloadedPlaylist, err := getSoundCloudPlaylist(ctx, s, opts.PlaylistURL)
 
 	if err != nil {
 		p(
-			streaming.SoundCloudPlaylist{},
+			streaming.SourcePlaylist{},
 			fault.Wrap(
 				err,
 				fmsg.WithDesc(
@@ -329,12 +339,15 @@ func (e *OpEnv) GetSoundCloudPlaylist(ctx context.Context, opts GetSoundCloudPla
 		// check if playlist with same external id already exists in database
 		numPlaylists, err := e.SerenDB.GetNumSoundCloudPlaylistByExternalID(
 			context.Background(),
-			sql.NullInt64{Valid: true, Int64: downloadedPlaylist.ExternalID},
+			data.GetNumSoundCloudPlaylistByExternalIDParams{
+				ExternalID: sql.NullInt64{Valid: true, Int64: downloadedPlaylist.ExternalID},
+				SourceType: string(downloadedPlaylist.SourceType),
+			},
 		)
 
 		if err != nil {
 			p(
-				streaming.SoundCloudPlaylist{},
+				streaming.SourcePlaylist{},
 				fault.Wrap(
 					err,
 					fmsg.With("error checking if playlist already exists in database by external id"),
@@ -345,7 +358,7 @@ func (e *OpEnv) GetSoundCloudPlaylist(ctx context.Context, opts GetSoundCloudPla
 
 		if numPlaylists > 0 {
 			p(
-				streaming.SoundCloudPlaylist{},
+				streaming.SourcePlaylist{},
 				fault.Wrap(
 					helpers.ErrPlaylistAlreadyExists,
 					fmsg.With("playlist with same external id already exists in db"),
@@ -360,20 +373,20 @@ func (e *OpEnv) GetSoundCloudPlaylist(ctx context.Context, opts GetSoundCloudPla
 	dataP, dataT := downloadedPlaylist.ToDB()
 
 	// save playlist to database
-	err = e.SerenDB.TxUpsertSoundCloudPlaylistAndTracks(dataP, dataT)
+	err = e.SerenDB.TxUpsertSoundCloudPlaylistAndTracks(ctx, dataP, dataT)
 
 	if err != nil {
 
 		e.Logger.NonFatalError(err)
 
 		p(
-			streaming.SoundCloudPlaylist{},
+			streaming.SourcePlaylist{},
 			fault.Wrap(err, fmsg.With("error saving playlist to database")),
 		)
 		return
 	}
 
-	p(downloadedPlaylist, nil)
+	p(downloadedPlaylist.ToSourcePlaylist(), nil)
 }
 
 /*
@@ -382,34 +395,29 @@ DownloadSoundCloudFile downloads a file straight from SoundCloud
 # This only works for files with download enabled
 
 playlistName is optional and is used to create a folder for the playlist within the download directory
+
+Any post download hooks are run on the track once it has been downloaded, a failing hook doesn't
+fail the download
 */
-func (e *OpEnv) DownloadSoundCloudFile(track streaming.SoundCloudTrack, playlistName string) {
+func (e *OpEnv) DownloadSoundCloudFile(ctx context.Context, track streaming.SoundCloudTrack, playlistName string) {
 
-	clientID, err := streaming.GetAndSetSoundCloudClientID(e.Config)
+	s, err := e.streamingSource(ctx, streaming.PlatformSoundCloud)
 
 	if err != nil {
 		e.FinishError(
 			fault.Wrap(
 				err,
-				fmsg.With("error getting/ setting SoundCloud client ID"),
+				fmsg.With("error getting SoundCloud client ID"),
 			),
 		)
 		return
 	}
 
-	downloadDir := e.Config.DownloadDir
-
-	if playlistName != "" {
-		downloadDir = helpers.JoinFilepathToSlash(downloadDir, playlistName)
-	}
-
-	s := streaming.SoundCloud{
-		ClientID: clientID,
-	}
-
-	filePath, err := s.DownloadFile(
-		downloadDir,
-		track.ExternalID,
+	filePath, err := s.DownloadTrack(
+		ctx,
+		track.ToSourceTrack(),
+		e.playlistDownloadDir(playlistName),
+		helpers.CollisionSuffix,
 	)
 
 	if err != nil {
@@ -424,7 +432,13 @@ func (e *OpEnv) DownloadSoundCloudFile(track streaming.SoundCloudTrack, playlist
 
 	track.LocalPath = filePath
 
-	err = e.SerenDB.TxUpsertSoundCloudTracks([]data.SoundcloudTrack{track.ToDB()})
+	err = e.runPostDownloadHooks(ctx, &track)
+
+	if err != nil {
+		e.Logger.NonFatalError(err)
+	}
+
+	err = e.SerenDB.TxSaveSoundCloudTracks(ctx, []data.SoundcloudTrack{track.ToDB()})
 	if err != nil {
 		e.FinishError(
 			fault.Wrap(
@@ -435,55 +449,11 @@ func (e *OpEnv) DownloadSoundCloudFile(track streaming.SoundCloudTrack, playlist
 		return
 	}
 
+	e.separateQueuedStems(ctx)
+
 	e.FinishSuccess(
 		map[string]any{
-			"filepath": filePath,
+			"filepath": track.LocalPath,
 		},
 	)
 }
-
-/*
-Flatten directory iteraves through a directory recursively and moves all files to the root of the directory
-*/
-func (e *OpEnv) FlattenDirectory(dirPath string) {
-	// Get the list of files in the specified directory
-	filePaths, err := helpers.GetFilesInDir(dirPath, true)
-
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-
-	// Iterate through each file and move it to the root of the directory
-	// TODO: move move file to path to a helper func
-	for _, filePath := range filePaths {
-
-		fileName, err := helpers.GetFileNameFromFilePath(filePath)
-
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		fileExt, err := helpers.GetFileExtensionFromFilePath(filePath)
-
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		newPath := helpers.JoinFilepathToSlash(dirPath, fileName+fileExt)
-
-		if filePath != newPath {
-			err = os.Rename(filePath, newPath)
-
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-		}
-	}
-
-	// TODO: remove directories in the specified directory...
-
-}
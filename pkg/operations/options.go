@@ -1,7 +1,13 @@
 package operations
 
 import (
+	"time"
+
+	"github.com/billiem/seren-management/pkg/data"
 	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/billiem/seren-management/pkg/purchases"
+	"github.com/billiem/seren-management/pkg/streaming"
 
 	stems "github.com/billiem/seren-management/pkg/operations/stems"
 )
@@ -97,7 +103,7 @@ func (p ConvertFolderMp3Opts) Check() (bool, error) {
 GetSoundCloudPlaylistOpts contains the options for GetSoundCloudPlaylist
 */
 type GetSoundCloudPlaylistOpts struct {
-	PlaylistURL string // Mandatory
+	PlaylistURL string // Mandatory - a playlist, a track, or a user's likes, tracks or reposts
 	Refresh     bool   // Optional
 }
 
@@ -108,8 +114,732 @@ func (p GetSoundCloudPlaylistOpts) Check() (bool, error) {
 	if p.PlaylistURL == "" {
 		return false, helpers.ErrMissingPlaylistURL
 	}
+	if !streaming.IsSoundCloudURL(p.PlaylistURL) {
+		return false, helpers.ErrNotSoundCloudURL
+	}
+	if _, err := streaming.ClassifySoundCloudURL(p.PlaylistURL); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+/*
+GetSpotifyPlaylistOpts contains the options for GetSpotifyPlaylist
+*/
+type GetSpotifyPlaylistOpts struct {
+	PlaylistURL string // Mandatory - a playlist link or URI
+	Refresh     bool   // Optional - update a playlist which has already been imported
+}
+
+/*
+check checks the options for the GetSpotifyPlaylist operation
+*/
+func (p GetSpotifyPlaylistOpts) Check() (bool, error) {
+	if p.PlaylistURL == "" {
+		return false, helpers.ErrMissingPlaylistURL
+	}
+	if !streaming.IsSpotifyURL(p.PlaylistURL) {
+		return false, helpers.ErrNotSpotifyURL
+	}
+	if _, err := streaming.ParseSpotifyPlaylistID(p.PlaylistURL); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+/*
+ImportPlaylistOpts contains the options for ImportPlaylist
+*/
+type ImportPlaylistOpts struct {
+	PlaylistURL string // Mandatory - a playlist from any supported platform
+	Refresh     bool   // Optional - update a playlist which has already been imported
+}
+
+/*
+check checks the options for the ImportPlaylist operation
+*/
+func (p ImportPlaylistOpts) Check() (bool, error) {
+	if p.PlaylistURL == "" {
+		return false, helpers.ErrMissingPlaylistURL
+	}
+
+	ps, err := platformSourceForURL(p.PlaylistURL)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := ps.urls.ResolveURL(p.PlaylistURL); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+/*
+ListPlaylistsOpts contains the options for ListPlaylists
+*/
+type ListPlaylistsOpts struct {
+	Platform streaming.Platform // Optional - only list playlists from this platform, lists every playlist if empty
+}
+
+/*
+check checks the options for the ListPlaylists operation
+*/
+func (p ListPlaylistsOpts) Check() (bool, error) {
+	if p.Platform != "" && !p.Platform.Check() {
+		return false, helpers.ErrInvalidPlatform
+	}
+
+	return true, nil
+}
+
+/*
+OrganiseLibraryOpts contains the options for OrganiseLibrary
+*/
+type OrganiseLibraryOpts struct {
+	InDirPath  string // Optional - if not provided, will use the download dir from config
+	OutDirPath string // Optional - if not provided, will use the base dir from config
+	Template   string // Optional - if not provided, will use the organise template from config
+}
+
+/*
+build fills any missing optional values from the config
+*/
+func (p OrganiseLibraryOpts) build(cfg helpers.Config) OrganiseLibraryOpts {
+	if p.InDirPath == "" {
+		p.InDirPath = cfg.DownloadDir
+	}
+	if p.OutDirPath == "" {
+		p.OutDirPath = cfg.BaseDir
+	}
+	if p.Template == "" {
+		p.Template = cfg.OrganiseTemplate
+	}
+	if p.Template == "" {
+		p.Template = helpers.DefaultOrganiseTemplate
+	}
+	return p
+}
+
+/*
+check checks the options for the OrganiseLibrary operation
+*/
+func (p OrganiseLibraryOpts) Check() (bool, error) {
+	if p.InDirPath == "" {
+		return false, helpers.ErrInDirPathRequired
+	}
+	if p.OutDirPath == "" {
+		return false, helpers.ErrOutDirPathRequired
+	}
+	if p.Template == "" {
+		return false, helpers.ErrMissingOrganiseTemplate
+	}
+
+	return true, nil
+}
+
+/*
+FlattenDirectoryOpts contains the options for FlattenDirectory
+*/
+type FlattenDirectoryOpts struct {
+	InDirPath string                    // Mandatory
+	Collision helpers.CollisionStrategy // Optional - if not provided, colliding files will be suffixed
+	DryRun    bool                      // Optional - if true, the plan is returned without moving any files
+	Plan      FlattenPlan               // Optional - the plan returned by a dry run, if not provided it is built from InDirPath
+}
+
+/*
+build fills any missing optional values
+*/
+func (p FlattenDirectoryOpts) build() FlattenDirectoryOpts {
+	if p.Collision == "" {
+		p.Collision = helpers.CollisionSuffix
+	}
+	return p
+}
+
+/*
+check checks the options for the FlattenDirectory operation
+*/
+func (p FlattenDirectoryOpts) Check() (bool, error) {
+	if p.InDirPath == "" {
+		return false, helpers.ErrInDirPathRequired
+	}
+	if !p.Collision.Check() {
+		return false, helpers.ErrInvalidCollisionStrategy
+	}
+
+	return true, nil
+}
+
+/*
+UndoFlattenDirectoryOpts contains the options for UndoFlattenDirectory
+*/
+type UndoFlattenDirectoryOpts struct {
+	JournalPath string // Optional - if not provided, the most recent journal will be used
+}
+
+/*
+check checks the options for the UndoFlattenDirectory operation
+*/
+func (p UndoFlattenDirectoryOpts) Check() (bool, error) {
+	return true, nil
+}
+
+/*
+IndexLocalFilesOpts contains the options for IndexLocalFiles
+*/
+type IndexLocalFilesOpts struct {
+	InDirPath   string // Optional - if not provided, will use the base dir from config
+	Fingerprint bool   // Optional - if true, acoustic fingerprints are calculated using fpcalc
+}
+
+/*
+build fills any missing optional values from the config
+*/
+func (p IndexLocalFilesOpts) build(cfg helpers.Config) IndexLocalFilesOpts {
+	if p.InDirPath == "" {
+		p.InDirPath = cfg.BaseDir
+	}
+	return p
+}
+
+/*
+check checks the options for the IndexLocalFiles operation
+*/
+func (p IndexLocalFilesOpts) Check() (bool, error) {
+	if p.InDirPath == "" {
+		return false, helpers.ErrInDirPathRequired
+	}
+
+	return true, nil
+}
+
+/*
+FindDuplicatesOpts contains the options for FindDuplicates
+*/
+type FindDuplicatesOpts struct {
+	Method    DuplicateMethod // Mandatory
+	Threshold float64         // Optional - min fingerprint similarity (0-1), defaults to DefaultFingerprintThreshold
+}
+
+/*
+build fills any missing optional values
+*/
+func (p FindDuplicatesOpts) build() FindDuplicatesOpts {
+	if p.Threshold == 0 {
+		p.Threshold = DefaultFingerprintThreshold
+	}
+	return p
+}
+
+/*
+check checks the options for the FindDuplicates operation
+*/
+func (p FindDuplicatesOpts) Check() (bool, error) {
+	if !p.Method.Check() {
+		return false, helpers.ErrInvalidDuplicateMethod
+	}
+
+	return true, nil
+}
+
+/*
+MergeDuplicatesOpts contains the options for MergeDuplicates
+*/
+type MergeDuplicatesOpts struct {
+	KeeperPath     string   // Mandatory
+	DuplicatePaths []string // Mandatory
+	DeleteFiles    bool     // Optional - if true, the duplicate files are deleted from disk
+}
+
+/*
+check checks the options for the MergeDuplicates operation
+*/
+func (p MergeDuplicatesOpts) Check() (bool, error) {
+	if p.KeeperPath == "" {
+		return false, helpers.ErrMissingKeeperPath
+	}
+	if len(p.DuplicatePaths) == 0 {
+		return false, helpers.ErrMissingDuplicatePaths
+	}
+	for _, d := range p.DuplicatePaths {
+		if d == p.KeeperPath {
+			return false, helpers.ErrKeeperIsDuplicate
+		}
+	}
+
+	return true, nil
+}
 
-	// perform some regex mapping to check the url is correct
+/*
+FindRelocationsOpts contains the options for FindRelocations
+*/
+type FindRelocationsOpts struct {
+	MinConfidence float64 // Optional - min confidence (0-1) for a match to be proposed, defaults to DefaultMinRelocationConfidence
+}
+
+/*
+build fills any missing optional values
+*/
+func (p FindRelocationsOpts) build() FindRelocationsOpts {
+	if p.MinConfidence == 0 {
+		p.MinConfidence = DefaultMinRelocationConfidence
+	}
+	return p
+}
+
+/*
+check checks the options for the FindRelocations operation
+*/
+func (p FindRelocationsOpts) Check() (bool, error) {
+	if p.MinConfidence < 0 || p.MinConfidence > 1 {
+		return false, helpers.ErrInvalidMinConfidence
+	}
+
+	return true, nil
+}
+
+/*
+ApplyRelocationsOpts contains the options for ApplyRelocations
+*/
+type ApplyRelocationsOpts struct {
+	Matches []RelocationMatch // Mandatory - the matches to apply, usually found with FindRelocations
+}
+
+/*
+check checks the options for the ApplyRelocations operation
+*/
+func (p ApplyRelocationsOpts) Check() (bool, error) {
+	if len(p.Matches) == 0 {
+		return false, helpers.ErrMissingRelocationMatches
+	}
+
+	return true, nil
+}
+
+/*
+MatchSoundCloudTracksOpts contains the options for MatchSoundCloudTracks
+*/
+type MatchSoundCloudTracksOpts struct {
+	MinConfidence float64 // Optional - min confidence (0-1) for a track to be matched, defaults to DefaultMinMatchConfidence
+}
+
+/*
+build fills any missing optional values
+*/
+func (p MatchSoundCloudTracksOpts) build() MatchSoundCloudTracksOpts {
+	if p.MinConfidence == 0 {
+		p.MinConfidence = DefaultMinMatchConfidence
+	}
+	return p
+}
+
+/*
+check checks the options for the MatchSoundCloudTracks operation
+*/
+func (p MatchSoundCloudTracksOpts) Check() (bool, error) {
+	if p.MinConfidence < 0 || p.MinConfidence > 1 {
+		return false, helpers.ErrInvalidMinConfidence
+	}
+
+	return true, nil
+}
+
+/*
+BackupDatabaseOpts contains the options for BackupDatabase
+*/
+type BackupDatabaseOpts struct {
+	Dir  string // Optional - directory to store backups in, defaults to 'backups' next to the database
+	Keep int    // Optional - number of backups to keep, defaults to DefaultBackupsKept
+}
+
+/*
+build fills any missing optional values
+*/
+func (p BackupDatabaseOpts) build(sDB *data.SerenDB) BackupDatabaseOpts {
+	if p.Dir == "" {
+		p.Dir = defaultBackupDir(sDB)
+	}
+	if p.Keep == 0 {
+		p.Keep = DefaultBackupsKept
+	}
+	return p
+}
+
+/*
+check checks the options for the BackupDatabase operation
+*/
+func (p BackupDatabaseOpts) Check() (bool, error) {
+	if p.Dir == "" {
+		return false, helpers.ErrDatabaseNotFile
+	}
+	if p.Keep < 1 {
+		return false, helpers.ErrInvalidBackupsKept
+	}
+
+	return true, nil
+}
+
+/*
+ExportDatabaseOpts contains the options for ExportDatabase
+*/
+type ExportDatabaseOpts struct {
+	Format  DumpFormat // Optional - format to export to, defaults to json
+	OutPath string     // Mandatory - file to write the export to
+}
+
+/*
+build fills any missing optional values
+*/
+func (p ExportDatabaseOpts) build() ExportDatabaseOpts {
+	if p.Format == "" {
+		p.Format = DumpFormatJSON
+	}
+	return p
+}
+
+/*
+check checks the options for the ExportDatabase operation
+*/
+func (p ExportDatabaseOpts) Check() (bool, error) {
+	if !p.Format.Check() {
+		return false, helpers.ErrInvalidExportFormat
+	}
+	if p.OutPath == "" {
+		return false, helpers.ErrMissingExportPath
+	}
+
+	return true, nil
+}
+
+/*
+ImportDatabaseOpts contains the options for ImportDatabase
+*/
+type ImportDatabaseOpts struct {
+	InPath string // Mandatory - file written by ExportDatabase
+}
+
+/*
+check checks the options for the ImportDatabase operation
+*/
+func (p ImportDatabaseOpts) Check() (bool, error) {
+	if p.InPath == "" || !helpers.DoesFileExist(p.InPath) {
+		return false, helpers.ErrMissingImportPath
+	}
+
+	return true, nil
+}
+
+/*
+ImportPurchasesOpts contains the options for ImportPurchases
+*/
+type ImportPurchasesOpts struct {
+	Store         purchases.Store // Mandatory - the store the export is from
+	FilePath      string          // Mandatory - a Beatport order history CSV or a Bandcamp collection JSON
+	MinConfidence float64         // Optional - min confidence (0-1) for a purchase to be matched, defaults to DefaultMinMatchConfidence
+}
+
+/*
+build fills any missing optional values
+*/
+func (p ImportPurchasesOpts) build() ImportPurchasesOpts {
+	if p.MinConfidence == 0 {
+		p.MinConfidence = DefaultMinMatchConfidence
+	}
+	return p
+}
+
+/*
+check checks the options for the ImportPurchases operation
+*/
+func (p ImportPurchasesOpts) Check() (bool, error) {
+	if !p.Store.Check() {
+		return false, helpers.ErrInvalidStore
+	}
+	if p.FilePath == "" {
+		return false, helpers.ErrMissingPurchaseFile
+	}
+	if !helpers.DoesFileExist(p.FilePath) {
+		return false, helpers.ErrMissingPurchaseFile
+	}
+	if p.MinConfidence < 0 || p.MinConfidence > 1 {
+		return false, helpers.ErrInvalidMinConfidence
+	}
+
+	return true, nil
+}
+
+/*
+ListPurchasesOpts contains the options for ListPurchases
+*/
+type ListPurchasesOpts struct {
+	Status PurchaseStatus  // Optional - only list purchases with this status, lists every purchase if empty
+	Store  purchases.Store // Optional - only list purchases from this store
+}
+
+/*
+check checks the options for the ListPurchases operation
+*/
+func (p ListPurchasesOpts) Check() (bool, error) {
+	if p.Status != "" && !p.Status.Check() {
+		return false, helpers.ErrInvalidPurchaseStatus
+	}
+	if p.Store != "" && !p.Store.Check() {
+		return false, helpers.ErrInvalidStore
+	}
+
+	return true, nil
+}
+
+/*
+DownloadSoundCloudPlaylistOpts contains the options for DownloadSoundCloudPlaylist
+*/
+type DownloadSoundCloudPlaylistOpts struct {
+	PlaylistExternalID int64                          // Mandatory
+	PlaylistSourceType streaming.SoundCloudSourceType // Optional - defaults to a playlist
+	PlaylistName       string                         // Optional - if provided, tracks are downloaded into a folder with this name
+	Workers            int                            // Optional - number of downloads to run at once, defaults to DefaultDownloadWorkers
+	RequestsPerSecond  float64                        // Optional - max downloads started per second, defaults to DefaultDownloadRequestsPerSecond
+	Collision          helpers.CollisionStrategy      // Optional - if not provided, colliding files will be suffixed
+}
+
+/*
+build fills any missing optional values
+*/
+func (p DownloadSoundCloudPlaylistOpts) build(cfg helpers.Config) DownloadSoundCloudPlaylistOpts {
+	if p.PlaylistSourceType == "" {
+		p.PlaylistSourceType = streaming.SoundCloudSourcePlaylist
+	}
+	if p.Workers == 0 {
+		p.Workers = cfg.DownloadWorkers
+	}
+	if p.Workers == 0 {
+		p.Workers = DefaultDownloadWorkers
+	}
+	if p.RequestsPerSecond == 0 {
+		p.RequestsPerSecond = DefaultDownloadRequestsPerSecond
+	}
+	if p.Collision == "" {
+		p.Collision = helpers.CollisionSuffix
+	}
+	return p
+}
+
+/*
+check checks the options for the DownloadSoundCloudPlaylist operation
+*/
+func (p DownloadSoundCloudPlaylistOpts) Check() (bool, error) {
+	if p.PlaylistExternalID == 0 {
+		return false, helpers.ErrMissingPlaylistID
+	}
+	if !p.PlaylistSourceType.Check() {
+		return false, helpers.ErrInvalidSoundCloudSourceType
+	}
+	if p.Workers < 1 {
+		return false, helpers.ErrInvalidWorkers
+	}
+	if p.RequestsPerSecond <= 0 {
+		return false, helpers.ErrInvalidRequestsPerSecond
+	}
+	if !p.Collision.Check() {
+		return false, helpers.ErrInvalidCollisionStrategy
+	}
+
+	return true, nil
+}
+
+/*
+EmbedSoundCloudArtworkOpts contains the options for EmbedSoundCloudArtwork
+*/
+type EmbedSoundCloudArtworkOpts struct {
+	PlaylistExternalID int64                          // Optional - if provided, artwork is embedded for every track in the playlist
+	PlaylistSourceType streaming.SoundCloudSourceType // Optional - defaults to a playlist
+	Tracks             []streaming.SoundCloudTrack    // Optional - the tracks to embed artwork for, used if no playlist is provided
+}
+
+/*
+build fills any missing optional values
+*/
+func (p EmbedSoundCloudArtworkOpts) build() EmbedSoundCloudArtworkOpts {
+	if p.PlaylistSourceType == "" {
+		p.PlaylistSourceType = streaming.SoundCloudSourcePlaylist
+	}
+	return p
+}
+
+/*
+check checks the options for the EmbedSoundCloudArtwork operation
+*/
+func (p EmbedSoundCloudArtworkOpts) Check() (bool, error) {
+	if p.PlaylistExternalID == 0 && len(p.Tracks) == 0 {
+		return false, helpers.ErrMissingArtworkTracks
+	}
+	if !p.PlaylistSourceType.Check() {
+		return false, helpers.ErrInvalidSoundCloudSourceType
+	}
+
+	return true, nil
+}
+
+/*
+RefreshSoundCloudPlaylistOpts contains the options for RefreshSoundCloudPlaylist
+*/
+type RefreshSoundCloudPlaylistOpts struct {
+	Playlist streaming.SoundCloudPlaylist // Mandatory - the stored playlist to refresh
+}
+
+/*
+check checks the options for the RefreshSoundCloudPlaylist operation
+*/
+func (p RefreshSoundCloudPlaylistOpts) Check() (bool, error) {
+	if p.Playlist.ExternalID == 0 {
+		return false, helpers.ErrMissingPlaylistID
+	}
+	if p.Playlist.PermalinkUrl == "" && p.Playlist.SearchUrl == "" {
+		return false, helpers.ErrMissingPlaylistURL
+	}
+
+	return true, nil
+}
+
+/*
+RefreshAllSoundCloudPlaylistsOpts contains the options for RefreshAllSoundCloudPlaylists
+*/
+type RefreshAllSoundCloudPlaylistsOpts struct {
+	Interval time.Duration // Optional - if provided, playlists are refreshed again after each interval until cancelled
+}
+
+/*
+check checks the options for the RefreshAllSoundCloudPlaylists operation
+*/
+func (p RefreshAllSoundCloudPlaylistsOpts) Check() (bool, error) {
+	if p.Interval != 0 && p.Interval < MinRefreshInterval {
+		return false, helpers.ErrRefreshIntervalTooShort
+	}
+
+	return true, nil
+}
+
+/*
+ListSoundCloudPlaylistChangesOpts contains the options for ListSoundCloudPlaylistChanges
+*/
+type ListSoundCloudPlaylistChangesOpts struct {
+	Limit int // Optional - max number of changes to list, defaults to DefaultChangeLogLimit
+}
+
+/*
+build fills any missing optional values
+*/
+func (p ListSoundCloudPlaylistChangesOpts) build() ListSoundCloudPlaylistChangesOpts {
+	if p.Limit == 0 {
+		p.Limit = DefaultChangeLogLimit
+	}
+	return p
+}
+
+/*
+check checks the options for the ListSoundCloudPlaylistChanges operation
+*/
+func (p ListSoundCloudPlaylistChangesOpts) Check() (bool, error) {
+	if p.Limit < 1 {
+		return false, helpers.ErrInvalidLimit
+	}
+
+	return true, nil
+}
+
+/*
+ListSoundCloudPlaylistTrackHistoryOpts contains the options for ListSoundCloudPlaylistTrackHistory
+*/
+type ListSoundCloudPlaylistTrackHistoryOpts struct {
+	PlaylistExternalID int64                          // Mandatory - the stored playlist to list the history of
+	PlaylistSourceType streaming.SoundCloudSourceType // Optional - defaults to a playlist
+}
+
+/*
+build fills any missing optional values
+*/
+func (p ListSoundCloudPlaylistTrackHistoryOpts) build() ListSoundCloudPlaylistTrackHistoryOpts {
+	if p.PlaylistSourceType == "" {
+		p.PlaylistSourceType = streaming.SoundCloudSourcePlaylist
+	}
+	return p
+}
+
+/*
+check checks the options for the ListSoundCloudPlaylistTrackHistory operation
+*/
+func (p ListSoundCloudPlaylistTrackHistoryOpts) Check() (bool, error) {
+	if p.PlaylistExternalID == 0 {
+		return false, helpers.ErrMissingPlaylistID
+	}
+	if !p.PlaylistSourceType.Check() {
+		return false, helpers.ErrInvalidSoundCloudSourceType
+	}
+
+	return true, nil
+}
+
+/*
+ListSoundCloudPurchaseLinksOpts contains the options for ListSoundCloudPurchaseLinks
+*/
+type ListSoundCloudPurchaseLinksOpts struct {
+	Category       streaming.PurchaseCategory // Optional - only list tracks with links in this category, lists every track with a link if empty
+	IncludeRemoved bool                       // Optional - include tracks removed from their playlist
+}
+
+/*
+check checks the options for the ListSoundCloudPurchaseLinks operation
+*/
+func (p ListSoundCloudPurchaseLinksOpts) Check() (bool, error) {
+	if p.Category != "" && !p.Category.Check() {
+		return false, helpers.ErrInvalidPurchaseCategory
+	}
+
+	return true, nil
+}
+
+/*
+SearchLibraryOpts contains the options for SearchLibrary
+*/
+type SearchLibraryOpts struct {
+	Query            string // Mandatory - see internal.ParseSearchQuery
+	Limit            int    // Optional - max number of results, defaults to DefaultSearchLimit
+	IndexCollections bool   // Optional - index the collection before searching it
+}
+
+/*
+build fills any missing optional values
+*/
+func (p SearchLibraryOpts) build() SearchLibraryOpts {
+	if p.Limit == 0 {
+		p.Limit = DefaultSearchLimit
+	}
+	return p
+}
+
+/*
+check checks the options for the SearchLibrary operation
+*/
+func (p SearchLibraryOpts) Check() (bool, error) {
+	q, err := internal.ParseSearchQuery(p.Query)
+
+	if err != nil {
+		return false, err
+	}
+
+	switch q.Source {
+	case "", data.SearchSourceSoundCloud, data.SearchSourceLocal, data.SearchSourceCollection:
+	default:
+		return false, helpers.ErrInvalidSearchSource
+	}
+
+	if p.Limit < 1 {
+		return false, helpers.ErrInvalidLimit
+	}
 
 	return true, nil
 }
@@ -0,0 +1,240 @@
+package operations
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/collection"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+OrganiseLibrary moves audio files from the download directory into the library (base) directory,
+placing each file at a path built from its tags using a template (i.e. '{genre}/{year}/{artist} - {title}')
+
+Any directories left empty inside of the download directory are removed, and the SoundCloud local
+paths and collection locations of moved files are updated to match
+*/
+func (e *OpEnv) OrganiseLibrary(ctx context.Context, opts OrganiseLibraryOpts) {
+
+	opts = opts.build(e.Config)
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	e.Logger.Info("Finding files to organise")
+	filePaths, err := helpers.GetFilesInDir(opts.InDirPath, true)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error getting file paths",
+				"There was an error getting the paths of the files to organise",
+			),
+		))
+		return
+	}
+
+	var audioPaths []string
+	for _, path := range filePaths {
+		if helpers.IsExtensionInArray(path, helpers.GetAudioExtensions()) {
+			audioPaths = append(audioPaths, filepath.ToSlash(path))
+		}
+	}
+
+	e.Logger.Infof("Found %v files to organise", len(audioPaths))
+
+	if len(audioPaths) == 0 {
+		e.FinishSuccess(nil)
+		return
+	}
+
+	e.BuildProgressTracker(len(audioPaths), 1)
+
+	// map of old file paths to new file paths
+	moves := make(map[string]string)
+
+	for i, path := range audioPaths {
+		if ctx.Err() != nil {
+			e.Logger.Info("Operation cancelled, stopping")
+			break
+		}
+
+		newPath, err := e.organiseFile(path, opts)
+		e.ProcessComplete(i)
+
+		if err != nil {
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fctx.With(fctx.WithMeta(ctx, "path", path)),
+				fmsg.With("error organising file"),
+			))
+			continue
+		}
+
+		if newPath != "" {
+			moves[path] = newPath
+		}
+	}
+
+	e.Logger.Infof("Moved %v files into the library", len(moves))
+
+	removedDirs, err := helpers.RemoveEmptyDirs(opts.InDirPath)
+
+	if err != nil {
+		e.Logger.NonFatalError(fault.Wrap(
+			err,
+			fmsg.With("error removing empty directories"),
+		))
+	}
+
+	e.Logger.Infof("Removed %v empty directories", len(removedDirs))
+
+	if len(moves) > 0 {
+		e.updateMovedLocalPaths(ctx, moves)
+		e.updateMovedCollectionLocations(moves)
+	}
+
+	e.FinishSuccess(map[string]any{
+		"moved": len(moves),
+	})
+}
+
+/*
+organiseFile moves a single file to its place in the library
+
+Returns the new path of the file, or an empty string if the file is already in place
+*/
+func (e *OpEnv) organiseFile(path string, opts OrganiseLibraryOpts) (string, error) {
+
+	fileInfo, err := internal.SplitFilePathRequired(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	tags, err := helpers.ReadAudioTags(path)
+
+	if err != nil {
+		return "", fault.Wrap(
+			err,
+			fmsg.With("error reading tags"),
+		)
+	}
+
+	relPath, err := internal.BuildOrganisePath(opts.Template, tags, fileInfo)
+
+	if err != nil {
+		return "", err
+	}
+
+	newPath := helpers.JoinFilepathToSlash(opts.OutDirPath, relPath)
+
+	if newPath == path {
+		return "", nil
+	}
+
+	newPath = helpers.GetNextAvailableFilePath(newPath)
+
+	e.Logger.Debugf("Moving %s to %s", path, newPath)
+
+	err = helpers.MoveFile(path, newPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	return newPath, nil
+}
+
+/*
+updateMovedLocalPaths updates the local path of any SoundCloud tracks linked to moved files
+*/
+func (e *OpEnv) updateMovedLocalPaths(ctx context.Context, moves map[string]string) {
+
+	tracks, err := e.SerenDB.ListSoundCloudTracksHasLocalPath(ctx)
+
+	if err != nil {
+		e.Logger.NonFatalError(fault.Wrap(
+			err,
+			fmsg.With("error listing tracks with local path in db"),
+		))
+		return
+	}
+
+	var dataT []data.SoundcloudTrack
+
+	for _, track := range tracks {
+		t := streaming.SoundCloudTrack{}
+		t.LoadFromDB(track)
+
+		newPath, ok := moves[filepath.ToSlash(t.LocalPath)]
+
+		if !ok {
+			continue
+		}
+
+		t.LocalPath = newPath
+		t.LocalPathBroken = false
+
+		dataT = append(dataT, t.ToDB())
+	}
+
+	if len(dataT) == 0 {
+		return
+	}
+
+	err = e.SerenDB.TxSetSoundCloudTrackLocalPaths(ctx, dataT)
+
+	if err != nil {
+		e.Logger.NonFatalError(fault.Wrap(
+			err,
+			fmsg.With("error updating moved tracks in db"),
+		))
+		return
+	}
+
+	e.Logger.Infof("Updated local path of %v SoundCloud tracks", len(dataT))
+}
+
+/*
+updateMovedCollectionLocations updates the location of any moved files inside of the
+Traktor collection, if a collection path is set in config
+*/
+func (e *OpEnv) updateMovedCollectionLocations(moves map[string]string) {
+
+	if e.Config.TraktorCollectionPath == "" {
+		return
+	}
+
+	c := collection.ReadTraktorOpts{}.Build(e.Config)
+
+	n, err := c.RelocateTracks(moves)
+
+	if err != nil {
+		e.Logger.NonFatalError(fault.Wrap(
+			err,
+			fmsg.With("error relocating tracks in collection"),
+		))
+		return
+	}
+
+	e.Logger.Infof("Updated location of %v collection entries", n)
+}
@@ -0,0 +1,220 @@
+package operations
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/billiem/seren-management/pkg/streaming"
+
+	stems "github.com/billiem/seren-management/pkg/operations/stems"
+)
+
+/*
+PostDownloadHook is run on a track once it has been downloaded from SoundCloud, hooks may
+change the track, i.e. a conversion updates the local path to the converted file
+*/
+type PostDownloadHook func(ctx context.Context, track *streaming.SoundCloudTrack) error
+
+/*
+stemQueue collects the paths of downloaded tracks to be separated into stems, as stem separation
+is run once all downloads have finished rather than per track
+*/
+type stemQueue struct {
+	mu       sync.Mutex
+	stemType stems.StemSeparationType
+	paths    []string
+}
+
+/*
+AttachDefaultPostDownloadHooks builds the post download hook chain from the config
+
+The chain runs in the order mp3 conversion, tag writing and stem separation, so the tags are
+written to the converted file and the stems are separated from the tagged file
+*/
+func (e *OpEnv) AttachDefaultPostDownloadHooks() {
+	e.PostDownloadHooks = nil
+	e.stemQueue = nil
+
+	if e.Config.PostDownloadConvertMp3 {
+		if e.Mp3EnvBuilder == nil {
+			e.AttachDefaultMp3EnvBuilder()
+		}
+		e.PostDownloadHooks = append(e.PostDownloadHooks, e.convertMp3Hook)
+	}
+
+	if e.Config.PostDownloadWriteTags {
+		e.PostDownloadHooks = append(e.PostDownloadHooks, e.writeSoundCloudTagsHook)
+	}
+
+	stemType := stems.StemSeparationType(e.Config.PostDownloadStemType)
+
+	if stemType.Check() {
+		if e.StemEnvBuilder == nil {
+			e.AttachDefaultStemEnvBuilder()
+		}
+		e.stemQueue = &stemQueue{stemType: stemType}
+		e.PostDownloadHooks = append(e.PostDownloadHooks, e.queueStemsHook)
+	}
+}
+
+/*
+runPostDownloadHooks runs each post download hook on the track in order, stopping at the first
+error. The track is updated by the hooks which succeeded, even if a later hook fails
+*/
+func (e *OpEnv) runPostDownloadHooks(ctx context.Context, track *streaming.SoundCloudTrack) error {
+	for _, hook := range e.PostDownloadHooks {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := hook(ctx, track)
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("error running post download hook"),
+			)
+		}
+	}
+
+	return nil
+}
+
+/*
+convertMp3Hook converts the downloaded file to mp3 next to the original, if its extension is
+one of the extensions to convert in the config. The original file is kept
+*/
+func (e *OpEnv) convertMp3Hook(ctx context.Context, track *streaming.SoundCloudTrack) error {
+	if !helpers.IsExtensionInArray(track.LocalPath, e.Config.ExtensionsToConvertToMp3) {
+		return nil
+	}
+
+	mp3Env := e.Mp3EnvBuilder()
+
+	tracks, alreadyExistsCnt, errs := mp3Env.GetMp3Tracks([]string{track.LocalPath}, "")
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	if alreadyExistsCnt > 0 || len(tracks) == 0 {
+		e.Logger.Infof("Converted file already exists for %s", track.Name)
+		return nil
+	}
+
+	e.Logger.Infof("Converting: %s", track.Name)
+
+	converted, err := mp3Env.ConvertMp3Track(tracks[0])
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("error converting downloaded file to mp3"),
+		)
+	}
+
+	track.LocalPath = converted.NewFile.FileInfo.FullPath
+
+	return nil
+}
+
+/*
+writeSoundCloudTagsHook writes the SoundCloud metadata of the track to the downloaded file, with
+the permalink in the comment and the SoundCloud tags in the grouping. The artwork is embedded if
+the track has any, using the artwork cache
+*/
+func (e *OpEnv) writeSoundCloudTagsHook(ctx context.Context, track *streaming.SoundCloudTrack) error {
+	artist, title := internal.SplitArtistTitle(track.Name)
+
+	if track.PublisherArtist != "" {
+		artist = track.PublisherArtist
+	}
+	if artist == "" {
+		artist = track.SoundCloudUser
+	}
+
+	tags := helpers.AudioTags{
+		Title:    title,
+		Artist:   artist,
+		Genre:    track.Genre,
+		Comment:  track.PermalinkUrl,
+		Grouping: track.TagList,
+	}
+
+	artworkPath := ""
+
+	if track.ArtworkURL != "" && helpers.SupportsArtwork(track.LocalPath) {
+		p, err := getCachedSoundCloudArtwork(ctx, *track)
+
+		// missing artwork shouldn't stop the rest of the tags being written
+		if err != nil {
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fmsg.With("error getting artwork"),
+			))
+		}
+
+		artworkPath = p
+	}
+
+	return helpers.WriteAudioTags(track.LocalPath, tags, artworkPath)
+}
+
+/*
+queueStemsHook queues the downloaded file to be separated into stems once all downloads have
+finished, if its extension is one of the extensions to separate in the config
+*/
+func (e *OpEnv) queueStemsHook(ctx context.Context, track *streaming.SoundCloudTrack) error {
+	if !helpers.IsExtensionInArray(track.LocalPath, e.Config.ExtensionsToSeparateToStems) {
+		return nil
+	}
+
+	e.stemQueue.mu.Lock()
+	defer e.stemQueue.mu.Unlock()
+
+	e.stemQueue.paths = append(e.stemQueue.paths, track.LocalPath)
+
+	return nil
+}
+
+/*
+separateQueuedStems separates the files queued by queueStemsHook into stems next to the files,
+and empties the queue
+*/
+func (e *OpEnv) separateQueuedStems(ctx context.Context) {
+	if e.stemQueue == nil {
+		return
+	}
+
+	e.stemQueue.mu.Lock()
+	paths := e.stemQueue.paths
+	e.stemQueue.paths = nil
+	e.stemQueue.mu.Unlock()
+
+	if len(paths) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	stemEnv := e.StemEnvBuilder()
+
+	tracks, alreadyExistsCnt, errs := stemEnv.GetStemTracks(paths, "", e.stemQueue.stemType)
+
+	for _, err := range errs {
+		e.Logger.NonFatalError(fault.Wrap(
+			err,
+			fmsg.With("error building stem track array"),
+		))
+	}
+
+	e.Logger.Infof("%v downloaded files already have stems, %v left to separate", alreadyExistsCnt, len(tracks))
+
+	if len(tracks) == 0 {
+		return
+	}
+
+	stemEnv.ConvertStemTracks(ctx, tracks)
+}
@@ -0,0 +1,96 @@
+package operations
+
+import (
+	"context"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+ListSoundCloudPurchaseLinks lists the SoundCloud tracks with a purchase link, so links such as free
+download gates can be worked through in one go. Tracks saved before their purchase link was classified
+are classified and saved
+
+The tracks are returned under the 'tracks' key as a []streaming.SoundCloudTrack, and the number of
+tracks in each category under the 'counts' key as a map[streaming.PurchaseCategory]int
+*/
+func (e *OpEnv) ListSoundCloudPurchaseLinks(ctx context.Context, opts ListSoundCloudPurchaseLinksOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	dataTracks, err := e.SerenDB.ListSoundCloudTracks(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error listing soundcloud tracks in db",
+				"There was an error getting your SoundCloud tracks from the database",
+			),
+		))
+		return
+	}
+
+	var unclassified []data.SoundcloudTrack
+
+	tracks := []streaming.SoundCloudTrack{}
+	counts := map[streaming.PurchaseCategory]int{}
+
+	for _, dt := range dataTracks {
+		var t streaming.SoundCloudTrack
+		t.LoadFromDB(dt)
+
+		if !dt.PurchaseCategory.Valid {
+			unclassified = append(unclassified, t.ToDB())
+		}
+
+		if t.RemovedFromPlaylist && !opts.IncludeRemoved {
+			continue
+		}
+
+		if t.PurchaseCategory == streaming.PurchaseNone {
+			continue
+		}
+
+		counts[t.PurchaseCategory]++
+
+		if opts.Category != "" && t.PurchaseCategory != opts.Category {
+			continue
+		}
+
+		tracks = append(tracks, t)
+	}
+
+	if len(unclassified) > 0 {
+		e.Logger.Infof("Saving purchase categories of %v tracks", len(unclassified))
+
+		err = e.SerenDB.TxUpsertSoundCloudTracks(ctx, unclassified)
+
+		if err != nil {
+			// the categories are classified again next time, so the tracks can still be listed
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fmsg.With("error saving purchase categories"),
+			))
+		}
+	}
+
+	e.FinishSuccess(map[string]any{
+		"tracks": tracks,
+		"counts": counts,
+	})
+}
@@ -0,0 +1,335 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"os"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/collection"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/billiem/seren-management/pkg/purchases"
+)
+
+/*
+Provides operations for importing the purchase history exported from Beatport and Bandcamp, and
+finding purchases missing from the local library or the collection
+*/
+
+/*
+PurchaseStatus filters purchases by where they are in the library
+*/
+type PurchaseStatus string
+
+const (
+	PurchaseNotDownloaded   PurchaseStatus = "not_downloaded"    // bought but not found in the local file index
+	PurchaseNotInCollection PurchaseStatus = "not_in_collection" // downloaded but not in the collection
+)
+
+func (s PurchaseStatus) Check() bool {
+	switch s {
+	case PurchaseNotDownloaded, PurchaseNotInCollection:
+		return true
+	}
+	return false
+}
+
+/*
+ImportPurchases reads a store's purchase history export and stores the purchases in the database,
+importing the same export again updates the purchases rather than duplicating them
+
+Every purchase is then matched against the local file index, which should be built with IndexLocalFiles
+first, so files downloaded since the last import are found
+
+The number of purchases read is returned under the 'imported' key, and the number of purchases
+matched to a local file under the 'matched' key
+*/
+func (e *OpEnv) ImportPurchases(ctx context.Context, opts ImportPurchasesOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	ctx = fctx.WithMeta(ctx, "store", string(opts.Store), "file_path", opts.FilePath)
+
+	read, err := readPurchaseExport(opts.Store, opts.FilePath)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error reading purchase export",
+				"There was an error reading the purchase history, check it is the export from the store",
+			),
+		))
+		return
+	}
+
+	dataPurchases := make([]data.Purchase, len(read))
+	for i, p := range read {
+		dataPurchases[i] = p.ToDB()
+	}
+
+	imported, err := e.SerenDB.TxUpsertPurchases(ctx, dataPurchases)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error saving purchases to db",
+				"There was an error saving your purchases to the database",
+			),
+		))
+		return
+	}
+
+	e.Logger.Infof("Imported %v purchases from %s", imported, opts.Store)
+
+	matched, err := e.matchPurchases(ctx, opts.MinConfidence)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error matching purchases",
+				"Your purchases were imported, but there was an error finding them in your library",
+			),
+		))
+		return
+	}
+
+	e.FinishSuccess(map[string]any{
+		"imported": imported,
+		"matched":  matched,
+	})
+}
+
+func readPurchaseExport(store purchases.Store, filePath string) ([]purchases.Purchase, error) {
+
+	f, err := os.Open(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var read func(io.Reader) ([]purchases.Purchase, error)
+
+	switch store {
+	case purchases.StoreBeatport:
+		read = purchases.ReadBeatportCSV
+	case purchases.StoreBandcamp:
+		read = purchases.ReadBandcampJSON
+	default:
+		return nil, helpers.ErrInvalidStore
+	}
+
+	return read(f)
+}
+
+/*
+matchPurchases matches every stored purchase against the local file index, tracks are matched by
+artist, title and duration and albums by artist and album, returning the number matched
+*/
+func (e *OpEnv) matchPurchases(ctx context.Context, minConfidence float64) (int, error) {
+
+	dataPurchases, err := e.SerenDB.ListPurchases(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := e.SerenDB.ListLocalFiles(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	trackCandidates := make([]internal.MatchCandidate, len(files))
+	albumCandidates := make([]internal.AlbumCandidate, len(files))
+
+	for i, f := range files {
+		trackCandidates[i] = internal.NewMatchCandidate(
+			MatchSourceLocal,
+			f.Path.String,
+			f.Artist.String,
+			f.Title.String,
+			f.Duration.Float64,
+		)
+		albumCandidates[i] = internal.AlbumCandidate{
+			Path:   f.Path.String,
+			Artist: f.Artist.String,
+			Album:  f.Album.String,
+		}
+	}
+
+	matcher := internal.NewTrackMatcher(trackCandidates)
+
+	matched := 0
+
+	for i, dp := range dataPurchases {
+		var p purchases.Purchase
+		p.LoadFromDB(dp)
+
+		var m internal.TrackMatch
+		var ok bool
+
+		if p.Kind == purchases.KindAlbum {
+			m, ok = internal.MatchAlbum(albumCandidates, p.Artist, p.Album)
+		} else {
+			m, ok = matcher.Match(internal.MatchQuery{
+				Artist:   p.Artist,
+				Title:    p.Title,
+				Duration: p.Duration,
+			})
+		}
+
+		if !ok || m.Confidence < minConfidence {
+			dataPurchases[i].LocalPath = sql.NullString{}
+			dataPurchases[i].MatchConfidence = sql.NullFloat64{}
+			continue
+		}
+
+		e.Logger.Debugf("Matched purchase %s to %s (%.2f)", p, m.Path, m.Confidence)
+
+		dataPurchases[i].LocalPath = sql.NullString{Valid: true, String: m.Path}
+		dataPurchases[i].MatchConfidence = sql.NullFloat64{Valid: true, Float64: m.Confidence}
+		matched++
+	}
+
+	err = e.SerenDB.TxSetPurchaseMatches(ctx, dataPurchases)
+
+	if err != nil {
+		return 0, err
+	}
+
+	e.Logger.Infof("Matched %v of %v purchases to local files", matched, len(dataPurchases))
+
+	return matched, nil
+}
+
+/*
+ListPurchases lists the purchases stored in the database, optionally only those not downloaded or
+not in the collection. Purchases are in the collection if their file is, or for albums if any file in
+their directory is
+
+The purchases are returned under the 'purchases' key as a []purchases.Purchase
+*/
+func (e *OpEnv) ListPurchases(ctx context.Context, opts ListPurchasesOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	var inCollection internal.PathSet
+
+	if opts.Status == PurchaseNotInCollection {
+		inCollection, err = e.getCollectionPaths()
+
+		if err != nil {
+			e.FinishError(fault.Wrap(
+				err,
+				fmsg.WithDesc(
+					"error reading collection",
+					"There was an error reading your collection, check the collection path in settings",
+				),
+			))
+			return
+		}
+	}
+
+	dataPurchases, err := e.SerenDB.ListPurchases(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error listing purchases in db",
+				"There was an error getting your purchases from the database",
+			),
+		))
+		return
+	}
+
+	out := []purchases.Purchase{}
+
+	for _, dp := range dataPurchases {
+		var p purchases.Purchase
+		p.LoadFromDB(dp)
+
+		if opts.Store != "" && p.Store != opts.Store {
+			continue
+		}
+
+		switch opts.Status {
+		case PurchaseNotDownloaded:
+			if p.LocalPath != "" {
+				continue
+			}
+		case PurchaseNotInCollection:
+			if p.LocalPath == "" || inCollection.Has(p.LocalPath) {
+				continue
+			}
+			if p.Kind == purchases.KindAlbum && inCollection.HasUnder(p.LocalPath) {
+				continue
+			}
+		}
+
+		out = append(out, p)
+	}
+
+	e.FinishSuccess(map[string]any{
+		"purchases": out,
+	})
+}
+
+/*
+getCollectionPaths returns the path of every entry in the collection
+*/
+func (e *OpEnv) getCollectionPaths() (internal.PathSet, error) {
+
+	if e.Config.TraktorCollectionPath == "" {
+		return nil, helpers.ErrMissingCollectionPath
+	}
+
+	tracks, err := collection.ReadTraktorOpts{}.Build(e.Config).ListTracks()
+
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, len(tracks))
+	for i, t := range tracks {
+		paths[i] = t.Path
+	}
+
+	return internal.NewPathSet(paths), nil
+}
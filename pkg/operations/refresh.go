@@ -0,0 +1,438 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+Provides operations for refreshing SoundCloud playlists, keeping a log of the changes found in each refresh
+*/
+
+// The types of change logged when refreshing a playlist
+const (
+	SoundCloudChangeAdded           = internal.PlaylistChangeAdded
+	SoundCloudChangeRemoved         = internal.PlaylistChangeRemoved
+	SoundCloudChangeDownloadEnabled = internal.PlaylistChangeDownloadEnabled
+	SoundCloudChangePurchaseURL     = internal.PlaylistChangePurchaseURL
+)
+
+const (
+	MinRefreshInterval    = 15 * time.Minute // min time between scheduled refreshes, to avoid hammering SoundCloud
+	DefaultChangeLogLimit = 200              // number of changes listed by default
+)
+
+/*
+SoundCloudPlaylistChange is a change to a track of a SoundCloud playlist, found when refreshing the playlist
+*/
+type SoundCloudPlaylistChange struct {
+	CreatedAt         time.Time
+	PlaylistName      string
+	TrackName         string
+	TrackPermalinkUrl string
+	Type              string // one of the SoundCloudChange constants
+	OldValue          string // Optional - only set for changed values
+	NewValue          string // Optional - only set for changed values
+}
+
+/*
+SoundCloudPlaylistTrackPeriod is a period a track was in a SoundCloud playlist
+*/
+type SoundCloudPlaylistTrackPeriod struct {
+	TrackExternalID   int64
+	TrackName         string
+	TrackPermalinkUrl string
+	AddedAt           time.Time
+	RemovedAt         time.Time // Optional - zero while the track is still in the playlist
+}
+
+/*
+RefreshSoundCloudPlaylist gets the current tracks of a stored playlist from SoundCloud, saving them along
+with a log of the tracks added, removed, with downloads newly enabled or with a changed purchase link
+
+The refreshed playlist, including tracks which have been removed, is returned under the 'playlist' key as a
+streaming.SoundCloudPlaylist, and the changes under the 'changes' key as a []SoundCloudPlaylistChange
+*/
+func (e *OpEnv) RefreshSoundCloudPlaylist(ctx context.Context, opts RefreshSoundCloudPlaylistOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	s, err := e.streamingSource(ctx, streaming.PlatformSoundCloud)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.With("error getting SoundCloud client ID"),
+		))
+		return
+	}
+
+	p, changes, err := e.refreshSoundCloudPlaylist(ctx, s, opts.Playlist)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error refreshing playlist",
+				fmt.Sprintf("There was an error refreshing %s", opts.Playlist.Name),
+			),
+		))
+		return
+	}
+
+	e.FinishSuccess(map[string]any{
+		"playlist": p,
+		"changes":  changes,
+	})
+}
+
+/*
+RefreshAllSoundCloudPlaylists refreshes every SoundCloud playlist stored in the database, as in
+RefreshSoundCloudPlaylist. A playlist failing to refresh doesn't stop the others being refreshed
+
+If opts.Interval is set, every playlist is refreshed again after each interval until ctx is
+done, finishing once per run
+
+The number of playlists refreshed is returned under the 'refreshed' key, the number which failed
+under the 'failed' key and the changes found under the 'changes' key as a []SoundCloudPlaylistChange
+*/
+func (e *OpEnv) RefreshAllSoundCloudPlaylists(ctx context.Context, opts RefreshAllSoundCloudPlaylistsOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	e.refreshAllSoundCloudPlaylists(ctx)
+
+	if opts.Interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.refreshAllSoundCloudPlaylists(ctx)
+		}
+	}
+}
+
+func (e *OpEnv) refreshAllSoundCloudPlaylists(ctx context.Context) {
+
+	dataPlaylists, err := e.SerenDB.ListSoundCloudPlaylists(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error getting soundcloud playlists from db",
+				"There was an error getting the SoundCloud playlists from the database",
+			),
+		))
+		return
+	}
+
+	changes := []SoundCloudPlaylistChange{}
+
+	if len(dataPlaylists) == 0 {
+		e.FinishSuccess(map[string]any{
+			"refreshed": 0,
+			"failed":    0,
+			"changes":   changes,
+		})
+		return
+	}
+
+	s, err := e.streamingSource(ctx, streaming.PlatformSoundCloud)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.With("error getting SoundCloud client ID"),
+		))
+		return
+	}
+
+	e.BuildProgressTracker(len(dataPlaylists), 1)
+
+	var refreshed, failed int
+
+	for i, dp := range dataPlaylists {
+		if ctx.Err() != nil {
+			e.Logger.Info("Operation cancelled, stopping refresh")
+			break
+		}
+
+		var playlist streaming.SoundCloudPlaylist
+		playlist.LoadFromDB(dp, nil)
+
+		e.Logger.Infof("Refreshing: %s", playlist.Name)
+
+		_, playlistChanges, err := e.refreshSoundCloudPlaylist(ctx, s, playlist)
+
+		e.ProcessComplete(i)
+
+		if err != nil {
+			failed++
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fctx.With(fctx.WithMeta(ctx, "playlist_name", playlist.Name)),
+				fmsg.With("error refreshing playlist"),
+			))
+			continue
+		}
+
+		refreshed++
+		changes = append(changes, playlistChanges...)
+	}
+
+	e.Logger.Infof("Refreshed %v playlists, %v failed, %v changes", refreshed, failed, len(changes))
+
+	e.FinishSuccess(map[string]any{
+		"refreshed": refreshed,
+		"failed":    failed,
+		"changes":   changes,
+	})
+}
+
+/*
+refreshSoundCloudPlaylist gets the current tracks of a stored playlist, diffs them with the stored
+tracks, and saves the tracks and changes
+*/
+func (e *OpEnv) refreshSoundCloudPlaylist(ctx context.Context, s streaming.Source, playlist streaming.SoundCloudPlaylist) (streaming.SoundCloudPlaylist, []SoundCloudPlaylistChange, error) {
+
+	ctx = fctx.WithMeta(
+		ctx,
+		"playlist_name", playlist.Name,
+		"playlist_external_id", fmt.Sprintf("%d", playlist.ExternalID),
+	)
+
+	sourceType := playlist.SourceType
+
+	if sourceType == "" {
+		sourceType = streaming.SoundCloudSourcePlaylist
+	}
+
+	dataTracks, err := e.SerenDB.ListSoundCloudTracksByPlaylistExternalID(
+		ctx,
+		data.ListSoundCloudTracksByPlaylistExternalIDParams{
+			PlaylistExternalID: sql.NullInt64{Valid: true, Int64: playlist.ExternalID},
+			SourceType:         string(sourceType),
+		},
+	)
+
+	if err != nil {
+		return streaming.SoundCloudPlaylist{}, nil, fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.With("error getting stored tracks"),
+		)
+	}
+
+	stored := make([]streaming.SoundCloudTrack, len(dataTracks))
+	for i, dt := range dataTracks {
+		stored[i].LoadFromPlaylistDB(dt)
+	}
+
+	url := playlist.PermalinkUrl
+
+	if url == "" {
+		url = playlist.SearchUrl
+	}
+
+	current, err := getSoundCloudPlaylist(ctx, s, url)
+
+	if err != nil {
+		return streaming.SoundCloudPlaylist{}, nil, fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.With("error getting playlist from SoundCloud"),
+		)
+	}
+
+	diff := internal.DiffPlaylistTracks(stored, current.Tracks)
+
+	current.SearchUrl = playlist.SearchUrl
+	current.Tracks = diff.Tracks
+
+	dataP, dataT := current.ToDB()
+
+	dataChanges := make([]data.SoundCloudTrackChange, len(diff.Changes))
+	changes := make([]SoundCloudPlaylistChange, len(diff.Changes))
+
+	now := time.Now()
+
+	for i, c := range diff.Changes {
+		dataChanges[i] = data.SoundCloudTrackChange{
+			TrackExternalID: c.Track.ExternalID,
+			ChangeType:      c.Type,
+			OldValue:        sql.NullString{Valid: c.OldValue != "", String: c.OldValue},
+			NewValue:        sql.NullString{Valid: c.NewValue != "", String: c.NewValue},
+		}
+		changes[i] = SoundCloudPlaylistChange{
+			CreatedAt:         now,
+			PlaylistName:      current.Name,
+			TrackName:         c.Track.Name,
+			TrackPermalinkUrl: c.Track.PermalinkUrl,
+			Type:              c.Type,
+			OldValue:          c.OldValue,
+			NewValue:          c.NewValue,
+		}
+	}
+
+	err = e.SerenDB.TxSaveSoundCloudPlaylistRefresh(ctx, dataP, dataT, dataChanges)
+
+	if err != nil {
+		return streaming.SoundCloudPlaylist{}, nil, fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.With("error saving refreshed playlist to database"),
+		)
+	}
+
+	return current, changes, nil
+}
+
+/*
+ListSoundCloudPlaylistChanges lists the changes logged when refreshing SoundCloud playlists, most recent first
+
+The changes are returned under the 'changes' key as a []SoundCloudPlaylistChange
+*/
+func (e *OpEnv) ListSoundCloudPlaylistChanges(ctx context.Context, opts ListSoundCloudPlaylistChangesOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	rows, err := e.SerenDB.ListSoundCloudPlaylistChanges(ctx, int64(opts.Limit))
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error listing playlist changes",
+				"There was an error getting the playlist changes from the database",
+			),
+		))
+		return
+	}
+
+	changes := make([]SoundCloudPlaylistChange, len(rows))
+
+	for i, r := range rows {
+		changes[i] = SoundCloudPlaylistChange{
+			CreatedAt:         r.CreatedAt.Time,
+			PlaylistName:      r.PlaylistName.String,
+			TrackName:         r.TrackName.String,
+			TrackPermalinkUrl: r.TrackPermalinkUrl.String,
+			Type:              r.ChangeType.String,
+			OldValue:          r.OldValue.String,
+			NewValue:          r.NewValue.String,
+		}
+	}
+
+	e.FinishSuccess(map[string]any{
+		"changes": changes,
+	})
+}
+
+/*
+ListSoundCloudPlaylistTrackHistory lists each period a track was in a SoundCloud playlist, from when it
+was added to when it was removed, most recently added first
+
+The history is returned under the 'history' key as a []SoundCloudPlaylistTrackPeriod
+*/
+func (e *OpEnv) ListSoundCloudPlaylistTrackHistory(ctx context.Context, opts ListSoundCloudPlaylistTrackHistoryOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	rows, err := e.SerenDB.ListSoundCloudPlaylistTrackHistory(ctx, data.ListSoundCloudPlaylistTrackHistoryParams{
+		PlaylistExternalID: sql.NullInt64{Valid: true, Int64: opts.PlaylistExternalID},
+		SourceType:         string(opts.PlaylistSourceType),
+	})
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(fctx.WithMeta(ctx, "playlist_external_id", fmt.Sprint(opts.PlaylistExternalID))),
+			fmsg.WithDesc(
+				"error listing playlist track history",
+				"There was an error getting the history of the playlist from the database",
+			),
+		))
+		return
+	}
+
+	history := make([]SoundCloudPlaylistTrackPeriod, len(rows))
+
+	for i, r := range rows {
+		history[i] = SoundCloudPlaylistTrackPeriod{
+			TrackExternalID:   r.TrackExternalID.Int64,
+			TrackName:         r.TrackName.String,
+			TrackPermalinkUrl: r.TrackPermalinkUrl.String,
+			AddedAt:           r.AddedAt.Time,
+			RemovedAt:         r.RemovedAt.Time,
+		}
+	}
+
+	e.FinishSuccess(map[string]any{
+		"history": history,
+	})
+}
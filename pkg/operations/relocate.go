@@ -0,0 +1,292 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sort"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/collection"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+RelocationSource is where a broken path was found
+*/
+type RelocationSource string
+
+const (
+	RelocationSoundCloud RelocationSource = "soundcloud" // the local path of a SoundCloud track
+	RelocationTraktor    RelocationSource = "traktor"    // the location of a Traktor collection entry
+)
+
+// DefaultMinRelocationConfidence is the default min confidence for a relocation to be proposed
+const DefaultMinRelocationConfidence = 0.3
+
+// traktorSizeTolerance allows for Traktor storing file sizes in kilobytes
+const traktorSizeTolerance = 1024
+
+/*
+RelocationMatch is a proposed new location for a file which can no longer be found
+*/
+type RelocationMatch struct {
+	Source     RelocationSource
+	OldPath    string
+	NewPath    string
+	Confidence float64 // 0-1
+	Reason     string
+}
+
+/*
+FindRelocations looks for SoundCloud tracks with a broken local path, and Traktor collection
+entries whose file no longer exists, then searches the local file index for where each file
+has been moved to, matching on content hash, file name and size
+
+The local file index should be built with IndexLocalFiles first. Proposed matches are returned under
+the 'matches' key as a []RelocationMatch sorted by confidence, and the number of broken
+paths without a match under the 'unmatched' key
+*/
+func (e *OpEnv) FindRelocations(ctx context.Context, opts FindRelocationsOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	files, err := e.SerenDB.ListLocalFiles(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error listing local files in db",
+				"There was an error getting the local file index from the database",
+			),
+		))
+		return
+	}
+
+	idx := internal.BuildRelocationIndex(files)
+
+	targets, err := e.getSoundCloudRelocationTargets(ctx)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error getting broken soundcloud paths",
+				"There was an error finding SoundCloud tracks with broken paths",
+			),
+		))
+		return
+	}
+
+	if e.Config.TraktorCollectionPath != "" {
+		traktorTargets, err := e.getTraktorRelocationTargets()
+
+		if err != nil {
+			e.FinishError(fault.Wrap(
+				err,
+				fmsg.WithDesc(
+					"error getting broken traktor locations",
+					"There was an error finding missing files in your collection",
+				),
+			))
+			return
+		}
+
+		targets = append(targets, traktorTargets...)
+	}
+
+	e.Logger.Infof("Found %v broken paths", len(targets))
+
+	var matches []RelocationMatch
+	var unmatched int
+
+	for _, t := range targets {
+		c, ok := idx.Match(t.target)
+
+		if !ok || c.Confidence < opts.MinConfidence {
+			e.Logger.Debugf("No match found for %s", t.target.Path)
+			unmatched++
+			continue
+		}
+
+		matches = append(matches, RelocationMatch{
+			Source:     t.source,
+			OldPath:    t.target.Path,
+			NewPath:    c.Path,
+			Confidence: c.Confidence,
+			Reason:     c.Reason,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+
+	e.Logger.Infof("Found %v possible matches, %v paths unmatched", len(matches), unmatched)
+
+	e.FinishSuccess(map[string]any{
+		"matches":   matches,
+		"unmatched": unmatched,
+	})
+}
+
+type relocationTarget struct {
+	source RelocationSource
+	target internal.RelocationTarget
+}
+
+/*
+getSoundCloudRelocationTargets returns each SoundCloud track local path which no longer exists,
+using the local file index entry from before it went missing for the size and hash if there is one
+*/
+func (e *OpEnv) getSoundCloudRelocationTargets(ctx context.Context) ([]relocationTarget, error) {
+
+	tracks, err := e.SerenDB.ListSoundCloudTracksHasLocalPath(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []relocationTarget
+
+	for _, track := range tracks {
+		t := streaming.SoundCloudTrack{}
+		t.LoadFromDB(track)
+
+		if t.LocalPath == "" || helpers.DoesFileExist(t.LocalPath) {
+			continue
+		}
+
+		path := filepath.ToSlash(t.LocalPath)
+		target := internal.RelocationTarget{Path: path}
+
+		f, err := e.SerenDB.GetLocalFileByPath(ctx, sql.NullString{Valid: true, String: path})
+
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		if err == nil {
+			target.Size = f.Size.Int64
+			target.ContentHash = f.ContentHash.String
+		}
+
+		targets = append(targets, relocationTarget{
+			source: RelocationSoundCloud,
+			target: target,
+		})
+	}
+
+	return targets, nil
+}
+
+/*
+getTraktorRelocationTargets returns the path of each collection entry which no longer exists
+*/
+func (e *OpEnv) getTraktorRelocationTargets() ([]relocationTarget, error) {
+
+	c := collection.ReadTraktorOpts{}.Build(e.Config)
+
+	tracks, err := c.ListTracks()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []relocationTarget
+
+	for _, track := range tracks {
+		if track.Path == "" || helpers.DoesFileExist(track.Path) {
+			continue
+		}
+
+		targets = append(targets, relocationTarget{
+			source: RelocationTraktor,
+			target: internal.RelocationTarget{
+				Path:          track.Path,
+				Size:          track.Size,
+				SizeTolerance: traktorSizeTolerance,
+			},
+		})
+	}
+
+	return targets, nil
+}
+
+/*
+ApplyRelocations updates the broken paths in opts.Matches to their new locations, in the
+database for SoundCloud tracks and in the collection for Traktor entries
+
+Stale entries for the old paths are removed from the local file index
+*/
+func (e *OpEnv) ApplyRelocations(ctx context.Context, opts ApplyRelocationsOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	soundCloudMoves := make(map[string]string)
+	traktorMoves := make(map[string]string)
+
+	for _, m := range opts.Matches {
+		switch m.Source {
+		case RelocationSoundCloud:
+			soundCloudMoves[m.OldPath] = m.NewPath
+		case RelocationTraktor:
+			traktorMoves[m.OldPath] = m.NewPath
+		}
+	}
+
+	if len(soundCloudMoves) > 0 {
+		e.updateMovedLocalPaths(ctx, soundCloudMoves)
+	}
+
+	if len(traktorMoves) > 0 {
+		e.updateMovedCollectionLocations(traktorMoves)
+	}
+
+	for _, m := range opts.Matches {
+		err := e.SerenDB.DeleteLocalFileByPath(ctx, sql.NullString{Valid: true, String: m.OldPath})
+
+		if err != nil {
+			e.Logger.NonFatalError(fault.Wrap(
+				err,
+				fctx.With(fctx.WithMeta(ctx, "path", m.OldPath)),
+				fmsg.With("error removing relocated file from index"),
+			))
+		}
+	}
+
+	e.FinishSuccess(map[string]any{
+		"soundcloud": len(soundCloudMoves),
+		"traktor":    len(traktorMoves),
+	})
+}
@@ -0,0 +1,164 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/collection"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/operations/internal"
+)
+
+/*
+Provides an operation for searching SoundCloud tracks, local files and collection entries from one place
+*/
+
+const (
+	DefaultSearchLimit = 50 // number of results returned by default
+)
+
+/*
+SearchLibrary searches the SoundCloud tracks, local files and collection entries in the database,
+see internal.ParseSearchQuery for what can be searched
+
+Local files are added to the index by IndexLocalFiles, and collection entries by IndexCollections or by
+setting IndexCollections in the opts
+
+The results are returned under the 'results' key as a slice of data.SearchResult
+*/
+func (e *OpEnv) SearchLibrary(ctx context.Context, opts SearchLibraryOpts) {
+
+	opts = opts.build()
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	ctx = fctx.WithMeta(ctx, "query", opts.Query)
+
+	// already checked
+	q, _ := internal.ParseSearchQuery(opts.Query)
+
+	if opts.IndexCollections {
+		n, err := e.indexCollections(ctx)
+
+		if err != nil {
+			e.FinishError(fault.Wrap(
+				err,
+				fctx.With(ctx),
+				fmsg.WithDesc(
+					"error indexing collection",
+					"There was an error reading your collection, check the collection path in the config",
+				),
+			))
+			return
+		}
+
+		e.Logger.Infof("updated %d collection entries", n)
+	}
+
+	arg := data.SearchParams{
+		Match:  q.Match,
+		Source: q.Source,
+		Key:    sql.NullString{Valid: q.Key != "", String: q.Key},
+		Limit:  int64(opts.Limit),
+	}
+
+	if q.HasBpm {
+		arg.MinBpm = sql.NullFloat64{Valid: true, Float64: q.MinBpm}
+		arg.MaxBpm = sql.NullFloat64{Valid: true, Float64: q.MaxBpm}
+	}
+
+	results, err := e.SerenDB.Search(ctx, arg)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error searching library",
+				"There was an error searching your library",
+			),
+		))
+		return
+	}
+
+	e.FinishSuccess(map[string]any{
+		"results": results,
+	})
+}
+
+/*
+IndexCollections updates the collection entries in the database to match the Traktor collection, so
+they can be searched, nothing is done if no collection path is set
+*/
+func (e *OpEnv) IndexCollections() {
+
+	n, err := e.indexCollections(context.Background())
+
+	if err != nil {
+		e.Logger.NonFatalError(fault.Wrap(
+			err,
+			fmsg.With("error indexing collection"),
+		))
+		return
+	}
+
+	e.Logger.Debugf("updated %d collection entries", n)
+}
+
+/*
+indexCollections updates the collection entries in the database to match the Traktor collection,
+returning the number of entries which were added, changed or removed
+*/
+func (e *OpEnv) indexCollections(ctx context.Context) (int, error) {
+
+	if e.Config.TraktorCollectionPath == "" {
+		return 0, nil
+	}
+
+	tracks, err := collection.ReadTraktorOpts{}.Build(e.Config).ListTracks()
+
+	if err != nil {
+		return 0, err
+	}
+
+	str := func(v string) sql.NullString {
+		return sql.NullString{Valid: v != "", String: v}
+	}
+
+	num := func(v float64) sql.NullFloat64 {
+		return sql.NullFloat64{Valid: v != 0, Float64: v}
+	}
+
+	entries := make([]data.CollectionTrack, len(tracks))
+
+	for i, t := range tracks {
+		entries[i] = data.CollectionTrack{
+			Path:     t.Path,
+			Title:    str(t.Title),
+			Artist:   str(t.Artist),
+			Album:    str(t.Album),
+			Genre:    str(t.Genre),
+			Label:    str(t.Label),
+			Comments: str(t.Comments),
+			Key:      str(t.Key),
+			Bpm:      num(t.BPM),
+			Duration: num(t.Duration),
+		}
+	}
+
+	return e.SerenDB.TxSyncCollectionTracks(ctx, MatchSourceTraktor, entries)
+}
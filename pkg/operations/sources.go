@@ -0,0 +1,228 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+Provides operations which work with playlists from any streaming platform, each platform is registered
+in platformSources so adding one doesn't need new operations or CLI commands
+*/
+
+/*
+platformSource is a streaming platform which playlists can be imported from
+
+urls is a client without credentials, only used to match and resolve URLs so that no requests are made
+until the playlist is imported. source returns a client with the credentials needed to get playlists and
+tracks, and importPlaylist gets the playlist and saves it to the platform's tables
+*/
+type platformSource struct {
+	urls           streaming.Source
+	source         func(e *OpEnv, ctx context.Context) (streaming.Source, error)
+	importPlaylist func(e *OpEnv, ctx context.Context, playlistURL string, refresh bool) (streaming.SourcePlaylist, error)
+}
+
+/*
+platformSources returns every registered platform, it's a function rather than a variable as the
+operations it refers to look up platforms themselves
+*/
+func platformSources() []platformSource {
+	return []platformSource{
+		{
+			urls: streaming.NewSoundCloud(""),
+			source: func(e *OpEnv, ctx context.Context) (streaming.Source, error) {
+				return e.soundCloudClient(ctx)
+			},
+			importPlaylist: (*OpEnv).importSoundCloudPlaylist,
+		},
+		{
+			urls: streaming.NewSpotify("", ""),
+			source: func(e *OpEnv, ctx context.Context) (streaming.Source, error) {
+				return e.spotifyClient()
+			},
+			importPlaylist: func(e *OpEnv, ctx context.Context, playlistURL string, refresh bool) (streaming.SourcePlaylist, error) {
+				p, err := e.importSpotifyPlaylist(ctx, GetSpotifyPlaylistOpts{PlaylistURL: playlistURL, Refresh: refresh})
+				return p.ToSourcePlaylist(), err
+			},
+		},
+	}
+}
+
+/*
+streamingSource returns the Source for a platform, with the credentials needed to get playlists and tracks
+*/
+func (e *OpEnv) streamingSource(ctx context.Context, platform streaming.Platform) (streaming.Source, error) {
+	for _, ps := range platformSources() {
+		if ps.urls.Platform() == platform {
+			return ps.source(e, ctx)
+		}
+	}
+	return nil, helpers.ErrUnsupportedURL
+}
+
+/*
+getSoundCloudPlaylist gets a playlist through the SoundCloud Source, as a SoundCloudPlaylist so that it
+can be stored in the SoundCloud tables
+*/
+func getSoundCloudPlaylist(ctx context.Context, s streaming.Source, playlistURL string) (streaming.SoundCloudPlaylist, error) {
+
+	sp, err := s.GetPlaylist(ctx, playlistURL)
+
+	if err != nil {
+		return streaming.SoundCloudPlaylist{}, err
+	}
+
+	var p streaming.SoundCloudPlaylist
+
+	err = p.LoadFromSource(sp)
+
+	if err != nil {
+		return streaming.SoundCloudPlaylist{}, err
+	}
+
+	return p, nil
+}
+
+/*
+platformSourceForURL returns the registered platform which the URL belongs to
+*/
+func platformSourceForURL(rawURL string) (platformSource, error) {
+	for _, ps := range platformSources() {
+		if ps.urls.Matches(rawURL) {
+			return ps, nil
+		}
+	}
+	return platformSource{}, helpers.ErrUnsupportedURL
+}
+
+/*
+importSoundCloudPlaylist runs GetSoundCloudPlaylist, which calls back before returning, as a function
+*/
+func (e *OpEnv) importSoundCloudPlaylist(ctx context.Context, playlistURL string, refresh bool) (streaming.SourcePlaylist, error) {
+	var playlist streaming.SourcePlaylist
+	var playlistErr error
+
+	e.GetSoundCloudPlaylist(ctx, GetSoundCloudPlaylistOpts{
+		PlaylistURL: playlistURL,
+		Refresh:     refresh,
+	}, func(p streaming.SourcePlaylist, err error) {
+		playlist, playlistErr = p, err
+	})
+
+	return playlist, playlistErr
+}
+
+/*
+ImportPlaylist gets a playlist from the streaming platform its URL belongs to and stores it, along with
+its tracks, in the database
+
+The playlist is returned under the 'playlist' key as a streaming.SourcePlaylist
+*/
+func (e *OpEnv) ImportPlaylist(ctx context.Context, opts ImportPlaylistOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"The URL must be a playlist from a supported streaming platform",
+			),
+		))
+		return
+	}
+
+	// Check has already made sure the URL belongs to a platform
+	ps, _ := platformSourceForURL(opts.PlaylistURL)
+
+	ctx = fctx.WithMeta(ctx, "platform", string(ps.urls.Platform()), "playlist_url", opts.PlaylistURL)
+
+	p, err := ps.importPlaylist(e, ctx, opts.PlaylistURL, opts.Refresh)
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fctx.With(ctx),
+		))
+		return
+	}
+
+	e.FinishSuccess(map[string]any{
+		"playlist": p,
+	})
+}
+
+/*
+ListPlaylists lists the playlists from every streaming platform, or from one platform, stored in the
+database along with their tracks
+
+The playlists are returned under the 'playlists' key as a []streaming.SourcePlaylist
+*/
+func (e *OpEnv) ListPlaylists(ctx context.Context, opts ListPlaylistsOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"There was an error whilst checking the options for the operation",
+			),
+		))
+		return
+	}
+
+	dataPlaylists, err := e.SerenDB.ListStreamingPlaylists(ctx, sql.NullString{
+		Valid:  opts.Platform != "",
+		String: string(opts.Platform),
+	})
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error getting playlists from db",
+				"There was an error getting your playlists from the database",
+			),
+		))
+		return
+	}
+
+	playlists := make([]streaming.SourcePlaylist, len(dataPlaylists))
+
+	for i, dp := range dataPlaylists {
+		dataTracks, err := e.SerenDB.ListStreamingTracksByPlaylist(ctx, data.ListStreamingTracksByPlaylistParams{
+			Platform:   dp.Platform,
+			SourceType: dp.SourceType,
+			ExternalID: dp.ExternalID,
+		})
+
+		if err != nil {
+			e.FinishError(fault.Wrap(
+				err,
+				fctx.With(fctx.WithMeta(ctx, "platform", dp.Platform, "playlist_external_id", dp.ExternalID.String)),
+				fmsg.WithDesc(
+					"error getting playlist tracks from db",
+					"There was an error getting the tracks of your playlists from the database",
+				),
+			))
+			return
+		}
+
+		playlists[i].LoadFromDB(dp, dataTracks)
+	}
+
+	e.FinishSuccess(map[string]any{
+		"playlists": playlists,
+	})
+}
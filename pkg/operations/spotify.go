@@ -0,0 +1,160 @@
+package operations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+Provides operations for importing Spotify playlists into the database
+*/
+
+/*
+spotifyClient returns a Spotify client using the client credentials from the config
+*/
+func (e *OpEnv) spotifyClient() (streaming.Spotify, error) {
+	if e.Config.SpotifyClientID == "" || e.Config.SpotifyClientSecret == "" {
+		return streaming.Spotify{}, helpers.ErrMissingSpotifyCredentials
+	}
+	return streaming.NewSpotify(e.Config.SpotifyClientID, e.Config.SpotifyClientSecret), nil
+}
+
+/*
+GetSpotifyPlaylist gets a playlist from Spotify and stores it, along with its tracks, in the database.
+When refreshing, tracks stored against the playlist but no longer in it are kept and flagged as removed
+
+The playlist is returned under the 'playlist' key as a streaming.SpotifyPlaylist
+*/
+func (e *OpEnv) GetSpotifyPlaylist(ctx context.Context, opts GetSpotifyPlaylistOpts) {
+
+	_, err := opts.Check()
+
+	if err != nil {
+		e.FinishError(fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error checking opts",
+				"The URL must be a Spotify playlist",
+			),
+		))
+		return
+	}
+
+	p, err := e.importSpotifyPlaylist(ctx, opts)
+
+	if err != nil {
+		e.FinishError(err)
+		return
+	}
+
+	e.FinishSuccess(map[string]any{
+		"playlist": p,
+	})
+}
+
+/*
+importSpotifyPlaylist gets a playlist from Spotify and saves it, the opts must have been checked
+*/
+func (e *OpEnv) importSpotifyPlaylist(ctx context.Context, opts GetSpotifyPlaylistOpts) (streaming.SpotifyPlaylist, error) {
+
+	s, err := e.spotifyClient()
+
+	if err != nil {
+		return streaming.SpotifyPlaylist{}, fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error building spotify client",
+				"SPOTIFY_CLIENT_ID and SPOTIFY_CLIENT_SECRET must be set to import Spotify playlists",
+			),
+		)
+	}
+
+	p, err := s.GetSpotifyPlaylist(ctx, opts.PlaylistURL)
+
+	if err != nil {
+		return streaming.SpotifyPlaylist{}, fault.Wrap(
+			err,
+			fmsg.WithDesc(
+				"error getting playlist info from Spotify",
+				"Error getting playlist information from Spotify",
+			),
+		)
+	}
+
+	ctx = fctx.WithMeta(ctx, "playlist_name", p.Name, "playlist_external_id", p.ExternalID)
+
+	externalID := sql.NullString{Valid: true, String: p.ExternalID}
+
+	numPlaylists, err := e.SerenDB.GetNumSpotifyPlaylistByExternalID(ctx, externalID)
+
+	if err != nil {
+		return streaming.SpotifyPlaylist{}, fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.With("error checking if playlist already exists in database by external id"),
+		)
+	}
+
+	if numPlaylists > 0 && !opts.Refresh {
+		return streaming.SpotifyPlaylist{}, fault.Wrap(
+			helpers.ErrPlaylistAlreadyExists,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"playlist with same external id already exists in db",
+				"That playlist has already been imported",
+			),
+		)
+	}
+
+	if numPlaylists > 0 {
+		stored, err := e.SerenDB.ListSpotifyTracksByPlaylistExternalID(ctx, externalID)
+
+		if err != nil {
+			return streaming.SpotifyPlaylist{}, fault.Wrap(
+				err,
+				fctx.With(ctx),
+				fmsg.With("error getting stored tracks"),
+			)
+		}
+
+		current := make(map[string]bool, len(p.Tracks))
+		for _, t := range p.Tracks {
+			current[t.ExternalID] = true
+		}
+
+		for _, dt := range stored {
+			if current[dt.SpotifyTrack.ExternalID.String] {
+				continue
+			}
+			var t streaming.SpotifyTrack
+			t.LoadFromDB(dt.SpotifyTrack)
+			t.RemovedFromPlaylist = true
+			p.Tracks = append(p.Tracks, t)
+		}
+	}
+
+	dataP, dataT := p.ToDB()
+
+	err = e.SerenDB.TxUpsertSpotifyPlaylistAndTracks(ctx, dataP, dataT)
+
+	if err != nil {
+		return streaming.SpotifyPlaylist{}, fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.WithDesc(
+				"error saving playlist to database",
+				"There was an error saving the Spotify playlist to the database",
+			),
+		)
+	}
+
+	e.Logger.Infof("Imported %s with %v tracks", p.Name, len(p.Tracks))
+
+	return p, nil
+}
@@ -0,0 +1,168 @@
+package purchases
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+bandcampItem is an item in a Bandcamp collection export, the fields follow the collection items
+returned by Bandcamp's fan collection API. tracks is only set by exporters which also get the tracks
+of each album
+*/
+type bandcampItem struct {
+	ItemID      json.Number     `json:"item_id"`
+	TralbumID   json.Number     `json:"tralbum_id"`
+	ItemType    string          `json:"item_type"`    // track, album or package
+	TralbumType string          `json:"tralbum_type"` // t or a
+	BandName    string          `json:"band_name"`
+	ItemTitle   string          `json:"item_title"`
+	AlbumTitle  string          `json:"album_title"`
+	ItemURL     string          `json:"item_url"`
+	Purchased   string          `json:"purchased"`
+	Label       string          `json:"label"`
+	SaleItemID  json.Number     `json:"sale_item_id"`
+	Tracks      []bandcampTrack `json:"tracks"`
+}
+
+type bandcampTrack struct {
+	TrackID  json.Number `json:"track_id"`
+	Title    string      `json:"title"`
+	Artist   string      `json:"artist"`
+	Duration float64     `json:"duration"`
+}
+
+var bandcampDateLayouts = []string{
+	"02 Jan 2006 15:04:05 MST",
+	time.RFC1123,
+	time.RFC3339,
+	"2006-01-02",
+}
+
+/*
+ReadBandcampJSON reads the purchases in a Bandcamp collection exported as JSON, either a list of
+items or an object with the items under 'items'
+
+Albums with a list of tracks are read as a purchase for each track, albums without one are read as a
+single purchase of the whole album
+*/
+func ReadBandcampJSON(r io.Reader) ([]Purchase, error) {
+
+	body, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, fault.Wrap(
+			err,
+			fmsg.With("Error reading Bandcamp export"),
+		)
+	}
+
+	body = bytes.TrimSpace(body)
+
+	if len(body) == 0 {
+		return nil, helpers.ErrEmptyPurchaseExport
+	}
+
+	var items []bandcampItem
+
+	if body[0] == '[' {
+		err = json.Unmarshal(body, &items)
+	} else {
+		var export struct {
+			Items []bandcampItem `json:"items"`
+		}
+		err = json.Unmarshal(body, &export)
+		items = export.Items
+	}
+
+	if err != nil {
+		return nil, fault.Wrap(
+			err,
+			fmsg.With("Error unmarshalling Bandcamp export"),
+		)
+	}
+
+	purchases := []Purchase{}
+
+	for _, item := range items {
+		purchases = append(purchases, item.purchases()...)
+	}
+
+	return purchases, nil
+}
+
+func (item bandcampItem) purchases() []Purchase {
+
+	id := item.ItemID.String()
+	if id == "" {
+		id = item.TralbumID.String()
+	}
+
+	if id == "" || item.ItemTitle == "" {
+		return nil
+	}
+
+	base := Purchase{
+		Store:       StoreBandcamp,
+		ExternalID:  id,
+		OrderID:     item.SaleItemID.String(),
+		PurchasedAt: parseTime(item.Purchased, bandcampDateLayouts...),
+		Artist:      item.BandName,
+		Label:       item.Label,
+		URL:         item.ItemURL,
+	}
+
+	if item.ItemType == "track" || item.TralbumType == "t" {
+		p := base
+		p.Kind = KindTrack
+		p.Title = item.ItemTitle
+		p.Album = item.AlbumTitle
+		if len(item.Tracks) == 1 {
+			p.Duration = item.Tracks[0].Duration
+		}
+		return []Purchase{p}
+	}
+
+	if len(item.Tracks) == 0 {
+		p := base
+		p.Kind = KindAlbum
+		p.Album = item.ItemTitle
+		return []Purchase{p}
+	}
+
+	purchases := make([]Purchase, 0, len(item.Tracks))
+
+	for i, t := range item.Tracks {
+		if t.Title == "" {
+			continue
+		}
+
+		p := base
+		p.Kind = KindTrack
+		p.Title = t.Title
+		p.Album = item.ItemTitle
+		p.Duration = t.Duration
+
+		// compilations credit each track to its own artist
+		if t.Artist != "" {
+			p.Artist = t.Artist
+		}
+
+		trackID := t.TrackID.String()
+		if trackID == "" {
+			trackID = fmt.Sprint(i + 1)
+		}
+		p.ExternalID = id + ":" + trackID
+
+		purchases = append(purchases, p)
+	}
+
+	return purchases
+}
@@ -0,0 +1,172 @@
+package purchases
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+beatportColumns are the headers used for each field in Beatport's order history export, the export
+has changed over time so each field accepts a few headers
+*/
+var beatportColumns = map[string][]string{
+	"order":    {"order id", "order number", "order"},
+	"date":     {"order date", "purchase date", "date"},
+	"id":       {"track id", "id"},
+	"title":    {"track title", "track name", "title", "track"},
+	"mix":      {"mix", "mix name"},
+	"artists":  {"artists", "artist"},
+	"remixers": {"remixers", "remixer"},
+	"release":  {"release", "release title", "release name"},
+	"label":    {"label"},
+	"isrc":     {"isrc"},
+	"length":   {"length", "duration"},
+	"url":      {"url", "track url", "link"},
+}
+
+var beatportDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006 15:04",
+	"01/02/2006",
+}
+
+/*
+ReadBeatportCSV reads the tracks in an order history CSV exported from Beatport
+
+Columns are found by their header, only the title and artists are required. The mix is added to the
+title as it is in the files Beatport sells, i.e. 'Title (Original Mix)'
+*/
+func ReadBeatportCSV(r io.Reader) ([]Purchase, error) {
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+
+	if errors.Is(err, io.EOF) {
+		return nil, helpers.ErrEmptyPurchaseExport
+	}
+
+	if err != nil {
+		return nil, fault.Wrap(
+			err,
+			fmsg.With("Error reading Beatport CSV header"),
+		)
+	}
+
+	cols := map[string]int{}
+
+	for i, h := range header {
+		// Excel adds a byte order mark to the start of CSVs it saves
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		for field, names := range beatportColumns {
+			if _, ok := cols[field]; ok {
+				continue
+			}
+			for _, name := range names {
+				if h == name {
+					cols[field] = i
+				}
+			}
+		}
+	}
+
+	_, hasTitle := cols["title"]
+	_, hasArtists := cols["artists"]
+
+	if !hasTitle || !hasArtists {
+		return nil, helpers.ErrMissingPurchaseColumns
+	}
+
+	purchases := []Purchase{}
+
+	for {
+		record, err := cr.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fault.Wrap(
+				err,
+				fmsg.With("Error reading Beatport CSV row"),
+			)
+		}
+
+		get := func(field string) string {
+			i, ok := cols[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		title := get("title")
+		artist := get("artists")
+
+		// totals and blank lines at the end of the export
+		if title == "" || artist == "" {
+			continue
+		}
+
+		if mix := get("mix"); mix != "" && !strings.Contains(strings.ToLower(title), strings.ToLower(mix)) {
+			title += " (" + mix + ")"
+		}
+
+		p := Purchase{
+			Store:       StoreBeatport,
+			ExternalID:  get("id"),
+			OrderID:     get("order"),
+			PurchasedAt: parseTime(get("date"), beatportDateLayouts...),
+			Kind:        KindTrack,
+			Artist:      artist,
+			Title:       title,
+			Album:       get("release"),
+			Label:       get("label"),
+			ISRC:        get("isrc"),
+			URL:         get("url"),
+			Duration:    parseLength(get("length")),
+		}
+
+		if p.ExternalID == "" {
+			p.ExternalID = fallbackExternalID(p.OrderID, artist, title)
+		}
+
+		purchases = append(purchases, p)
+	}
+
+	return purchases, nil
+}
+
+/*
+parseLength parses a track length in the form 'm:ss' or 'h:mm:ss', 0 is returned if it can't be parsed
+*/
+func parseLength(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	var seconds float64
+
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+
+	return seconds
+}
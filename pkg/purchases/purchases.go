@@ -0,0 +1,135 @@
+package purchases
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/billiem/seren-management/pkg/data"
+)
+
+/*
+Provides readers for the purchase history exported from music stores, so bought tracks can be
+checked against the local library
+*/
+
+type Store string
+
+const (
+	StoreBeatport Store = "beatport"
+	StoreBandcamp Store = "bandcamp"
+)
+
+func (s Store) Check() bool {
+	switch s {
+	case StoreBeatport, StoreBandcamp:
+		return true
+	}
+	return false
+}
+
+type Kind string
+
+const (
+	KindTrack Kind = "track"
+	KindAlbum Kind = "album" // an album bought without a list of its tracks
+)
+
+/*
+Purchase is a track or album bought from a store
+
+LocalPath is the file the purchase has been matched to in the local file index, or for albums the
+directory holding the matched files. It is empty if the purchase hasn't been downloaded
+*/
+type Purchase struct {
+	Store           Store
+	ExternalID      string // unique within the store
+	OrderID         string
+	PurchasedAt     time.Time
+	Kind            Kind
+	Artist          string
+	Title           string // empty for albums
+	Album           string
+	Label           string
+	ISRC            string
+	URL             string
+	Duration        float64 // in seconds, 0 if unknown
+	LocalPath       string
+	MatchConfidence float64
+}
+
+func (p Purchase) String() string {
+	if p.Kind == KindAlbum {
+		return fmt.Sprintf("%s: %s - %s (album)", p.Store, p.Artist, p.Album)
+	}
+	return fmt.Sprintf("%s: %s - %s", p.Store, p.Artist, p.Title)
+}
+
+/*
+LoadFromDB loads a Purchase from a data.Purchase
+*/
+func (p *Purchase) LoadFromDB(dp data.Purchase) {
+	p.Store = Store(dp.Store)
+	p.ExternalID = dp.ExternalID
+	p.OrderID = dp.OrderID.String
+	p.PurchasedAt = dp.PurchasedAt.Time
+	p.Kind = Kind(dp.Kind.String)
+	p.Artist = dp.Artist.String
+	p.Title = dp.Title.String
+	p.Album = dp.Album.String
+	p.Label = dp.Label.String
+	p.ISRC = dp.Isrc.String
+	p.URL = dp.Url.String
+	p.Duration = dp.Duration.Float64
+	p.LocalPath = dp.LocalPath.String
+	p.MatchConfidence = dp.MatchConfidence.Float64
+}
+
+/*
+ToDB converts the purchase to a data.Purchase, the local path and match confidence are left unset
+as they are stored separately when matching
+*/
+func (p Purchase) ToDB() data.Purchase {
+	return data.Purchase{
+		Store:       string(p.Store),
+		ExternalID:  p.ExternalID,
+		OrderID:     nullString(p.OrderID),
+		PurchasedAt: sql.NullTime{Valid: !p.PurchasedAt.IsZero(), Time: p.PurchasedAt},
+		Kind:        sql.NullString{Valid: true, String: string(p.Kind)},
+		Artist:      nullString(p.Artist),
+		Title:       nullString(p.Title),
+		Album:       nullString(p.Album),
+		Label:       nullString(p.Label),
+		Isrc:        nullString(p.ISRC),
+		Url:         nullString(p.URL),
+		Duration:    sql.NullFloat64{Valid: p.Duration > 0, Float64: p.Duration},
+	}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{Valid: s != "", String: s}
+}
+
+/*
+fallbackExternalID builds an ID for purchases the store didn't give an ID for, from the order and
+the track so importing the same export twice doesn't duplicate purchases
+*/
+func fallbackExternalID(orderID string, artist string, title string) string {
+	return strings.ToLower(fmt.Sprintf("%s:%s - %s", orderID, artist, title))
+}
+
+/*
+parseTime parses a date in any of the layouts, a zero time is returned if none match
+*/
+func parseTime(s string, layouts ...string) time.Time {
+	s = strings.TrimSpace(s)
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
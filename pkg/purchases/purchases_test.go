@@ -0,0 +1,207 @@
+package purchases
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadBeatportCSV(t *testing.T) {
+	f, err := os.Open("testdata/beatport_orders.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := ReadBeatportCSV(f)
+
+	if err != nil {
+		t.Fatalf("ReadBeatportCSV() error = %v", err)
+	}
+
+	ordered := time.Date(2023, 11, 2, 18, 21, 5, 0, time.UTC)
+
+	want := []Purchase{
+		{
+			Store:       StoreBeatport,
+			ExternalID:  "17654321",
+			OrderID:     "1001",
+			PurchasedAt: ordered,
+			Kind:        KindTrack,
+			Artist:      "Coolman, Another Artist",
+			Title:       "Funky Song (Original Mix)",
+			Album:       "Funky Album",
+			Label:       "Cool Records",
+			ISRC:        "GBAAA2300001",
+			Duration:    392,
+		},
+		{
+			Store:       StoreBeatport,
+			ExternalID:  "17654322",
+			OrderID:     "1001",
+			PurchasedAt: ordered,
+			Kind:        KindTrack,
+			Artist:      "Third Artist",
+			Title:       "Late Night (Extended Mix)",
+			Album:       "Late Night EP",
+			Label:       "Night Label",
+			ISRC:        "USBBB2300002",
+			Duration:    425,
+		},
+		{
+			Store:       StoreBeatport,
+			ExternalID:  "1002:fourth - sunrise (fourth remix)",
+			OrderID:     "1002",
+			PurchasedAt: time.Date(2023, 12, 24, 0, 0, 0, 0, time.UTC),
+			Kind:        KindTrack,
+			Artist:      "Fourth",
+			Title:       "Sunrise (Fourth Remix)",
+			Album:       "Sunrise",
+			Label:       "Dawn",
+			Duration:    300,
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReadBeatportCSV() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadBeatportCSVErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		wantErr error
+	}{
+		{name: "empty", csv: "", wantErr: helpers.ErrEmptyPurchaseExport},
+		{name: "missing artist column", csv: "Order ID,Track Title\n1,Song\n", wantErr: helpers.ErrMissingPurchaseColumns},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadBeatportCSV(strings.NewReader(tt.csv))
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReadBeatportCSV() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadBeatportCSVByteOrderMark(t *testing.T) {
+	got, err := ReadBeatportCSV(strings.NewReader("\ufeffTrack Title,Artists\nSong,Artist\n"))
+
+	if err != nil {
+		t.Fatalf("ReadBeatportCSV() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].Title != "Song" {
+		t.Errorf("ReadBeatportCSV() = %v", got)
+	}
+}
+
+func TestReadBandcampJSON(t *testing.T) {
+	f, err := os.Open("testdata/bandcamp_collection.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := ReadBandcampJSON(f)
+
+	if err != nil {
+		t.Fatalf("ReadBandcampJSON() error = %v", err)
+	}
+
+	sampler := time.Date(2023, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	want := []Purchase{
+		{
+			Store:       StoreBandcamp,
+			ExternalID:  "3001",
+			OrderID:     "555",
+			PurchasedAt: time.Date(2023, 2, 14, 21, 38, 15, 0, time.UTC),
+			Kind:        KindTrack,
+			Artist:      "Coolman",
+			Title:       "Funky Song",
+			Album:       "Funky Album",
+			URL:         "https://coolman.bandcamp.com/track/funky-song",
+		},
+		{
+			Store:       StoreBandcamp,
+			ExternalID:  "3002:11",
+			PurchasedAt: sampler,
+			Kind:        KindTrack,
+			Artist:      "Third Artist",
+			Title:       "Opener",
+			Album:       "Label Sampler",
+			URL:         "https://label.bandcamp.com/album/label-sampler",
+			Duration:    301.5,
+		},
+		{
+			Store:       StoreBandcamp,
+			ExternalID:  "3002:12",
+			PurchasedAt: sampler,
+			Kind:        KindTrack,
+			Artist:      "Various Artists",
+			Title:       "Closer",
+			Album:       "Label Sampler",
+			URL:         "https://label.bandcamp.com/album/label-sampler",
+			Duration:    280,
+		},
+		{
+			Store:       StoreBandcamp,
+			ExternalID:  "3003",
+			PurchasedAt: time.Date(2023, 3, 2, 10, 0, 0, 0, time.UTC),
+			Kind:        KindAlbum,
+			Artist:      "Fourth",
+			Album:       "Sunrise LP",
+			URL:         "https://fourth.bandcamp.com/album/sunrise-lp",
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReadBandcampJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadBandcampJSONList(t *testing.T) {
+	got, err := ReadBandcampJSON(strings.NewReader(`[{"item_id": "1", "item_type": "track", "band_name": "A", "item_title": "B"}]`))
+
+	if err != nil {
+		t.Fatalf("ReadBandcampJSON() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].ExternalID != "1" || got[0].Title != "B" {
+		t.Errorf("ReadBandcampJSON() = %v", got)
+	}
+
+	if _, err := ReadBandcampJSON(strings.NewReader("  ")); !errors.Is(err, helpers.ErrEmptyPurchaseExport) {
+		t.Errorf("ReadBandcampJSON() error = %v, want %v", err, helpers.ErrEmptyPurchaseExport)
+	}
+}
+
+func TestPurchaseDBRoundTrip(t *testing.T) {
+	p := Purchase{
+		Store:       StoreBeatport,
+		ExternalID:  "17654321",
+		OrderID:     "1001",
+		PurchasedAt: time.Date(2023, 11, 2, 18, 21, 5, 0, time.UTC),
+		Kind:        KindTrack,
+		Artist:      "Coolman",
+		Title:       "Funky Song (Original Mix)",
+		Duration:    392,
+	}
+
+	var got Purchase
+	got.LoadFromDB(p.ToDB())
+
+	if diff := cmp.Diff(p, got); diff != "" {
+		t.Errorf("LoadFromDB(ToDB()) mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,27 @@
+package streaming
+
+import "strings"
+
+/*
+ArtworkURLVariants returns the urls of the artwork sizes SoundCloud serves for an artwork url,
+from the highest resolution down, ending with the url itself
+
+SoundCloud artwork urls end in a size suffix (i.e. '-large.jpg' for 100x100), which can be
+replaced to get the original upload or a 500x500 version. The original isn't always available,
+so each url should be tried in order
+*/
+func ArtworkURLVariants(url string) []string {
+	i := strings.LastIndex(url, "-large.")
+
+	if i == -1 {
+		return []string{url}
+	}
+
+	base, ext := url[:i], url[i+len("-large"):]
+
+	return []string{
+		base + "-original" + ext,
+		base + "-t500x500" + ext,
+		url,
+	}
+}
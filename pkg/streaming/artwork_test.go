@@ -0,0 +1,41 @@
+package streaming_test
+
+import (
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/streaming"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestArtworkURLVariants(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want []string
+	}{
+		{
+			name: "large artwork",
+			url:  "https://i1.sndcdn.com/artworks-000123-abcdef-large.jpg",
+			want: []string{
+				"https://i1.sndcdn.com/artworks-000123-abcdef-original.jpg",
+				"https://i1.sndcdn.com/artworks-000123-abcdef-t500x500.jpg",
+				"https://i1.sndcdn.com/artworks-000123-abcdef-large.jpg",
+			},
+		},
+		{
+			name: "no size suffix",
+			url:  "https://i1.sndcdn.com/artworks-000123-abcdef-t500x500.png",
+			want: []string{"https://i1.sndcdn.com/artworks-000123-abcdef-t500x500.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := streaming.ArtworkURLVariants(tt.url)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ArtworkURLVariants() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
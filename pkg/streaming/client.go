@@ -0,0 +1,392 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides the HTTP client used for all requests to SoundCloud, requests are rate limited and
+retried with a backoff when SoundCloud is rate limiting or has an error
+*/
+
+const (
+	DefaultSoundCloudAPIURL            = "https://api-v2.soundcloud.com"
+	DefaultSoundCloudWebURL            = "https://soundcloud.com"
+	DefaultSoundCloudAppVersion        = "1700828706"
+	DefaultSoundCloudRequestsPerSecond = 5
+	DefaultSoundCloudBurst             = 5
+	DefaultSoundCloudMaxRetries        = 3
+	DefaultSoundCloudRetryBackoff      = time.Second
+	DefaultSoundCloudTimeout           = 30 * time.Second // max time to wait for response headers
+)
+
+/*
+SoundCloud is a client for the SoundCloud API and website
+
+The zero value (apart from the ClientID) talks to the real SoundCloud without rate limiting,
+NewSoundCloud should be used to get a client with the defaults set
+*/
+type SoundCloud struct {
+	ClientID string
+
+	HTTPClient   *http.Client  // defaults to a client with DefaultSoundCloudTimeout
+	APIURL       string        // defaults to DefaultSoundCloudAPIURL
+	WebURL       string        // defaults to DefaultSoundCloudWebURL
+	AppVersion   string        // defaults to DefaultSoundCloudAppVersion
+	MaxRetries   int           // number of times a request is retried after a 429 or 5xx response
+	RetryBackoff time.Duration // wait before the first retry, doubled on each following retry
+
+	limiter   *tokenBucket   // shared between copies, as methods use value receivers
+	clientIDs *clientIDCache // set by WithClientIDRefresh, shared between copies
+}
+
+/*
+NewSoundCloud returns a SoundCloud client using the default settings
+*/
+func NewSoundCloud(clientID string) SoundCloud {
+	return SoundCloud{
+		ClientID:     clientID,
+		HTTPClient:   defaultSoundCloudHTTPClient(),
+		APIURL:       DefaultSoundCloudAPIURL,
+		WebURL:       DefaultSoundCloudWebURL,
+		AppVersion:   DefaultSoundCloudAppVersion,
+		MaxRetries:   DefaultSoundCloudMaxRetries,
+		RetryBackoff: DefaultSoundCloudRetryBackoff,
+		limiter:      newTokenBucket(DefaultSoundCloudRequestsPerSecond, DefaultSoundCloudBurst),
+	}
+}
+
+/*
+WithRateLimit returns a copy of the client limited to requestsPerSecond, with bursts of up to burst
+requests. A requestsPerSecond of 0 disables rate limiting
+*/
+func (s SoundCloud) WithRateLimit(requestsPerSecond float64, burst int) SoundCloud {
+	s.limiter = nil
+	if requestsPerSecond > 0 {
+		s.limiter = newTokenBucket(requestsPerSecond, burst)
+	}
+	return s
+}
+
+/*
+WithClientIDRefresh returns a copy of the client which calls refresh to get a new client_id when
+an API request is rejected with a 401 or 403, the request is then retried once with the new client_id
+
+The new client_id is used by every copy of the returned client
+*/
+func (s SoundCloud) WithClientIDRefresh(refresh func(ctx context.Context) (string, error)) SoundCloud {
+	s.clientIDs = &clientIDCache{
+		id:      s.ClientID,
+		refresh: refresh,
+	}
+	return s
+}
+
+/*
+clientIDCache holds the latest client_id of a client, so a client_id is only refreshed once
+when several requests are rejected at the same time
+*/
+type clientIDCache struct {
+	mu      sync.Mutex
+	id      string
+	refresh func(ctx context.Context) (string, error)
+}
+
+/*
+refreshAfter gets a new client_id, unless the client_id has already been refreshed since
+rejected was used
+*/
+func (c *clientIDCache) refreshAfter(ctx context.Context, rejected string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.id != rejected {
+		return c.id, nil
+	}
+
+	id, err := c.refresh(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	c.id = id
+
+	return id, nil
+}
+
+func (s SoundCloud) clientID() string {
+	if s.clientIDs == nil {
+		return s.ClientID
+	}
+
+	s.clientIDs.mu.Lock()
+	defer s.clientIDs.mu.Unlock()
+
+	return s.clientIDs.id
+}
+
+/*
+defaultSoundCloudHTTPClient times out waiting for a response rather than for the whole request,
+so large file downloads aren't cut off
+*/
+func defaultSoundCloudHTTPClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ResponseHeaderTimeout = DefaultSoundCloudTimeout
+	return &http.Client{Transport: t}
+}
+
+func (s SoundCloud) httpClient() *http.Client {
+	if s.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return s.HTTPClient
+}
+
+func (s SoundCloud) apiURL() string {
+	if s.APIURL == "" {
+		return DefaultSoundCloudAPIURL
+	}
+	return s.APIURL
+}
+
+func (s SoundCloud) webURL() string {
+	if s.WebURL == "" {
+		return DefaultSoundCloudWebURL
+	}
+	return s.WebURL
+}
+
+func (s SoundCloud) appVersion() string {
+	if s.AppVersion == "" {
+		return DefaultSoundCloudAppVersion
+	}
+	return s.AppVersion
+}
+
+/*
+newAPIRequest builds a GET request to the given path of the SoundCloud API, adding the
+client_id, app_version and app_locale to the query
+*/
+func (s SoundCloud) newAPIRequest(ctx context.Context, path string, query url.Values) (*http.Request, error) {
+	return s.newAPIRequestURL(ctx, s.apiURL()+path, query)
+}
+
+/*
+newAPIRequestURL builds a GET request to a full SoundCloud API url, such as the 'next_href' of a
+page of results, keeping its query and adding the client_id, app_version and app_locale
+*/
+func (s SoundCloud) newAPIRequestURL(ctx context.Context, rawURL string, query url.Values) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+
+	for k, v := range query {
+		q[k] = v
+	}
+
+	q.Set("client_id", s.clientID())
+	q.Set("app_locale", "en")
+	q.Set("app_version", s.appVersion())
+
+	req.URL.RawQuery = q.Encode()
+
+	return req, nil
+}
+
+/*
+do sends a request once allowed by the rate limiter, retrying if the request fails or the
+response is a 429 or 5xx, the response of the last attempt is returned
+
+If the client_id of an API request is rejected and the client can refresh its client_id, the
+request is sent again with a new client_id
+
+Only requests without a body can be retried
+*/
+func (s SoundCloud) do(req *http.Request) (*http.Response, error) {
+	backoff := s.RetryBackoff
+	refreshed := false
+
+	for attempt := 0; ; attempt++ {
+		if s.limiter != nil {
+			err := s.limiter.Wait(req.Context())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := s.httpClient().Do(req)
+
+		if err == nil && !refreshed && isRejectedClientID(resp.StatusCode) && s.canRefreshClientID(req) {
+			resp.Body.Close()
+			refreshed = true
+
+			err = s.setRefreshedClientID(req)
+
+			if err != nil {
+				return nil, err
+			}
+
+			attempt--
+			continue
+		}
+
+		if attempt >= s.MaxRetries || req.Context().Err() != nil || (err == nil && !isRetryableStatus(resp.StatusCode)) {
+			return resp, err
+		}
+
+		wait := backoff
+
+		if err == nil {
+			if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > 0 {
+				wait = retryAfter
+			}
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+	}
+}
+
+func (s SoundCloud) canRefreshClientID(req *http.Request) bool {
+	return s.clientIDs != nil && s.clientIDs.refresh != nil && req.URL.Query().Has("client_id")
+}
+
+/*
+setRefreshedClientID replaces the client_id in the query of req with a refreshed client_id
+*/
+func (s SoundCloud) setRefreshedClientID(req *http.Request) error {
+	q := req.URL.Query()
+
+	id, err := s.clientIDs.refreshAfter(req.Context(), q.Get("client_id"))
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("error refreshing SoundCloud client_id"),
+		)
+	}
+
+	q.Set("client_id", id)
+	req.URL.RawQuery = q.Encode()
+
+	return nil
+}
+
+/*
+get sends a GET request to the given url using do, returning an error for a non 200 response
+*/
+func (s SoundCloud) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", helpers.ErrUnexpectedStatusCode, resp.Status)
+	}
+
+	return resp, nil
+}
+
+func isRejectedClientID(code int) bool {
+	return code == http.StatusUnauthorized || code == http.StatusForbidden
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+/*
+parseRetryAfter parses a Retry-After header given in seconds, returning 0 if it isn't set or
+is a date
+*/
+func parseRetryAfter(s string) time.Duration {
+	secs, err := strconv.Atoi(s)
+
+	if err != nil || secs < 0 {
+		return 0
+	}
+
+	return time.Duration(secs) * time.Second
+}
+
+/*
+tokenBucket is a token bucket rate limiter, tokens are added at rate per second up to burst,
+and each request takes a token
+*/
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+/*
+Wait blocks until a token is available or the context is done
+*/
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+
+		now := time.Now()
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
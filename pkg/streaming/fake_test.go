@@ -0,0 +1,357 @@
+package streaming_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+/*
+fakeSoundCloud is a local SoundCloud serving recorded responses from testdata, so the streaming
+package can be tested offline
+
+The website is served at the root of the server and the API under '/api'
+*/
+type fakeSoundCloud struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures []int // status codes returned by the next API requests, before responding normally
+	requests int   // number of API requests received
+}
+
+const (
+	fakePlaylistPath = "/coolman/sets/test"
+	fakeUserPath     = "/coolman"
+	fakeTrackPath    = "/thirdartist/third-artist---late-night"
+	fakeClientID     = "abcdefghijklmnopqrstuvwxyz123456"
+)
+
+func newFakeSoundCloud(t *testing.T) *fakeSoundCloud {
+	t.Helper()
+
+	playlistHTML, err := os.ReadFile("testdata/playlist.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userHTML, err := os.ReadFile("testdata/user.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trackHTML, err := os.ReadFile("testdata/track.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracksJSON, err := os.ReadFile("testdata/tracks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tracks []map[string]any
+	if err := json.Unmarshal(tracksJSON, &tracks); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeSoundCloud{}
+
+	api := http.NewServeMux()
+
+	api.HandleFunc("/tracks", func(w http.ResponseWriter, r *http.Request) {
+		ids := map[string]bool{}
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			ids[id] = true
+		}
+
+		out := []map[string]any{}
+		for _, track := range tracks {
+			if ids[strconv.FormatFloat(track["id"].(float64), 'f', -1, 64)] {
+				out = append(out, track)
+			}
+		}
+
+		json.NewEncoder(w).Encode(out)
+	})
+
+	api.HandleFunc("/tracks/", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/tracks/"), "/download")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"redirectUri": fmt.Sprintf("%s/files/%s", f.URL, id),
+		})
+	})
+
+	// likes are split over two pages to test following next_href
+	api.HandleFunc("/users/100/track_likes", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"collection": []map[string]any{{"kind": "like", "track": tracks[0]}},
+				"next_href":  f.URL + "/api/users/100/track_likes?offset=1&limit=1",
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"collection": []map[string]any{{"kind": "like", "track": tracks[1]}},
+			"next_href":  nil,
+		})
+	})
+
+	api.HandleFunc("/users/100/tracks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"collection": tracks,
+		})
+	})
+
+	api.HandleFunc("/stream/users/100/reposts", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"collection": []map[string]any{
+				{"type": "track-repost", "track": tracks[1]},
+				{"type": "playlist-repost", "playlist": map[string]any{"id": 5, "kind": "playlist"}},
+			},
+		})
+	})
+
+	mux := http.NewServeMux()
+
+	mux.Handle("/api/", http.StripPrefix("/api", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("client_id") != fakeClientID {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		f.mu.Lock()
+		f.requests++
+		var status int
+		if len(f.failures) > 0 {
+			status, f.failures = f.failures[0], f.failures[1:]
+		}
+		f.mu.Unlock()
+
+		if status != 0 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+
+		api.ServeHTTP(w, r)
+	})))
+
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/files/")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="track %s.wav"`, id))
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader([]byte("audio data for track "+id)))
+	})
+
+	mux.HandleFunc("/assets/app.js", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `var config={client_id:"%s",app_version:"1700828706"};`, fakeClientID)
+	})
+
+	mux.HandleFunc(fakePlaylistPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(playlistHTML)
+	})
+
+	for _, path := range []string{fakeUserPath + "/likes", fakeUserPath + "/tracks", fakeUserPath + "/reposts"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Write(userHTML)
+		})
+	}
+
+	mux.HandleFunc(fakeTrackPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(trackHTML)
+	})
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `<html><body><script crossorigin src="%s/assets/app.js"></script></body></html>`, f.URL)
+	})
+
+	f.Server = httptest.NewTLSServer(mux)
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+/*
+client returns a SoundCloud client using the fake, without rate limiting and with short retries
+*/
+func (f *fakeSoundCloud) client() streaming.SoundCloud {
+	s := streaming.NewSoundCloud(fakeClientID).WithRateLimit(0, 0)
+	s.HTTPClient = f.Client()
+	s.APIURL = f.URL + "/api"
+	s.WebURL = f.URL
+	s.RetryBackoff = time.Millisecond
+	return s
+}
+
+func (f *fakeSoundCloud) failNext(statuses ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, statuses...)
+}
+
+func (f *fakeSoundCloud) numRequests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+/*
+fakeSpotify is a local Spotify serving recorded responses from testdata, the accounts service is
+served at the root of the server and the API under '/v1'
+*/
+type fakeSpotify struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	tokens        int  // number of access tokens issued
+	rejectToken   bool // reject the next API request as if the token had expired
+	tokenRequests int
+}
+
+const (
+	fakeSpotifyPlaylistID   = "37i9dQZF1DXcBWIGoYBM5M"
+	fakeSpotifyClientID     = "spotifyclientid"
+	fakeSpotifyClientSecret = "spotifyclientsecret"
+)
+
+func newFakeSpotify(t *testing.T) *fakeSpotify {
+	t.Helper()
+
+	playlistJSON, err := os.ReadFile("testdata/spotify_playlist.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracksJSON, err := os.ReadFile("testdata/spotify_tracks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeSpotify{}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/token", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.tokenRequests++
+		f.mu.Unlock()
+
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != fakeSpotifyClientID || secret != fakeSpotifyClientSecret || r.Method != "POST" {
+			http.Error(w, "invalid_client", http.StatusBadRequest)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+			http.Error(w, "unsupported_grant_type", http.StatusBadRequest)
+			return
+		}
+
+		f.mu.Lock()
+		f.tokens++
+		token := fmt.Sprintf("token-%d", f.tokens)
+		f.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+
+	api := http.NewServeMux()
+
+	api.HandleFunc("/playlists/"+fakeSpotifyPlaylistID, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.ReplaceAll(playlistJSON, []byte("{{server}}"), []byte(f.URL)))
+	})
+
+	api.HandleFunc("/playlists/"+fakeSpotifyPlaylistID+"/tracks", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tracksJSON)
+	})
+
+	var trackPage struct {
+		Items []struct {
+			Track map[string]any `json:"track"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(tracksJSON, &trackPage); err != nil {
+		t.Fatal(err)
+	}
+
+	api.HandleFunc("/tracks", func(w http.ResponseWriter, r *http.Request) {
+		out := []map[string]any{}
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			var found map[string]any // unknown tracks are null, as they are from Spotify
+			for _, item := range trackPage.Items {
+				if item.Track != nil && item.Track["id"] == id {
+					found = item.Track
+				}
+			}
+			out = append(out, found)
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"tracks": out})
+	})
+
+	mux.Handle("/v1/", http.StripPrefix("/v1", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		valid := r.Header.Get("Authorization") == fmt.Sprintf("Bearer token-%d", f.tokens) && !f.rejectToken
+		f.rejectToken = false
+		f.mu.Unlock()
+
+		if !valid {
+			http.Error(w, "The access token expired", http.StatusUnauthorized)
+			return
+		}
+
+		api.ServeHTTP(w, r)
+	})))
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+/*
+client returns a Spotify client using the fake, with short retries
+*/
+func (f *fakeSpotify) client() streaming.Spotify {
+	s := streaming.NewSpotify(fakeSpotifyClientID, fakeSpotifyClientSecret)
+	s.HTTPClient = f.Client()
+	s.APIURL = f.URL + "/v1"
+	s.AccountsURL = f.URL
+	s.RetryBackoff = time.Millisecond
+	return s
+}
+
+func (f *fakeSpotify) expireToken() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rejectToken = true
+}
+
+func (f *fakeSpotify) numTokenRequests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tokenRequests
+}
@@ -0,0 +1,170 @@
+package streaming
+
+import (
+	"sort"
+	"strings"
+)
+
+/*
+Provides filtering and sorting of SoundCloud tracks, used by the track list
+*/
+
+/*
+FilterState is used to filter tracks on a yes/no property, FilterAny includes every track
+*/
+type FilterState int
+
+const (
+	FilterAny FilterState = iota
+	FilterYes
+	FilterNo
+)
+
+func (s FilterState) matches(b bool) bool {
+	switch s {
+	case FilterYes:
+		return b
+	case FilterNo:
+		return !b
+	}
+	return true
+}
+
+/*
+SoundCloudTrackFilter determines which tracks are included in a track list
+*/
+type SoundCloudTrackFilter struct {
+	Text                string // matched against the name, publisher, user and tags, case insensitive
+	Genre               string // matched against the genre, case insensitive
+	HasDownloadsLeft    FilterState
+	HasPurchaseLink     FilterState
+	PurchaseCategory    PurchaseCategory // empty includes every category
+	Downloaded          FilterState      // has a working local path
+	BrokenPath          FilterState
+	RemovedFromPlaylist FilterState
+}
+
+/*
+Matches returns true if the track should be included
+*/
+func (f SoundCloudTrackFilter) Matches(t *SoundCloudTrack) bool {
+	if f.Text != "" {
+		text := strings.ToLower(f.Text)
+		found := false
+		for _, s := range []string{t.Name, t.PublisherArtist, t.SoundCloudUser, t.TagList} {
+			if strings.Contains(strings.ToLower(s), text) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.Genre != "" && !strings.Contains(strings.ToLower(t.Genre), strings.ToLower(f.Genre)) {
+		return false
+	}
+
+	if f.PurchaseCategory != "" && t.purchaseCategory() != f.PurchaseCategory {
+		return false
+	}
+
+	return f.HasDownloadsLeft.matches(t.HasDownloadsLeft) &&
+		f.HasPurchaseLink.matches(t.PurchaseURL != "") &&
+		f.Downloaded.matches(t.LocalPath != "" && !t.LocalPathBroken) &&
+		f.BrokenPath.matches(t.LocalPathBroken) &&
+		f.RemovedFromPlaylist.matches(t.RemovedFromPlaylist)
+}
+
+/*
+purchaseCategory returns the category of the track's purchase link, classifying it if it hasn't been
+*/
+func (t *SoundCloudTrack) purchaseCategory() PurchaseCategory {
+	if t.PurchaseCategory.Check() {
+		return t.PurchaseCategory
+	}
+	return ClassifyPurchaseLink(t.PurchaseURL, t.PurchaseTitle)
+}
+
+/*
+SoundCloudTrackSortField is a field tracks can be sorted by
+*/
+type SoundCloudTrackSortField string
+
+const (
+	SortDefault        SoundCloudTrackSortField = ""
+	SortName           SoundCloudTrackSortField = "name"
+	SortGenre          SoundCloudTrackSortField = "genre"
+	SortTags           SoundCloudTrackSortField = "tags"
+	SortPublisher      SoundCloudTrackSortField = "publisher"
+	SortSoundCloudUser SoundCloudTrackSortField = "user"
+)
+
+/*
+SoundCloudTrackSortKey is a single field to sort by, and the direction to sort in
+*/
+type SoundCloudTrackSortKey struct {
+	Field SoundCloudTrackSortField
+	Desc  bool
+}
+
+func (k SoundCloudTrackSortKey) value(t *SoundCloudTrack) string {
+	switch k.Field {
+	case SortName:
+		return strings.ToLower(t.Name)
+	case SortGenre:
+		return strings.ToLower(t.Genre)
+	case SortTags:
+		return strings.ToLower(t.TagList)
+	case SortPublisher:
+		return strings.ToLower(t.PublisherArtist)
+	case SortSoundCloudUser:
+		return strings.ToLower(t.SoundCloudUser)
+	}
+	return ""
+}
+
+/*
+FilterSortInfo contains the filter and sort settings for a track list
+*/
+type FilterSortInfo struct {
+	Filter SoundCloudTrackFilter
+	Sort   []SoundCloudTrackSortKey // tracks are sorted by each key in order, ties are kept in their original order
+}
+
+/*
+Apply returns the tracks which match the filter, sorted by the sort keys
+
+The tracks passed in are not modified
+*/
+func (fsi FilterSortInfo) Apply(tracks []*SoundCloudTrack) []*SoundCloudTrack {
+	var out []*SoundCloudTrack
+
+	for _, t := range tracks {
+		if fsi.Filter.Matches(t) {
+			out = append(out, t)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		for _, k := range fsi.Sort {
+			if k.Field == SortDefault {
+				continue
+			}
+
+			a, b := k.value(out[i]), k.value(out[j])
+			if a == b {
+				continue
+			}
+
+			if k.Desc {
+				return a > b
+			}
+			return a < b
+		}
+		return false
+	})
+
+	return out
+}
@@ -0,0 +1,149 @@
+package streaming_test
+
+import (
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/streaming"
+	"github.com/google/go-cmp/cmp"
+)
+
+func trackNames(tracks []*streaming.SoundCloudTrack) []string {
+	var names []string
+	for _, t := range tracks {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func testTracks() []*streaming.SoundCloudTrack {
+	return []*streaming.SoundCloudTrack{
+		{Name: "Coolman - Funky Song", Genre: "House", TagList: "deep", SoundCloudUser: "coolman", HasDownloadsLeft: true},
+		{Name: "Bass Track", Genre: "Drum & Bass", PublisherArtist: "Bassline", PurchaseURL: "https://example.com/buy", LocalPath: "/music/bass.wav"},
+		{Name: "Another Song", Genre: "Deep House", SoundCloudUser: "someone", LocalPath: "/music/gone.wav", LocalPathBroken: true},
+		{Name: "Removed Song", Genre: "Techno", SoundCloudUser: "coolman", RemovedFromPlaylist: true},
+	}
+}
+
+func TestFilterSortInfoApplyFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter streaming.SoundCloudTrackFilter
+		want   []string
+	}{
+		{
+			name: "no filter",
+			want: []string{"Coolman - Funky Song", "Bass Track", "Another Song", "Removed Song"},
+		},
+		{
+			name:   "text matches name, user and tags",
+			filter: streaming.SoundCloudTrackFilter{Text: "COOLMAN"},
+			want:   []string{"Coolman - Funky Song", "Removed Song"},
+		},
+		{
+			name:   "text matches publisher",
+			filter: streaming.SoundCloudTrackFilter{Text: "bassline"},
+			want:   []string{"Bass Track"},
+		},
+		{
+			name:   "genre",
+			filter: streaming.SoundCloudTrackFilter{Genre: "house"},
+			want:   []string{"Coolman - Funky Song", "Another Song"},
+		},
+		{
+			name:   "has downloads left",
+			filter: streaming.SoundCloudTrackFilter{HasDownloadsLeft: streaming.FilterYes},
+			want:   []string{"Coolman - Funky Song"},
+		},
+		{
+			name:   "has purchase link",
+			filter: streaming.SoundCloudTrackFilter{HasPurchaseLink: streaming.FilterYes},
+			want:   []string{"Bass Track"},
+		},
+		{
+			name:   "purchase category",
+			filter: streaming.SoundCloudTrackFilter{PurchaseCategory: streaming.PurchaseLabelSite},
+			want:   []string{"Bass Track"},
+		},
+		{
+			name:   "purchase category without links",
+			filter: streaming.SoundCloudTrackFilter{PurchaseCategory: streaming.PurchaseNone},
+			want:   []string{"Coolman - Funky Song", "Another Song", "Removed Song"},
+		},
+		{
+			name:   "purchase category no matches",
+			filter: streaming.SoundCloudTrackFilter{PurchaseCategory: streaming.PurchaseFreeGate},
+		},
+		{
+			name:   "not downloaded",
+			filter: streaming.SoundCloudTrackFilter{Downloaded: streaming.FilterNo},
+			want:   []string{"Coolman - Funky Song", "Another Song", "Removed Song"},
+		},
+		{
+			name:   "broken path",
+			filter: streaming.SoundCloudTrackFilter{BrokenPath: streaming.FilterYes},
+			want:   []string{"Another Song"},
+		},
+		{
+			name:   "hide removed",
+			filter: streaming.SoundCloudTrackFilter{RemovedFromPlaylist: streaming.FilterNo},
+			want:   []string{"Coolman - Funky Song", "Bass Track", "Another Song"},
+		},
+		{
+			name:   "combined",
+			filter: streaming.SoundCloudTrackFilter{Text: "song", Genre: "house", Downloaded: streaming.FilterNo},
+			want:   []string{"Coolman - Funky Song", "Another Song"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := streaming.FilterSortInfo{Filter: tt.filter}.Apply(testTracks())
+
+			if diff := cmp.Diff(tt.want, trackNames(got)); diff != "" {
+				t.Errorf("Apply() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterSortInfoApplySort(t *testing.T) {
+	tests := []struct {
+		name string
+		sort []streaming.SoundCloudTrackSortKey
+		want []string
+	}{
+		{
+			name: "default keeps order",
+			sort: []streaming.SoundCloudTrackSortKey{{Field: streaming.SortDefault, Desc: true}},
+			want: []string{"Coolman - Funky Song", "Bass Track", "Another Song", "Removed Song"},
+		},
+		{
+			name: "name",
+			sort: []streaming.SoundCloudTrackSortKey{{Field: streaming.SortName}},
+			want: []string{"Another Song", "Bass Track", "Coolman - Funky Song", "Removed Song"},
+		},
+		{
+			name: "genre descending",
+			sort: []streaming.SoundCloudTrackSortKey{{Field: streaming.SortGenre, Desc: true}},
+			want: []string{"Removed Song", "Coolman - Funky Song", "Bass Track", "Another Song"},
+		},
+		{
+			name: "user then name descending",
+			sort: []streaming.SoundCloudTrackSortKey{
+				{Field: streaming.SortSoundCloudUser},
+				{Field: streaming.SortName, Desc: true},
+			},
+			want: []string{"Bass Track", "Removed Song", "Coolman - Funky Song", "Another Song"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := streaming.FilterSortInfo{Sort: tt.sort}.Apply(testTracks())
+
+			if diff := cmp.Diff(tt.want, trackNames(got)); diff != "" {
+				t.Errorf("Apply() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -43,6 +43,13 @@ func (h *Hydratable) UnmarshalJSON(p []byte) error {
 				return err
 			}
 			h.Playlist = playlist
+		case "sound":
+			var sound HydratableSoundCloudTrack
+			err := json.Unmarshal(jsonBytes, &sound)
+			if err != nil {
+				return err
+			}
+			h.Sound = sound
 		}
 
 	}
@@ -72,6 +79,7 @@ func extractSCHydrationString(body string) (string, error) {
 type Hydratable struct {
 	User     HydratableSoundCloudUser
 	Playlist HydratableSoundCloudPlaylist
+	Sound    HydratableSoundCloudTrack
 }
 
 type HydratableSoundCloudUser struct {
@@ -0,0 +1,143 @@
+package streaming
+
+import (
+	"net/url"
+	"strings"
+)
+
+/*
+Provides classification of the purchase links set on SoundCloud tracks, many of which point to
+"free download" gates rather than a store
+*/
+
+type PurchaseCategory string
+
+const (
+	PurchaseNone      PurchaseCategory = "none"       // no purchase link
+	PurchaseStore     PurchaseCategory = "store"      // a store selling the track i.e. Beatport, Bandcamp
+	PurchaseFreeGate  PurchaseCategory = "free_gate"  // a free download behind a gate i.e. Hypeddit, ToneDen
+	PurchaseLabelSite PurchaseCategory = "label_site" // any other site, usually the label or artist's own
+)
+
+func (c PurchaseCategory) Check() bool {
+	switch c {
+	case PurchaseNone, PurchaseStore, PurchaseFreeGate, PurchaseLabelSite:
+		return true
+	}
+	return false
+}
+
+/*
+freeGateHosts are sites which give out free downloads in exchange for follows, likes or an email
+*/
+var freeGateHosts = []string{
+	"hypeddit.com",
+	"toneden.io",
+	"gate.fm",
+	"fanlink.to",
+	"edmdistrict.com",
+	"feature.fm",
+	"presave.io",
+	"pumpyoursound.com",
+}
+
+/*
+storeHosts are sites which sell tracks
+*/
+var storeHosts = []string{
+	"beatport.com",
+	"bandcamp.com",
+	"traxsource.com",
+	"junodownload.com",
+	"juno.co.uk",
+	"itunes.apple.com",
+	"music.apple.com",
+	"amazon.com",
+	"amazon.co.uk",
+	"qobuz.com",
+	"7digital.com",
+	"bleep.com",
+	"boomkat.com",
+}
+
+/*
+freeTitleWords are words in a purchase title which mean the link is a free download, SoundCloud lets
+users set any title on the buy button
+*/
+var freeTitleWords = []string{
+	"free",
+	"gratis",
+}
+
+/*
+ClassifyPurchaseLink finds the category of a track's purchase link from its URL and title
+
+Stores are classed as free gates when the title offers a free download, as Bandcamp "name your price"
+links are commonly used to give tracks away
+*/
+func ClassifyPurchaseLink(purchaseURL, purchaseTitle string) PurchaseCategory {
+
+	purchaseURL = strings.TrimSpace(purchaseURL)
+
+	if purchaseURL == "" {
+		return PurchaseNone
+	}
+
+	u, err := url.Parse(purchaseURL)
+
+	if err != nil || u.Hostname() == "" {
+		// SoundCloud doesn't require a scheme, so retry with one before giving up on the link
+		u, err = url.Parse("https://" + purchaseURL)
+		if err != nil || u.Hostname() == "" {
+			return PurchaseLabelSite
+		}
+	}
+
+	host := strings.ToLower(u.Hostname())
+
+	if hostMatches(host, freeGateHosts) {
+		return PurchaseFreeGate
+	}
+
+	free := titleOffersFree(purchaseTitle)
+
+	if hostMatches(host, storeHosts) {
+		if free {
+			return PurchaseFreeGate
+		}
+		return PurchaseStore
+	}
+
+	if free {
+		return PurchaseFreeGate
+	}
+
+	return PurchaseLabelSite
+}
+
+/*
+hostMatches reports whether the host is one of the hosts, or a subdomain of one of them
+*/
+func hostMatches(host string, hosts []string) bool {
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func titleOffersFree(title string) bool {
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), isTitleSeparator) {
+		for _, w := range freeTitleWords {
+			if word == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isTitleSeparator(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+}
@@ -0,0 +1,38 @@
+package streaming_test
+
+import (
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/streaming"
+)
+
+func TestClassifyPurchaseLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		title string
+		want  streaming.PurchaseCategory
+	}{
+		{name: "no link", want: streaming.PurchaseNone},
+		{name: "blank link", url: "  ", title: "Buy", want: streaming.PurchaseNone},
+		{name: "hypeddit", url: "https://hypeddit.com/track/abc123", title: "Free Download", want: streaming.PurchaseFreeGate},
+		{name: "toneden subdomain", url: "https://www.toneden.io/coolman/post/free", want: streaming.PurchaseFreeGate},
+		{name: "gate without scheme", url: "hypeddit.com/coolman/song", want: streaming.PurchaseFreeGate},
+		{name: "beatport", url: "https://www.beatport.com/track/song/123", title: "Buy", want: streaming.PurchaseStore},
+		{name: "bandcamp", url: "https://coolman.bandcamp.com/track/song", title: "Buy on Bandcamp", want: streaming.PurchaseStore},
+		{name: "bandcamp free", url: "https://coolman.bandcamp.com/track/song", title: "FREE DL", want: streaming.PurchaseFreeGate},
+		{name: "store without scheme", url: "www.traxsource.com/track/1", want: streaming.PurchaseStore},
+		{name: "label site", url: "https://coollabel.com/releases/1", title: "Buy", want: streaming.PurchaseLabelSite},
+		{name: "label site free", url: "https://coollabel.com/releases/1", title: "Free download!", want: streaming.PurchaseFreeGate},
+		{name: "free inside a word", url: "https://coollabel.com/releases/1", title: "Freestyle", want: streaming.PurchaseLabelSite},
+		{name: "lookalike host", url: "https://notbeatport.com/track/1", want: streaming.PurchaseLabelSite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streaming.ClassifyPurchaseLink(tt.url, tt.title); got != tt.want {
+				t.Errorf("ClassifyPurchaseLink(%q, %q) = %q, want %q", tt.url, tt.title, got, tt.want)
+			}
+		})
+	}
+}
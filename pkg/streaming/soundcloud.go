@@ -7,7 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/Southclaws/fault"
@@ -31,15 +32,12 @@ https://api-v2.soundcloud.com/tracks?ids=1032527881%2C1164496324%2C1380759907%2C
 	- requires client_id
 */
 
-type SoundCloud struct {
-	ClientID string
-}
-
 type SoundCloudPlaylist struct {
-	ExternalID   int64
+	ExternalID   int64 // the user ID for likes, tracks and reposts, and the track ID for a single track
 	Name         string
 	SearchUrl    string
 	PermalinkUrl string
+	SourceType   SoundCloudSourceType
 
 	Tracks []SoundCloudTrack
 
@@ -67,6 +65,7 @@ func (p *SoundCloudPlaylist) loadFromHydratable(hp HydratableSoundCloudPlaylist)
 	p.ExternalID = hp.ID
 	p.Name = hp.Title
 	p.PermalinkUrl = hp.PermalinkURL
+	p.SourceType = SoundCloudSourcePlaylist
 	p.Tracks = tracks
 }
 
@@ -86,14 +85,22 @@ func (p *SoundCloudPlaylist) LoadFromDB(dp data.SoundcloudPlaylist, tracks []dat
 	p.Name = dp.Name.String
 	p.SearchUrl = dp.SearchUrl.String
 	p.PermalinkUrl = dp.PermalinkUrl.String
+	p.SourceType = SoundCloudSourceType(dp.SourceType)
 }
 
 func (p *SoundCloudPlaylist) ToDB() (data.SoundcloudPlaylist, []data.SoundcloudTrack) {
+	sourceType := p.SourceType
+
+	if sourceType == "" {
+		sourceType = SoundCloudSourcePlaylist
+	}
+
 	dataP := data.SoundcloudPlaylist{
 		ExternalID:   sql.NullInt64{Valid: true, Int64: p.ExternalID},
 		Name:         sql.NullString{Valid: true, String: p.Name},
 		SearchUrl:    sql.NullString{Valid: true, String: p.SearchUrl},
 		PermalinkUrl: sql.NullString{Valid: true, String: p.PermalinkUrl},
+		SourceType:   string(sourceType),
 	}
 
 	dataTracks := []data.SoundcloudTrack{}
@@ -111,6 +118,7 @@ type SoundCloudTrack struct {
 	PermalinkUrl        string
 	PurchaseTitle       string
 	PurchaseURL         string
+	PurchaseCategory    PurchaseCategory
 	HasDownloadsLeft    bool
 	Genre               string
 	ArtworkURL          string
@@ -120,6 +128,7 @@ type SoundCloudTrack struct {
 	LocalPath           string
 	LocalPathBroken     bool
 	RemovedFromPlaylist bool
+	Duration            float64 // in seconds
 
 	Playlists []SoundCloudPlaylist `gorm:"many2many:playlist_tracks;"`
 }
@@ -139,12 +148,14 @@ func (t *SoundCloudTrack) loadFromHydratable(ht HydratableSoundCloudTrack) {
 	t.PermalinkUrl = ht.PermalinkURL
 	t.PurchaseTitle = ht.PurchaseTitle
 	t.PurchaseURL = ht.PurchaseURL
+	t.PurchaseCategory = ClassifyPurchaseLink(ht.PurchaseURL, ht.PurchaseTitle)
 	t.HasDownloadsLeft = ht.HasDownloadsLeft
 	t.Genre = ht.Genre
 	t.ArtworkURL = ht.ArtworkURL
 	t.TagList = ht.TagList
 	t.PublisherArtist = ht.PublisherMetadata.Artist
 	t.SoundCloudUser = ht.User.Username
+	t.Duration = float64(ht.Duration) / 1000
 }
 
 /*
@@ -157,6 +168,11 @@ func (t *SoundCloudTrack) LoadFromDB(dt data.SoundcloudTrack) {
 	t.PermalinkUrl = dt.PermalinkUrl.String
 	t.PurchaseTitle = dt.PurchaseTitle.String
 	t.PurchaseURL = dt.PurchaseUrl.String
+	t.PurchaseCategory = PurchaseCategory(dt.PurchaseCategory.String)
+	if !t.PurchaseCategory.Check() {
+		// tracks saved before purchase links were classified
+		t.PurchaseCategory = ClassifyPurchaseLink(t.PurchaseURL, t.PurchaseTitle)
+	}
 	t.HasDownloadsLeft = dt.HasDownloadsLeft.Bool
 	t.Genre = dt.Genre.String
 	t.ArtworkURL = dt.ArtworkUrl.String
@@ -166,6 +182,16 @@ func (t *SoundCloudTrack) LoadFromDB(dt data.SoundcloudTrack) {
 	t.LocalPath = dt.LocalPath.String
 	t.LocalPathBroken = dt.LocalPathBroken.Bool
 	t.RemovedFromPlaylist = dt.RemovedFromPlaylist.Bool
+	t.Duration = dt.Duration.Float64
+}
+
+/*
+LoadFromPlaylistDB loads a SoundCloudTrack listed in a playlist, whether the track was removed is
+taken from the playlist rather than the track, as it may have only been removed from other playlists
+*/
+func (t *SoundCloudTrack) LoadFromPlaylistDB(dt data.ListSoundCloudTracksByPlaylistExternalIDRow) {
+	t.LoadFromDB(dt.SoundcloudTrack)
+	t.RemovedFromPlaylist = dt.RemovedAt.Valid
 }
 
 func (t *SoundCloudTrack) ToDB() data.SoundcloudTrack {
@@ -184,81 +210,122 @@ func (t *SoundCloudTrack) ToDB() data.SoundcloudTrack {
 		LocalPath:           sql.NullString{Valid: true, String: t.LocalPath},
 		LocalPathBroken:     sql.NullBool{Valid: true, Bool: t.LocalPathBroken},
 		RemovedFromPlaylist: sql.NullBool{Valid: true, Bool: t.RemovedFromPlaylist},
+		Duration:            sql.NullFloat64{Valid: t.Duration > 0, Float64: t.Duration},
+		PurchaseCategory:    sql.NullString{Valid: t.PurchaseCategory.Check(), String: string(t.PurchaseCategory)},
 	}
 }
 
+/*
+GetSoundCloudPlaylist gets a SoundCloud source and its tracks, the type of source is found from the
+URL using ClassifySoundCloudURL
+
+Playlists, single tracks and the likes, tracks and reposts of a user are all returned as a
+SoundCloudPlaylist so they can be synced and stored in the same way
+*/
 func (s SoundCloud) GetSoundCloudPlaylist(ctx context.Context, playlistUrl string) (SoundCloudPlaylist, error) {
 
 	ctx = fctx.WithMeta(
 		ctx,
 		"playlist_url", playlistUrl,
-		"client_id", s.ClientID,
+		"client_id", s.clientID(),
 	)
 
-	resp, err := http.Get(playlistUrl)
+	sourceType, err := ClassifySoundCloudURL(playlistUrl)
 
 	if err != nil {
 		return SoundCloudPlaylist{}, fault.Wrap(
 			err,
 			fctx.With(ctx),
-			fmsg.With("Error making request to get SoundCloud playlist"),
+			fmsg.With("Error classifying SoundCloud URL"),
 		)
 	}
 
-	defer resp.Body.Close()
+	ctx = fctx.WithMeta(ctx, "source_type", string(sourceType))
 
-	body, err := io.ReadAll(resp.Body)
+	h, err := s.getHydratable(ctx, playlistUrl)
 
 	if err != nil {
 		return SoundCloudPlaylist{}, fault.Wrap(
 			err,
 			fctx.With(ctx),
-			fmsg.With("Error reading response body"),
 		)
 	}
 
-	hydratableStr, err := extractSCHydrationString(string(body))
+	switch sourceType {
+	case SoundCloudSourceTrack:
+		return soundCloudTrackSource(h.Sound)
+	case SoundCloudSourceLikes, SoundCloudSourceTracks, SoundCloudSourceReposts:
+		return s.getSoundCloudUserSource(ctx, h.User, sourceType)
+	}
 
-	if err != nil {
+	if h.Playlist.ID == 0 {
 		return SoundCloudPlaylist{}, fault.Wrap(
-			err,
+			fault.New("Missing playlist ID"),
 			fctx.With(ctx),
-			fmsg.With("Error extracting hydration string"),
 		)
 	}
 
-	h := Hydratable{}
-	err = h.UnmarshalJSON([]byte(hydratableStr))
+	err = s.completeTracks(ctx, &h.Playlist)
 
 	if err != nil {
 		return SoundCloudPlaylist{}, fault.Wrap(
 			err,
 			fctx.With(ctx),
-			fmsg.With("Error unmarshalling hydratable string"),
+			fmsg.With("Error completing tracks"),
 		)
 	}
 
-	if h.Playlist.ID == 0 {
-		return SoundCloudPlaylist{}, fault.Wrap(
-			fault.New("Missing playlist ID"),
-			fctx.With(ctx),
+	p := SoundCloudPlaylist{}
+	p.loadFromHydratable(h.Playlist)
+
+	return p, nil
+}
+
+/*
+getHydratable requests a page of the SoundCloud website and parses the hydration data embedded in it
+*/
+func (s SoundCloud) getHydratable(ctx context.Context, pageUrl string) (Hydratable, error) {
+
+	resp, err := s.get(ctx, pageUrl)
+
+	if err != nil {
+		return Hydratable{}, fault.Wrap(
+			err,
+			fmsg.With("Error making request to get SoundCloud page"),
 		)
 	}
 
-	err = s.completeTracks(ctx, &h.Playlist)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
 
 	if err != nil {
-		return SoundCloudPlaylist{}, fault.Wrap(
+		return Hydratable{}, fault.Wrap(
 			err,
-			fctx.With(ctx),
-			fmsg.With("Error completing tracks"),
+			fmsg.With("Error reading response body"),
 		)
 	}
 
-	p := SoundCloudPlaylist{}
-	p.loadFromHydratable(h.Playlist)
+	hydratableStr, err := extractSCHydrationString(string(body))
 
-	return p, nil
+	if err != nil {
+		return Hydratable{}, fault.Wrap(
+			err,
+			fmsg.With("Error extracting hydration string"),
+		)
+	}
+
+	h := Hydratable{}
+	err = h.UnmarshalJSON([]byte(hydratableStr))
+
+	if err != nil {
+		return Hydratable{}, fault.Wrap(
+			err,
+			fmsg.With("Error unmarshalling hydratable string"),
+		)
+	}
+
+	return h, nil
 }
 
 /*
@@ -324,7 +391,7 @@ func (s SoundCloud) getRemainingTracks(ctx context.Context, ids []int64) ([]Hydr
 
 	// TODO: figure out how big this array can be
 	tracksOut := pipeline.ProcessBatchConcurrently(ctx, 2, 50, time.Second*15, pipeline.NewProcessor(func(ctx context.Context, ids []int64) ([]HydratableSoundCloudTrack, error) {
-		trackArr, err := s.makeSoundCloudTracksRequest(ids)
+		trackArr, err := s.makeSoundCloudTracksRequest(ctx, ids)
 		if err != nil {
 			return nil, fault.Wrap(
 				err,
@@ -354,9 +421,11 @@ func (s SoundCloud) getRemainingTracks(ctx context.Context, ids []int64) ([]Hydr
 	return outTracks, ctx.Err()
 }
 
-func (s SoundCloud) makeSoundCloudTracksRequest(ids []int64) ([]HydratableSoundCloudTrack, error) {
+func (s SoundCloud) makeSoundCloudTracksRequest(ctx context.Context, ids []int64) ([]HydratableSoundCloudTrack, error) {
 
-	req, err := http.NewRequest("GET", "https://api-v2.soundcloud.com/tracks", nil)
+	req, err := s.newAPIRequest(ctx, "/tracks", url.Values{
+		"ids": {helpers.Int64ArrayToJoinedString(ids)},
+	})
 
 	if err != nil {
 		return nil, fault.Wrap(
@@ -365,16 +434,7 @@ func (s SoundCloud) makeSoundCloudTracksRequest(ids []int64) ([]HydratableSoundC
 		)
 	}
 
-	q := req.URL.Query()
-
-	q.Add("client_id", s.ClientID)
-	q.Add("app_locale", "en")
-	q.Add("ids", helpers.Int64ArrayToJoinedString(ids))
-	q.Add("app_version", "1700828706")
-
-	req.URL.RawQuery = q.Encode()
-
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := s.do(req)
 
 	if err != nil {
 		return nil, fault.Wrap(
@@ -384,6 +444,7 @@ func (s SoundCloud) makeSoundCloudTracksRequest(ids []int64) ([]HydratableSoundC
 	}
 
 	if resp.StatusCode != 200 {
+		resp.Body.Close()
 		return nil, fault.New(fmt.Sprintf(
 			"Error making request to get SoundCloud tracks, status code %d", resp.StatusCode,
 		))
@@ -416,35 +477,29 @@ DownloadFile downloads a file from SoundCloud
 
 returns the path to the downloaded file if successful, otherwise returns an error
 
-The file extension is gathered from the Content-Disposition header
+The file extension is gathered from the Content-Disposition header. The file is downloaded to a
+'<id>.part' file which is resumed on the next attempt if the download fails, and collision decides
+what happens if a file with the same name already exists in dirPath
 */
-func (s SoundCloud) DownloadFile(dirPath string, id int64) (string, error) {
+func (s SoundCloud) DownloadFile(ctx context.Context, dirPath string, id int64, collision helpers.CollisionStrategy) (string, error) {
 
-	req, err := http.NewRequest(
-		"GET",
-		fmt.Sprintf("https://api-v2.soundcloud.com/tracks/%d/download", id),
-		nil,
-	)
+	req, err := s.newAPIRequest(ctx, fmt.Sprintf("/tracks/%d/download", id), nil)
 
 	if err != nil {
 		return "", err
 	}
 
-	q := req.URL.Query()
-
-	q.Add("client_id", s.ClientID)
-	q.Add("app_locale", "en")
-	q.Add("app_version", "1700828706")
-
-	req.URL.RawQuery = q.Encode()
-
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := s.do(req)
 
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s", helpers.ErrUnexpectedStatusCode, resp.Status)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
@@ -464,7 +519,22 @@ func (s SoundCloud) DownloadFile(dirPath string, id int64) (string, error) {
 		return "", helpers.ErrMissingRedirectURI
 	}
 
-	resp, err = http.Get(val)
+	return helpers.DownloadFile(s.httpClient(), val, dirPath, strconv.FormatInt(id, 10), collision)
+}
+
+/*
+GenerateSoundCloudClientID scrapes a client_id from the SoundCloud website using the default client
+*/
+func GenerateSoundCloudClientID() (string, error) {
+	return NewSoundCloud("").GenerateClientID(context.Background())
+}
+
+/*
+GenerateClientID scrapes a client_id from the javascript assets of the SoundCloud website
+*/
+func (s SoundCloud) GenerateClientID(ctx context.Context) (string, error) {
+
+	resp, err := s.get(ctx, s.webURL())
 
 	if err != nil {
 		return "", err
@@ -472,98 +542,326 @@ func (s SoundCloud) DownloadFile(dirPath string, id int64) (string, error) {
 
 	defer resp.Body.Close()
 
-	contentDisposition := resp.Header.Get("Content-Disposition")
-
-	filename, err := helpers.GetFileNameFromContentDisposition(contentDisposition)
+	body, err := io.ReadAll(resp.Body)
 
 	if err != nil {
 		return "", err
 	}
 
-	err = helpers.CreateDirIfNotExists(dirPath)
+	assetMatches := helpers.RegexAllSubmatches(string(body), `src="(https[^"]+.js)"`)
 
-	if err != nil {
-		return "", err
+	for _, match := range assetMatches {
+
+		clientID, err := s.findClientIDInAsset(ctx, match[1])
+
+		if err != nil {
+			continue
+		}
+
+		if clientID != "" {
+			return clientID, nil
+		}
 	}
 
-	filePath := helpers.JoinFilepathToSlash(dirPath, filename)
+	return "", fault.New("Error generating SoundCloud client_id")
+}
 
-	f, err := os.Create(filePath)
+func (s SoundCloud) findClientIDInAsset(ctx context.Context, assetURL string) (string, error) {
+
+	resp, err := s.get(ctx, assetURL)
 
 	if err != nil {
 		return "", err
 	}
 
-	defer f.Close()
+	defer resp.Body.Close()
 
-	_, err = io.Copy(f, resp.Body)
+	body, err := io.ReadAll(resp.Body)
 
 	if err != nil {
 		return "", err
 	}
 
-	return filePath, nil
-}
+	clientIDMatches := helpers.RegexAllSubmatches(string(body), `client_id[=:]\s*"*([a-zA-Z0-9]{32})`)
 
-func GetAndSetSoundCloudClientID(cfg helpers.Config) (string, error) {
-	if cfg.SoundCloudClientID != "" {
-		return cfg.SoundCloudClientID, nil
+	if len(clientIDMatches) > 0 {
+		return clientIDMatches[0][1], nil
 	}
 
-	clientID, err := GenerateSoundCloudClientID()
+	return "", nil
+}
 
+/*
+Below implements Source for SoundCloud
+*/
+
+func (s SoundCloud) Platform() Platform {
+	return PlatformSoundCloud
+}
+
+/*
+Matches reports whether the URL is on the SoundCloud website, or on the WebURL the client was set up with
+*/
+func (s SoundCloud) Matches(rawURL string) bool {
+	if IsSoundCloudURL(rawURL) {
+		return true
+	}
+
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return "", fault.Wrap(
-			err,
-			fmsg.With("Error generating SoundCloud client_id"),
-		)
+		return false
 	}
 
-	cfg.SoundCloudClientID = clientID
+	web, err := url.Parse(s.webURL())
+	if err != nil {
+		return false
+	}
 
-	return clientID, nil
+	return u.Host != "" && u.Host == web.Host
 }
 
-func GenerateSoundCloudClientID() (string, error) {
+/*
+ResolveURL checks the URL is a SoundCloud playlist, track, or the likes, tracks or reposts of a user,
+the query and fragment are dropped as SoundCloud adds tracking parameters to shared links
+*/
+func (s SoundCloud) ResolveURL(rawURL string) (string, error) {
+	if !s.Matches(rawURL) {
+		return "", helpers.ErrNotSoundCloudURL
+	}
 
-	resp, err := http.Get("https://www.soundcloud.com")
+	if _, err := ClassifySoundCloudURL(rawURL); err != nil {
+		return "", err
+	}
 
+	u, err := url.Parse(rawURL)
 	if err != nil {
 		return "", err
 	}
 
-	defer resp.Body.Close()
+	u.RawQuery = ""
+	u.Fragment = ""
 
-	body, err := io.ReadAll(resp.Body)
+	return u.String(), nil
+}
+
+func (s SoundCloud) GetPlaylist(ctx context.Context, rawURL string) (SourcePlaylist, error) {
+	p, err := s.GetSoundCloudPlaylist(ctx, rawURL)
 
 	if err != nil {
-		return "", err
+		return SourcePlaylist{}, err
 	}
 
-	assetMatches := helpers.RegexAllSubmatches(string(body), `src="(https[^"]+.js)"`)
+	p.SearchUrl = rawURL
 
-	for _, match := range assetMatches {
+	return p.ToSourcePlaylist(), nil
+}
 
-		assetResp, err := http.Get(match[1])
+func (s SoundCloud) GetTracks(ctx context.Context, externalIDs []string) ([]SourceTrack, error) {
+	ids := make([]int64, len(externalIDs))
 
+	for i, id := range externalIDs {
+		n, err := strconv.ParseInt(id, 10, 64)
 		if err != nil {
-			continue
+			return nil, fault.Wrap(
+				err,
+				fctx.With(fctx.WithMeta(ctx, "track_external_id", id)),
+				fmsg.With("Error parsing SoundCloud track ID"),
+			)
 		}
+		ids[i] = n
+	}
+
+	hTracks, err := s.getRemainingTracks(ctx, ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make([]SourceTrack, len(hTracks))
+
+	for i, ht := range hTracks {
+		var t SoundCloudTrack
+		t.loadFromHydratable(ht)
+		tracks[i] = t.ToSourceTrack()
+	}
+
+	return tracks, nil
+}
+
+/*
+DownloadTrack downloads a track with downloads enabled using DownloadFile
+*/
+func (s SoundCloud) DownloadTrack(ctx context.Context, t SourceTrack, dirPath string, collision helpers.CollisionStrategy) (string, error) {
+	if !t.Downloadable {
+		return "", helpers.ErrTrackNotDownloadable
+	}
+
+	id, err := strconv.ParseInt(t.ExternalID, 10, 64)
+	if err != nil {
+		return "", err
+	}
+
+	return s.DownloadFile(ctx, dirPath, id, collision)
+}
+
+/*
+PurchaseLinks returns the link set on the buy button of the track, if there is one
+*/
+func (s SoundCloud) PurchaseLinks(t SourceTrack) []PurchaseLink {
+	if t.PurchaseURL == "" {
+		return nil
+	}
 
-		defer assetResp.Body.Close()
+	category := t.PurchaseCategory
+	if !category.Check() {
+		category = ClassifyPurchaseLink(t.PurchaseURL, t.PurchaseTitle)
+	}
+
+	return []PurchaseLink{{URL: t.PurchaseURL, Title: t.PurchaseTitle, Category: category}}
+}
+
+/*
+ToSourcePlaylist converts the playlist to a SourcePlaylist
+*/
+func (p SoundCloudPlaylist) ToSourcePlaylist() SourcePlaylist {
+	sourceType := p.SourceType
+	if sourceType == "" {
+		sourceType = SoundCloudSourcePlaylist
+	}
+
+	tracks := make([]SourceTrack, len(p.Tracks))
+	for i, t := range p.Tracks {
+		tracks[i] = t.ToSourceTrack()
+	}
+
+	return SourcePlaylist{
+		Platform:     PlatformSoundCloud,
+		ExternalID:   strconv.FormatInt(p.ExternalID, 10),
+		SourceType:   string(sourceType),
+		Name:         p.Name,
+		SearchUrl:    p.SearchUrl,
+		PermalinkUrl: p.PermalinkUrl,
+		Tracks:       tracks,
+	}
+}
+
+/*
+ToSourceTrack converts the track to a SourceTrack, the publisher's artist is used when set as the uploader is
+often a label or promoter
+*/
+func (t SoundCloudTrack) ToSourceTrack() SourceTrack {
+	var artists []string
+
+	switch {
+	case t.PublisherArtist != "":
+		artists = []string{t.PublisherArtist}
+	case t.SoundCloudUser != "":
+		artists = []string{t.SoundCloudUser}
+	}
+
+	return SourceTrack{
+		Platform:            PlatformSoundCloud,
+		ExternalID:          strconv.FormatInt(t.ExternalID, 10),
+		Name:                t.Name,
+		Artists:             artists,
+		Genre:               t.Genre,
+		PermalinkUrl:        t.PermalinkUrl,
+		ArtworkURL:          t.ArtworkURL,
+		PurchaseURL:         t.PurchaseURL,
+		PurchaseTitle:       t.PurchaseTitle,
+		PurchaseCategory:    t.PurchaseCategory,
+		Downloadable:        t.HasDownloadsLeft,
+		LocalPath:           t.LocalPath,
+		RemovedFromPlaylist: t.RemovedFromPlaylist,
+		Duration:            t.Duration,
+		Uploader:            t.SoundCloudUser,
+		Tags:                t.TagList,
+	}
+}
+
+/*
+LoadFromSource loads a SoundCloudPlaylist from a SourcePlaylist got from the SoundCloud source, so it
+can be stored in the SoundCloud tables
+*/
+func (p *SoundCloudPlaylist) LoadFromSource(sp SourcePlaylist) error {
+
+	if sp.Platform != PlatformSoundCloud {
+		return helpers.ErrNotSoundCloudURL
+	}
+
+	externalID, err := strconv.ParseInt(sp.ExternalID, 10, 64)
 
-		assetBody, err := io.ReadAll(assetResp.Body)
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error parsing SoundCloud playlist ID"),
+		)
+	}
+
+	sourceType := SoundCloudSourceType(sp.SourceType)
+
+	if sourceType == "" {
+		sourceType = SoundCloudSourcePlaylist
+	}
+
+	if !sourceType.Check() {
+		return helpers.ErrInvalidSoundCloudSourceType
+	}
+
+	tracks := make([]SoundCloudTrack, len(sp.Tracks))
+
+	for i, st := range sp.Tracks {
+		err = tracks[i].LoadFromSource(st)
 
 		if err != nil {
-			continue
+			return err
 		}
+	}
 
-		clientIDMatches := helpers.RegexAllSubmatches(string(assetBody), `client_id[=:]\s*"*([a-zA-Z0-9]{32})`)
+	p.ExternalID = externalID
+	p.Name = sp.Name
+	p.SearchUrl = sp.SearchUrl
+	p.PermalinkUrl = sp.PermalinkUrl
+	p.SourceType = sourceType
+	p.Tracks = tracks
 
-		if len(clientIDMatches) > 0 {
-			return clientIDMatches[0][1], nil
-		}
+	return nil
+}
+
+/*
+LoadFromSource loads a SoundCloudTrack from a SourceTrack, reversing ToSourceTrack. The artist is only
+kept as the publisher's artist when it differs from the uploader
+*/
+func (t *SoundCloudTrack) LoadFromSource(st SourceTrack) error {
+
+	externalID, err := strconv.ParseInt(st.ExternalID, 10, 64)
+
+	if err != nil {
+		return fault.Wrap(
+			err,
+			fmsg.With("Error parsing SoundCloud track ID"),
+		)
 	}
 
-	return "", fault.New("Error generating SoundCloud client_id")
+	t.ExternalID = externalID
+	t.Name = st.Name
+	t.PermalinkUrl = st.PermalinkUrl
+	t.PurchaseTitle = st.PurchaseTitle
+	t.PurchaseURL = st.PurchaseURL
+	t.PurchaseCategory = st.PurchaseCategory
+	t.HasDownloadsLeft = st.Downloadable
+	t.Genre = st.Genre
+	t.ArtworkURL = st.ArtworkURL
+	t.TagList = st.Tags
+	t.SoundCloudUser = st.Uploader
+	t.LocalPath = st.LocalPath
+	t.RemovedFromPlaylist = st.RemovedFromPlaylist
+	t.Duration = st.Duration
+
+	t.PublisherArtist = ""
+	if len(st.Artists) > 0 && st.Artists[0] != st.Uploader {
+		t.PublisherArtist = st.Artists[0]
+	}
+
+	return nil
 }
@@ -0,0 +1,308 @@
+package streaming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides the different kinds of SoundCloud URL which can be synced like a playlist, along with the
+requests needed to get the tracks of a user's likes, tracks and reposts
+*/
+
+type SoundCloudSourceType string
+
+const (
+	SoundCloudSourcePlaylist SoundCloudSourceType = "playlist" // /{user}/sets/{playlist}
+	SoundCloudSourceLikes    SoundCloudSourceType = "likes"    // /{user}/likes
+	SoundCloudSourceTracks   SoundCloudSourceType = "tracks"   // /{user}/tracks
+	SoundCloudSourceReposts  SoundCloudSourceType = "reposts"  // /{user}/reposts
+	SoundCloudSourceTrack    SoundCloudSourceType = "track"    // /{user}/{track}
+)
+
+func (t SoundCloudSourceType) Check() bool {
+	switch t {
+	case SoundCloudSourcePlaylist, SoundCloudSourceLikes, SoundCloudSourceTracks, SoundCloudSourceReposts, SoundCloudSourceTrack:
+		return true
+	}
+	return false
+}
+
+/*
+soundCloudUserSourcePaths are the API paths used to list the tracks of a user source, formatted with the user ID
+*/
+var soundCloudUserSourcePaths = map[SoundCloudSourceType]string{
+	SoundCloudSourceLikes:   "/users/%d/track_likes",
+	SoundCloudSourceTracks:  "/users/%d/tracks",
+	SoundCloudSourceReposts: "/stream/users/%d/reposts",
+}
+
+/*
+reservedSoundCloudPaths are pages of the SoundCloud website which aren't users
+*/
+var reservedSoundCloudPaths = map[string]bool{
+	"charts":        true,
+	"discover":      true,
+	"messages":      true,
+	"notifications": true,
+	"pages":         true,
+	"search":        true,
+	"settings":      true,
+	"stream":        true,
+	"upload":        true,
+	"you":           true,
+}
+
+/*
+reservedSoundCloudUserPaths are pages of a user which aren't tracks
+*/
+var reservedSoundCloudUserPaths = map[string]bool{
+	"albums":         true,
+	"comments":       true,
+	"followers":      true,
+	"following":      true,
+	"popular-tracks": true,
+	"sets":           true,
+	"spotlight":      true,
+}
+
+const soundCloudUserSourcePageSize = "200"
+
+/*
+IsSoundCloudURL reports whether the URL is on the SoundCloud website
+*/
+func IsSoundCloudURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return false
+	}
+
+	host := strings.TrimPrefix(strings.TrimPrefix(u.Hostname(), "www."), "m.")
+
+	return host == "soundcloud.com"
+}
+
+/*
+ClassifySoundCloudURL finds the type of source a SoundCloud URL points to from its path, the host
+isn't checked so IsSoundCloudURL should be used as well for URLs given by a user
+
+Private playlists and tracks, which end in a secret token, are supported
+*/
+func ClassifySoundCloudURL(rawURL string) (SoundCloudSourceType, error) {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	if len(segments) < 2 || segments[0] == "" || reservedSoundCloudPaths[segments[0]] {
+		return "", helpers.ErrUnsupportedSoundCloudURL
+	}
+
+	switch segments[1] {
+	case "sets":
+		if len(segments) == 3 || (len(segments) == 4 && isSecretToken(segments[3])) {
+			return SoundCloudSourcePlaylist, nil
+		}
+	case "likes":
+		if len(segments) == 2 {
+			return SoundCloudSourceLikes, nil
+		}
+	case "tracks":
+		if len(segments) == 2 {
+			return SoundCloudSourceTracks, nil
+		}
+	case "reposts":
+		if len(segments) == 2 {
+			return SoundCloudSourceReposts, nil
+		}
+	default:
+		if reservedSoundCloudUserPaths[segments[1]] {
+			break
+		}
+		if len(segments) == 2 || (len(segments) == 3 && isSecretToken(segments[2])) {
+			return SoundCloudSourceTrack, nil
+		}
+	}
+
+	return "", helpers.ErrUnsupportedSoundCloudURL
+}
+
+func isSecretToken(segment string) bool {
+	return strings.HasPrefix(segment, "s-")
+}
+
+/*
+soundCloudTrackSource wraps a single track as a SoundCloudPlaylist
+*/
+func soundCloudTrackSource(ht HydratableSoundCloudTrack) (SoundCloudPlaylist, error) {
+
+	if ht.ID == 0 {
+		return SoundCloudPlaylist{}, helpers.ErrTrackMissingID
+	}
+
+	var t SoundCloudTrack
+	t.loadFromHydratable(ht)
+
+	return SoundCloudPlaylist{
+		ExternalID:   ht.ID,
+		Name:         ht.Title,
+		PermalinkUrl: ht.PermalinkURL,
+		SourceType:   SoundCloudSourceTrack,
+		Tracks:       []SoundCloudTrack{t},
+	}, nil
+}
+
+/*
+soundCloudCollection is a page of results from the SoundCloud API, items are either tracks or
+likes/ reposts which hold a track
+*/
+type soundCloudCollection struct {
+	Collection []soundCloudCollectionItem `json:"collection"`
+	NextHref   string                     `json:"next_href"`
+}
+
+type soundCloudCollectionItem struct {
+	HydratableSoundCloudTrack
+	Track *HydratableSoundCloudTrack `json:"track"`
+}
+
+/*
+track returns the track of an item, false is returned for items which aren't tracks i.e. reposted playlists
+*/
+func (i soundCloudCollectionItem) track() (HydratableSoundCloudTrack, bool) {
+	if i.Track != nil {
+		return *i.Track, true
+	}
+	if i.Kind == Track {
+		return i.HydratableSoundCloudTrack, true
+	}
+	return HydratableSoundCloudTrack{}, false
+}
+
+/*
+getSoundCloudUserSource gets the likes, tracks or reposts of a user as a SoundCloudPlaylist, following
+every page of results
+*/
+func (s SoundCloud) getSoundCloudUserSource(ctx context.Context, user HydratableSoundCloudUser, sourceType SoundCloudSourceType) (SoundCloudPlaylist, error) {
+
+	if user.ID == 0 {
+		return SoundCloudPlaylist{}, fault.Wrap(
+			helpers.ErrMissingSoundCloudUser,
+			fctx.With(ctx),
+		)
+	}
+
+	ctx = fctx.WithMeta(ctx, "user_id", fmt.Sprintf("%d", user.ID))
+
+	req, err := s.newAPIRequest(ctx, fmt.Sprintf(soundCloudUserSourcePaths[sourceType], user.ID), url.Values{
+		"limit":               {soundCloudUserSourcePageSize},
+		"linked_partitioning": {"1"},
+	})
+
+	if err != nil {
+		return SoundCloudPlaylist{}, fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.With("Error creating request"),
+		)
+	}
+
+	hp := HydratableSoundCloudPlaylist{}
+
+	for {
+		page, err := s.getCollectionPage(req)
+
+		if err != nil {
+			return SoundCloudPlaylist{}, fault.Wrap(
+				err,
+				fctx.With(ctx),
+				fmsg.With(fmt.Sprintf("Error getting SoundCloud user %s", sourceType)),
+			)
+		}
+
+		for _, item := range page.Collection {
+			if t, ok := item.track(); ok {
+				hp.Tracks = append(hp.Tracks, t)
+			}
+		}
+
+		if page.NextHref == "" || page.NextHref == req.URL.String() {
+			break
+		}
+
+		req, err = s.newAPIRequestURL(ctx, page.NextHref, nil)
+
+		if err != nil {
+			return SoundCloudPlaylist{}, fault.Wrap(
+				err,
+				fctx.With(ctx),
+				fmsg.With("Error creating request for next page"),
+			)
+		}
+	}
+
+	err = s.completeTracks(ctx, &hp)
+
+	if err != nil {
+		return SoundCloudPlaylist{}, fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.With("Error completing tracks"),
+		)
+	}
+
+	p := SoundCloudPlaylist{}
+	p.loadFromHydratable(hp)
+
+	p.ExternalID = user.ID
+	p.Name = fmt.Sprintf("%s %s", user.Username, sourceType)
+	p.PermalinkUrl = strings.TrimSuffix(user.PermalinkURL, "/") + "/" + string(sourceType)
+	p.SourceType = sourceType
+
+	return p, nil
+}
+
+func (s SoundCloud) getCollectionPage(req *http.Request) (soundCloudCollection, error) {
+
+	resp, err := s.do(req)
+
+	if err != nil {
+		return soundCloudCollection{}, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return soundCloudCollection{}, fmt.Errorf("%w: %s", helpers.ErrUnexpectedStatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return soundCloudCollection{}, err
+	}
+
+	var page soundCloudCollection
+
+	err = json.Unmarshal(body, &page)
+
+	if err != nil {
+		return soundCloudCollection{}, err
+	}
+
+	return page, nil
+}
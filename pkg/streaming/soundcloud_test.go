@@ -0,0 +1,333 @@
+package streaming_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/streaming"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetSoundCloudPlaylist(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     []int
+		wantErr      bool
+		wantRequests int
+	}{
+		{
+			name:         "completes tracks missing from the hydration",
+			wantRequests: 1,
+		},
+		{
+			name:         "retries rate limited and failed requests",
+			failures:     []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+			wantRequests: 3,
+		},
+		{
+			name:         "gives up after max retries",
+			failures:     []int{500, 500, 500, 500},
+			wantErr:      true,
+			wantRequests: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSoundCloud(t)
+			fake.failNext(tt.failures...)
+
+			p, err := fake.client().GetSoundCloudPlaylist(context.Background(), fake.URL+fakePlaylistPath)
+
+			if got := fake.numRequests(); got != tt.wantRequests {
+				t.Errorf("API requests = %v, want %v", got, tt.wantRequests)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GetSoundCloudPlaylist() expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetSoundCloudPlaylist() error = %v", err)
+			}
+
+			if p.ExternalID != 1720019865 || p.Name != "Test Playlist" {
+				t.Errorf("GetSoundCloudPlaylist() playlist = %v", p)
+			}
+
+			var got []streaming.SoundCloudTrack
+			for _, track := range p.Tracks {
+				got = append(got, streaming.SoundCloudTrack{
+					ExternalID:       track.ExternalID,
+					Name:             track.Name,
+					HasDownloadsLeft: track.HasDownloadsLeft,
+					PurchaseURL:      track.PurchaseURL,
+					PublisherArtist:  track.PublisherArtist,
+					SoundCloudUser:   track.SoundCloudUser,
+					Duration:         track.Duration,
+				})
+			}
+
+			want := []streaming.SoundCloudTrack{
+				{ExternalID: 1, Name: "Coolman - Funky Song", HasDownloadsLeft: true, PublisherArtist: "Coolman", SoundCloudUser: "coolman", Duration: 301},
+				{ExternalID: 2, Name: "Other Artist - Cool Track", PurchaseURL: "https://bandcamp.com/cool-track", PublisherArtist: "Other Artist", SoundCloudUser: "otherartist", Duration: 302},
+				{ExternalID: 3, Name: "Third Artist - Late Night", HasDownloadsLeft: true, SoundCloudUser: "thirdartist", Duration: 303},
+				{ExternalID: 4, Name: "Fourth - Sunrise", PublisherArtist: "Fourth", SoundCloudUser: "fourth", Duration: 304},
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("GetSoundCloudPlaylist() tracks mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetSoundCloudSources(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		want         streaming.SoundCloudPlaylist
+		wantTracks   []int64
+		wantRequests int
+	}{
+		{
+			name: "single track",
+			path: fakeTrackPath,
+			want: streaming.SoundCloudPlaylist{
+				ExternalID:   3,
+				Name:         "Third Artist - Late Night",
+				PermalinkUrl: "https://soundcloud.com/thirdartist/third-artist---late-night",
+				SourceType:   streaming.SoundCloudSourceTrack,
+			},
+			wantTracks: []int64{3},
+		},
+		{
+			name: "user likes over multiple pages",
+			path: fakeUserPath + "/likes",
+			want: streaming.SoundCloudPlaylist{
+				ExternalID:   100,
+				Name:         "coolman likes",
+				PermalinkUrl: "https://soundcloud.com/coolman/likes",
+				SourceType:   streaming.SoundCloudSourceLikes,
+			},
+			wantTracks:   []int64{3, 4},
+			wantRequests: 2,
+		},
+		{
+			name: "user tracks",
+			path: fakeUserPath + "/tracks",
+			want: streaming.SoundCloudPlaylist{
+				ExternalID:   100,
+				Name:         "coolman tracks",
+				PermalinkUrl: "https://soundcloud.com/coolman/tracks",
+				SourceType:   streaming.SoundCloudSourceTracks,
+			},
+			wantTracks:   []int64{3, 4},
+			wantRequests: 1,
+		},
+		{
+			name: "user reposts skips playlists",
+			path: fakeUserPath + "/reposts",
+			want: streaming.SoundCloudPlaylist{
+				ExternalID:   100,
+				Name:         "coolman reposts",
+				PermalinkUrl: "https://soundcloud.com/coolman/reposts",
+				SourceType:   streaming.SoundCloudSourceReposts,
+			},
+			wantTracks:   []int64{4},
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSoundCloud(t)
+
+			p, err := fake.client().GetSoundCloudPlaylist(context.Background(), fake.URL+tt.path)
+
+			if err != nil {
+				t.Fatalf("GetSoundCloudPlaylist() error = %v", err)
+			}
+
+			if got := fake.numRequests(); got != tt.wantRequests {
+				t.Errorf("API requests = %v, want %v", got, tt.wantRequests)
+			}
+
+			var gotTracks []int64
+			for _, track := range p.Tracks {
+				gotTracks = append(gotTracks, track.ExternalID)
+			}
+
+			if diff := cmp.Diff(tt.wantTracks, gotTracks); diff != "" {
+				t.Errorf("GetSoundCloudPlaylist() track IDs mismatch (-want +got):\n%s", diff)
+			}
+
+			p.Tracks = nil
+
+			if diff := cmp.Diff(tt.want, p); diff != "" {
+				t.Errorf("GetSoundCloudPlaylist() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClassifySoundCloudURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    streaming.SoundCloudSourceType
+		wantErr error
+	}{
+		{url: "https://soundcloud.com/coolman/sets/test", want: streaming.SoundCloudSourcePlaylist},
+		{url: "https://soundcloud.com/coolman/sets/test/s-AbCdE", want: streaming.SoundCloudSourcePlaylist},
+		{url: "https://soundcloud.com/coolman/likes", want: streaming.SoundCloudSourceLikes},
+		{url: "https://m.soundcloud.com/coolman/tracks/", want: streaming.SoundCloudSourceTracks},
+		{url: "https://soundcloud.com/coolman/reposts", want: streaming.SoundCloudSourceReposts},
+		{url: "https://soundcloud.com/coolman/funky-song", want: streaming.SoundCloudSourceTrack},
+		{url: "https://soundcloud.com/coolman/funky-song/s-AbCdE", want: streaming.SoundCloudSourceTrack},
+		{url: "https://soundcloud.com/coolman", wantErr: helpers.ErrUnsupportedSoundCloudURL},
+		{url: "https://soundcloud.com/coolman/sets", wantErr: helpers.ErrUnsupportedSoundCloudURL},
+		{url: "https://soundcloud.com/coolman/albums", wantErr: helpers.ErrUnsupportedSoundCloudURL},
+		{url: "https://soundcloud.com/you/likes", wantErr: helpers.ErrUnsupportedSoundCloudURL},
+		{url: "https://soundcloud.com/coolman/funky-song/comments", wantErr: helpers.ErrUnsupportedSoundCloudURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := streaming.ClassifySoundCloudURL(tt.url)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ClassifySoundCloudURL() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ClassifySoundCloudURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSoundCloudURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://soundcloud.com/coolman/likes", want: true},
+		{url: "https://www.soundcloud.com/coolman/likes", want: true},
+		{url: "https://m.soundcloud.com/coolman/likes", want: true},
+		{url: "https://notsoundcloud.com/coolman/likes", want: false},
+		{url: "https://bandcamp.com/coolman", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := streaming.IsSoundCloudURL(tt.url); got != tt.want {
+				t.Errorf("IsSoundCloudURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSoundCloudDownloadFile(t *testing.T) {
+	fake := newFakeSoundCloud(t)
+	dir := filepath.ToSlash(t.TempDir())
+
+	got, err := fake.client().DownloadFile(context.Background(), dir, 3, helpers.CollisionSuffix)
+
+	if err != nil {
+		t.Fatalf("DownloadFile() error = %v", err)
+	}
+
+	if want := dir + "/track 3.wav"; got != want {
+		t.Errorf("DownloadFile() = %v, want %v", got, want)
+	}
+
+	b, err := os.ReadFile(got)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "audio data for track 3" {
+		t.Errorf("DownloadFile() contents = %q", b)
+	}
+}
+
+func TestSoundCloudDownloadFileUnauthorized(t *testing.T) {
+	fake := newFakeSoundCloud(t)
+
+	s := fake.client()
+	s.ClientID = "invalid"
+
+	_, err := s.DownloadFile(context.Background(), t.TempDir(), 3, helpers.CollisionSuffix)
+
+	if !errors.Is(err, helpers.ErrUnexpectedStatusCode) {
+		t.Errorf("DownloadFile() error = %v, want %v", err, helpers.ErrUnexpectedStatusCode)
+	}
+}
+
+func TestSoundCloudClientIDRefresh(t *testing.T) {
+	fake := newFakeSoundCloud(t)
+
+	refreshes := 0
+
+	s := fake.client()
+	s.ClientID = "invalid"
+	s = s.WithClientIDRefresh(func(ctx context.Context) (string, error) {
+		refreshes++
+		return fakeClientID, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := s.DownloadFile(context.Background(), t.TempDir(), 3, helpers.CollisionSuffix)
+		if err != nil {
+			t.Fatalf("DownloadFile() error = %v", err)
+		}
+	}
+
+	if refreshes != 1 {
+		t.Errorf("client_id refreshed %v times, want 1", refreshes)
+	}
+}
+
+func TestGenerateClientID(t *testing.T) {
+	fake := newFakeSoundCloud(t)
+
+	got, err := fake.client().GenerateClientID(context.Background())
+
+	if err != nil {
+		t.Fatalf("GenerateClientID() error = %v", err)
+	}
+
+	if got != fakeClientID {
+		t.Errorf("GenerateClientID() = %v, want %v", got, fakeClientID)
+	}
+}
+
+func TestSoundCloudRateLimit(t *testing.T) {
+	fake := newFakeSoundCloud(t)
+
+	// one request is allowed straight away, the next two wait 100ms each
+	s := fake.client().WithRateLimit(10, 1)
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, err := s.DownloadFile(context.Background(), t.TempDir(), 3, helpers.CollisionSuffix)
+		if err != nil {
+			t.Fatalf("DownloadFile() error = %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("3 rate limited requests took %v, want at least 150ms", elapsed)
+	}
+}
@@ -0,0 +1,195 @@
+package streaming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides the Source interface implemented by each streaming platform, along with the platform agnostic
+SourcePlaylist and SourceTrack types, so playlists from any platform can be listed and worked with in the same way
+*/
+
+type Platform string
+
+const (
+	PlatformSoundCloud Platform = "soundcloud"
+	PlatformSpotify    Platform = "spotify"
+)
+
+func (p Platform) Check() bool {
+	switch p {
+	case PlatformSoundCloud, PlatformSpotify:
+		return true
+	}
+	return false
+}
+
+/*
+Source is a streaming platform which playlists and tracks can be got from
+
+Platforms which can't download or don't have purchase links return helpers.ErrDownloadNotSupported
+from DownloadTrack and no links from PurchaseLinks
+*/
+type Source interface {
+	// Platform returns the platform of the source
+	Platform() Platform
+	// Matches reports whether the URL belongs to the platform, without checking it can be got
+	Matches(rawURL string) bool
+	// ResolveURL checks the URL points to something which can be got as a playlist and returns its canonical form
+	ResolveURL(rawURL string) (string, error)
+	// GetPlaylist gets a playlist and all of its tracks
+	GetPlaylist(ctx context.Context, rawURL string) (SourcePlaylist, error)
+	// GetTracks gets tracks by their external IDs, IDs which aren't found are left out
+	GetTracks(ctx context.Context, externalIDs []string) ([]SourceTrack, error)
+	// DownloadTrack downloads a track into dirPath, returning the path to the downloaded file
+	DownloadTrack(ctx context.Context, t SourceTrack, dirPath string, collision helpers.CollisionStrategy) (string, error)
+	// PurchaseLinks returns the links where a track can be bought or downloaded
+	PurchaseLinks(t SourceTrack) []PurchaseLink
+}
+
+var (
+	_ Source = SoundCloud{}
+	_ Source = Spotify{}
+)
+
+/*
+SourceForURL returns the first of the sources which the URL belongs to
+*/
+func SourceForURL(rawURL string, sources ...Source) (Source, error) {
+	for _, s := range sources {
+		if s.Matches(rawURL) {
+			return s, nil
+		}
+	}
+	return nil, helpers.ErrUnsupportedURL
+}
+
+/*
+SourcePlaylist is a playlist from any platform
+
+ExternalID is the ID used by the platform, and SourceType is the kind of playlist on platforms which
+support more than one i.e. the likes of a SoundCloud user
+*/
+type SourcePlaylist struct {
+	Platform     Platform
+	ExternalID   string
+	SourceType   string
+	Name         string
+	Owner        string
+	SearchUrl    string // the url the playlist was added with
+	PermalinkUrl string
+	Tracks       []SourceTrack
+}
+
+func (p SourcePlaylist) String() string {
+	return fmt.Sprintf(
+		"%s %s: %s",
+		p.Platform,
+		p.ExternalID,
+		p.Name,
+	)
+}
+
+/*
+LoadFromDB loads a SourcePlaylist from the streaming_playlists view and its tracks, tracks removed
+from the playlist are marked as removed even if they are still in other playlists
+*/
+func (p *SourcePlaylist) LoadFromDB(dp data.StreamingPlaylist, dt []data.ListStreamingTracksByPlaylistRow) {
+	p.Platform = Platform(dp.Platform)
+	p.ExternalID = dp.ExternalID.String
+	p.SourceType = dp.SourceType
+	p.Name = dp.Name.String
+	p.Owner = dp.Owner.String
+	p.SearchUrl = dp.SearchUrl.String
+	p.PermalinkUrl = dp.PermalinkUrl.String
+
+	p.Tracks = make([]SourceTrack, len(dt))
+	for i, t := range dt {
+		p.Tracks[i].LoadFromDB(t.StreamingTrack)
+		if t.RemovedAt.Valid {
+			p.Tracks[i].RemovedFromPlaylist = true
+		}
+	}
+}
+
+/*
+SourceTrack is a track from any platform, fields the platform doesn't have are left empty
+*/
+type SourceTrack struct {
+	Platform            Platform
+	ExternalID          string
+	Name                string
+	Artists             []string
+	Album               string
+	Genre               string
+	ISRC                string
+	PermalinkUrl        string
+	ArtworkURL          string
+	PurchaseURL         string
+	PurchaseTitle       string
+	PurchaseCategory    PurchaseCategory
+	Downloadable        bool
+	LocalPath           string
+	RemovedFromPlaylist bool
+	Duration            float64 // in seconds
+	Uploader            string  // the account the track was uploaded by, which may not be the artist
+	Tags                string  // as formatted by the platform
+}
+
+func (t SourceTrack) String() string {
+	if len(t.Artists) == 0 {
+		return fmt.Sprintf("%s: %s", t.ExternalID, t.Name)
+	}
+	return fmt.Sprintf(
+		"%s: %s - %s",
+		t.ExternalID,
+		strings.Join(t.Artists, ", "),
+		t.Name,
+	)
+}
+
+/*
+LoadFromDB loads a SourceTrack from the streaming_tracks view, artists are stored as a JSON array
+*/
+func (t *SourceTrack) LoadFromDB(dt data.StreamingTrack) {
+	t.Platform = Platform(dt.Platform)
+	t.ExternalID = dt.ExternalID.String
+	t.Name = dt.Name.String
+	t.Album = dt.Album.String
+	t.Genre = dt.Genre.String
+	t.ISRC = dt.Isrc.String
+	t.PermalinkUrl = dt.PermalinkUrl.String
+	t.ArtworkURL = dt.ArtworkUrl.String
+	t.PurchaseURL = dt.PurchaseUrl.String
+	t.PurchaseTitle = dt.PurchaseTitle.String
+	t.PurchaseCategory = PurchaseCategory(dt.PurchaseCategory.String)
+	t.Downloadable = dt.Downloadable.Bool
+	t.LocalPath = dt.LocalPath.String
+	t.RemovedFromPlaylist = dt.RemovedFromPlaylist.Bool
+	t.Duration = dt.Duration.Float64
+
+	if t.PurchaseURL != "" && !t.PurchaseCategory.Check() {
+		t.PurchaseCategory = ClassifyPurchaseLink(t.PurchaseURL, t.PurchaseTitle)
+	}
+
+	t.Artists = nil
+	if dt.Artists.Valid && dt.Artists.String != "" {
+		// a malformed value is treated as no artists, as for SpotifyTrack
+		_ = json.Unmarshal([]byte(dt.Artists.String), &t.Artists)
+	}
+}
+
+/*
+PurchaseLink is a link where a track can be bought or downloaded
+*/
+type PurchaseLink struct {
+	URL      string
+	Title    string
+	Category PurchaseCategory
+}
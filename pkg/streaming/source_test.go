@@ -0,0 +1,247 @@
+package streaming_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/streaming"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSourceForURL(t *testing.T) {
+	sources := []streaming.Source{
+		streaming.NewSoundCloud(""),
+		streaming.NewSpotify("", ""),
+	}
+
+	tests := []struct {
+		url     string
+		want    streaming.Platform
+		wantErr error
+	}{
+		{url: "https://soundcloud.com/coolman/sets/test", want: streaming.PlatformSoundCloud},
+		{url: "https://m.soundcloud.com/coolman/likes", want: streaming.PlatformSoundCloud},
+		{url: "https://open.spotify.com/playlist/" + fakeSpotifyPlaylistID, want: streaming.PlatformSpotify},
+		{url: "spotify:playlist:" + fakeSpotifyPlaylistID, want: streaming.PlatformSpotify},
+		{url: "https://www.youtube.com/playlist?list=abc", wantErr: helpers.ErrUnsupportedURL},
+		{url: "", wantErr: helpers.ErrUnsupportedURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			s, err := streaming.SourceForURL(tt.url, sources...)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SourceForURL() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && s.Platform() != tt.want {
+				t.Errorf("SourceForURL() platform = %v, want %v", s.Platform(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceResolveURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  streaming.Source
+		url     string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "soundcloud drops share parameters",
+			source: streaming.NewSoundCloud(""),
+			url:    "https://soundcloud.com/coolman/sets/test?si=abc&utm_source=clipboard",
+			want:   "https://soundcloud.com/coolman/sets/test",
+		},
+		{
+			name:   "soundcloud keeps secret token",
+			source: streaming.NewSoundCloud(""),
+			url:    "https://soundcloud.com/coolman/sets/test/s-AbCdE",
+			want:   "https://soundcloud.com/coolman/sets/test/s-AbCdE",
+		},
+		{
+			name:    "soundcloud unsupported page",
+			source:  streaming.NewSoundCloud(""),
+			url:     "https://soundcloud.com/coolman/followers",
+			wantErr: helpers.ErrUnsupportedSoundCloudURL,
+		},
+		{
+			name:    "soundcloud other platform",
+			source:  streaming.NewSoundCloud(""),
+			url:     "https://open.spotify.com/playlist/" + fakeSpotifyPlaylistID,
+			wantErr: helpers.ErrNotSoundCloudURL,
+		},
+		{
+			name:   "spotify drops locale and share parameters",
+			source: streaming.NewSpotify("", ""),
+			url:    "https://open.spotify.com/intl-de/playlist/" + fakeSpotifyPlaylistID + "?si=abc",
+			want:   "https://open.spotify.com/playlist/" + fakeSpotifyPlaylistID,
+		},
+		{
+			name:   "spotify uri",
+			source: streaming.NewSpotify("", ""),
+			url:    "spotify:playlist:" + fakeSpotifyPlaylistID,
+			want:   "https://open.spotify.com/playlist/" + fakeSpotifyPlaylistID,
+		},
+		{
+			name:    "spotify album",
+			source:  streaming.NewSpotify("", ""),
+			url:     "https://open.spotify.com/album/abc",
+			wantErr: helpers.ErrUnsupportedSpotifyURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.source.ResolveURL(tt.url)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResolveURL() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ResolveURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSoundCloudSource(t *testing.T) {
+	fake := newFakeSoundCloud(t)
+
+	var s streaming.Source = fake.client()
+
+	p, err := s.GetPlaylist(context.Background(), fake.URL+fakePlaylistPath)
+
+	if err != nil {
+		t.Fatalf("GetPlaylist() error = %v", err)
+	}
+
+	if p.Platform != streaming.PlatformSoundCloud || p.ExternalID != "1720019865" || p.SourceType != "playlist" || p.SearchUrl != fake.URL+fakePlaylistPath {
+		t.Errorf("GetPlaylist() playlist = %+v", p)
+	}
+
+	if len(p.Tracks) != 4 {
+		t.Fatalf("GetPlaylist() got %v tracks, want 4", len(p.Tracks))
+	}
+
+	// the playlist can be stored as it would be if it were got from SoundCloud directly
+	wantPlaylist, err := fake.client().GetSoundCloudPlaylist(context.Background(), fake.URL+fakePlaylistPath)
+
+	if err != nil {
+		t.Fatalf("GetSoundCloudPlaylist() error = %v", err)
+	}
+
+	wantPlaylist.SearchUrl = fake.URL + fakePlaylistPath
+
+	var gotPlaylist streaming.SoundCloudPlaylist
+
+	if err := gotPlaylist.LoadFromSource(p); err != nil {
+		t.Fatalf("LoadFromSource() error = %v", err)
+	}
+
+	if diff := cmp.Diff(wantPlaylist, gotPlaylist); diff != "" {
+		t.Errorf("LoadFromSource() mismatch (-want +got):\n%s", diff)
+	}
+
+	tracks, err := s.GetTracks(context.Background(), []string{"3", "4"})
+
+	if err != nil {
+		t.Fatalf("GetTracks() error = %v", err)
+	}
+
+	var got []streaming.SourceTrack
+	for _, track := range tracks {
+		got = append(got, streaming.SourceTrack{
+			Platform:         track.Platform,
+			ExternalID:       track.ExternalID,
+			Artists:          track.Artists,
+			PurchaseURL:      track.PurchaseURL,
+			PurchaseCategory: track.PurchaseCategory,
+			Downloadable:     track.Downloadable,
+		})
+	}
+
+	want := []streaming.SourceTrack{
+		{Platform: streaming.PlatformSoundCloud, ExternalID: "3", Artists: []string{"thirdartist"}, PurchaseCategory: streaming.PurchaseNone, Downloadable: true},
+		{Platform: streaming.PlatformSoundCloud, ExternalID: "4", Artists: []string{"Fourth"}, PurchaseCategory: streaming.PurchaseNone},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetTracks() mismatch (-want +got):\n%s", diff)
+	}
+
+	links := s.PurchaseLinks(p.Tracks[1])
+	if len(links) != 1 || links[0].URL != "https://bandcamp.com/cool-track" || links[0].Category != streaming.PurchaseStore {
+		t.Errorf("PurchaseLinks() = %v", links)
+	}
+
+	if links := s.PurchaseLinks(tracks[0]); len(links) != 0 {
+		t.Errorf("PurchaseLinks() = %v, want none", links)
+	}
+
+	dir := filepath.ToSlash(t.TempDir())
+
+	if _, err := s.DownloadTrack(context.Background(), tracks[1], dir, helpers.CollisionSuffix); !errors.Is(err, helpers.ErrTrackNotDownloadable) {
+		t.Errorf("DownloadTrack() error = %v, want %v", err, helpers.ErrTrackNotDownloadable)
+	}
+
+	path, err := s.DownloadTrack(context.Background(), tracks[0], dir, helpers.CollisionSuffix)
+
+	if err != nil {
+		t.Fatalf("DownloadTrack() error = %v", err)
+	}
+
+	if want := dir + "/track 3.wav"; path != want {
+		t.Errorf("DownloadTrack() = %v, want %v", path, want)
+	}
+}
+
+func TestSpotifySource(t *testing.T) {
+	f := newFakeSpotify(t)
+
+	var s streaming.Source = f.client()
+
+	p, err := s.GetPlaylist(context.Background(), "spotify:playlist:"+fakeSpotifyPlaylistID)
+
+	if err != nil {
+		t.Fatalf("GetPlaylist() error = %v", err)
+	}
+
+	if p.Platform != streaming.PlatformSpotify || p.ExternalID != fakeSpotifyPlaylistID || p.Owner != "Cool Man" || len(p.Tracks) != 2 {
+		t.Errorf("GetPlaylist() playlist = %+v", p)
+	}
+
+	tracks, err := s.GetTracks(context.Background(), []string{"7qiZfU4dY1lWllzX7mPBI3", "missing"})
+
+	if err != nil {
+		t.Fatalf("GetTracks() error = %v", err)
+	}
+
+	want := []streaming.SourceTrack{
+		{
+			Platform:     streaming.PlatformSpotify,
+			ExternalID:   "7qiZfU4dY1lWllzX7mPBI3",
+			Name:         "Late Night",
+			Artists:      []string{"Third Artist"},
+			Album:        "Late Night EP",
+			ISRC:         "USBBB2300002",
+			PermalinkUrl: "https://open.spotify.com/track/7qiZfU4dY1lWllzX7mPBI3",
+			Duration:     187.5,
+		},
+	}
+
+	if diff := cmp.Diff(want, tracks); diff != "" {
+		t.Errorf("GetTracks() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := s.DownloadTrack(context.Background(), tracks[0], t.TempDir(), helpers.CollisionSuffix); !errors.Is(err, helpers.ErrDownloadNotSupported) {
+		t.Errorf("DownloadTrack() error = %v, want %v", err, helpers.ErrDownloadNotSupported)
+	}
+}
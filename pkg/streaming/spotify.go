@@ -0,0 +1,662 @@
+package streaming
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Southclaws/fault"
+	"github.com/Southclaws/fault/fctx"
+	"github.com/Southclaws/fault/fmsg"
+	"github.com/billiem/seren-management/pkg/data"
+	"github.com/billiem/seren-management/pkg/helpers"
+)
+
+/*
+Provides the client used to get playlists from the Spotify Web API, authenticated with the
+client credentials flow so only public playlists can be read
+*/
+
+const (
+	DefaultSpotifyAPIURL       = "https://api.spotify.com/v1"
+	DefaultSpotifyAccountsURL  = "https://accounts.spotify.com"
+	DefaultSpotifyMaxRetries   = 3
+	DefaultSpotifyRetryBackoff = time.Second
+	DefaultSpotifyTimeout      = 30 * time.Second
+
+	spotifyTokenExpiryMargin = time.Minute // tokens are refreshed this long before they expire
+)
+
+/*
+Spotify is a client for the Spotify Web API
+
+NewSpotify should be used to get a client with the defaults set, APIURL and AccountsURL can be
+changed to point the client at a local server
+*/
+type Spotify struct {
+	ClientID     string
+	ClientSecret string
+
+	HTTPClient   *http.Client  // defaults to a client with DefaultSpotifyTimeout
+	APIURL       string        // defaults to DefaultSpotifyAPIURL
+	AccountsURL  string        // defaults to DefaultSpotifyAccountsURL
+	MaxRetries   int           // number of times a request is retried after a 429 or 5xx response
+	RetryBackoff time.Duration // wait before the first retry, doubled on each following retry
+
+	token *spotifyToken // shared between copies, as methods use value receivers
+}
+
+/*
+NewSpotify returns a Spotify client using the default settings
+*/
+func NewSpotify(clientID, clientSecret string) Spotify {
+	return Spotify{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		HTTPClient:   &http.Client{Timeout: DefaultSpotifyTimeout},
+		APIURL:       DefaultSpotifyAPIURL,
+		AccountsURL:  DefaultSpotifyAccountsURL,
+		MaxRetries:   DefaultSpotifyMaxRetries,
+		RetryBackoff: DefaultSpotifyRetryBackoff,
+		token:        &spotifyToken{},
+	}
+}
+
+/*
+spotifyToken holds the access token of a client, so every copy of a client shares one token
+*/
+type spotifyToken struct {
+	mu        sync.Mutex
+	value     string
+	expiresAt time.Time
+}
+
+func (s Spotify) httpClient() *http.Client {
+	if s.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return s.HTTPClient
+}
+
+func (s Spotify) apiURL() string {
+	if s.APIURL == "" {
+		return DefaultSpotifyAPIURL
+	}
+	return strings.TrimSuffix(s.APIURL, "/")
+}
+
+func (s Spotify) accountsURL() string {
+	if s.AccountsURL == "" {
+		return DefaultSpotifyAccountsURL
+	}
+	return strings.TrimSuffix(s.AccountsURL, "/")
+}
+
+/*
+accessToken returns the current access token, requesting a new one if there isn't one or it is
+about to expire. A token equal to rejected is never returned, so a token refused by the API is replaced
+*/
+func (s Spotify) accessToken(ctx context.Context, rejected string) (string, error) {
+
+	if s.ClientID == "" || s.ClientSecret == "" {
+		return "", helpers.ErrMissingSpotifyCredentials
+	}
+
+	if s.token == nil {
+		return "", fault.New("spotify client must be built with NewSpotify")
+	}
+
+	s.token.mu.Lock()
+	defer s.token.mu.Unlock()
+
+	if s.token.value != "" && s.token.value != rejected && time.Now().Before(s.token.expiresAt) {
+		return s.token.value, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.accountsURL()+"/api/token", strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return "", err
+	}
+
+	req.SetBasicAuth(s.ClientID, s.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient().Do(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s", helpers.ErrSpotifyAuthFailed, resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&token)
+
+	if err != nil {
+		return "", err
+	}
+
+	if token.AccessToken == "" {
+		return "", helpers.ErrSpotifyAuthFailed
+	}
+
+	s.token.value = token.AccessToken
+	s.token.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - spotifyTokenExpiryMargin)
+
+	return s.token.value, nil
+}
+
+/*
+get sends a GET request to a full Spotify API url with the access token, retrying with a backoff
+if the response is a 429 or 5xx, and once with a new token if the token is rejected. The body of
+a 200 response is decoded into v
+*/
+func (s Spotify) get(ctx context.Context, rawURL string, v any) error {
+	backoff := s.RetryBackoff
+	rejected := ""
+
+	for attempt := 0; ; attempt++ {
+		token, err := s.accessToken(ctx, rejected)
+
+		if err != nil {
+			return fault.Wrap(
+				err,
+				fmsg.With("error getting Spotify access token"),
+			)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := s.httpClient().Do(req)
+
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && rejected == "" {
+			resp.Body.Close()
+			rejected = token
+			attempt--
+			continue
+		}
+
+		if attempt >= s.MaxRetries || ctx.Err() != nil || (err == nil && !isRetryableStatus(resp.StatusCode)) {
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			return decodeSpotifyResponse(resp, v)
+		}
+
+		wait := backoff
+
+		if err == nil {
+			if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > 0 {
+				wait = retryAfter
+			}
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+	}
+}
+
+func decodeSpotifyResponse(resp *http.Response, v any) error {
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s", helpers.ErrUnexpectedStatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+/*
+IsSpotifyURL reports whether the URL is a Spotify web link or a Spotify URI
+*/
+func IsSpotifyURL(rawURL string) bool {
+	if strings.HasPrefix(rawURL, "spotify:") {
+		return true
+	}
+
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return false
+	}
+
+	return u.Hostname() == "open.spotify.com"
+}
+
+/*
+ParseSpotifyPlaylistID gets the ID of a playlist from a Spotify playlist link, i.e.
+https://open.spotify.com/playlist/{id}, or a Spotify URI, i.e. spotify:playlist:{id}
+*/
+func ParseSpotifyPlaylistID(rawURL string) (string, error) {
+
+	if strings.HasPrefix(rawURL, "spotify:") {
+		parts := strings.Split(rawURL, ":")
+		if len(parts) == 3 && parts[1] == "playlist" && parts[2] != "" {
+			return parts[2], nil
+		}
+		return "", helpers.ErrUnsupportedSpotifyURL
+	}
+
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	// links shared from some regions are prefixed with the locale, i.e. /intl-de/playlist/{id}
+	if len(segments) > 0 && strings.HasPrefix(segments[0], "intl-") {
+		segments = segments[1:]
+	}
+
+	if len(segments) != 2 || segments[0] != "playlist" || segments[1] == "" {
+		return "", helpers.ErrUnsupportedSpotifyURL
+	}
+
+	return segments[1], nil
+}
+
+type SpotifyPlaylist struct {
+	ExternalID   string
+	Name         string
+	Owner        string
+	SearchUrl    string // the url the playlist was added with
+	PermalinkUrl string
+	Tracks       []SpotifyTrack
+}
+
+/*
+LoadFromDB loads a SpotifyPlaylist from a data.SpotifyPlaylist and its tracks
+*/
+func (p *SpotifyPlaylist) LoadFromDB(dp data.SpotifyPlaylist, dt []data.SpotifyTrack) {
+	p.ExternalID = dp.ExternalID.String
+	p.Name = dp.Name.String
+	p.Owner = dp.Owner.String
+	p.SearchUrl = dp.SearchUrl.String
+	p.PermalinkUrl = dp.PermalinkUrl.String
+
+	p.Tracks = make([]SpotifyTrack, len(dt))
+	for i, t := range dt {
+		p.Tracks[i].LoadFromDB(t)
+	}
+}
+
+func (p SpotifyPlaylist) ToDB() (data.SpotifyPlaylist, []data.SpotifyTrack) {
+	dataP := data.SpotifyPlaylist{
+		ExternalID:   sql.NullString{Valid: true, String: p.ExternalID},
+		Name:         sql.NullString{Valid: true, String: p.Name},
+		Owner:        sql.NullString{Valid: true, String: p.Owner},
+		SearchUrl:    sql.NullString{Valid: true, String: p.SearchUrl},
+		PermalinkUrl: sql.NullString{Valid: true, String: p.PermalinkUrl},
+	}
+
+	dataTracks := make([]data.SpotifyTrack, len(p.Tracks))
+
+	for i, t := range p.Tracks {
+		dataTracks[i] = t.ToDB()
+	}
+
+	return dataP, dataTracks
+}
+
+type SpotifyTrack struct {
+	ExternalID          string
+	Name                string
+	PermalinkUrl        string
+	Artists             []string
+	Album               string
+	ISRC                string
+	Duration            float64 // in seconds
+	RemovedFromPlaylist bool
+}
+
+func (t SpotifyTrack) String() string {
+	return fmt.Sprintf(
+		"%s: %s - %s",
+		t.ExternalID,
+		strings.Join(t.Artists, ", "),
+		t.Name,
+	)
+}
+
+/*
+LoadFromDB loads a SpotifyTrack from a data.SpotifyTrack, artists are stored as a JSON array
+*/
+func (t *SpotifyTrack) LoadFromDB(dt data.SpotifyTrack) {
+	t.ExternalID = dt.ExternalID.String
+	t.Name = dt.Name.String
+	t.PermalinkUrl = dt.PermalinkUrl.String
+	t.Album = dt.Album.String
+	t.ISRC = dt.Isrc.String
+	t.Duration = dt.Duration.Float64
+	t.RemovedFromPlaylist = dt.RemovedFromPlaylist.Bool
+
+	t.Artists = nil
+	if dt.Artists.Valid && dt.Artists.String != "" {
+		// a malformed value is treated as no artists, it is overwritten the next time the track is saved
+		_ = json.Unmarshal([]byte(dt.Artists.String), &t.Artists)
+	}
+}
+
+func (t SpotifyTrack) ToDB() data.SpotifyTrack {
+	artists := t.Artists
+	if artists == nil {
+		artists = []string{}
+	}
+
+	// marshalling a []string can't fail
+	artistsJSON, _ := json.Marshal(artists)
+
+	return data.SpotifyTrack{
+		ExternalID:          sql.NullString{Valid: true, String: t.ExternalID},
+		Name:                sql.NullString{Valid: true, String: t.Name},
+		PermalinkUrl:        sql.NullString{Valid: true, String: t.PermalinkUrl},
+		Artists:             sql.NullString{Valid: true, String: string(artistsJSON)},
+		Album:               sql.NullString{Valid: true, String: t.Album},
+		Isrc:                sql.NullString{Valid: t.ISRC != "", String: t.ISRC},
+		Duration:            sql.NullFloat64{Valid: t.Duration > 0, Float64: t.Duration},
+		RemovedFromPlaylist: sql.NullBool{Valid: true, Bool: t.RemovedFromPlaylist},
+	}
+}
+
+/*
+Below are the parts of the Spotify API responses used when getting a playlist
+*/
+
+type spotifyPlaylistResponse struct {
+	ID           string              `json:"id"`
+	Name         string              `json:"name"`
+	Owner        spotifyOwner        `json:"owner"`
+	ExternalURLs spotifyExternalURLs `json:"external_urls"`
+	Tracks       spotifyTrackPage    `json:"tracks"`
+}
+
+type spotifyOwner struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+type spotifyExternalURLs struct {
+	Spotify string `json:"spotify"`
+}
+
+type spotifyTrackPage struct {
+	Items []spotifyPlaylistItem `json:"items"`
+	Next  string                `json:"next"`
+}
+
+type spotifyPlaylistItem struct {
+	Track *spotifyTrackResponse `json:"track"` // null for tracks which are no longer available
+}
+
+type spotifyTrackResponse struct {
+	ID           string              `json:"id"`
+	Name         string              `json:"name"`
+	Type         string              `json:"type"`
+	IsLocal      bool                `json:"is_local"`
+	DurationMS   int64               `json:"duration_ms"`
+	ExternalURLs spotifyExternalURLs `json:"external_urls"`
+	ExternalIDs  struct {
+		ISRC string `json:"isrc"`
+	} `json:"external_ids"`
+	Album struct {
+		Name string `json:"name"`
+	} `json:"album"`
+	Artists []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+}
+
+func (tr spotifyTrackResponse) toTrack() SpotifyTrack {
+	t := SpotifyTrack{
+		ExternalID:   tr.ID,
+		Name:         tr.Name,
+		PermalinkUrl: tr.ExternalURLs.Spotify,
+		Album:        tr.Album.Name,
+		ISRC:         tr.ExternalIDs.ISRC,
+		Duration:     float64(tr.DurationMS) / 1000,
+	}
+
+	for _, a := range tr.Artists {
+		t.Artists = append(t.Artists, a.Name)
+	}
+
+	return t
+}
+
+/*
+GetSpotifyPlaylist gets a playlist and every page of its tracks from Spotify
+
+Podcast episodes, local files and tracks which are no longer available are skipped, as they can't be matched
+*/
+func (s Spotify) GetSpotifyPlaylist(ctx context.Context, playlistURL string) (SpotifyPlaylist, error) {
+
+	id, err := ParseSpotifyPlaylistID(playlistURL)
+
+	if err != nil {
+		return SpotifyPlaylist{}, fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.With("Error parsing Spotify playlist URL"),
+		)
+	}
+
+	ctx = fctx.WithMeta(ctx, "playlist_external_id", id)
+
+	var resp spotifyPlaylistResponse
+
+	err = s.get(ctx, s.apiURL()+"/playlists/"+url.PathEscape(id), &resp)
+
+	if err != nil {
+		return SpotifyPlaylist{}, fault.Wrap(
+			err,
+			fctx.With(ctx),
+			fmsg.With("Error getting Spotify playlist"),
+		)
+	}
+
+	owner := resp.Owner.DisplayName
+	if owner == "" {
+		owner = resp.Owner.ID
+	}
+
+	p := SpotifyPlaylist{
+		ExternalID:   resp.ID,
+		Name:         resp.Name,
+		Owner:        owner,
+		SearchUrl:    playlistURL,
+		PermalinkUrl: resp.ExternalURLs.Spotify,
+	}
+
+	seen := map[string]bool{}
+	page := resp.Tracks
+
+	for {
+		for _, item := range page.Items {
+			tr := item.Track
+			if tr == nil || tr.ID == "" || tr.IsLocal || (tr.Type != "" && tr.Type != "track") || seen[tr.ID] {
+				continue
+			}
+			seen[tr.ID] = true
+			p.Tracks = append(p.Tracks, tr.toTrack())
+		}
+
+		if page.Next == "" {
+			break
+		}
+
+		next := page.Next
+		page = spotifyTrackPage{}
+
+		err = s.get(ctx, next, &page)
+
+		if err != nil {
+			return SpotifyPlaylist{}, fault.Wrap(
+				err,
+				fctx.With(ctx),
+				fmsg.With("Error getting next page of Spotify playlist tracks"),
+			)
+		}
+	}
+
+	return p, nil
+}
+
+/*
+Below implements Source for Spotify, tracks can't be downloaded from Spotify and it has no purchase links
+*/
+
+const spotifyMaxTracksPerRequest = 50
+
+func (s Spotify) Platform() Platform {
+	return PlatformSpotify
+}
+
+func (s Spotify) Matches(rawURL string) bool {
+	return IsSpotifyURL(rawURL)
+}
+
+/*
+ResolveURL checks the URL is a Spotify playlist and returns its link without the locale or share parameters
+*/
+func (s Spotify) ResolveURL(rawURL string) (string, error) {
+	if !s.Matches(rawURL) {
+		return "", helpers.ErrNotSpotifyURL
+	}
+
+	id, err := ParseSpotifyPlaylistID(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	return "https://open.spotify.com/playlist/" + id, nil
+}
+
+func (s Spotify) GetPlaylist(ctx context.Context, rawURL string) (SourcePlaylist, error) {
+	p, err := s.GetSpotifyPlaylist(ctx, rawURL)
+
+	if err != nil {
+		return SourcePlaylist{}, err
+	}
+
+	return p.ToSourcePlaylist(), nil
+}
+
+/*
+GetTracks gets tracks in batches of the most Spotify allows in one request
+*/
+func (s Spotify) GetTracks(ctx context.Context, externalIDs []string) ([]SourceTrack, error) {
+	tracks := []SourceTrack{}
+
+	for start := 0; start < len(externalIDs); start += spotifyMaxTracksPerRequest {
+		end := min(start+spotifyMaxTracksPerRequest, len(externalIDs))
+
+		var resp struct {
+			Tracks []*spotifyTrackResponse `json:"tracks"`
+		}
+
+		err := s.get(ctx, s.apiURL()+"/tracks?"+url.Values{
+			"ids": {strings.Join(externalIDs[start:end], ",")},
+		}.Encode(), &resp)
+
+		if err != nil {
+			return nil, fault.Wrap(
+				err,
+				fctx.With(ctx),
+				fmsg.With("Error getting Spotify tracks"),
+			)
+		}
+
+		for _, tr := range resp.Tracks {
+			// unknown IDs are returned as null
+			if tr == nil || tr.ID == "" {
+				continue
+			}
+			tracks = append(tracks, tr.toTrack().ToSourceTrack())
+		}
+	}
+
+	return tracks, nil
+}
+
+func (s Spotify) DownloadTrack(ctx context.Context, t SourceTrack, dirPath string, collision helpers.CollisionStrategy) (string, error) {
+	return "", helpers.ErrDownloadNotSupported
+}
+
+func (s Spotify) PurchaseLinks(t SourceTrack) []PurchaseLink {
+	return nil
+}
+
+/*
+ToSourcePlaylist converts the playlist to a SourcePlaylist
+*/
+func (p SpotifyPlaylist) ToSourcePlaylist() SourcePlaylist {
+	tracks := make([]SourceTrack, len(p.Tracks))
+	for i, t := range p.Tracks {
+		tracks[i] = t.ToSourceTrack()
+	}
+
+	return SourcePlaylist{
+		Platform:     PlatformSpotify,
+		ExternalID:   p.ExternalID,
+		SourceType:   "playlist",
+		Name:         p.Name,
+		Owner:        p.Owner,
+		SearchUrl:    p.SearchUrl,
+		PermalinkUrl: p.PermalinkUrl,
+		Tracks:       tracks,
+	}
+}
+
+/*
+ToSourceTrack converts the track to a SourceTrack
+*/
+func (t SpotifyTrack) ToSourceTrack() SourceTrack {
+	return SourceTrack{
+		Platform:            PlatformSpotify,
+		ExternalID:          t.ExternalID,
+		Name:                t.Name,
+		Artists:             t.Artists,
+		Album:               t.Album,
+		ISRC:                t.ISRC,
+		PermalinkUrl:        t.PermalinkUrl,
+		RemovedFromPlaylist: t.RemovedFromPlaylist,
+		Duration:            t.Duration,
+	}
+}
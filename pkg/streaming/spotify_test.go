@@ -0,0 +1,143 @@
+package streaming_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/billiem/seren-management/pkg/helpers"
+	"github.com/billiem/seren-management/pkg/streaming"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetSpotifyPlaylist(t *testing.T) {
+	f := newFakeSpotify(t)
+
+	url := "https://open.spotify.com/playlist/" + fakeSpotifyPlaylistID + "?si=abc123"
+
+	p, err := f.client().GetSpotifyPlaylist(context.Background(), url)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := streaming.SpotifyPlaylist{
+		ExternalID:   fakeSpotifyPlaylistID,
+		Name:         "Test Playlist",
+		Owner:        "Cool Man",
+		SearchUrl:    url,
+		PermalinkUrl: "https://open.spotify.com/playlist/" + fakeSpotifyPlaylistID,
+		Tracks: []streaming.SpotifyTrack{
+			{
+				ExternalID:   "4uLU6hMCjMI75M1A2tKUQC",
+				Name:         "Funky Song",
+				PermalinkUrl: "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC",
+				Artists:      []string{"Coolman", "Another Artist"},
+				Album:        "Funky Album",
+				ISRC:         "GBAAA2300001",
+				Duration:     215,
+			},
+			{
+				ExternalID:   "7qiZfU4dY1lWllzX7mPBI3",
+				Name:         "Late Night",
+				PermalinkUrl: "https://open.spotify.com/track/7qiZfU4dY1lWllzX7mPBI3",
+				Artists:      []string{"Third Artist"},
+				Album:        "Late Night EP",
+				ISRC:         "USBBB2300002",
+				Duration:     187.5,
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("GetSpotifyPlaylist() mismatch (-want +got):\n%s", diff)
+	}
+
+	if n := f.numTokenRequests(); n != 1 {
+		t.Errorf("got %v token requests, want 1 shared between pages", n)
+	}
+}
+
+func TestSpotifyTokenRefresh(t *testing.T) {
+	f := newFakeSpotify(t)
+	s := f.client()
+
+	url := "spotify:playlist:" + fakeSpotifyPlaylistID
+
+	if _, err := s.GetSpotifyPlaylist(context.Background(), url); err != nil {
+		t.Fatal(err)
+	}
+
+	f.expireToken()
+
+	if _, err := s.GetSpotifyPlaylist(context.Background(), url); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := f.numTokenRequests(); n != 2 {
+		t.Errorf("got %v token requests, want 2", n)
+	}
+}
+
+func TestSpotifyMissingCredentials(t *testing.T) {
+	f := newFakeSpotify(t)
+	s := f.client()
+	s.ClientSecret = ""
+
+	_, err := s.GetSpotifyPlaylist(context.Background(), "https://open.spotify.com/playlist/"+fakeSpotifyPlaylistID)
+
+	if !errors.Is(err, helpers.ErrMissingSpotifyCredentials) {
+		t.Errorf("got error %v, want %v", err, helpers.ErrMissingSpotifyCredentials)
+	}
+
+	if n := f.numTokenRequests(); n != 0 {
+		t.Errorf("got %v token requests, want 0", n)
+	}
+}
+
+func TestParseSpotifyPlaylistID(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M", want: "37i9dQZF1DXcBWIGoYBM5M"},
+		{url: "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc", want: "37i9dQZF1DXcBWIGoYBM5M"},
+		{url: "https://open.spotify.com/intl-de/playlist/37i9dQZF1DXcBWIGoYBM5M", want: "37i9dQZF1DXcBWIGoYBM5M"},
+		{url: "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M", want: "37i9dQZF1DXcBWIGoYBM5M"},
+		{url: "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC", wantErr: true},
+		{url: "https://open.spotify.com/playlist/", wantErr: true},
+		{url: "spotify:album:4uLU6hMCjMI75M1A2tKUQC", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := streaming.ParseSpotifyPlaylistID(tt.url)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSpotifyPlaylistID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseSpotifyPlaylistID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpotifyTrackDBRoundTrip(t *testing.T) {
+	want := streaming.SpotifyTrack{
+		ExternalID: "4uLU6hMCjMI75M1A2tKUQC",
+		Name:       "Funky Song",
+		Artists:    []string{"Coolman, Jr.", "Another Artist"},
+		ISRC:       "GBAAA2300001",
+		Duration:   215,
+	}
+
+	var got streaming.SpotifyTrack
+	got.LoadFromDB(want.ToDB())
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadFromDB(ToDB()) mismatch (-want +got):\n%s", diff)
+	}
+}